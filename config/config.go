@@ -21,13 +21,20 @@ import (
 var Conf = new(config)
 
 type config struct {
-	System     *SystemConfig    `mapstructure:"system" json:"system"`
-	Logs       *LogsConfig      `mapstructure:"logs" json:"logs"`
-	Mysql      *MysqlConfig     `mapstructure:"mysql" json:"mysql"`
-	Casbin     *CasbinConfig    `mapstructure:"casbin" json:"casbin"`
-	Jwt        *JwtConfig       `mapstructure:"jwt" json:"jwt"`
-	RateLimit  *RateLimitConfig `mapstructure:"rate-limit" json:"rateLimit"`
-	UploadFile *UploadFile      `mapstructure:"upload-file" json:"uploadFile"`
+	System       *SystemConfig       `mapstructure:"system" json:"system"`
+	Logs         *LogsConfig         `mapstructure:"logs" json:"logs"`
+	Database     *DatabaseConfig     `mapstructure:"database" json:"database"`
+	Mysql        *MysqlConfig        `mapstructure:"mysql" json:"mysql"`
+	Postgres     *PostgresConfig     `mapstructure:"postgres" json:"postgres"`
+	Sqlite       *SqliteConfig       `mapstructure:"sqlite" json:"sqlite"`
+	Casbin       *CasbinConfig       `mapstructure:"casbin" json:"casbin"`
+	Jwt          *JwtConfig          `mapstructure:"jwt" json:"jwt"`
+	RateLimit    *RateLimitConfig    `mapstructure:"rate-limit" json:"rateLimit"`
+	UploadFile   *UploadFile         `mapstructure:"upload-file" json:"uploadFile"`
+	Metrics      *MetricsConfig      `mapstructure:"metrics" json:"metrics"`
+	OperationLog *OperationLogConfig `mapstructure:"operation-log" json:"operationLog"`
+	GeoIP        *GeoIPConfig        `mapstructure:"geoip" json:"geoip"`
+	LoginGuard   *LoginGuardConfig   `mapstructure:"login-guard" json:"loginGuard"`
 }
 
 // 设置读取配置信息
@@ -68,17 +75,21 @@ func InitConfig() {
 }
 
 type SystemConfig struct {
-	Mode            string `mapstructure:"mode" json:"mode"`
-	UrlPathPrefix   string `mapstructure:"url-path-prefix" json:"urlPathPrefix"`
-	Port            int    `mapstructure:"port" json:"port"`
-	InitData        bool   `mapstructure:"init-data" json:"initData"`
-	RSAPublicKey    string `mapstructure:"rsa-public-key" json:"rsaPublicKey"`
-	RSAPrivateKey   string `mapstructure:"rsa-private-key" json:"rsaPrivateKey"`
-	RSAPublicBytes  []byte `mapstructure:"-" json:"-"`
-	RSAPrivateBytes []byte `mapstructure:"-" json:"-"`
-	CDNDomain       string `mapstructure:"cdn-domain" json:"CDNDomain"`
-	EnableMigrate   bool   `mapstructure:"enable-migrate" json:"enableMigrate"`
-	EnableOss       bool   `mapstructure:"enable-oss" json:"enableOss"`
+	Mode            string   `mapstructure:"mode" json:"mode"`
+	UrlPathPrefix   string   `mapstructure:"url-path-prefix" json:"urlPathPrefix"`
+	Port            int      `mapstructure:"port" json:"port"`
+	InitData        bool     `mapstructure:"init-data" json:"initData"`
+	SeedDirs        []string `mapstructure:"seed-dirs" json:"seedDirs"`
+	RSAPublicKey    string   `mapstructure:"rsa-public-key" json:"rsaPublicKey"`
+	RSAPrivateKey   string   `mapstructure:"rsa-private-key" json:"rsaPrivateKey"`
+	RSAPublicBytes  []byte   `mapstructure:"-" json:"-"`
+	RSAPrivateBytes []byte   `mapstructure:"-" json:"-"`
+	CDNDomain       string   `mapstructure:"cdn-domain" json:"CDNDomain"`
+	EnableMigrate   bool     `mapstructure:"enable-migrate" json:"enableMigrate"`
+	ShutdownDelay   int      `mapstructure:"shutdown-delay" json:"shutdownDelay"`
+	ApiSync         string   `mapstructure:"api-sync" json:"apiSync"`
+	TrustedProxies  []string `mapstructure:"trusted-proxies" json:"trustedProxies"`
+	EnableOss       bool     `mapstructure:"enable-oss" json:"enableOss"` // 已废弃, 仅在未配置upload-file.driver时生效
 }
 
 type LogsConfig struct {
@@ -90,6 +101,11 @@ type LogsConfig struct {
 	Compress   bool          `mapstructure:"compress" json:"compress"`
 }
 
+type DatabaseConfig struct {
+	Driver       string `mapstructure:"driver" json:"driver"`
+	QueryTimeout int    `mapstructure:"query-timeout" json:"queryTimeout"`
+}
+
 type MysqlConfig struct {
 	Username  string `mapstructure:"username" json:"username"`
 	Password  string `mapstructure:"password" json:"password"`
@@ -102,8 +118,26 @@ type MysqlConfig struct {
 	Collation string `mapstructure:"collation" json:"collation"`
 }
 
+type PostgresConfig struct {
+	Username string `mapstructure:"username" json:"username"`
+	Password string `mapstructure:"password" json:"password"`
+	Database string `mapstructure:"database" json:"database"`
+	Host     string `mapstructure:"host" json:"host"`
+	Port     int    `mapstructure:"port" json:"port"`
+	SSLMode  string `mapstructure:"ssl-mode" json:"sslMode"`
+	TimeZone string `mapstructure:"time-zone" json:"timeZone"`
+	LogMode  bool   `mapstructure:"log-mode" json:"logMode"`
+}
+
+type SqliteConfig struct {
+	Path    string `mapstructure:"path" json:"path"`
+	Query   string `mapstructure:"query" json:"query"`
+	LogMode bool   `mapstructure:"log-mode" json:"logMode"`
+}
+
 type CasbinConfig struct {
-	ModelPath string `mapstructure:"model-path" json:"modelPath"`
+	ModelPath     string `mapstructure:"model-path" json:"modelPath"`
+	WatchInterval int    `mapstructure:"watch-interval" json:"watchInterval"`
 }
 
 type JwtConfig struct {
@@ -111,6 +145,8 @@ type JwtConfig struct {
 	Key        string `mapstructure:"key" json:"key"`
 	Timeout    int    `mapstructure:"timeout" json:"timeout"`
 	MaxRefresh int    `mapstructure:"max-refresh" json:"maxRefresh"`
+	// 两步验证challenge的有效期, 分钟
+	TotpChallengeTimeout int `mapstructure:"totp-challenge-timeout" json:"totpChallengeTimeout"`
 }
 
 type RateLimitConfig struct {
@@ -119,8 +155,47 @@ type RateLimitConfig struct {
 }
 
 type UploadFile struct {
+	Driver    string `mapstructure:"driver" json:"driver"`
 	Accesskey string `mapstructure:"access-key" json:"accesskey"`
 	Secretkey string `mapstructure:"secret-key" json:"secretkey"`
 	Bucket    string `mapstructure:"bucket" json:"bucket"`
 	Endpoint  string `mapstructure:"endpoint" json:"endpoint"`
+	Region    string `mapstructure:"region" json:"region"`
+	LocalPath string `mapstructure:"local-path" json:"localPath"`
+	LocalURL  string `mapstructure:"local-url" json:"localURL"`
+}
+
+type MetricsConfig struct {
+	Enable   bool     `mapstructure:"enable" json:"enable"`
+	Path     string   `mapstructure:"path" json:"path"`
+	Token    string   `mapstructure:"token" json:"token"`
+	AllowIPs []string `mapstructure:"allow-ips" json:"allowIPs"`
+}
+
+type OperationLogConfig struct {
+	BatchSize         int      `mapstructure:"batch-size" json:"batchSize"`
+	FlushInterval     int      `mapstructure:"flush-interval" json:"flushInterval"`
+	QueueSize         int      `mapstructure:"queue-size" json:"queueSize"`
+	Overflow          string   `mapstructure:"overflow" json:"overflow"`
+	SpillPath         string   `mapstructure:"spill-path" json:"spillPath"`
+	RequestBodyLimit  int      `mapstructure:"request-body-limit" json:"requestBodyLimit"`
+	ResponseBodyLimit int      `mapstructure:"response-body-limit" json:"responseBodyLimit"`
+	MaskFields        []string `mapstructure:"mask-fields" json:"maskFields"`
+	RetentionDays     int      `mapstructure:"retention-days" json:"retentionDays"`
+	Archive           bool     `mapstructure:"archive" json:"archive"`
+	ArchivePath       string   `mapstructure:"archive-path" json:"archivePath"`
+}
+
+type GeoIPConfig struct {
+	Driver   string `mapstructure:"driver" json:"driver"`
+	Path     string `mapstructure:"path" json:"path"`
+	Language string `mapstructure:"language" json:"language"`
+}
+
+type LoginGuardConfig struct {
+	MaxAttempts     int `mapstructure:"max-attempts" json:"maxAttempts"`
+	IPMaxAttempts   int `mapstructure:"ip-max-attempts" json:"ipMaxAttempts"`
+	LockDuration    int `mapstructure:"lock-duration" json:"lockDuration"`
+	MaxLockDuration int `mapstructure:"max-lock-duration" json:"maxLockDuration"`
+	Window          int `mapstructure:"window" json:"window"`
 }
@@ -0,0 +1,180 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"golang.org/x/term"
+	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/pkg/util"
+	"io"
+	"os"
+	"strings"
+)
+
+// 管理员密码的环境变量, 用于在脚本中创建管理员或重置密码
+const adminPasswordEnv = "GOTRIBE_ADMIN_PASSWORD"
+
+// 管理员维护: admin create|reset-password
+func adminCommand(args []string) error {
+	action, args, err := subAction("admin", args, "create", "reset-password")
+	if err != nil {
+		return err
+	}
+	if action == "create" {
+		return createAdmin(args)
+	}
+	return resetAdminPassword(args)
+}
+
+// 创建管理员
+func createAdmin(args []string) error {
+	flags := flag.NewFlagSet("admin create", flag.ExitOnError)
+	username := flags.String("username", "", "用户名(必填)")
+	mobile := flags.String("mobile", "", "手机号(必填)")
+	nickname := flags.String("nickname", "", "昵称")
+	roles := flags.String("roles", "admin", "角色关键字, 多个用逗号分隔")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *username == "" || *mobile == "" {
+		return errors.New("用户名和手机号不能为空")
+	}
+	password, err := readAdminPassword()
+	if err != nil {
+		return err
+	}
+
+	c := setup(false)
+
+	var roleList []*model.Role
+	keywords := strings.Split(*roles, ",")
+	if err := c.DB.Where("keyword IN (?)", keywords).Find(&roleList).Error; err != nil {
+		return err
+	}
+	if len(roleList) != len(keywords) {
+		return fmt.Errorf("角色不存在: %s", *roles)
+	}
+
+	admin := model.Admin{
+		Username: *username,
+		Password: util.GenPasswd(password),
+		Mobile:   *mobile,
+		Nickname: nickname,
+		Status:   1,
+		Creator:  "命令行",
+		Roles:    roleList,
+	}
+	if err := c.Repositories.Admin.CreateAdmin(context.Background(), &admin); err != nil {
+		return fmt.Errorf("创建管理员失败: %v", err)
+	}
+	fmt.Printf("创建管理员%s成功, ID: %d\n", admin.Username, admin.ID)
+	return nil
+}
+
+// 重置管理员密码, 同时解除禁用与登录锁定, 用于找回被锁定的超级管理员
+// 指定-reset-totp时同时关闭两步验证, 用于丢失验证器的管理员重新绑定
+func resetAdminPassword(args []string) error {
+	flags := flag.NewFlagSet("admin reset-password", flag.ExitOnError)
+	username := flags.String("username", "", "用户名(必填)")
+	resetTotp := flags.Bool("reset-totp", false, "同时关闭两步验证并清除密钥与恢复码")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *username == "" {
+		return errors.New("用户名不能为空")
+	}
+	password, err := readAdminPassword()
+	if err != nil {
+		return err
+	}
+
+	c := setup(false)
+
+	var admin model.Admin
+	if err := c.DB.Where("username = ?", *username).First(&admin).Error; err != nil {
+		return fmt.Errorf("未获取到用户名为%s的管理员: %v", *username, err)
+	}
+	err = c.Repositories.Admin.ChangePwd(context.Background(), admin.Username, util.GenPasswd(password))
+	if err != nil {
+		return fmt.Errorf("重置密码失败: %v", err)
+	}
+	if admin.Status != 1 {
+		if err := c.DB.Model(&admin).Update("status", 1).Error; err != nil {
+			return fmt.Errorf("重置密码成功, 解除禁用失败: %v", err)
+		}
+	}
+	if err := c.LoginGuard.Unlock(context.Background(), admin.Username); err != nil {
+		return fmt.Errorf("重置密码成功, 解除登录锁定失败: %v", err)
+	}
+	if *resetTotp {
+		admin.TotpSecret = ""
+		admin.TotpEnabled = false
+		admin.TotpRecoveryCodes = ""
+		admin.TotpLastStep = 0
+		if err := c.Repositories.Admin.UpdateAdminTotp(context.Background(), &admin); err != nil {
+			return fmt.Errorf("重置密码成功, 关闭两步验证失败: %v", err)
+		}
+		fmt.Printf("已关闭管理员%s的两步验证\n", admin.Username)
+	}
+	fmt.Printf("重置管理员%s密码成功\n", admin.Username)
+	return nil
+}
+
+// 读取管理员密码, 不通过命令行参数传递, 避免密码出现在进程列表与shell历史中
+// 优先读取环境变量, 其次在终端中提示输入(不回显), 否则从标准输入读取第一行
+func readAdminPassword() (string, error) {
+	password, ok := os.LookupEnv(adminPasswordEnv)
+	if !ok {
+		var err error
+		if term.IsTerminal(int(os.Stdin.Fd())) {
+			password, err = promptPassword()
+		} else {
+			password, err = readLine(os.Stdin)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	if len(password) < 6 {
+		return "", errors.New("密码至少6位")
+	}
+	return password, nil
+}
+
+// 在终端中输入两次密码
+func promptPassword() (string, error) {
+	fd := int(os.Stdin.Fd())
+	fmt.Fprint(os.Stderr, "密码: ")
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("读取密码失败: %v", err)
+	}
+	fmt.Fprint(os.Stderr, "确认密码: ")
+	confirm, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("读取密码失败: %v", err)
+	}
+	if string(password) != string(confirm) {
+		return "", errors.New("两次输入的密码不一致")
+	}
+	return string(password), nil
+}
+
+// 从标准输入读取一行密码
+func readLine(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("从标准输入读取密码失败: %v", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package cmd
+
+import (
+	"context"
+	"embed"
+	"flag"
+	"fmt"
+	"gotribe-admin/internal/app/routes"
+)
+
+// 接口表维护: api sync
+func apiCommand(fs embed.FS, args []string) error {
+	_, args, err := subAction("api", args, "sync")
+	if err != nil {
+		return err
+	}
+	return syncApis(fs, args)
+}
+
+// 根据已注册路由同步接口表
+// 1. 登记缺少的接口, 所属类别与说明取自初始数据
+// 2. 输出没有对应路由的接口与策略, 指定-prune时删除
+func syncApis(fs embed.FS, args []string) error {
+	flags := flag.NewFlagSet("api sync", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "只输出差异, 不修改接口表")
+	prune := flags.Bool("prune", false, "删除没有对应路由的接口与策略")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	c := setup(true)
+	r := routes.InitRoutes(fs, c)
+
+	ctx := context.Background()
+	report, err := routes.DiffApis(ctx, r, c)
+	if err != nil {
+		return err
+	}
+	for _, api := range report.Missing {
+		fmt.Printf("+ %s %s [%s] %s\n", api.Method, api.Path, api.Category, api.Desc)
+	}
+	for _, api := range report.Stale {
+		fmt.Printf("- %s %s (ID: %d)\n", api.Method, api.Path, api.ID)
+	}
+	for _, policy := range report.StalePolicies {
+		fmt.Printf("- %v\n", policy)
+	}
+	if *dryRun || report.Empty() || (len(report.Missing) == 0 && !*prune) {
+		fmt.Printf("缺少%d个接口, 多余%d个接口、%d条策略(未修改)\n", len(report.Missing), len(report.Stale), len(report.StalePolicies))
+		return nil
+	}
+
+	if err := routes.ApplyApiSync(ctx, c, report, "命令行", *prune); err != nil {
+		return err
+	}
+	if *prune {
+		fmt.Printf("登记%d个接口, 删除%d个接口、%d条策略\n", len(report.Missing), len(report.Stale), len(report.StalePolicies))
+	} else {
+		fmt.Printf("登记%d个接口, 多余%d个接口、%d条策略(未删除, 使用-prune删除)\n", len(report.Missing), len(report.Stale), len(report.StalePolicies))
+	}
+	return nil
+}
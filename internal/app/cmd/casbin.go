@@ -0,0 +1,97 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"gotribe-admin/internal/pkg/model"
+)
+
+// 权限策略维护: casbin sync
+func casbinCommand(args []string) error {
+	_, args, err := subAction("casbin", args, "sync")
+	if err != nil {
+		return err
+	}
+	return syncCasbin(args)
+}
+
+// 同步角色接口权限策略
+// 1. 为指定角色补齐api表中的全部接口权限
+// 2. 删除已不存在的角色或接口对应的策略
+func syncCasbin(args []string) error {
+	flags := flag.NewFlagSet("casbin sync", flag.ExitOnError)
+	role := flags.String("role", "admin", "拥有全部接口权限的角色关键字, 为空时不补齐")
+	dryRun := flags.Bool("dry-run", false, "只输出差异, 不修改策略")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	c := setup(true)
+
+	var roles []model.Role
+	if err := c.DB.Find(&roles).Error; err != nil {
+		return err
+	}
+	var apis []model.Api
+	if err := c.DB.Find(&apis).Error; err != nil {
+		return err
+	}
+	roleSet := make(map[string]bool, len(roles))
+	for _, r := range roles {
+		roleSet[r.Keyword] = true
+	}
+	apiSet := make(map[string]bool, len(apis))
+	for _, api := range apis {
+		apiSet[api.Method+" "+api.Path] = true
+	}
+	if *role != "" && !roleSet[*role] {
+		return fmt.Errorf("角色不存在: %s", *role)
+	}
+
+	e := c.Enforcer
+	// 需要补齐的策略
+	var addPolicies [][]string
+	if *role != "" {
+		for _, api := range apis {
+			if !e.HasPolicy(*role, api.Path, api.Method) {
+				addPolicies = append(addPolicies, []string{*role, api.Path, api.Method})
+			}
+		}
+	}
+	// 需要删除的策略
+	var rmPolicies [][]string
+	for _, p := range e.GetPolicy() {
+		if len(p) < 3 || !roleSet[p[0]] || !apiSet[p[2]+" "+p[1]] {
+			rmPolicies = append(rmPolicies, p)
+		}
+	}
+
+	for _, p := range addPolicies {
+		fmt.Printf("+ %v\n", p)
+	}
+	for _, p := range rmPolicies {
+		fmt.Printf("- %v\n", p)
+	}
+	if *dryRun || (len(addPolicies) == 0 && len(rmPolicies) == 0) {
+		fmt.Printf("新增%d条, 删除%d条策略(未修改)\n", len(addPolicies), len(rmPolicies))
+		return nil
+	}
+
+	if len(addPolicies) > 0 {
+		if _, err := e.AddPolicies(addPolicies); err != nil {
+			return fmt.Errorf("新增策略失败: %v", err)
+		}
+	}
+	if len(rmPolicies) > 0 {
+		if _, err := e.RemovePolicies(rmPolicies); err != nil {
+			return fmt.Errorf("删除策略失败: %v", err)
+		}
+	}
+	fmt.Printf("新增%d条, 删除%d条策略\n", len(addPolicies), len(rmPolicies))
+	return nil
+}
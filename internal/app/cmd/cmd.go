@@ -0,0 +1,92 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+// Package cmd 命令行子命令
+package cmd
+
+import (
+	"embed"
+	"fmt"
+	"github.com/casbin/casbin/v2"
+	"gotribe-admin/config"
+	"gotribe-admin/internal/app"
+	"gotribe-admin/internal/pkg/common"
+)
+
+const usage = `用法: gotribe-admin <命令> [参数]
+
+命令:
+  serve                               启动HTTP服务(默认)
+  migrate up|down [步数]|status        执行、回滚数据库迁移或查看迁移状态
+  seed                                写入初始数据(角色、菜单、接口、权限及默认管理员)
+  admin create [参数]                  创建管理员
+  admin reset-password [参数]          重置管理员密码并解除禁用
+  casbin sync [参数]                   同步角色接口权限策略
+  api sync [参数]                      根据已注册路由同步接口表
+  config validate [参数]               校验配置文件
+  gen -f 实体定义文件 [参数]            根据实体定义生成增删改查模块代码
+
+使用 gotribe-admin <命令> -h 查看命令参数`
+
+// 执行子命令, 未指定子命令时启动HTTP服务
+func Execute(fs embed.FS, args []string) error {
+	name := "serve"
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	switch name {
+	case "serve":
+		return serve(fs, args)
+	case "migrate":
+		return migrateCommand(args)
+	case "seed":
+		return seedCommand(args)
+	case "admin":
+		return adminCommand(args)
+	case "casbin":
+		return casbinCommand(args)
+	case "api":
+		return apiCommand(fs, args)
+	case "config":
+		return configCommand(args)
+	case "gen":
+		return genCommand(args)
+	case "help", "-h", "--help":
+		fmt.Println(usage)
+		return nil
+	default:
+		fmt.Println(usage)
+		return fmt.Errorf("未知命令: %s", name)
+	}
+}
+
+// 加载配置并初始化日志、数据库, withCasbin为true时同时初始化casbin, 返回组装好的依赖容器
+func setup(withCasbin bool) *app.Container {
+	// 加载配置文件到全局配置结构体
+	config.InitConfig()
+	// 初始化日志
+	common.InitLogger()
+	// 初始化数据库(mysql/postgres/sqlite)
+	db := common.InitDB()
+	var enforcer *casbin.SyncedCachedEnforcer
+	if withCasbin {
+		// 初始化casbin策略管理器
+		enforcer = common.InitCasbinEnforcer(db)
+	}
+	return app.NewContainer(db, enforcer, common.Log)
+}
+
+// 取子命令的动作名, 如 admin create 中的 create
+func subAction(command string, args []string, actions ...string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, fmt.Errorf("缺少%s子命令, 可选: %v", command, actions)
+	}
+	for _, action := range actions {
+		if args[0] == action {
+			return action, args[1:], nil
+		}
+	}
+	return "", nil, fmt.Errorf("未知的%s子命令: %s, 可选: %v", command, args[0], actions)
+}
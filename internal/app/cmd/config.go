@@ -0,0 +1,176 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"gotribe-admin/config"
+	"gotribe-admin/internal/app/routes"
+	"gotribe-admin/internal/pkg/common"
+	"gotribe-admin/internal/pkg/geoip"
+	"gotribe-admin/internal/pkg/oplog"
+	"gotribe-admin/pkg/util"
+	"gotribe-admin/pkg/util/upload"
+	"os"
+	"strings"
+)
+
+// 配置维护: config validate
+func configCommand(args []string) error {
+	_, args, err := subAction("config", args, "validate")
+	if err != nil {
+		return err
+	}
+	return validateConfig(args)
+}
+
+// 校验配置文件, 输出全部问题
+func validateConfig(args []string) (err error) {
+	flags := flag.NewFlagSet("config validate", flag.ExitOnError)
+	ping := flags.Bool("ping", false, "同时尝试连接数据库")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	// 配置文件不存在或格式错误时InitConfig会panic
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	config.InitConfig()
+
+	problems := configProblems()
+	if len(problems) == 0 && *ping {
+		common.InitLogger()
+		sqlDB, err := common.InitDB().DB()
+		if err == nil {
+			err = sqlDB.Ping()
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("数据库连接失败: %v", err))
+		}
+	}
+
+	if len(problems) > 0 {
+		for _, p := range problems {
+			fmt.Println("✗", p)
+		}
+		return errors.New(fmt.Sprintf("配置校验未通过, 共%d个问题", len(problems)))
+	}
+	fmt.Println("配置校验通过")
+	return nil
+}
+
+// 逐项检查配置
+func configProblems() []string {
+	var problems []string
+	conf := config.Conf
+	if conf.System == nil || conf.Logs == nil || conf.Jwt == nil || conf.Casbin == nil ||
+		conf.RateLimit == nil || conf.UploadFile == nil {
+		return []string{"缺少system/logs/jwt/casbin/rate-limit/upload-file配置段"}
+	}
+
+	if conf.System.Port <= 0 || conf.System.Port > 65535 {
+		problems = append(problems, fmt.Sprintf("system.port无效: %d", conf.System.Port))
+	}
+	switch conf.System.ApiSync {
+	case "", routes.ApiSyncModeOff, routes.ApiSyncModeReport, routes.ApiSyncModeInsert:
+	default:
+		problems = append(problems, fmt.Sprintf("system.api-sync无效: %s", conf.System.ApiSync))
+	}
+
+	// rsa密钥需能正常加解密
+	testData := "gotribe"
+	encrypted, err := util.RSAEncrypt([]byte(testData), conf.System.RSAPublicBytes)
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("system.rsa-public-key无效: %v", err))
+	} else if decrypted, err := util.RSADecrypt(encrypted, conf.System.RSAPrivateBytes); err != nil {
+		problems = append(problems, fmt.Sprintf("system.rsa-private-key无效: %v", err))
+	} else if string(decrypted) != testData {
+		problems = append(problems, "system.rsa-public-key与rsa-private-key不匹配")
+	}
+
+	switch common.DBDriver() {
+	case common.DriverMySQL:
+		if conf.Mysql == nil || conf.Mysql.Host == "" || conf.Mysql.Database == "" || conf.Mysql.Username == "" {
+			problems = append(problems, "mysql.host/database/username不能为空")
+		}
+	case common.DriverPostgres:
+		if conf.Postgres == nil || conf.Postgres.Host == "" || conf.Postgres.Database == "" || conf.Postgres.Username == "" {
+			problems = append(problems, "postgres.host/database/username不能为空")
+		}
+	case common.DriverSQLite:
+		if conf.Sqlite == nil || conf.Sqlite.Path == "" {
+			problems = append(problems, "sqlite.path不能为空")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("database.driver不支持: %s", common.DBDriver()))
+	}
+	if conf.Database != nil && conf.Database.QueryTimeout < 0 {
+		problems = append(problems, "database.query-timeout不能小于0")
+	}
+
+	if _, err := os.Stat(conf.Casbin.ModelPath); err != nil {
+		problems = append(problems, fmt.Sprintf("casbin.model-path无法读取: %v", err))
+	}
+
+	if strings.TrimSpace(conf.Jwt.Key) == "" {
+		problems = append(problems, "jwt.key不能为空")
+	}
+	if conf.Jwt.Timeout <= 0 {
+		problems = append(problems, "jwt.timeout必须大于0")
+	}
+
+	if conf.RateLimit.FillInterval <= 0 || conf.RateLimit.Capacity <= 0 {
+		problems = append(problems, "rate-limit.fill-interval与capacity必须大于0")
+	}
+
+	if conf.Metrics != nil && conf.Metrics.Enable && conf.Metrics.Token == "" && len(conf.Metrics.AllowIPs) == 0 {
+		problems = append(problems, "metrics开启时token与allow-ips至少配置一项")
+	}
+
+	if oplogConf := conf.OperationLog; oplogConf != nil {
+		if oplogConf.BatchSize < 0 || oplogConf.FlushInterval < 0 || oplogConf.QueueSize < 0 {
+			problems = append(problems, "operation-log.batch-size/flush-interval/queue-size不能小于0")
+		}
+		if oplogConf.RetentionDays < 0 {
+			problems = append(problems, "operation-log.retention-days不能小于0")
+		}
+		if oplogConf.RequestBodyLimit > 65535 || oplogConf.ResponseBodyLimit > 65535 {
+			problems = append(problems, "operation-log.request-body-limit/response-body-limit不能大于65535")
+		}
+		if oplogConf.Overflow != "" && oplogConf.Overflow != oplog.OverflowDrop && oplogConf.Overflow != oplog.OverflowSpill {
+			problems = append(problems, fmt.Sprintf("operation-log.overflow不支持: %s", oplogConf.Overflow))
+		}
+	}
+
+	if geoConf := conf.GeoIP; geoConf != nil && geoConf.Driver != "" {
+		if geoConf.Driver != geoip.DriverIP2Region && geoConf.Driver != geoip.DriverMMDB {
+			problems = append(problems, fmt.Sprintf("geoip.driver不支持: %s", geoConf.Driver))
+		} else if _, err := os.Stat(geoConf.Path); err != nil {
+			problems = append(problems, fmt.Sprintf("geoip.path无法读取: %v", err))
+		}
+	}
+
+	uploadConf := conf.UploadFile
+	switch driver := common.UploadDriver(); driver {
+	case upload.DriverLocal:
+	case upload.DriverOSS, upload.DriverQiniu, upload.DriverS3:
+		if uploadConf.Accesskey == "" || uploadConf.Secretkey == "" || uploadConf.Bucket == "" {
+			problems = append(problems, fmt.Sprintf("upload-file.access-key/secret-key/bucket不能为空(%s)", driver))
+		}
+		if driver != upload.DriverQiniu && uploadConf.Endpoint == "" {
+			problems = append(problems, fmt.Sprintf("upload-file.endpoint不能为空(%s)", driver))
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("upload-file.driver不支持: %s", driver))
+	}
+
+	return problems
+}
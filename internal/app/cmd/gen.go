@@ -0,0 +1,55 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"gotribe-admin/internal/pkg/gen"
+)
+
+// 根据实体定义生成增删改查模块: gen -f entity.yml [-root .] [-force] [-dry-run]
+func genCommand(args []string) error {
+	flags := flag.NewFlagSet("gen", flag.ExitOnError)
+	file := flags.String("f", "", "实体定义文件(yaml), 格式见 docs/gen/example.yml")
+	root := flags.String("root", ".", "项目根目录")
+	force := flags.Bool("force", false, "覆盖已存在的文件")
+	dryRun := flags.Bool("dry-run", false, "只打印将要生成的文件, 不写入磁盘")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New("缺少实体定义文件, 使用 -f 指定")
+	}
+
+	entity, err := gen.Load(*file)
+	if err != nil {
+		return err
+	}
+	files, err := gen.Generate(entity, *root)
+	if err != nil {
+		return err
+	}
+	if !*dryRun {
+		if err := gen.Write(*root, files, *force); err != nil {
+			return err
+		}
+	}
+	for _, f := range files {
+		action := "新增"
+		if f.Exists {
+			action = "修改"
+		}
+		fmt.Printf("%s %s\n", action, f.Path)
+	}
+	if *dryRun {
+		fmt.Println("dry-run模式, 未写入任何文件")
+		return nil
+	}
+	fmt.Println("生成完成, 执行 gotribe-admin migrate up 创建数据表, gotribe-admin seed 写入菜单与接口权限")
+	return nil
+}
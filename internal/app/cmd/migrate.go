@@ -0,0 +1,62 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package cmd
+
+import (
+	"fmt"
+	"gotribe-admin/internal/pkg/model/migrate"
+	"gotribe-admin/pkg/api/known"
+	"strconv"
+)
+
+// 数据库迁移: migrate up|down [步数]|status
+func migrateCommand(args []string) error {
+	action, args, err := subAction("migrate", args, "up", "down", "status")
+	if err != nil {
+		return err
+	}
+
+	c := setup(false)
+
+	switch action {
+	case "up":
+		done, err := migrate.Up(c.DB)
+		for _, m := range done {
+			fmt.Printf("已执行迁移 %04d %s\n", m.Version, m.Description)
+		}
+		if err == nil && len(done) == 0 {
+			fmt.Println("没有需要执行的迁移")
+		}
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 0 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				return fmt.Errorf("回滚步数必须为正整数: %s", args[0])
+			}
+			steps = n
+		}
+		done, err := migrate.Down(c.DB, steps)
+		for _, m := range done {
+			fmt.Printf("已回滚迁移 %04d %s\n", m.Version, m.Description)
+		}
+		return err
+	default:
+		list, err := migrate.Status(c.DB)
+		if err != nil {
+			return err
+		}
+		for _, s := range list {
+			appliedAt := "未执行"
+			if s.Applied {
+				appliedAt = s.AppliedAt.Format(known.TIME_FORMAT)
+			}
+			fmt.Printf("%04d  %-20s  %s\n", s.Version, appliedAt, s.Description)
+		}
+		return nil
+	}
+}
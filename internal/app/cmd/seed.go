@@ -0,0 +1,27 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"gotribe-admin/internal/pkg/seed"
+)
+
+// 写入初始数据, 不受system.init-data配置影响
+func seedCommand(args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	c := setup(true)
+	if err := seed.Run(c.DB, c.Enforcer); err != nil {
+		return err
+	}
+	fmt.Println("初始数据写入完成")
+	return nil
+}
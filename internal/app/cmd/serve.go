@@ -0,0 +1,123 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package cmd
+
+import (
+	"context"
+	"embed"
+	"flag"
+	"fmt"
+	"github.com/fatih/color"
+	"gotribe-admin/config"
+	"gotribe-admin/internal/app/jobs"
+	"gotribe-admin/internal/app/routes"
+	"gotribe-admin/internal/pkg/common"
+	"gotribe-admin/internal/pkg/seed"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// 启动HTTP服务
+func serve(fs embed.FS, args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	c := setup(true)
+
+	// 执行未应用的数据库迁移
+	common.AutoMigrate(c.DB)
+
+	// 初始化Validator数据校验
+	common.InitValidate()
+
+	// 写入初始数据
+	seed.InitData(c.DB, c.Enforcer)
+
+	// 初始化定时任务
+	cron := jobs.InitCron(c)
+	cron.Start()
+	defer cron.Stop()
+
+	// 操作日志中间件只将日志放入内存队列, 由写入器在后台按批写入数据库
+	c.OperationLogs.Start()
+
+	// 注册所有路由
+	r := routes.InitRoutes(fs, c)
+
+	// 比较已注册路由与接口表
+	routes.SyncApisOnStartup(r, c)
+
+	host := "localhost"
+	port := config.Conf.System.Port
+
+	srv := &http.Server{
+		Addr:    fmt.Sprintf("%s:%d", host, port),
+		Handler: r,
+	}
+
+	// Initializing the server in a goroutine so that
+	// it won't block the graceful shutdown handling below
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			c.Log.Fatalf("listen: %s\n", err)
+		}
+	}()
+
+	colorFg := color.New(color.FgCyan, color.Bold)
+	colorFg.Println(`
+	░██████╗░░█████╗░████████╗██████╗░██╗██████╗░███████╗
+	██╔════╝░██╔══██╗╚══██╔══╝██╔══██╗██║██╔══██╗██╔════╝
+	██║░░██╗░██║░░██║░░░██║░░░██████╔╝██║██████╦╝█████╗░░
+	██║░░╚██╗██║░░██║░░░██║░░░██╔══██╗██║██╔══██╗██╔══╝░░
+	╚██████╔╝╚█████╔╝░░░██║░░░██║░░██║██║██████╦╝███████╗
+	░╚═════╝░░╚════╝░░░░╚═╝░░░╚═╝░░╚═╝╚═╝╚═════╝░╚══════╝`)
+	fmt.Println("	App running at:")
+	fmt.Printf("	- Local: %s%s:%d\n", "http://", host, port)
+	// Wait for interrupt signal to gracefully shutdown the server with
+	// a timeout of 5 seconds.
+	quit := make(chan os.Signal, 1)
+	// kill (no param) default send syscall.SIGTERM
+	// kill -2 is syscall.SIGINT
+	// kill -9 is syscall.SIGKILL but can't be catch, so don't need add it
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	// 先让就绪检查失败, 等待负载均衡摘除流量后再关闭服务
+	c.Health.Shutdown()
+	if delay := config.Conf.System.ShutdownDelay; delay > 0 {
+		c.Log.Infof("Readiness failing, waiting %ds for load balancers to drain...", delay)
+		time.Sleep(time.Duration(delay) * time.Second)
+	}
+	c.Log.Info("Shutting down server...")
+
+	// The context is used to inform the server it has 5 seconds to finish
+	// the request it is currently handling
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	shutdownErr := srv.Shutdown(ctx)
+	if shutdownErr != nil {
+		c.Log.Errorf("Server forced to shutdown: %v", shutdownErr)
+	}
+
+	// 无论服务是否正常关闭都写完剩余的操作日志, 关闭超时后ctx已过期, 需要单独计时
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer drainCancel()
+	if err := c.OperationLogs.Close(drainCtx); err != nil {
+		c.Log.Errorf("Operation log drain incomplete: %v", err)
+	}
+	c.GeoIP.Close()
+
+	if shutdownErr != nil {
+		return fmt.Errorf("服务强制关闭: %w", shutdownErr)
+	}
+	c.Log.Info("Server exiting!")
+	return nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+// Package app 应用依赖组装
+package app
+
+import (
+	"context"
+
+	"github.com/casbin/casbin/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gotribe-admin/internal/app/repository"
+	"gotribe-admin/internal/pkg/common"
+	"gotribe-admin/internal/pkg/geoip"
+	"gotribe-admin/internal/pkg/health"
+	"gotribe-admin/internal/pkg/loginguard"
+	"gotribe-admin/internal/pkg/metrics"
+	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/internal/pkg/oplog"
+)
+
+// 依赖容器, 启动时统一创建后注入路由、控制器、中间件与定时任务
+// 同一进程内可以创建多个相互隔离的容器, 测试中也可以替换其中的仓储
+type Container struct {
+	DB           *gorm.DB
+	Enforcer     *casbin.SyncedCachedEnforcer
+	Log          *zap.SugaredLogger
+	Metrics      *metrics.Metrics
+	Health       *health.Checker
+	Repositories *repository.Repositories
+	GeoIP        geoip.Resolver
+
+	// 操作日志写入器, 由serve启动, 停机时写完队列中剩余日志
+	OperationLogs *oplog.Writer
+
+	// 登录保护, 在数据库中统计登录失败次数并写入登录日志
+	LoginGuard *loginguard.Guard
+}
+
+// Container构造函数
+func NewContainer(db *gorm.DB, enforcer *casbin.SyncedCachedEnforcer, log *zap.SugaredLogger) *Container {
+	c := &Container{
+		DB:           db,
+		Enforcer:     enforcer,
+		Log:          log,
+		Metrics:      metrics.New(db),
+		Health:       health.New(),
+		Repositories: repository.NewRepositories(db, enforcer, log),
+	}
+	geo, err := common.NewGeoIPResolver()
+	if err != nil {
+		log.Errorf("打开IP归属地数据库失败, 将不查询归属地: %v", err)
+		geo = geoip.Nop()
+	}
+	c.GeoIP = geo
+	c.OperationLogs = oplog.NewWriter(common.OperationLogOptions(), c.saveOperationLogs, log, c.Metrics)
+	c.Metrics.RegisterQueueDepth("operation_log", c.OperationLogs.Len)
+	c.LoginGuard = loginguard.New(common.LoginGuardOptions(), c.Repositories.LoginLock, c.saveLoginLog, log)
+	c.addHealthChecks()
+	return c
+}
+
+// 查询IP归属地, 查询失败时返回空
+func (c *Container) LookupIP(ip string) geoip.Location {
+	location, err := c.GeoIP.Lookup(ip)
+	if err != nil {
+		c.Log.Debugf("查询IP归属地失败, ip: %s, err: %v", ip, err)
+	}
+	return location
+}
+
+// 写入操作日志, 写入前补充IP归属地
+func (c *Container) saveOperationLogs(ctx context.Context, logs []model.OperationLog) error {
+	for i := range logs {
+		if logs[i].IpLocation == "" {
+			logs[i].IpLocation = c.LookupIP(logs[i].Ip).String()
+		}
+	}
+	return c.Repositories.OperationLog.CreateOperationLogs(ctx, logs)
+}
+
+// 写入登录日志, 写入前补充请求ID与IP归属地, 写入失败只记录错误, 不影响登录
+func (c *Container) saveLoginLog(ctx context.Context, log *model.LoginLog) {
+	log.RequestID = common.RequestID(ctx)
+	log.IpLocation = c.LookupIP(log.Ip).String()
+	if err := c.Repositories.LoginLog.CreateLoginLog(ctx, log); err != nil {
+		common.LogWithContext(ctx, c.Log).Errorf("写入登录日志失败, 用户: %s, err: %v", log.Username, err)
+	}
+}
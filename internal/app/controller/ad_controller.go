@@ -30,15 +30,14 @@ type AdController struct {
 }
 
 // 构造函数
-func NewAdController() IAdController {
-	adRepository := repository.NewAdRepository()
+func NewAdController(adRepository repository.IAdRepository) IAdController {
 	adController := AdController{AdRepository: adRepository}
 	return adController
 }
 
 // 获取当前广告信息
 func (pc AdController) GetAdInfo(c *gin.Context) {
-	ad, err := pc.AdRepository.GetAdByAdID(c.Param("adID"))
+	ad, err := pc.AdRepository.GetAdByAdID(c.Request.Context(), c.Param("adID"))
 	if err != nil {
 		response.Fail(c, nil, "获取当前广告信息失败: "+err.Error())
 		return
@@ -65,7 +64,7 @@ func (pc AdController) GetAds(c *gin.Context) {
 	}
 
 	// 获取
-	ad, total, err := pc.AdRepository.GetAds(&req)
+	ad, total, err := pc.AdRepository.GetAds(c.Request.Context(), &req)
 	if err != nil {
 		response.Fail(c, nil, "获取广告列表失败: "+err.Error())
 		return
@@ -101,7 +100,7 @@ func (pc AdController) CreateAd(c *gin.Context) {
 		Description: req.Description,
 	}
 
-	err := pc.AdRepository.CreateAd(&ad)
+	err := pc.AdRepository.CreateAd(c.Request.Context(), &ad)
 	if err != nil {
 		response.Fail(c, nil, "创建广告失败: "+err.Error())
 		return
@@ -126,7 +125,7 @@ func (pc AdController) UpdateAdByID(c *gin.Context) {
 	}
 
 	// 根据path中的AdID获取广告信息
-	oldAd, err := pc.AdRepository.GetAdByAdID(c.Param("adID"))
+	oldAd, err := pc.AdRepository.GetAdByAdID(c.Request.Context(), c.Param("adID"))
 	if err != nil {
 		response.Fail(c, nil, "获取需要更新的广告信息失败: "+err.Error())
 		return
@@ -142,7 +141,7 @@ func (pc AdController) UpdateAdByID(c *gin.Context) {
 	oldAd.Video = req.Video
 	oldAd.SceneID = req.SceneID
 	// 更新广告
-	err = pc.AdRepository.UpdateAd(&oldAd)
+	err = pc.AdRepository.UpdateAd(c.Request.Context(), &oldAd)
 	if err != nil {
 		response.Fail(c, nil, "更新广告失败: "+err.Error())
 		return
@@ -167,7 +166,7 @@ func (pc AdController) BatchDeleteAdByIds(c *gin.Context) {
 
 	// 前端传来的广告ID
 	reqAdIds := strings.Split(req.AdIds, ",")
-	err := pc.AdRepository.BatchDeleteAdByIds(reqAdIds)
+	err := pc.AdRepository.BatchDeleteAdByIds(c.Request.Context(), reqAdIds)
 	if err != nil {
 		response.Fail(c, nil, "删除广告失败: "+err.Error())
 		return
@@ -30,15 +30,14 @@ type AdSceneController struct {
 }
 
 // 构造函数
-func NewAdSceneController() IAdSceneController {
-	adSceneRepository := repository.NewAdSceneRepository()
+func NewAdSceneController(adSceneRepository repository.IAdSceneRepository) IAdSceneController {
 	adSceneController := AdSceneController{AdSceneRepository: adSceneRepository}
 	return adSceneController
 }
 
 // 获取当前推广场景信息
 func (pc AdSceneController) GetAdSceneInfo(c *gin.Context) {
-	adScene, err := pc.AdSceneRepository.GetAdSceneByAdSceneID(c.Param("adSceneID"))
+	adScene, err := pc.AdSceneRepository.GetAdSceneByAdSceneID(c.Request.Context(), c.Param("adSceneID"))
 	if err != nil {
 		response.Fail(c, nil, "获取当前推广场景信息失败: "+err.Error())
 		return
@@ -65,7 +64,7 @@ func (pc AdSceneController) GetAdScenes(c *gin.Context) {
 	}
 
 	// 获取
-	adScene, total, err := pc.AdSceneRepository.GetAdScenes(&req)
+	adScene, total, err := pc.AdSceneRepository.GetAdScenes(c.Request.Context(), &req)
 	if err != nil {
 		response.Fail(c, nil, "获取推广场景列表失败: "+err.Error())
 		return
@@ -94,7 +93,7 @@ func (pc AdSceneController) CreateAdScene(c *gin.Context) {
 		Description: req.Description,
 	}
 
-	err := pc.AdSceneRepository.CreateAdScene(&adScene)
+	err := pc.AdSceneRepository.CreateAdScene(c.Request.Context(), &adScene)
 	if err != nil {
 		response.Fail(c, nil, "创建推广场景失败: "+err.Error())
 		return
@@ -119,7 +118,7 @@ func (pc AdSceneController) UpdateAdSceneByID(c *gin.Context) {
 	}
 
 	// 根据path中的AdSceneID获取推广场景信息
-	oldAdScene, err := pc.AdSceneRepository.GetAdSceneByAdSceneID(c.Param("adSceneID"))
+	oldAdScene, err := pc.AdSceneRepository.GetAdSceneByAdSceneID(c.Request.Context(), c.Param("adSceneID"))
 	if err != nil {
 		response.Fail(c, nil, "获取需要更新的推广场景信息失败: "+err.Error())
 		return
@@ -127,7 +126,7 @@ func (pc AdSceneController) UpdateAdSceneByID(c *gin.Context) {
 	oldAdScene.Title = req.Title
 	oldAdScene.Description = req.Description
 	// 更新推广场景
-	err = pc.AdSceneRepository.UpdateAdScene(&oldAdScene)
+	err = pc.AdSceneRepository.UpdateAdScene(c.Request.Context(), &oldAdScene)
 	if err != nil {
 		response.Fail(c, nil, "更新推广场景失败: "+err.Error())
 		return
@@ -152,7 +151,7 @@ func (pc AdSceneController) BatchDeleteAdSceneByIds(c *gin.Context) {
 
 	// 前端传来的推广场景ID
 	reqAdSceneIds := strings.Split(req.AdSceneIds, ",")
-	err := pc.AdSceneRepository.BatchDeleteAdSceneByIds(reqAdSceneIds)
+	err := pc.AdSceneRepository.BatchDeleteAdSceneByIds(c.Request.Context(), reqAdSceneIds)
 	if err != nil {
 		response.Fail(c, nil, "删除推广场景失败: "+err.Error())
 		return
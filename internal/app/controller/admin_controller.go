@@ -12,6 +12,7 @@ import (
 	"gotribe-admin/config"
 	"gotribe-admin/internal/app/repository"
 	"gotribe-admin/internal/pkg/common"
+	"gotribe-admin/internal/pkg/loginguard"
 	"gotribe-admin/internal/pkg/model"
 	"gotribe-admin/pkg/api/dto"
 	"gotribe-admin/pkg/api/response"
@@ -22,22 +23,25 @@ import (
 )
 
 type IAdminController interface {
-	GetAdminInfo(c *gin.Context)          // 获取当前登录用户信息
-	GetAdmins(c *gin.Context)             // 获取用户列表
-	ChangePwd(c *gin.Context)             // 更新用户登录密码
-	CreateAdmin(c *gin.Context)           // 创建用户
-	UpdateAdminByID(c *gin.Context)       // 更新用户
-	BatchDeleteAdminByIds(c *gin.Context) // 批量删除用户
+	GetAdminInfo(c *gin.Context)            // 获取当前登录用户信息
+	GetAdmins(c *gin.Context)               // 获取用户列表
+	ChangePwd(c *gin.Context)               // 更新用户登录密码
+	CreateAdmin(c *gin.Context)             // 创建用户
+	UpdateAdminByID(c *gin.Context)         // 更新用户
+	BatchDeleteAdminByIds(c *gin.Context)   // 批量删除用户
+	UpdateAdminProjectsByID(c *gin.Context) // 更新用户可管理的项目
+	UnlockAdminByID(c *gin.Context)         // 解除用户因登录失败次数过多导致的锁定
 }
 
 type AdminController struct {
 	AdminRepository repository.IAdminRepository
+	RoleRepository  repository.IRoleRepository
+	LoginGuard      *loginguard.Guard
 }
 
 // 构造函数
-func NewAdminController() IAdminController {
-	userRepository := repository.NewAdminRepository()
-	userController := AdminController{AdminRepository: userRepository}
+func NewAdminController(userRepository repository.IAdminRepository, roleRepository repository.IRoleRepository, loginGuard *loginguard.Guard) IAdminController {
+	userController := AdminController{AdminRepository: userRepository, RoleRepository: roleRepository, LoginGuard: loginGuard}
 	return userController
 }
 
@@ -70,7 +74,7 @@ func (uc AdminController) GetAdmins(c *gin.Context) {
 	}
 
 	// 获取
-	users, total, err := uc.AdminRepository.GetAdmins(&req)
+	users, total, err := uc.AdminRepository.GetAdmins(c.Request.Context(), &req)
 	if err != nil {
 		response.Fail(c, nil, "获取管理员列表失败: "+err.Error())
 		return
@@ -124,7 +128,7 @@ func (uc AdminController) ChangePwd(c *gin.Context) {
 		return
 	}
 	// 更新密码
-	err = uc.AdminRepository.ChangePwd(user.Username, util.GenPasswd(req.NewPassword))
+	err = uc.AdminRepository.ChangePwd(c.Request.Context(), user.Username, util.GenPasswd(req.NewPassword))
 	if err != nil {
 		response.Fail(c, nil, "更新密码失败: "+err.Error())
 		return
@@ -172,8 +176,8 @@ func (uc AdminController) CreateAdmin(c *gin.Context) {
 	// 获取前端传来的用户角色id
 	reqRoleIds := req.RoleIds
 	// 根据角色id获取角色
-	rr := repository.NewRoleRepository()
-	roles, err := rr.GetRolesByIds(reqRoleIds)
+	rr := uc.RoleRepository
+	roles, err := rr.GetRolesByIds(c.Request.Context(), reqRoleIds)
 	if err != nil {
 		response.Fail(c, nil, "根据角色ID获取角色信息失败: "+err.Error())
 		return
@@ -211,11 +215,19 @@ func (uc AdminController) CreateAdmin(c *gin.Context) {
 		Roles:        roles,
 	}
 
-	err = uc.AdminRepository.CreateAdmin(&user)
+	err = uc.AdminRepository.CreateAdmin(c.Request.Context(), &user)
 	if err != nil {
 		response.Fail(c, nil, "创建用户失败: "+err.Error())
 		return
 	}
+	// 受项目限制的用户创建的用户继承其可管理的项目
+	if projectIDs, restricted := common.ProjectScope(c.Request.Context()); restricted {
+		err = uc.AdminRepository.UpdateAdminProjects(c.Request.Context(), &user, projectIDs)
+		if err != nil {
+			response.Fail(c, nil, "设置用户可管理的项目失败: "+err.Error())
+			return
+		}
+	}
 	response.Success(c, nil, "创建用户成功")
 
 }
@@ -243,7 +255,7 @@ func (uc AdminController) UpdateAdminByID(c *gin.Context) {
 	}
 
 	// 根据path中的userID获取用户信息
-	oldAdmin, err := uc.AdminRepository.GetAdminByID(uint(userID))
+	oldAdmin, err := uc.AdminRepository.GetAdminByID(c.Request.Context(), uint(userID))
 	if err != nil {
 		response.Fail(c, nil, "获取需要更新的用户信息失败: "+err.Error())
 		return
@@ -271,8 +283,8 @@ func (uc AdminController) UpdateAdminByID(c *gin.Context) {
 	// 获取前端传来的用户角色id
 	reqRoleIds := req.RoleIds
 	// 根据角色id获取角色
-	rr := repository.NewRoleRepository()
-	roles, err := rr.GetRolesByIds(reqRoleIds)
+	rr := uc.RoleRepository
+	roles, err := rr.GetRolesByIds(c.Request.Context(), reqRoleIds)
 	if err != nil {
 		response.Fail(c, nil, "根据角色ID获取角色信息失败: "+err.Error())
 		return
@@ -328,7 +340,7 @@ func (uc AdminController) UpdateAdminByID(c *gin.Context) {
 		// 如果是更新别人
 		// 用户不能更新比自己角色等级高的或者相同等级的用户
 		// 根据path中的userID获取用户角色排序最小值
-		minRoleSorts, err := uc.AdminRepository.GetAdminMinRoleSortsByIds([]uint{uint(userID)})
+		minRoleSorts, err := uc.AdminRepository.GetAdminMinRoleSortsByIds(c.Request.Context(), []uint{uint(userID)})
 		if err != nil || len(minRoleSorts) == 0 {
 			response.Fail(c, nil, "根据用户ID获取用户角色排序最小值失败")
 			return
@@ -359,7 +371,7 @@ func (uc AdminController) UpdateAdminByID(c *gin.Context) {
 	}
 
 	// 更新用户
-	err = uc.AdminRepository.UpdateAdmin(&user)
+	err = uc.AdminRepository.UpdateAdmin(c.Request.Context(), &user)
 	if err != nil {
 		response.Fail(c, nil, "更新用户失败: "+err.Error())
 		return
@@ -386,7 +398,7 @@ func (uc AdminController) BatchDeleteAdminByIds(c *gin.Context) {
 	// 前端传来的用户ID
 	reqAdminIds := req.UserIds
 	// 根据用户ID获取用户角色排序最小值
-	roleMinSortList, err := uc.AdminRepository.GetAdminMinRoleSortsByIds(reqAdminIds)
+	roleMinSortList, err := uc.AdminRepository.GetAdminMinRoleSortsByIds(c.Request.Context(), reqAdminIds)
 	if err != nil || len(roleMinSortList) == 0 {
 		response.Fail(c, nil, "根据用户ID获取用户角色排序最小值失败")
 		return
@@ -414,7 +426,7 @@ func (uc AdminController) BatchDeleteAdminByIds(c *gin.Context) {
 		}
 	}
 
-	err = uc.AdminRepository.BatchDeleteAdminByIds(reqAdminIds)
+	err = uc.AdminRepository.BatchDeleteAdminByIds(c.Request.Context(), reqAdminIds)
 	if err != nil {
 		response.Fail(c, nil, "删除用户失败: "+err.Error())
 		return
@@ -423,3 +435,90 @@ func (uc AdminController) BatchDeleteAdminByIds(c *gin.Context) {
 	response.Success(c, nil, "删除用户成功")
 
 }
+
+// 更新用户可管理的项目
+func (uc AdminController) UpdateAdminProjectsByID(c *gin.Context) {
+	var req vo.UpdateAdminProjectsRequest
+	// 参数绑定
+	if err := c.ShouldBind(&req); err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	// 获取path中的userID
+	userID, _ := strconv.Atoi(c.Param("userID"))
+	if userID <= 0 {
+		response.Fail(c, nil, "用户ID不正确")
+		return
+	}
+	admin, err := uc.AdminRepository.GetAdminByID(c.Request.Context(), uint(userID))
+	if err != nil {
+		response.Fail(c, nil, "获取需要更新的用户信息失败: "+err.Error())
+		return
+	}
+
+	// 当前用户角色排序最小值（最高等级角色）
+	minSort, _, err := uc.AdminRepository.GetCurrentAdminMinRoleSort(c)
+	if err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	// (非管理员)不能更新比自己角色等级高或相等的用户(包括自己)的项目
+	if minSort != 1 {
+		roleMinSortList, err := uc.AdminRepository.GetAdminMinRoleSortsByIds(c.Request.Context(), []uint{admin.ID})
+		if err != nil || len(roleMinSortList) == 0 {
+			response.Fail(c, nil, "根据用户ID获取用户角色排序最小值失败")
+			return
+		}
+		if int(minSort) >= roleMinSortList[0] {
+			response.Fail(c, nil, "不能更新比自己角色等级高或相等的用户的项目")
+			return
+		}
+	}
+	// 只能设置自己可管理的项目
+	err = uc.AdminRepository.UpdateAdminProjects(c.Request.Context(), &admin, funk.UniqString(req.ProjectIds))
+	if err != nil {
+		response.Fail(c, nil, "更新用户可管理的项目失败: "+err.Error())
+		return
+	}
+	response.Success(c, nil, "更新用户可管理的项目成功")
+}
+
+// 解除用户因登录失败次数过多导致的锁定
+func (uc AdminController) UnlockAdminByID(c *gin.Context) {
+	// 获取path中的userID
+	userID, _ := strconv.Atoi(c.Param("userID"))
+	if userID <= 0 {
+		response.Fail(c, nil, "用户ID不正确")
+		return
+	}
+	admin, err := uc.AdminRepository.GetAdminByID(c.Request.Context(), uint(userID))
+	if err != nil {
+		response.Fail(c, nil, "获取需要解锁的用户信息失败: "+err.Error())
+		return
+	}
+
+	// 当前用户角色排序最小值（最高等级角色）
+	minSort, _, err := uc.AdminRepository.GetCurrentAdminMinRoleSort(c)
+	if err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	// (非管理员)不能解锁比自己角色等级高或相等的用户
+	if minSort != 1 {
+		roleMinSortList, err := uc.AdminRepository.GetAdminMinRoleSortsByIds(c.Request.Context(), []uint{admin.ID})
+		if err != nil || len(roleMinSortList) == 0 {
+			response.Fail(c, nil, "根据用户ID获取用户角色排序最小值失败")
+			return
+		}
+		if int(minSort) >= roleMinSortList[0] {
+			response.Fail(c, nil, "不能解锁比自己角色等级高或相等的用户")
+			return
+		}
+	}
+
+	if err := uc.LoginGuard.Unlock(c.Request.Context(), admin.Username); err != nil {
+		response.Fail(c, nil, "解锁用户失败: "+err.Error())
+		return
+	}
+	response.Success(c, nil, "解锁用户成功")
+}
@@ -26,12 +26,12 @@ type IApiController interface {
 }
 
 type ApiController struct {
-	ApiRepository repository.IApiRepository
+	ApiRepository   repository.IApiRepository
+	AdminRepository repository.IAdminRepository
 }
 
-func NewApiController() IApiController {
-	apiRepository := repository.NewApiRepository()
-	apiController := ApiController{ApiRepository: apiRepository}
+func NewApiController(apiRepository repository.IApiRepository, adminRepository repository.IAdminRepository) IApiController {
+	apiController := ApiController{ApiRepository: apiRepository, AdminRepository: adminRepository}
 	return apiController
 }
 
@@ -50,7 +50,7 @@ func (ac ApiController) GetApis(c *gin.Context) {
 		return
 	}
 	// 获取
-	apis, total, err := ac.ApiRepository.GetApis(&req)
+	apis, total, err := ac.ApiRepository.GetApis(c.Request.Context(), &req)
 	if err != nil {
 		response.Fail(c, nil, "获取接口列表失败")
 		return
@@ -62,7 +62,7 @@ func (ac ApiController) GetApis(c *gin.Context) {
 
 // 获取接口树(按接口Category字段分类)
 func (ac ApiController) GetApiTree(c *gin.Context) {
-	tree, err := ac.ApiRepository.GetApiTree()
+	tree, err := ac.ApiRepository.GetApiTree(c.Request.Context())
 	if err != nil {
 		response.Fail(c, nil, "获取接口树失败")
 		return
@@ -88,7 +88,7 @@ func (ac ApiController) CreateApi(c *gin.Context) {
 	}
 
 	// 获取当前用户
-	ur := repository.NewAdminRepository()
+	ur := ac.AdminRepository
 	ctxUser, err := ur.GetCurrentAdmin(c)
 	if err != nil {
 		response.Fail(c, nil, "获取当前用户信息失败")
@@ -104,7 +104,7 @@ func (ac ApiController) CreateApi(c *gin.Context) {
 	}
 
 	// 创建接口
-	err = ac.ApiRepository.CreateApi(&api)
+	err = ac.ApiRepository.CreateApi(c.Request.Context(), &api)
 	if err != nil {
 		response.Fail(c, nil, "创建接口失败: "+err.Error())
 		return
@@ -137,7 +137,7 @@ func (ac ApiController) UpdateApiByID(c *gin.Context) {
 	}
 
 	// 获取当前用户
-	ur := repository.NewAdminRepository()
+	ur := ac.AdminRepository
 	ctxUser, err := ur.GetCurrentAdmin(c)
 	if err != nil {
 		response.Fail(c, nil, "获取当前用户信息失败")
@@ -152,7 +152,7 @@ func (ac ApiController) UpdateApiByID(c *gin.Context) {
 		Creator:  ctxUser.Username,
 	}
 
-	err = ac.ApiRepository.UpdateApiByID(uint(apiID), &api)
+	err = ac.ApiRepository.UpdateApiByID(c.Request.Context(), uint(apiID), &api)
 	if err != nil {
 		response.Fail(c, nil, "更新接口失败: "+err.Error())
 		return
@@ -177,7 +177,7 @@ func (ac ApiController) BatchDeleteApiByIds(c *gin.Context) {
 	}
 
 	// 删除接口
-	err := ac.ApiRepository.BatchDeleteApiByIds(req.ApiIds)
+	err := ac.ApiRepository.BatchDeleteApiByIds(c.Request.Context(), req.ApiIds)
 	if err != nil {
 		response.Fail(c, nil, "删除接口失败: "+err.Error())
 		return
@@ -29,15 +29,14 @@ type CategoryController struct {
 	CategoryRepository repository.ICategoryRepository
 }
 
-func NewCategoryController() ICategoryController {
-	categoryRepository := repository.NewCategoryRepository()
+func NewCategoryController(categoryRepository repository.ICategoryRepository) ICategoryController {
 	categoryController := CategoryController{CategoryRepository: categoryRepository}
 	return categoryController
 }
 
 // 获取当前分类信息
 func (cc CategoryController) GetCategoryInfo(c *gin.Context) {
-	category, err := cc.CategoryRepository.GetConfigByCategoryID(c.Param("categoryID"))
+	category, err := cc.CategoryRepository.GetConfigByCategoryID(c.Request.Context(), c.Param("categoryID"))
 	if err != nil {
 		response.Fail(c, nil, "获取当前分类信息失败: "+err.Error())
 		return
@@ -49,7 +48,7 @@ func (cc CategoryController) GetCategoryInfo(c *gin.Context) {
 
 // 获取分类列表
 func (cc CategoryController) GetCategorys(c *gin.Context) {
-	categorys, err := cc.CategoryRepository.GetCategorys()
+	categorys, err := cc.CategoryRepository.GetCategorys(c.Request.Context())
 	if err != nil {
 		response.Fail(c, nil, "获取分类列表失败: "+err.Error())
 		return
@@ -59,7 +58,7 @@ func (cc CategoryController) GetCategorys(c *gin.Context) {
 
 // 获取分类树
 func (cc CategoryController) GetCategoryTree(c *gin.Context) {
-	categoryTree, err := cc.CategoryRepository.GetCategoryTree()
+	categoryTree, err := cc.CategoryRepository.GetCategoryTree(c.Request.Context())
 	if err != nil {
 		response.Fail(c, nil, "获取分类树失败: "+err.Error())
 		return
@@ -93,7 +92,7 @@ func (cc CategoryController) CreateCategory(c *gin.Context) {
 		Description: req.Description,
 	}
 
-	err := cc.CategoryRepository.CreateCategory(&category)
+	err := cc.CategoryRepository.CreateCategory(c.Request.Context(), &category)
 	if err != nil {
 		response.Fail(c, nil, "创建分类失败: "+err.Error())
 		return
@@ -117,7 +116,7 @@ func (cc CategoryController) UpdateCategoryByID(c *gin.Context) {
 	}
 	categoryID := c.Param("categoryID")
 	// 校验父级分类ID
-	category, err := cc.CategoryRepository.GetConfigByCategoryID(categoryID)
+	category, err := cc.CategoryRepository.GetConfigByCategoryID(c.Request.Context(), categoryID)
 	if err != nil {
 		response.Fail(c, nil, "分类不存在")
 		return
@@ -134,7 +133,7 @@ func (cc CategoryController) UpdateCategoryByID(c *gin.Context) {
 	category.Hidden = req.Hidden
 	category.ParentID = req.ParentID
 	category.Description = req.Description
-	err = cc.CategoryRepository.UpdateCategoryByID(categoryID, &category)
+	err = cc.CategoryRepository.UpdateCategoryByID(c.Request.Context(), categoryID, &category)
 	if err != nil {
 		response.Fail(c, nil, "更新分类失败: "+err.Error())
 		return
@@ -159,7 +158,7 @@ func (cc CategoryController) BatchDeleteCategoryByIds(c *gin.Context) {
 		return
 	}
 	reqCategoryIds := strings.Split(req.CategoryIds, ",")
-	err := cc.CategoryRepository.BatchDeleteCategoryByIds(reqCategoryIds)
+	err := cc.CategoryRepository.BatchDeleteCategoryByIds(c.Request.Context(), reqCategoryIds)
 	if err != nil {
 		response.Fail(c, nil, "删除分类失败: "+err.Error())
 		return
@@ -31,15 +31,14 @@ type ColumnController struct {
 }
 
 // 构造函数
-func NewColumnController() IColumnController {
-	columnRepository := repository.NewColumnRepository()
+func NewColumnController(columnRepository repository.IColumnRepository) IColumnController {
 	columnController := ColumnController{ColumnRepository: columnRepository}
 	return columnController
 }
 
 // 获取当前专栏信息
 func (pc ColumnController) GetColumnInfo(c *gin.Context) {
-	column, err := pc.ColumnRepository.GetColumnByColumnID(c.Param("columnID"))
+	column, err := pc.ColumnRepository.GetColumnByColumnID(c.Request.Context(), c.Param("columnID"))
 	if err != nil {
 		response.Fail(c, nil, "获取当前专栏信息失败: "+err.Error())
 		return
@@ -66,7 +65,7 @@ func (pc ColumnController) GetColumns(c *gin.Context) {
 	}
 
 	// 获取
-	column, total, err := pc.ColumnRepository.GetColumns(&req)
+	column, total, err := pc.ColumnRepository.GetColumns(c.Request.Context(), &req)
 	if err != nil {
 		response.Fail(c, nil, "获取专栏列表失败: "+err.Error())
 		return
@@ -97,7 +96,7 @@ func (pc ColumnController) CreateColumn(c *gin.Context) {
 		ProjectID:   req.ProjectID,
 	}
 
-	err := pc.ColumnRepository.CreateColumn(&column)
+	err := pc.ColumnRepository.CreateColumn(c.Request.Context(), &column)
 	if err != nil {
 		response.Fail(c, nil, "创建专栏失败: "+err.Error())
 		return
@@ -122,7 +121,7 @@ func (pc ColumnController) UpdateColumnByID(c *gin.Context) {
 	}
 
 	// 根据path中的ColumnID获取专栏信息
-	oldColumn, err := pc.ColumnRepository.GetColumnByColumnID(c.Param("columnID"))
+	oldColumn, err := pc.ColumnRepository.GetColumnByColumnID(c.Request.Context(), c.Param("columnID"))
 	if err != nil {
 		response.Fail(c, nil, "获取需要更新的专栏信息失败: "+err.Error())
 		return
@@ -132,7 +131,7 @@ func (pc ColumnController) UpdateColumnByID(c *gin.Context) {
 	oldColumn.Info = req.Info
 	oldColumn.Icon = req.Icon
 	// 更新专栏
-	err = pc.ColumnRepository.UpdateColumn(&oldColumn)
+	err = pc.ColumnRepository.UpdateColumn(c.Request.Context(), &oldColumn)
 	if err != nil {
 		response.Fail(c, nil, "更新专栏失败: "+err.Error())
 		return
@@ -157,7 +156,7 @@ func (tc ColumnController) BatchDeleteColumnByIds(c *gin.Context) {
 
 	// 前端传来的标签ID
 	reqColumnIds := strings.Split(req.ColumnIds, ",")
-	err := tc.ColumnRepository.BatchDeleteColumnByIds(reqColumnIds)
+	err := tc.ColumnRepository.BatchDeleteColumnByIds(c.Request.Context(), reqColumnIds)
 	if err != nil {
 		response.Fail(c, nil, "删除专栏失败: "+err.Error())
 		return
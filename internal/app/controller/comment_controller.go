@@ -26,8 +26,7 @@ type CommentController struct {
 }
 
 // 构造函数
-func NewCommentController() ICommentController {
-	commentRepository := repository.NewCommentRepository()
+func NewCommentController(commentRepository repository.ICommentRepository) ICommentController {
 	commentController := CommentController{CommentRepository: commentRepository}
 	return commentController
 }
@@ -48,7 +47,7 @@ func (pc CommentController) GetComments(c *gin.Context) {
 	}
 
 	// 获取
-	comment, total, err := pc.CommentRepository.GetComments(&req)
+	comment, total, err := pc.CommentRepository.GetComments(c.Request.Context(), &req)
 	if err != nil {
 		response.Fail(c, nil, "获取评论列表失败: "+err.Error())
 		return
@@ -59,7 +58,7 @@ func (pc CommentController) GetComments(c *gin.Context) {
 // 更新评论
 func (pc CommentController) UpdateCommentByID(c *gin.Context) {
 	// 根据path中的CommentID获取评论信息
-	oldComment, err := pc.CommentRepository.GetCommentByComentID(c.Param("commentID"))
+	oldComment, err := pc.CommentRepository.GetCommentByComentID(c.Request.Context(), c.Param("commentID"))
 	if err != nil {
 		response.Fail(c, nil, "获取需要更新的评论信息失败: "+err.Error())
 		return
@@ -72,7 +71,7 @@ func (pc CommentController) UpdateCommentByID(c *gin.Context) {
 	}
 	oldComment.Status = reqStatus
 	// 更新评论
-	err = pc.CommentRepository.UpdateComment(&oldComment)
+	err = pc.CommentRepository.UpdateComment(c.Request.Context(), &oldComment)
 	if err != nil {
 		response.Fail(c, nil, "更新评论失败: "+err.Error())
 		return
@@ -30,15 +30,14 @@ type ConfigController struct {
 }
 
 // 构造函数
-func NewConfigController() IConfigController {
-	configRepository := repository.NewConfigRepository()
+func NewConfigController(configRepository repository.IConfigRepository) IConfigController {
 	cnfigController := ConfigController{ConfigRepository: configRepository}
 	return cnfigController
 }
 
 // 获取当前配置信息
 func (pc ConfigController) GetConfigInfo(c *gin.Context) {
-	config, err := pc.ConfigRepository.GetConfigByConfigID(c.Param("configID"))
+	config, err := pc.ConfigRepository.GetConfigByConfigID(c.Request.Context(), c.Param("configID"))
 	if err != nil {
 		response.Fail(c, nil, "获取当前配置信息失败: "+err.Error())
 		return
@@ -65,7 +64,7 @@ func (pc ConfigController) GetConfigs(c *gin.Context) {
 	}
 
 	// 获取
-	config, total, err := pc.ConfigRepository.GetConfigs(&req)
+	config, total, err := pc.ConfigRepository.GetConfigs(c.Request.Context(), &req)
 	if err != nil {
 		response.Fail(c, nil, "获取配置列表失败: "+err.Error())
 		return
@@ -98,7 +97,7 @@ func (pc ConfigController) CreateConfig(c *gin.Context) {
 		Info:        req.Info,
 	}
 
-	err := pc.ConfigRepository.CreateConfig(&config)
+	err := pc.ConfigRepository.CreateConfig(c.Request.Context(), &config)
 	if err != nil {
 		response.Fail(c, nil, "创建配置失败: "+err.Error())
 		return
@@ -123,7 +122,7 @@ func (pc ConfigController) UpdateConfigByID(c *gin.Context) {
 	}
 
 	// 根据path中的ConfigID获取配置信息
-	oldConfig, err := pc.ConfigRepository.GetConfigByConfigID(c.Param("configID"))
+	oldConfig, err := pc.ConfigRepository.GetConfigByConfigID(c.Request.Context(), c.Param("configID"))
 	if err != nil {
 		response.Fail(c, nil, "获取需要更新的配置信息失败: "+err.Error())
 		return
@@ -134,7 +133,7 @@ func (pc ConfigController) UpdateConfigByID(c *gin.Context) {
 	oldConfig.ProjectID = req.ProjectID
 	oldConfig.MDContent = req.MDContent
 	// 更新配置
-	err = pc.ConfigRepository.UpdateConfig(&oldConfig)
+	err = pc.ConfigRepository.UpdateConfig(c.Request.Context(), &oldConfig)
 	if err != nil {
 		response.Fail(c, nil, "更新配置失败: "+err.Error())
 		return
@@ -159,7 +158,7 @@ func (pc ConfigController) BatchDeleteConfigByIds(c *gin.Context) {
 
 	// 前端传来的配置ID
 	reqConfigIds := strings.Split(req.ConfigIds, ",")
-	err := pc.ConfigRepository.BatchDeleteConfigByIds(reqConfigIds)
+	err := pc.ConfigRepository.BatchDeleteConfigByIds(c.Request.Context(), reqConfigIds)
 	if err != nil {
 		response.Fail(c, nil, "删除配置失败: "+err.Error())
 		return
@@ -0,0 +1,48 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"gotribe-admin/internal/pkg/health"
+	"gotribe-admin/pkg/api/response"
+	"net/http"
+)
+
+type IHealthController interface {
+	Healthz(c *gin.Context) // 存活检查
+	Readyz(c *gin.Context)  // 就绪检查
+}
+
+type HealthController struct {
+	Checker *health.Checker
+}
+
+// 构造函数
+func NewHealthController(checker *health.Checker) IHealthController {
+	return HealthController{Checker: checker}
+}
+
+// 存活检查, 进程能处理请求即返回成功, 不检查外部依赖
+func (hc HealthController) Healthz(c *gin.Context) {
+	response.Success(c, gin.H{"status": health.StatusUp}, "ok")
+}
+
+// 就绪检查, 任一依赖不可用或进入停机流程时返回503
+func (hc HealthController) Readyz(c *gin.Context) {
+	ready, checks := hc.Checker.Ready(c.Request.Context())
+	data := gin.H{
+		"status":       health.StatusUp,
+		"shuttingDown": hc.Checker.ShuttingDown(),
+		"checks":       checks,
+	}
+	if !ready {
+		data["status"] = health.StatusDown
+		response.Response(c, http.StatusServiceUnavailable, http.StatusServiceUnavailable, data, "服务未就绪")
+		return
+	}
+	response.Success(c, data, "ok")
+}
@@ -0,0 +1,86 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gotribe-admin/internal/app/repository"
+	"gotribe-admin/internal/pkg/common"
+	"gotribe-admin/pkg/api/known"
+	"gotribe-admin/pkg/api/response"
+	"gotribe-admin/pkg/api/vo"
+)
+
+type ILoginLogController interface {
+	GetLoginLogs(c *gin.Context)             // 获取登录日志列表
+	GetCurrentAdminLoginLogs(c *gin.Context) // 获取当前用户的登录日志列表
+}
+
+type LoginLogController struct {
+	LoginLogRepository repository.ILoginLogRepository
+	AdminRepository    repository.IAdminRepository
+}
+
+func NewLoginLogController(loginLogRepository repository.ILoginLogRepository, adminRepository repository.IAdminRepository) ILoginLogController {
+	loginLogController := LoginLogController{LoginLogRepository: loginLogRepository, AdminRepository: adminRepository}
+	return loginLogController
+}
+
+// 获取登录日志列表
+func (lc LoginLogController) GetLoginLogs(c *gin.Context) {
+	var req vo.LoginLogListRequest
+	if !lc.bind(c, &req) {
+		return
+	}
+	lc.list(c, &req)
+}
+
+// 获取当前用户的登录日志列表
+func (lc LoginLogController) GetCurrentAdminLoginLogs(c *gin.Context) {
+	var req vo.LoginLogListRequest
+	if !lc.bind(c, &req) {
+		return
+	}
+	if _, ok := c.Get(known.SERVICE_ACCOUNT_KEY); ok {
+		response.Fail(c, nil, "服务账号没有登录日志")
+		return
+	}
+	admin, err := lc.AdminRepository.GetCurrentAdmin(c)
+	if err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	req.AdminID = admin.ID
+	req.Username = ""
+	lc.list(c, &req)
+}
+
+// 绑定并校验参数, 失败时已写入响应
+func (lc LoginLogController) bind(c *gin.Context, req *vo.LoginLogListRequest) bool {
+	// 绑定参数
+	if err := c.ShouldBind(req); err != nil {
+		response.Fail(c, nil, err.Error())
+		return false
+	}
+	// 参数校验
+	if err := common.Validate.Struct(req); err != nil {
+		errStr := err.(validator.ValidationErrors)[0].Translate(common.Trans)
+		response.Fail(c, nil, errStr)
+		return false
+	}
+	return true
+}
+
+// 查询并返回登录日志列表
+func (lc LoginLogController) list(c *gin.Context, req *vo.LoginLogListRequest) {
+	logs, total, err := lc.LoginLogRepository.GetLoginLogs(c.Request.Context(), req)
+	if err != nil {
+		response.Fail(c, nil, "获取登录日志列表失败: "+err.Error())
+		return
+	}
+	response.Success(c, gin.H{"logs": logs, "total": total}, "获取登录日志列表成功")
+}
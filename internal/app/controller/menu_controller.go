@@ -29,18 +29,18 @@ type IMenuController interface {
 }
 
 type MenuController struct {
-	MenuRepository repository.IMenuRepository
+	MenuRepository  repository.IMenuRepository
+	AdminRepository repository.IAdminRepository
 }
 
-func NewMenuController() IMenuController {
-	menuRepository := repository.NewMenuRepository()
-	menuController := MenuController{MenuRepository: menuRepository}
+func NewMenuController(menuRepository repository.IMenuRepository, adminRepository repository.IAdminRepository) IMenuController {
+	menuController := MenuController{MenuRepository: menuRepository, AdminRepository: adminRepository}
 	return menuController
 }
 
 // 获取菜单列表
 func (mc MenuController) GetMenus(c *gin.Context) {
-	menus, err := mc.MenuRepository.GetMenus()
+	menus, err := mc.MenuRepository.GetMenus(c.Request.Context())
 	if err != nil {
 		response.Fail(c, nil, "获取菜单列表失败: "+err.Error())
 		return
@@ -50,7 +50,7 @@ func (mc MenuController) GetMenus(c *gin.Context) {
 
 // 获取菜单树
 func (mc MenuController) GetMenuTree(c *gin.Context) {
-	menuTree, err := mc.MenuRepository.GetMenuTree()
+	menuTree, err := mc.MenuRepository.GetMenuTree(c.Request.Context())
 	if err != nil {
 		response.Fail(c, nil, "获取菜单树失败: "+err.Error())
 		return
@@ -74,7 +74,7 @@ func (mc MenuController) CreateMenu(c *gin.Context) {
 	}
 
 	// 获取当前用户
-	ur := repository.NewAdminRepository()
+	ur := mc.AdminRepository
 	ctxUser, err := ur.GetCurrentAdmin(c)
 	if err != nil {
 		response.Fail(c, nil, "获取当前用户信息失败")
@@ -99,7 +99,7 @@ func (mc MenuController) CreateMenu(c *gin.Context) {
 		Creator:    ctxUser.Username,
 	}
 
-	err = mc.MenuRepository.CreateMenu(&menu)
+	err = mc.MenuRepository.CreateMenu(c.Request.Context(), &menu)
 	if err != nil {
 		response.Fail(c, nil, "创建菜单失败: "+err.Error())
 		return
@@ -130,7 +130,7 @@ func (mc MenuController) UpdateMenuByID(c *gin.Context) {
 	}
 
 	// 获取当前用户
-	ur := repository.NewAdminRepository()
+	ur := mc.AdminRepository
 	ctxUser, err := ur.GetCurrentAdmin(c)
 	if err != nil {
 		response.Fail(c, nil, "获取当前用户信息失败")
@@ -155,7 +155,7 @@ func (mc MenuController) UpdateMenuByID(c *gin.Context) {
 		Creator:    ctxUser.Username,
 	}
 
-	err = mc.MenuRepository.UpdateMenuByID(uint(menuID), &menu)
+	err = mc.MenuRepository.UpdateMenuByID(c.Request.Context(), uint(menuID), &menu)
 	if err != nil {
 		response.Fail(c, nil, "更新菜单失败: "+err.Error())
 		return
@@ -179,7 +179,7 @@ func (mc MenuController) BatchDeleteMenuByIds(c *gin.Context) {
 		response.Fail(c, nil, errStr)
 		return
 	}
-	err := mc.MenuRepository.BatchDeleteMenuByIds(req.MenuIds)
+	err := mc.MenuRepository.BatchDeleteMenuByIds(c.Request.Context(), req.MenuIds)
 	if err != nil {
 		response.Fail(c, nil, "删除菜单失败: "+err.Error())
 		return
@@ -197,7 +197,7 @@ func (mc MenuController) GetUserMenusByUserID(c *gin.Context) {
 		return
 	}
 
-	menus, err := mc.MenuRepository.GetUserMenusByUserID(uint(userID))
+	menus, err := mc.MenuRepository.GetUserMenusByUserID(c.Request.Context(), uint(userID))
 	if err != nil {
 		response.Fail(c, nil, "获取用户的可访问菜单列表失败: "+err.Error())
 		return
@@ -214,7 +214,7 @@ func (mc MenuController) GetUserMenuTreeByUserID(c *gin.Context) {
 		return
 	}
 
-	menuTree, err := mc.MenuRepository.GetUserMenuTreeByUserID(uint(userID))
+	menuTree, err := mc.MenuRepository.GetUserMenuTreeByUserID(c.Request.Context(), uint(userID))
 	if err != nil {
 		response.Fail(c, nil, "获取用户的可访问菜单树失败: "+err.Error())
 		return
@@ -6,26 +6,38 @@
 package controller
 
 import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
 	"gotribe-admin/internal/app/repository"
 	"gotribe-admin/internal/pkg/common"
+	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/pkg/api/known"
 	"gotribe-admin/pkg/api/response"
 	"gotribe-admin/pkg/api/vo"
 )
 
 type IOperationLogController interface {
 	GetOperationLogs(c *gin.Context)             // 获取操作日志列表
+	ExportOperationLogs(c *gin.Context)          // 导出操作日志
 	BatchDeleteOperationLogByIds(c *gin.Context) //批量删除操作日志
 }
 
 type OperationLogController struct {
 	operationLogRepository repository.IOperationLogRepository
+	log                    *zap.SugaredLogger
 }
 
-func NewOperationLogController() IOperationLogController {
-	operationLogRepository := repository.NewOperationLogRepository()
-	operationLogController := OperationLogController{operationLogRepository: operationLogRepository}
+func NewOperationLogController(operationLogRepository repository.IOperationLogRepository, log *zap.SugaredLogger) IOperationLogController {
+	operationLogController := OperationLogController{operationLogRepository: operationLogRepository, log: log}
 	return operationLogController
 }
 
@@ -44,7 +56,7 @@ func (oc OperationLogController) GetOperationLogs(c *gin.Context) {
 		return
 	}
 	// 获取
-	logs, total, err := oc.operationLogRepository.GetOperationLogs(&req)
+	logs, total, err := oc.operationLogRepository.GetOperationLogs(c.Request.Context(), &req)
 	if err != nil {
 		response.Fail(c, nil, "获取操作日志列表失败: "+err.Error())
 		return
@@ -52,6 +64,91 @@ func (oc OperationLogController) GetOperationLogs(c *gin.Context) {
 	response.Success(c, gin.H{"logs": logs, "total": total}, "获取操作日志列表成功")
 }
 
+// 导出的csv表头, 与operationLogRecord的字段顺序一致
+var operationLogCSVHeader = []string{"ID", "请求ID", "用户名", "IP", "IP归属地", "请求方式", "访问路径", "说明", "状态码", "发起时间", "耗时(ms)", "浏览器标识", "请求体", "响应体"}
+
+// 每写入多少行刷新一次响应
+const operationLogCSVFlushRows = 500
+
+// 以csv格式导出筛选后的全部操作日志, 边查询边写入响应
+func (oc OperationLogController) ExportOperationLogs(c *gin.Context) {
+	var req vo.OperationLogListRequest
+	// 绑定参数
+	if err := c.ShouldBind(&req); err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	// 参数校验
+	if err := common.Validate.Struct(&req); err != nil {
+		errStr := err.(validator.ValidationErrors)[0].Translate(common.Trans)
+		response.Fail(c, nil, errStr)
+		return
+	}
+
+	filename := fmt.Sprintf("operation_log_%s.csv", time.Now().Format("20060102150405"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Status(http.StatusOK)
+	// 带BOM, 避免Excel打开时中文乱码
+	c.Writer.WriteString("\xEF\xBB\xBF")
+	writer := csv.NewWriter(c.Writer)
+	writer.Write(operationLogCSVHeader)
+
+	// 导出可能耗时较长, 不受查询超时限制; 客户端断开后写入失败即停止
+	ctx := context.WithoutCancel(c.Request.Context())
+	rows := 0
+	err := oc.operationLogRepository.ExportOperationLogs(ctx, &req, func(log *model.OperationLog) error {
+		if err := writer.Write(operationLogRecord(log)); err != nil {
+			return err
+		}
+		if rows++; rows%operationLogCSVFlushRows == 0 {
+			writer.Flush()
+			c.Writer.Flush()
+		}
+		return writer.Error()
+	})
+	writer.Flush()
+	if err == nil {
+		err = writer.Error()
+	}
+	if err != nil {
+		// 响应头已发送, 只能记录错误
+		common.LogWithContext(c.Request.Context(), oc.log).Errorf("导出操作日志失败, 已导出%d条: %v", rows, err)
+	}
+}
+
+// 操作日志转为csv行
+func operationLogRecord(log *model.OperationLog) []string {
+	record := []string{
+		strconv.FormatUint(uint64(log.ID), 10),
+		log.RequestID,
+		log.Username,
+		log.Ip,
+		log.IpLocation,
+		log.Method,
+		log.Path,
+		log.Desc,
+		strconv.Itoa(log.Status),
+		log.StartTime.Format(known.TIME_FORMAT),
+		strconv.FormatInt(log.TimeCost, 10),
+		log.UserAgent,
+		log.RequestBody,
+		log.ResponseBody,
+	}
+	for i, value := range record {
+		record[i] = escapeCSVFormula(value)
+	}
+	return record
+}
+
+// 以=、+、-、@开头的内容会被表格软件当作公式执行, 前面加单引号
+func escapeCSVFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
 // 批量删除操作日志
 func (oc OperationLogController) BatchDeleteOperationLogByIds(c *gin.Context) {
 	var req vo.DeleteOperationLogRequest
@@ -68,7 +165,7 @@ func (oc OperationLogController) BatchDeleteOperationLogByIds(c *gin.Context) {
 	}
 
 	// 删除接口
-	err := oc.operationLogRepository.BatchDeleteOperationLogByIds(req.OperationLogIds)
+	err := oc.operationLogRepository.BatchDeleteOperationLogByIds(c.Request.Context(), req.OperationLogIds)
 	if err != nil {
 		response.Fail(c, nil, "删除日志失败: "+err.Error())
 		return
@@ -33,9 +33,7 @@ type OrderController struct {
 }
 
 // 构造函数
-func NewOrderController() IOrderController {
-	orderRepository := repository.NewOrderRepository()
-	orderLogRepository := repository.NewOrderLogRepository()
+func NewOrderController(orderRepository repository.IOrderRepository, orderLogRepository repository.IOrderLogRepository) IOrderController {
 	orderController := OrderController{
 		OrderRepository:    orderRepository,
 		OrderLogRepository: orderLogRepository,
@@ -45,7 +43,7 @@ func NewOrderController() IOrderController {
 
 // 获取当前订单信息
 func (tc OrderController) GetOrderInfo(c *gin.Context) {
-	order, err := tc.OrderRepository.GetOrderByOrderID(c.Param("orderID"))
+	order, err := tc.OrderRepository.GetOrderByOrderID(c.Request.Context(), c.Param("orderID"))
 	if err != nil {
 		response.Fail(c, nil, "获取当前订单信息失败: "+err.Error())
 		return
@@ -72,7 +70,7 @@ func (tc OrderController) GetOrders(c *gin.Context) {
 	}
 
 	// 获取
-	order, total, err := tc.OrderRepository.GetOrders(&req)
+	order, total, err := tc.OrderRepository.GetOrders(c.Request.Context(), &req)
 	if err != nil {
 		response.Fail(c, nil, "获取订单列表失败: "+err.Error())
 		return
@@ -96,7 +94,7 @@ func (tc OrderController) UpdateOrderByID(c *gin.Context) {
 	}
 
 	// 根据path中的OrderID获取订单信息
-	oldOrder, err := tc.OrderRepository.GetOrderByOrderID(c.Param("orderID"))
+	oldOrder, err := tc.OrderRepository.GetOrderByOrderID(c.Request.Context(), c.Param("orderID"))
 	if err != nil {
 		response.Fail(c, nil, "获取需要更新的订单信息失败: "+err.Error())
 		return
@@ -105,13 +103,13 @@ func (tc OrderController) UpdateOrderByID(c *gin.Context) {
 	oldOrder.Status = req.Status
 	oldOrder.RemarkAdmin = req.RemarkAdmin
 	// 更新订单
-	err = tc.OrderRepository.UpdateOrder(oldOrder)
+	err = tc.OrderRepository.UpdateOrder(c.Request.Context(), oldOrder)
 	if err != nil {
 		response.Fail(c, nil, "更新订单失败: "+err.Error())
 		return
 	}
 	// 增加修改记录
-	err = tc.OrderLogRepository.CreateOrderLog(c.Param("orderID"), "后台编辑")
+	err = tc.OrderLogRepository.CreateOrderLog(c.Request.Context(), c.Param("orderID"), "后台编辑")
 	response.Success(c, nil, "更新订单成功")
 }
 
@@ -132,7 +130,7 @@ func (tc OrderController) BatchDeleteOrderByIds(c *gin.Context) {
 
 	// 前端传来的订单ID
 	reqOrderIds := strings.Split(req.OrderIds, ",")
-	err := tc.OrderRepository.BatchDeleteOrderByIds(reqOrderIds)
+	err := tc.OrderRepository.BatchDeleteOrderByIds(c.Request.Context(), reqOrderIds)
 	if err != nil {
 		response.Fail(c, nil, "删除订单失败: "+err.Error())
 		return
@@ -143,7 +141,7 @@ func (tc OrderController) BatchDeleteOrderByIds(c *gin.Context) {
 
 // 获取订单记录
 func (tc OrderController) GetOrderLogs(c *gin.Context) {
-	orderLogs, total, err := tc.OrderLogRepository.GetOrderLogs(c.Param("orderID"))
+	orderLogs, total, err := tc.OrderLogRepository.GetOrderLogs(c.Request.Context(), c.Param("orderID"))
 	if err != nil {
 		response.Fail(c, nil, "获取订单记录失败: "+err.Error())
 		return
@@ -169,7 +167,7 @@ func (tc OrderController) UpdateLogistics(c *gin.Context) {
 	}
 
 	// 根据path中的OrderID获取订单信息
-	oldOrder, err := tc.OrderRepository.GetOrderByOrderID(c.Param("orderID"))
+	oldOrder, err := tc.OrderRepository.GetOrderByOrderID(c.Request.Context(), c.Param("orderID"))
 	if err != nil {
 		response.Fail(c, nil, "获取需要更新的订单信息失败: "+err.Error())
 		return
@@ -178,12 +176,12 @@ func (tc OrderController) UpdateLogistics(c *gin.Context) {
 	oldOrder.LogisticsCompany = req.Company
 	oldOrder.Status = known.OrderStatusShipped
 	// 更新物流信息
-	err = tc.OrderRepository.UpdateOrder(oldOrder)
+	err = tc.OrderRepository.UpdateOrder(c.Request.Context(), oldOrder)
 	if err != nil {
 		response.Fail(c, nil, "更新订单失败: "+err.Error())
 		return
 	}
 	// 增加修改记录
-	err = tc.OrderLogRepository.CreateOrderLog(c.Param("orderID"), "更新物流")
+	err = tc.OrderLogRepository.CreateOrderLog(c.Request.Context(), c.Param("orderID"), "更新物流")
 	response.Success(c, nil, "更新订单成功")
 }
@@ -0,0 +1,266 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package controller
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gotribe-admin/internal/app/repository"
+	"gotribe-admin/internal/pkg/common"
+	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/internal/pkg/permission"
+	"gotribe-admin/pkg/api/dto"
+	"gotribe-admin/pkg/api/known"
+	"gotribe-admin/pkg/api/response"
+	"gotribe-admin/pkg/api/vo"
+)
+
+type IPermissionController interface {
+	ExplainPermission(c *gin.Context)      // 诊断用户或角色对接口的权限
+	GetPermissionMatrix(c *gin.Context)    // 获取用户对全部接口的有效权限
+	ExportPermissionMatrix(c *gin.Context) // 导出用户对全部接口的有效权限
+}
+
+type PermissionController struct {
+	AdminRepository repository.IAdminRepository
+	RoleRepository  repository.IRoleRepository
+	ApiRepository   repository.IApiRepository
+	Enforcer        *casbin.SyncedCachedEnforcer
+}
+
+// 构造函数
+func NewPermissionController(adminRepository repository.IAdminRepository, roleRepository repository.IRoleRepository, apiRepository repository.IApiRepository, enforcer *casbin.SyncedCachedEnforcer) IPermissionController {
+	return PermissionController{
+		AdminRepository: adminRepository,
+		RoleRepository:  roleRepository,
+		ApiRepository:   apiRepository,
+		Enforcer:        enforcer,
+	}
+}
+
+// 诊断用户或角色对接口的权限, 与CasbinMiddleware的校验规则一致
+func (pc PermissionController) ExplainPermission(c *gin.Context) {
+	var req vo.PermissionExplainRequest
+	// 参数绑定
+	if err := c.ShouldBind(&req); err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	// 参数校验
+	if err := common.Validate.Struct(&req); err != nil {
+		errStr := err.(validator.ValidationErrors)[0].Translate(common.Trans)
+		response.Fail(c, nil, errStr)
+		return
+	}
+	keyword := strings.TrimSpace(req.RoleKeyword)
+	if req.AdminID == 0 && keyword == "" {
+		response.Fail(c, nil, "用户ID与角色关键字不能同时为空")
+		return
+	}
+
+	// 支持直接粘贴请求地址, 去掉查询参数与接口前缀
+	path, _, _ := strings.Cut(strings.TrimSpace(req.Path), "?")
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	explain := dto.PermissionExplainDto{
+		Method:          strings.ToUpper(strings.TrimSpace(req.Method)),
+		Path:            permission.Object(path),
+		Roles:           make([]*model.Role, 0),
+		DisabledRoles:   make([]*model.Role, 0),
+		ClosestPolicies: make([]permission.Policy, 0),
+	}
+
+	api, err := pc.matchApi(c, explain.Method, explain.Path)
+	if err != nil {
+		response.Fail(c, nil, "获取接口列表失败: "+err.Error())
+		return
+	}
+	explain.Api = api
+	explain.Registered = api != nil
+
+	var subs []string
+	if req.AdminID > 0 {
+		admin, err := pc.AdminRepository.GetAdminByID(c.Request.Context(), req.AdminID)
+		if err != nil {
+			response.Fail(c, nil, fmt.Sprintf("未获取到ID为%d的用户", req.AdminID))
+			return
+		}
+		explain.Roles = admin.Roles
+		var disabled []*model.Role
+		subs, disabled = permission.Subjects(admin)
+		if len(disabled) > 0 {
+			explain.DisabledRoles = disabled
+		}
+		switch {
+		case admin.Status != known.DEFAULT_ID:
+			explain.Reason = "用户已被禁用"
+			response.Success(c, gin.H{"explain": explain}, "权限诊断成功")
+			return
+		case permission.IsSuperAdmin(admin):
+			explain.Allowed = true
+			explain.Reason = "超级管理员不受权限限制"
+			response.Success(c, gin.H{"explain": explain}, "权限诊断成功")
+			return
+		case len(subs) == 0:
+			explain.Reason = "用户没有未被禁用的角色"
+			response.Success(c, gin.H{"explain": explain}, "权限诊断成功")
+			return
+		}
+	} else {
+		roles, err := pc.RoleRepository.GetRolesByKeywords(c.Request.Context(), []string{keyword})
+		if err != nil || len(roles) == 0 {
+			response.Fail(c, nil, fmt.Sprintf("未获取到关键字为%s的角色", keyword))
+			return
+		}
+		explain.Roles = roles
+		// 角色被禁用时分配给用户也不参与校验, 但仍给出策略的匹配情况
+		if roles[0].Status != known.DEFAULT_ID {
+			explain.DisabledRoles = roles
+		}
+		subs = []string{keyword}
+	}
+
+	decision, err := permission.Explain(pc.Enforcer, subs, explain.Path, explain.Method)
+	if err != nil {
+		response.Fail(c, nil, "权限诊断失败: "+err.Error())
+		return
+	}
+	explain.MatchedPolicy = decision.Matched
+	explain.ClosestPolicies = decision.Closest
+	explain.Allowed = decision.Allowed && len(explain.DisabledRoles) < len(explain.Roles)
+	switch {
+	case decision.Allowed && !explain.Allowed:
+		explain.Reason = "命中策略, 但角色已被禁用"
+	case decision.Allowed && decision.Matched.Role != decision.Matched.Subject:
+		explain.Reason = fmt.Sprintf("角色%s继承了角色%s的策略", decision.Matched.Subject, decision.Matched.Role)
+	case decision.Allowed:
+		explain.Reason = fmt.Sprintf("命中角色%s的策略", decision.Matched.Role)
+	case !explain.Registered:
+		explain.Reason = "接口未在接口管理中登记, 无法分配给角色"
+	default:
+		explain.Reason = "没有匹配的策略"
+	}
+	response.Success(c, gin.H{"explain": explain}, "权限诊断成功")
+}
+
+// 获取用户对全部接口的有效权限
+func (pc PermissionController) GetPermissionMatrix(c *gin.Context) {
+	matrix, err := pc.permissionMatrix(c)
+	if err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	response.Success(c, gin.H{"matrix": matrix}, "获取用户的有效权限成功")
+}
+
+// 用户有效权限导出的表头
+var permissionMatrixCSVHeader = []string{"接口ID", "所属类别", "请求方式", "访问路径", "说明", "是否允许", "授权角色"}
+
+// 导出用户对全部接口的有效权限
+func (pc PermissionController) ExportPermissionMatrix(c *gin.Context) {
+	matrix, err := pc.permissionMatrix(c)
+	if err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("permission_%s_%s.csv", matrix.Username, time.Now().Format("20060102150405"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Status(http.StatusOK)
+	// 带BOM, 避免Excel打开时中文乱码
+	c.Writer.WriteString("\xEF\xBB\xBF")
+	writer := csv.NewWriter(c.Writer)
+	writer.Write(permissionMatrixCSVHeader)
+	for _, row := range matrix.Apis {
+		allowed := "否"
+		if row.Allowed {
+			allowed = "是"
+		}
+		roles := strings.Join(row.Roles, ",")
+		if matrix.SuperAdmin {
+			roles = "超级管理员"
+		}
+		record := []string{strconv.FormatUint(uint64(row.ID), 10), row.Category, row.Method, row.Path, row.Desc, allowed, roles}
+		for i, value := range record {
+			record[i] = escapeCSVFormula(value)
+		}
+		writer.Write(record)
+	}
+	writer.Flush()
+}
+
+// 计算path中用户对全部接口的有效权限
+func (pc PermissionController) permissionMatrix(c *gin.Context) (*dto.PermissionMatrixDto, error) {
+	userID, _ := strconv.Atoi(c.Param("userID"))
+	if userID <= 0 {
+		return nil, fmt.Errorf("用户ID不正确")
+	}
+	admin, err := pc.AdminRepository.GetAdminByID(c.Request.Context(), uint(userID))
+	if err != nil {
+		return nil, fmt.Errorf("未获取到ID为%d的用户", userID)
+	}
+	apis, _, err := pc.ApiRepository.GetApis(c.Request.Context(), &vo.ApiListRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("获取接口列表失败: %v", err)
+	}
+
+	subs, disabled := permission.Subjects(admin)
+	matrix := &dto.PermissionMatrixDto{
+		AdminID:       admin.ID,
+		Username:      admin.Username,
+		SuperAdmin:    permission.IsSuperAdmin(admin),
+		Disabled:      admin.Status != known.DEFAULT_ID,
+		DisabledRoles: make([]*model.Role, 0),
+	}
+	if len(disabled) > 0 {
+		matrix.DisabledRoles = disabled
+	}
+	// 被禁用的用户没有任何权限, 超级管理员拥有全部权限
+	if matrix.Disabled {
+		subs = nil
+	}
+	matrix.Apis, err = permission.Matrix(pc.Enforcer, subs, apis)
+	if err != nil {
+		return nil, fmt.Errorf("计算用户的有效权限失败: %v", err)
+	}
+	if matrix.SuperAdmin && !matrix.Disabled {
+		for i := range matrix.Apis {
+			matrix.Apis[i].Allowed = true
+		}
+	}
+	return matrix, nil
+}
+
+// 获取请求对应的已登记接口, 优先完全相同的路径
+func (pc PermissionController) matchApi(c *gin.Context, method string, path string) (*model.Api, error) {
+	apis, _, err := pc.ApiRepository.GetApis(c.Request.Context(), &vo.ApiListRequest{})
+	if err != nil {
+		return nil, err
+	}
+	var matched *model.Api
+	for _, api := range apis {
+		if api.Method != method {
+			continue
+		}
+		if api.Path == path {
+			return api, nil
+		}
+		if matched == nil && permission.PathMatch(path, api.Path) {
+			matched = api
+		}
+	}
+	return matched, nil
+}
@@ -25,8 +25,7 @@ type PointController struct {
 }
 
 // 构造函数
-func NewPointController() IPointController {
-	pointLogRepository := repository.NewPointLogRepository()
+func NewPointController(pointLogRepository repository.IPointLogRepository) IPointController {
 	pointController := PointController{PointRepository: pointLogRepository}
 	return pointController
 }
@@ -47,7 +46,7 @@ func (pc PointController) GetPoints(c *gin.Context) {
 	}
 
 	// 获取
-	point, total, err := pc.PointRepository.GetPointLogs(&req)
+	point, total, err := pc.PointRepository.GetPointLogs(c.Request.Context(), &req)
 	if err != nil {
 		response.Fail(c, nil, "获取积分列表失败: "+err.Error())
 		return
@@ -70,7 +69,7 @@ func (pc PointController) CreatePoint(c *gin.Context) {
 		return
 	}
 
-	err := pc.PointRepository.CreatePoint(req.UserID, "admin", "后台添加", "0", req.ProjectID, req.Point)
+	err := pc.PointRepository.CreatePoint(c.Request.Context(), req.UserID, "admin", "后台添加", "0", req.ProjectID, req.Point)
 	if err != nil {
 		response.Fail(c, nil, "创建积分失败: "+err.Error())
 		return
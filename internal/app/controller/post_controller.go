@@ -35,16 +35,14 @@ type PostController struct {
 }
 
 // 构造函数
-func NewPostController() IPostController {
-	postRepository := repository.NewPostRepository()
-	projectRepository := repository.NewProjectRepository()
+func NewPostController(postRepository repository.IPostRepository, projectRepository repository.IProjectRepository) IPostController {
 	postController := PostController{PostRepository: postRepository, ProjectRepository: projectRepository}
 	return postController
 }
 
 // 获取当前内容信息
 func (pc PostController) GetPostInfo(c *gin.Context) {
-	post, err := pc.PostRepository.GetPostByPostID(c.Param("postID"))
+	post, err := pc.PostRepository.GetPostByPostID(c.Request.Context(), c.Param("postID"))
 	if err != nil {
 		response.Fail(c, nil, "获取当前内容信息失败: "+err.Error())
 		return
@@ -71,7 +69,7 @@ func (pc PostController) GetPosts(c *gin.Context) {
 	}
 
 	// 获取
-	post, total, err := pc.PostRepository.GetPosts(&req)
+	post, total, err := pc.PostRepository.GetPosts(c.Request.Context(), &req)
 	if err != nil {
 		response.Fail(c, nil, "获取内容列表失败: "+err.Error())
 		return
@@ -119,7 +117,7 @@ func (pc PostController) CreatePost(c *gin.Context) {
 		Video:       req.Video,
 	}
 
-	err := pc.PostRepository.CreatePost(&post)
+	err := pc.PostRepository.CreatePost(c.Request.Context(), &post)
 	if err != nil {
 		response.Fail(c, nil, "创建内容失败: "+err.Error())
 		return
@@ -144,7 +142,7 @@ func (pc PostController) UpdatePostByID(c *gin.Context) {
 	}
 
 	// 根据path中的PostID获取内容信息
-	oldPost, err := pc.PostRepository.GetPostByPostID(c.Param("postID"))
+	oldPost, err := pc.PostRepository.GetPostByPostID(c.Request.Context(), c.Param("postID"))
 	if err != nil {
 		response.Fail(c, nil, "获取需要更新的内容信息失败: "+err.Error())
 		return
@@ -174,7 +172,7 @@ func (pc PostController) UpdatePostByID(c *gin.Context) {
 	oldPost.Images = imageStr
 	oldPost.Video = req.Video
 	// 更新内容
-	err = pc.PostRepository.UpdatePost(&oldPost)
+	err = pc.PostRepository.UpdatePost(c.Request.Context(), &oldPost)
 	if err != nil {
 		response.Fail(c, nil, "更新内容失败: "+err.Error())
 		return
@@ -199,7 +197,7 @@ func (tc PostController) BatchDeletePostByIds(c *gin.Context) {
 
 	// 前端传来的标签ID
 	reqPostIds := strings.Split(req.PostIds, ",")
-	err := tc.PostRepository.BatchDeletePostByIds(reqPostIds)
+	err := tc.PostRepository.BatchDeletePostByIds(c.Request.Context(), reqPostIds)
 	if err != nil {
 		response.Fail(c, nil, "删除内容失败: "+err.Error())
 		return
@@ -212,20 +210,20 @@ func (tc PostController) BatchDeletePostByIds(c *gin.Context) {
 // 更新内容
 func (pc PostController) PushPostByID(c *gin.Context) {
 	// 根据path中的PostID获取内容信息
-	oldPost, err := pc.PostRepository.GetPostByPostID(c.Param("postID"))
+	oldPost, err := pc.PostRepository.GetPostByPostID(c.Request.Context(), c.Param("postID"))
 	if err != nil {
 		response.Fail(c, nil, "获取需要更新的内容信息失败: "+err.Error())
 		return
 	}
 	oldPost.Status = known.POST_STATUS_PUBLIC
 	// 更新内容
-	err = pc.PostRepository.UpdatePost(&oldPost)
+	err = pc.PostRepository.UpdatePost(c.Request.Context(), &oldPost)
 	if err != nil {
 		response.Fail(c, nil, "更新内容失败: "+err.Error())
 		return
 	}
 	// 同步内容至百度
-	projectInfo, err := pc.ProjectRepository.GetProjectByProjectID(oldPost.ProjectID)
+	projectInfo, err := pc.ProjectRepository.GetProjectByProjectID(c.Request.Context(), oldPost.ProjectID)
 
 	if !gconvert.IsEmpty(projectInfo.PushToken) {
 		// 处理 url
@@ -29,15 +29,14 @@ type ProductCategoryController struct {
 	ProductCategoryRepository repository.IProductCategoryRepository
 }
 
-func NewProductCategoryController() IProductCategoryController {
-	productCategoryRepository := repository.NewProductCategoryRepository()
+func NewProductCategoryController(productCategoryRepository repository.IProductCategoryRepository) IProductCategoryController {
 	productCategoryController := ProductCategoryController{ProductCategoryRepository: productCategoryRepository}
 	return productCategoryController
 }
 
 // 获取当前分类信息
 func (cc ProductCategoryController) GetProductCategoryInfo(c *gin.Context) {
-	productCategory, err := cc.ProductCategoryRepository.GetConfigByProductCategoryID(c.Param("productCategoryID"))
+	productCategory, err := cc.ProductCategoryRepository.GetConfigByProductCategoryID(c.Request.Context(), c.Param("productCategoryID"))
 	if err != nil {
 		response.Fail(c, nil, "获取当前分类信息失败: "+err.Error())
 		return
@@ -49,7 +48,7 @@ func (cc ProductCategoryController) GetProductCategoryInfo(c *gin.Context) {
 
 // 获取分类列表
 func (cc ProductCategoryController) GetProductCategorys(c *gin.Context) {
-	productCategorys, err := cc.ProductCategoryRepository.GetProductCategorys()
+	productCategorys, err := cc.ProductCategoryRepository.GetProductCategorys(c.Request.Context())
 	if err != nil {
 		response.Fail(c, nil, "获取分类列表失败: "+err.Error())
 		return
@@ -59,7 +58,7 @@ func (cc ProductCategoryController) GetProductCategorys(c *gin.Context) {
 
 // 获取分类树
 func (cc ProductCategoryController) GetProductCategoryTree(c *gin.Context) {
-	productCategoryTree, err := cc.ProductCategoryRepository.GetProductCategoryTree()
+	productCategoryTree, err := cc.ProductCategoryRepository.GetProductCategoryTree(c.Request.Context())
 	if err != nil {
 		response.Fail(c, nil, "获取分类树失败: "+err.Error())
 		return
@@ -94,7 +93,7 @@ func (cc ProductCategoryController) CreateProductCategory(c *gin.Context) {
 		Description: req.Description,
 	}
 
-	err := cc.ProductCategoryRepository.CreateProductCategory(&productCategory)
+	err := cc.ProductCategoryRepository.CreateProductCategory(c.Request.Context(), &productCategory)
 	if err != nil {
 		response.Fail(c, nil, "创建分类失败: "+err.Error())
 		return
@@ -118,7 +117,7 @@ func (cc ProductCategoryController) UpdateProductCategoryByID(c *gin.Context) {
 	}
 	productCategoryID := c.Param("productCategoryID")
 	// 校验父级分类ID
-	productCategory, err := cc.ProductCategoryRepository.GetConfigByProductCategoryID(productCategoryID)
+	productCategory, err := cc.ProductCategoryRepository.GetConfigByProductCategoryID(c.Request.Context(), productCategoryID)
 	if err != nil {
 		response.Fail(c, nil, "分类不存在")
 		return
@@ -136,7 +135,7 @@ func (cc ProductCategoryController) UpdateProductCategoryByID(c *gin.Context) {
 	productCategory.ParentID = req.ParentID
 	productCategory.Description = req.Description
 	productCategory.ProjectID = req.ProjectID
-	err = cc.ProductCategoryRepository.UpdateProductCategoryByID(productCategoryID, &productCategory)
+	err = cc.ProductCategoryRepository.UpdateProductCategoryByID(c.Request.Context(), productCategoryID, &productCategory)
 	if err != nil {
 		response.Fail(c, nil, "更新分类失败: "+err.Error())
 		return
@@ -161,7 +160,7 @@ func (cc ProductCategoryController) BatchDeleteProductCategoryByIds(c *gin.Conte
 		return
 	}
 	reqProductCategoryIds := strings.Split(req.ProductCategoryIds, ",")
-	err := cc.ProductCategoryRepository.BatchDeleteProductCategoryByIds(reqProductCategoryIds)
+	err := cc.ProductCategoryRepository.BatchDeleteProductCategoryByIds(c.Request.Context(), reqProductCategoryIds)
 	if err != nil {
 		response.Fail(c, nil, "删除分类失败: "+err.Error())
 		return
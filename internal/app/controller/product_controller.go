@@ -35,10 +35,7 @@ type ProductController struct {
 }
 
 // 构造函数
-func NewProductController() IProductController {
-	productRepository := repository.NewProductRepository(common.DB)
-	productSpecRepository := repository.NewProductSpecRepository()
-	productSku := repository.NewProductSkuRepository()
+func NewProductController(productRepository repository.IProductRepository, productSpecRepository repository.IProductSpecRepository, productSku repository.IProductSkuRepository) IProductController {
 	productController := ProductController{
 		ProductRepository:     productRepository,
 		ProductSpecRepository: productSpecRepository,
@@ -51,7 +48,7 @@ func NewProductController() IProductController {
 // 获取当前产品信息
 // 获取当前产品信息
 func (tc ProductController) GetProductInfo(c *gin.Context) {
-	product, err := tc.ProductRepository.GetProductByProductID(c.Param("productID"))
+	product, err := tc.ProductRepository.GetProductByProductID(c.Request.Context(), c.Param("productID"))
 	if err != nil {
 		response.Fail(c, nil, "获取当前产品信息失败: "+err.Error())
 		return
@@ -59,7 +56,7 @@ func (tc ProductController) GetProductInfo(c *gin.Context) {
 
 	productInfoDto := dto.ToProductInfoDto(product)
 	// 通过 productID 获取 sku 信息,并追加进去
-	sku, err := tc.ProductSkuRepository.GetProductSkuByProductID(product.ProductID)
+	sku, err := tc.ProductSkuRepository.GetProductSkuByProductID(c.Request.Context(), product.ProductID)
 	if err != nil {
 		response.Fail(c, nil, "获取SKU信息失败: "+err.Error())
 		return
@@ -86,7 +83,7 @@ func (tc ProductController) GetProducts(c *gin.Context) {
 	}
 
 	// 获取
-	product, total, err := tc.ProductRepository.GetProducts(&req)
+	product, total, err := tc.ProductRepository.GetProducts(c.Request.Context(), &req)
 	if err != nil {
 		response.Fail(c, nil, "获取产品列表失败: "+err.Error())
 		return
@@ -139,7 +136,7 @@ func (tc ProductController) CreateProduct(c *gin.Context) {
 		}
 	}
 
-	tx, err := tc.ProductRepository.BeginTx()
+	tx, err := tc.ProductRepository.BeginTx(c.Request.Context())
 	if err != nil {
 		response.Fail(c, nil, "开始事务失败: "+err.Error())
 		return
@@ -249,13 +246,13 @@ func (tc ProductController) UpdateProductByID(c *gin.Context) {
 	}
 	imageStr := strings.Join(req.Image, ",")
 	// 根据path中的ProductID获取产品信息
-	oldProduct, err := tc.ProductRepository.GetProductByProductID(c.Param("productID"))
+	oldProduct, err := tc.ProductRepository.GetProductByProductID(c.Request.Context(), c.Param("productID"))
 	if err != nil {
 		response.Fail(c, nil, "获取需要更新的产品信息失败: "+err.Error())
 		return
 	}
 
-	tx, err := tc.ProductRepository.BeginTx()
+	tx, err := tc.ProductRepository.BeginTx(c.Request.Context())
 	if err != nil {
 		response.Fail(c, nil, "开始事务失败: "+err.Error())
 		return
@@ -342,7 +339,7 @@ func (tc ProductController) BatchDeleteProductByIds(c *gin.Context) {
 
 	// 前端传来的产品ID
 	reqProductIds := strings.Split(req.ProductIds, ",")
-	err := tc.ProductRepository.BatchDeleteProductByIds(reqProductIds)
+	err := tc.ProductRepository.BatchDeleteProductByIds(c.Request.Context(), reqProductIds)
 	if err != nil {
 		response.Fail(c, nil, "删除产品失败: "+err.Error())
 		return
@@ -31,15 +31,14 @@ type ProductSpecController struct {
 }
 
 // 构造函数
-func NewProductSpecController() IProductSpecController {
-	productSpecRepository := repository.NewProductSpecRepository()
+func NewProductSpecController(productSpecRepository repository.IProductSpecRepository) IProductSpecController {
 	productSpecController := ProductSpecController{ProductSpecRepository: productSpecRepository}
 	return productSpecController
 }
 
 // 获取当前商品规格信息
 func (tc ProductSpecController) GetProductSpecInfo(c *gin.Context) {
-	productSpec, err := tc.ProductSpecRepository.GetProductSpecByProductSpecID(c.Param("productSpecID"))
+	productSpec, err := tc.ProductSpecRepository.GetProductSpecByProductSpecID(c.Request.Context(), c.Param("productSpecID"))
 	if err != nil {
 		response.Fail(c, nil, "获取当前商品规格信息失败: "+err.Error())
 		return
@@ -66,7 +65,7 @@ func (tc ProductSpecController) GetProductSpecs(c *gin.Context) {
 	}
 
 	// 获取
-	productSpec, total, err := tc.ProductSpecRepository.GetProductSpecs(&req)
+	productSpec, total, err := tc.ProductSpecRepository.GetProductSpecs(c.Request.Context(), &req)
 	if err != nil {
 		response.Fail(c, nil, "获取商品规格列表失败: "+err.Error())
 		return
@@ -98,7 +97,7 @@ func (tc ProductSpecController) CreateProductSpec(c *gin.Context) {
 		Sort:   req.Sort,
 	}
 
-	productSpecInfo, err := tc.ProductSpecRepository.CreateProductSpec(&productSpec)
+	productSpecInfo, err := tc.ProductSpecRepository.CreateProductSpec(c.Request.Context(), &productSpec)
 	if err != nil {
 		response.Fail(c, nil, "创建商品规格失败: "+err.Error())
 		return
@@ -122,7 +121,7 @@ func (tc ProductSpecController) UpdateProductSpecByID(c *gin.Context) {
 	}
 
 	// 根据path中的ProductSpecID获取商品规格信息
-	oldProductSpec, err := tc.ProductSpecRepository.GetProductSpecByProductSpecID(c.Param("productSpecID"))
+	oldProductSpec, err := tc.ProductSpecRepository.GetProductSpecByProductSpecID(c.Request.Context(), c.Param("productSpecID"))
 	if err != nil {
 		response.Fail(c, nil, "获取需要更新的商品规格信息失败: "+err.Error())
 		return
@@ -133,7 +132,7 @@ func (tc ProductSpecController) UpdateProductSpecByID(c *gin.Context) {
 	oldProductSpec.Image = req.Image
 	oldProductSpec.Sort = req.Sort
 	// 更新商品规格
-	err = tc.ProductSpecRepository.UpdateProductSpec(&oldProductSpec)
+	err = tc.ProductSpecRepository.UpdateProductSpec(c.Request.Context(), &oldProductSpec)
 	if err != nil {
 		response.Fail(c, nil, "更新商品规格失败: "+err.Error())
 		return
@@ -158,7 +157,7 @@ func (tc ProductSpecController) BatchDeleteProductSpecByIds(c *gin.Context) {
 
 	// 前端传来的商品规格ID
 	reqProductSpecIds := strings.Split(req.ProductSpecIds, ",")
-	err := tc.ProductSpecRepository.BatchDeleteProductSpecByIds(reqProductSpecIds)
+	err := tc.ProductSpecRepository.BatchDeleteProductSpecByIds(c.Request.Context(), reqProductSpecIds)
 	if err != nil {
 		response.Fail(c, nil, "删除商品规格失败: "+err.Error())
 		return
@@ -171,7 +170,7 @@ func (tc ProductSpecController) BatchDeleteProductSpecByIds(c *gin.Context) {
 // 通过分类商品分类 ID 获取规格和规格项
 func (tc ProductSpecController) GetProductSpecAndItem(c *gin.Context) {
 	categoryID := c.Param("categoryID")
-	productSpecAndItem, err := tc.ProductSpecRepository.GetProductSpecAndItem(categoryID)
+	productSpecAndItem, err := tc.ProductSpecRepository.GetProductSpecAndItem(c.Request.Context(), categoryID)
 	if err != nil {
 		response.Fail(c, nil, "获取商品规格失败: "+err.Error())
 		return
@@ -30,15 +30,14 @@ type ProductSpecItemController struct {
 }
 
 // 构造函数
-func NewProductSpecItemController() IProductSpecItemController {
-	productSpecItemRepository := repository.NewProductSpecItemRepository()
+func NewProductSpecItemController(productSpecItemRepository repository.IProductSpecItemRepository) IProductSpecItemController {
 	productSpecItemController := ProductSpecItemController{ProductSpecItemRepository: productSpecItemRepository}
 	return productSpecItemController
 }
 
 // 获取当前商品规格信息
 func (tc ProductSpecItemController) GetProductSpecItemInfo(c *gin.Context) {
-	productSpecItem, err := tc.ProductSpecItemRepository.GetProductSpecItemByItemID(c.Param("productSpecItemID"))
+	productSpecItem, err := tc.ProductSpecItemRepository.GetProductSpecItemByItemID(c.Request.Context(), c.Param("productSpecItemID"))
 	if err != nil {
 		response.Fail(c, nil, "获取当前商品规格信息失败: "+err.Error())
 		return
@@ -65,7 +64,7 @@ func (tc ProductSpecItemController) GetProductSpecItems(c *gin.Context) {
 	}
 
 	// 获取
-	productSpecItem, total, err := tc.ProductSpecItemRepository.GetProductSpecItems(&req)
+	productSpecItem, total, err := tc.ProductSpecItemRepository.GetProductSpecItems(c.Request.Context(), &req)
 	if err != nil {
 		response.Fail(c, nil, "获取商品规格列表失败: "+err.Error())
 		return
@@ -95,7 +94,7 @@ func (tc ProductSpecItemController) CreateProductSpecItem(c *gin.Context) {
 		Enabled: req.Enabled,
 	}
 
-	productSpecItemInfo, err := tc.ProductSpecItemRepository.CreateProductSpecItem(&productSpecItem)
+	productSpecItemInfo, err := tc.ProductSpecItemRepository.CreateProductSpecItem(c.Request.Context(), &productSpecItem)
 	if err != nil {
 		response.Fail(c, nil, "创建商品规格失败: "+err.Error())
 		return
@@ -119,7 +118,7 @@ func (tc ProductSpecItemController) UpdateProductSpecItemByID(c *gin.Context) {
 	}
 
 	// 根据path中的ProductSpecItemID获取商品规格信息
-	oldProductSpecItem, err := tc.ProductSpecItemRepository.GetProductSpecItemByItemID(c.Param("productSpecItemID"))
+	oldProductSpecItem, err := tc.ProductSpecItemRepository.GetProductSpecItemByItemID(c.Request.Context(), c.Param("productSpecItemID"))
 	if err != nil {
 		response.Fail(c, nil, "获取需要更新的商品规格信息失败: "+err.Error())
 		return
@@ -128,7 +127,7 @@ func (tc ProductSpecItemController) UpdateProductSpecItemByID(c *gin.Context) {
 	oldProductSpecItem.Enabled = req.Enabled
 	oldProductSpecItem.Sort = req.Sort
 	// 更新商品规格
-	err = tc.ProductSpecItemRepository.UpdateProductSpecItem(&oldProductSpecItem)
+	err = tc.ProductSpecItemRepository.UpdateProductSpecItem(c.Request.Context(), &oldProductSpecItem)
 	if err != nil {
 		response.Fail(c, nil, "更新商品规格失败: "+err.Error())
 		return
@@ -153,7 +152,7 @@ func (tc ProductSpecItemController) BatchDeleteProductSpecItemByIds(c *gin.Conte
 
 	// 前端传来的商品规格ID
 	reqProductSpecItemIds := strings.Split(req.ProductSpecItemIds, ",")
-	err := tc.ProductSpecItemRepository.BatchDeleteProductSpecItemByIds(reqProductSpecItemIds)
+	err := tc.ProductSpecItemRepository.BatchDeleteProductSpecItemByIds(c.Request.Context(), reqProductSpecItemIds)
 	if err != nil {
 		response.Fail(c, nil, "删除商品规格失败: "+err.Error())
 		return
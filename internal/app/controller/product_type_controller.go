@@ -31,9 +31,7 @@ type ProductTypeController struct {
 }
 
 // 构造函数
-func NewProductTypeController() IProductTypeController {
-	productTypeRepository := repository.NewProductTypeRepository()
-	productSpecRepository := repository.NewProductSpecRepository()
+func NewProductTypeController(productTypeRepository repository.IProductTypeRepository, productSpecRepository repository.IProductSpecRepository) IProductTypeController {
 	productTypeController := ProductTypeController{
 		ProductTypeRepository: productTypeRepository,
 		ProductSpecRepository: productSpecRepository,
@@ -45,7 +43,7 @@ func NewProductTypeController() IProductTypeController {
 // 获取当前商品类型信息
 // 获取当前商品类型信息
 func (tc ProductTypeController) GetProductTypeInfo(c *gin.Context) {
-	productType, err := tc.ProductTypeRepository.GetProductTypeByProductTypeID(c.Param("productTypeID"))
+	productType, err := tc.ProductTypeRepository.GetProductTypeByProductTypeID(c.Request.Context(), c.Param("productTypeID"))
 	if err != nil {
 		response.Fail(c, nil, "获取当前商品类型信息失败: "+err.Error())
 		return
@@ -56,7 +54,7 @@ func (tc ProductTypeController) GetProductTypeInfo(c *gin.Context) {
 	specIds := strings.Split(productType.SpecIds, ",")
 
 	// 获取关联的规格信息并追加进去
-	productSpecs, err := tc.ProductSpecRepository.GetProductSpecsByProductSpecIDs(specIds)
+	productSpecs, err := tc.ProductSpecRepository.GetProductSpecsByProductSpecIDs(c.Request.Context(), specIds)
 	if err != nil {
 		response.Fail(c, nil, "获取商品规格信息失败: "+err.Error())
 		return
@@ -84,7 +82,7 @@ func (tc ProductTypeController) GetProductTypes(c *gin.Context) {
 	}
 
 	// 获取
-	productType, total, err := tc.ProductTypeRepository.GetProductTypes(&req)
+	productType, total, err := tc.ProductTypeRepository.GetProductTypes(c.Request.Context(), &req)
 	if err != nil {
 		response.Fail(c, nil, "获取商品类型列表失败: "+err.Error())
 		return
@@ -115,7 +113,7 @@ func (tc ProductTypeController) CreateProductType(c *gin.Context) {
 		SpecIds:           req.SpecIds,
 	}
 
-	productTypeInfo, err := tc.ProductTypeRepository.CreateProductType(&productType)
+	productTypeInfo, err := tc.ProductTypeRepository.CreateProductType(c.Request.Context(), &productType)
 	if err != nil {
 		response.Fail(c, nil, "创建商品类型失败: "+err.Error())
 		return
@@ -139,7 +137,7 @@ func (tc ProductTypeController) UpdateProductTypeByID(c *gin.Context) {
 	}
 
 	// 根据path中的ProductTypeID获取商品类型信息
-	oldProductType, err := tc.ProductTypeRepository.GetProductTypeByProductTypeID(c.Param("productTypeID"))
+	oldProductType, err := tc.ProductTypeRepository.GetProductTypeByProductTypeID(c.Request.Context(), c.Param("productTypeID"))
 	if err != nil {
 		response.Fail(c, nil, "获取需要更新的商品类型信息失败: "+err.Error())
 		return
@@ -149,7 +147,7 @@ func (tc ProductTypeController) UpdateProductTypeByID(c *gin.Context) {
 	oldProductType.ProductCategoryID = req.CategoryID
 	oldProductType.SpecIds = req.SpecIds
 	// 更新商品类型
-	err = tc.ProductTypeRepository.UpdateProductType(&oldProductType)
+	err = tc.ProductTypeRepository.UpdateProductType(c.Request.Context(), &oldProductType)
 	if err != nil {
 		response.Fail(c, nil, "更新商品类型失败: "+err.Error())
 		return
@@ -174,7 +172,7 @@ func (tc ProductTypeController) BatchDeleteProductTypeByIds(c *gin.Context) {
 
 	// 前端传来的商品类型ID
 	reqProductTypeIds := strings.Split(req.ProductTypeIds, ",")
-	err := tc.ProductTypeRepository.BatchDeleteProductTypeByIds(reqProductTypeIds)
+	err := tc.ProductTypeRepository.BatchDeleteProductTypeByIds(c.Request.Context(), reqProductTypeIds)
 	if err != nil {
 		response.Fail(c, nil, "删除商品类型失败: "+err.Error())
 		return
@@ -30,15 +30,14 @@ type ProjectController struct {
 }
 
 // 构造函数
-func NewProjectController() IProjectController {
-	projectRepository := repository.NewProjectRepository()
+func NewProjectController(projectRepository repository.IProjectRepository) IProjectController {
 	projectController := ProjectController{ProjectRepository: projectRepository}
 	return projectController
 }
 
 // 获取当前项目信息
 func (pc ProjectController) GetProjectInfo(c *gin.Context) {
-	project, err := pc.ProjectRepository.GetProjectByProjectID(c.Param("projectID"))
+	project, err := pc.ProjectRepository.GetProjectByProjectID(c.Request.Context(), c.Param("projectID"))
 	if err != nil {
 		response.Fail(c, nil, "获取当前项目信息失败: "+err.Error())
 		return
@@ -65,7 +64,7 @@ func (pc ProjectController) GetProjects(c *gin.Context) {
 	}
 
 	// 获取
-	project, total, err := pc.ProjectRepository.GetProjects(&req)
+	project, total, err := pc.ProjectRepository.GetProjects(c.Request.Context(), &req)
 	if err != nil {
 		response.Fail(c, nil, "获取项目列表失败: "+err.Error())
 		return
@@ -105,7 +104,7 @@ func (pc ProjectController) CreateProject(c *gin.Context) {
 		PushToken:      req.PushToken,
 	}
 
-	err := pc.ProjectRepository.CreateProject(&project)
+	err := pc.ProjectRepository.CreateProject(c.Request.Context(), &project)
 	if err != nil {
 		response.Fail(c, nil, "创建项目失败: "+err.Error())
 		return
@@ -130,7 +129,7 @@ func (pc ProjectController) UpdateProjectByID(c *gin.Context) {
 	}
 
 	// 根据path中的ProjectID获取项目信息
-	oldProject, err := pc.ProjectRepository.GetProjectByProjectID(c.Param("projectID"))
+	oldProject, err := pc.ProjectRepository.GetProjectByProjectID(c.Request.Context(), c.Param("projectID"))
 	if err != nil {
 		response.Fail(c, nil, "获取需要更新的项目信息失败: "+err.Error())
 		return
@@ -150,7 +149,7 @@ func (pc ProjectController) UpdateProjectByID(c *gin.Context) {
 	oldProject.BaiduAnalytics = req.BaiduAnalytics
 	oldProject.PushToken = req.PushToken
 	// 更新项目
-	err = pc.ProjectRepository.UpdateProject(&oldProject)
+	err = pc.ProjectRepository.UpdateProject(c.Request.Context(), &oldProject)
 	if err != nil {
 		response.Fail(c, nil, "更新项目失败: "+err.Error())
 		return
@@ -175,7 +174,7 @@ func (tc ProjectController) BatchDeleteProjectByIds(c *gin.Context) {
 
 	// 前端传来的标签ID
 	reqProjectIds := strings.Split(req.ProjectIds, ",")
-	err := tc.ProjectRepository.BatchDeleteProjectByIds(reqProjectIds)
+	err := tc.ProjectRepository.BatchDeleteProjectByIds(c.Request.Context(), reqProjectIds)
 	if err != nil {
 		response.Fail(c, nil, "删除项目失败: "+err.Error())
 		return
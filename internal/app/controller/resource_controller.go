@@ -18,7 +18,6 @@ import (
 	"gotribe-admin/pkg/api/response"
 	"gotribe-admin/pkg/api/vo"
 	"gotribe-admin/pkg/util"
-	"gotribe-admin/pkg/util/upload"
 )
 
 type IResourceController interface {
@@ -34,15 +33,14 @@ type ResourceController struct {
 }
 
 // 构造函数
-func NewResourceController() IResourceController {
-	resourceRepository := repository.NewResourceRepository()
+func NewResourceController(resourceRepository repository.IResourceRepository) IResourceController {
 	resourceController := ResourceController{ResourceRepository: resourceRepository}
 	return resourceController
 }
 
 // 获取当前资源信息
 func (pc ResourceController) GetResourceInfo(c *gin.Context) {
-	resource, err := pc.ResourceRepository.GetResourceByResourceID(c.Param("resourceID"))
+	resource, err := pc.ResourceRepository.GetResourceByResourceID(c.Request.Context(), c.Param("resourceID"))
 	if err != nil {
 		response.Fail(c, nil, "获取当前资源信息失败: "+err.Error())
 		return
@@ -69,7 +67,7 @@ func (pc ResourceController) GetResources(c *gin.Context) {
 	}
 
 	// 获取
-	resource, total, err := pc.ResourceRepository.GetResources(&req)
+	resource, total, err := pc.ResourceRepository.GetResources(c.Request.Context(), &req)
 	if err != nil {
 		response.Fail(c, nil, "获取资源列表失败: "+err.Error())
 		return
@@ -93,7 +91,7 @@ func (pc ResourceController) UpdateResourceByID(c *gin.Context) {
 	}
 
 	// 根据path中的ResourceID获取资源信息
-	oldResource, err := pc.ResourceRepository.GetResourceByResourceID(c.Param("resourceID"))
+	oldResource, err := pc.ResourceRepository.GetResourceByResourceID(c.Request.Context(), c.Param("resourceID"))
 	if err != nil {
 		response.Fail(c, nil, "获取需要更新的资源信息失败: "+err.Error())
 		return
@@ -101,7 +99,7 @@ func (pc ResourceController) UpdateResourceByID(c *gin.Context) {
 	oldResource.Title = req.Title
 	oldResource.Description = req.Description
 	// 更新资源
-	err = pc.ResourceRepository.UpdateResource(&oldResource)
+	err = pc.ResourceRepository.UpdateResource(c.Request.Context(), &oldResource)
 	if err != nil {
 		response.Fail(c, nil, "更新资源失败: "+err.Error())
 		return
@@ -120,20 +118,22 @@ func (pc ResourceController) UploadResources(c *gin.Context) {
 		response.Fail(c, nil, "上传资源过大")
 		return
 	}
-	upload, err := upload.NewUploadFile(
-		config.Conf.UploadFile.Endpoint,
-		config.Conf.UploadFile.Accesskey,
-		config.Conf.UploadFile.Secretkey,
-		config.Conf.UploadFile.Bucket,
-		config.Conf.System.EnableOss,
-	)
-	fileRes, err := upload.UploadFile(fileHeader)
+	uploadService, err := common.NewUploadService()
+	if err != nil {
+		response.Fail(c, nil, "初始化上传服务失败: "+err.Error())
+		return
+	}
+	fileRes, err := uploadService.UploadFile(fileHeader)
 	if err != nil {
 		response.Fail(c, nil, "上传 CDN 失败："+err.Error())
 		return
 	}
 	uploadRes := dto.ToUploadResourceDto(&fileRes)
-	uploadRes.Domain = config.Conf.System.CDNDomain
+	// 本地存储返回自身的访问前缀, 云存储使用cdn域名
+	uploadRes.Domain = fileRes.Domain
+	if uploadRes.Domain == "" {
+		uploadRes.Domain = config.Conf.System.CDNDomain
+	}
 	uploadRes.FileType = util.GetFileType(fileHeader)
 
 	// 资源入库
@@ -146,7 +146,7 @@ func (pc ResourceController) UploadResources(c *gin.Context) {
 		FileType:      gconvert.Uint(uploadRes.FileType),
 	}
 
-	if err = pc.ResourceRepository.CreateResource(&resource); err != nil {
+	if err = pc.ResourceRepository.CreateResource(c.Request.Context(), &resource); err != nil {
 		response.Fail(c, nil, "创建资源失败: "+err.Error())
 		return
 	}
@@ -168,7 +168,7 @@ func (pc ResourceController) DeleteResourceByID(c *gin.Context) {
 		return
 	}
 
-	err := pc.ResourceRepository.DeleteResourceByID(req.ResourceID)
+	err := pc.ResourceRepository.DeleteResourceByID(c.Request.Context(), req.ResourceID)
 	if err != nil {
 		response.Fail(c, nil, "删除资源失败: "+err.Error())
 		return
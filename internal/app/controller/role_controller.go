@@ -7,35 +7,52 @@ package controller
 
 import (
 	"fmt"
+	"github.com/casbin/casbin/v2"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/thoas/go-funk"
 	"gotribe-admin/internal/app/repository"
 	"gotribe-admin/internal/pkg/common"
 	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/pkg/api/dto"
+	"gotribe-admin/pkg/api/known"
 	"gotribe-admin/pkg/api/response"
 	"gotribe-admin/pkg/api/vo"
 	"strconv"
 )
 
 type IRoleController interface {
-	GetRoles(c *gin.Context)             // 获取角色列表
-	CreateRole(c *gin.Context)           // 创建角色
-	UpdateRoleByID(c *gin.Context)       // 更新角色
-	GetRoleMenusByID(c *gin.Context)     // 获取角色的权限菜单
-	UpdateRoleMenusByID(c *gin.Context)  // 更新角色的权限菜单
-	GetRoleApisByID(c *gin.Context)      // 获取角色的权限接口
-	UpdateRoleApisByID(c *gin.Context)   // 更新角色的权限接口
-	BatchDeleteRoleByIds(c *gin.Context) // 批量删除角色
+	GetRoles(c *gin.Context)               // 获取角色列表
+	CreateRole(c *gin.Context)             // 创建角色
+	UpdateRoleByID(c *gin.Context)         // 更新角色
+	GetRoleMenusByID(c *gin.Context)       // 获取角色的权限菜单
+	UpdateRoleMenusByID(c *gin.Context)    // 更新角色的权限菜单
+	GetRoleApisByID(c *gin.Context)        // 获取角色的权限接口
+	UpdateRoleApisByID(c *gin.Context)     // 更新角色的权限接口
+	BatchDeleteRoleByIds(c *gin.Context)   // 批量删除角色
+	GetRoleProjectsByID(c *gin.Context)    // 获取角色可管理的项目
+	UpdateRoleProjectsByID(c *gin.Context) // 更新角色可管理的项目
+	GetRoleParentsByID(c *gin.Context)     // 获取角色继承的父角色
+	UpdateRoleParentsByID(c *gin.Context)  // 更新角色继承的父角色
+	GetRolePermissionsByID(c *gin.Context) // 获取角色的有效权限(包括继承的权限)
 }
 
 type RoleController struct {
-	RoleRepository repository.IRoleRepository
+	RoleRepository  repository.IRoleRepository
+	AdminRepository repository.IAdminRepository
+	MenuRepository  repository.IMenuRepository
+	ApiRepository   repository.IApiRepository
+	Enforcer        *casbin.SyncedCachedEnforcer
 }
 
-func NewRoleController() IRoleController {
-	roleRepository := repository.NewRoleRepository()
-	roleController := RoleController{RoleRepository: roleRepository}
+func NewRoleController(roleRepository repository.IRoleRepository, adminRepository repository.IAdminRepository, menuRepository repository.IMenuRepository, apiRepository repository.IApiRepository, enforcer *casbin.SyncedCachedEnforcer) IRoleController {
+	roleController := RoleController{
+		RoleRepository:  roleRepository,
+		AdminRepository: adminRepository,
+		MenuRepository:  menuRepository,
+		ApiRepository:   apiRepository,
+		Enforcer:        enforcer,
+	}
 	return roleController
 }
 
@@ -55,7 +72,7 @@ func (rc RoleController) GetRoles(c *gin.Context) {
 	}
 
 	// 获取角色列表
-	roles, total, err := rc.RoleRepository.GetRoles(&req)
+	roles, total, err := rc.RoleRepository.GetRoles(c.Request.Context(), &req)
 	if err != nil {
 		response.Fail(c, nil, "获取角色列表失败: "+err.Error())
 		return
@@ -79,7 +96,7 @@ func (rc RoleController) CreateRole(c *gin.Context) {
 	}
 
 	// 获取当前用户最高角色等级
-	uc := repository.NewAdminRepository()
+	uc := rc.AdminRepository
 	sort, ctxUser, err := uc.GetCurrentAdminMinRoleSort(c)
 	if err != nil {
 		response.Fail(c, nil, "获取当前用户最高角色等级失败: "+err.Error())
@@ -92,17 +109,25 @@ func (rc RoleController) CreateRole(c *gin.Context) {
 		return
 	}
 
+	// 受项目限制的用户不能授予管理全部项目的权限
+	if _, restricted := common.ProjectScope(c.Request.Context()); restricted && req.AllProjects == known.DEFAULT_ID {
+		response.Fail(c, nil, "不能授予管理全部项目的权限")
+		return
+	}
+
 	role := model.Role{
-		Name:    req.Name,
-		Keyword: req.Keyword,
-		Desc:    &req.Desc,
-		Status:  req.Status,
-		Sort:    req.Sort,
-		Creator: ctxUser.Username,
+		Name:         req.Name,
+		Keyword:      req.Keyword,
+		Desc:         &req.Desc,
+		Status:       req.Status,
+		Sort:         req.Sort,
+		TotpRequired: req.TotpRequired,
+		AllProjects:  req.AllProjects,
+		Creator:      ctxUser.Username,
 	}
 
 	// 创建角色
-	err = rc.RoleRepository.CreateRole(&role)
+	err = rc.RoleRepository.CreateRole(c.Request.Context(), &role)
 	if err != nil {
 		response.Fail(c, nil, "创建角色失败: "+err.Error())
 		return
@@ -133,7 +158,7 @@ func (rc RoleController) UpdateRoleByID(c *gin.Context) {
 	}
 
 	// 当前用户角色排序最小值（最高等级角色）以及当前用户
-	ur := repository.NewAdminRepository()
+	ur := rc.AdminRepository
 	minSort, ctxUser, err := ur.GetCurrentAdminMinRoleSort(c)
 	if err != nil {
 		response.Fail(c, nil, err.Error())
@@ -142,7 +167,7 @@ func (rc RoleController) UpdateRoleByID(c *gin.Context) {
 
 	// 不能更新比自己角色等级高或相等的角色
 	// 根据path中的角色ID获取该角色信息
-	roles, err := rc.RoleRepository.GetRolesByIds([]uint{uint(roleID)})
+	roles, err := rc.RoleRepository.GetRolesByIds(c.Request.Context(), []uint{uint(roleID)})
 	if err != nil {
 		response.Fail(c, nil, err.Error())
 		return
@@ -162,26 +187,53 @@ func (rc RoleController) UpdateRoleByID(c *gin.Context) {
 		return
 	}
 
+	// 受项目限制的用户不能授予管理全部项目的权限
+	if _, restricted := common.ProjectScope(c.Request.Context()); restricted && req.AllProjects == known.DEFAULT_ID {
+		response.Fail(c, nil, "不能授予管理全部项目的权限")
+		return
+	}
+
 	role := model.Role{
-		Name:    req.Name,
-		Keyword: req.Keyword,
-		Desc:    &req.Desc,
-		Status:  req.Status,
-		Sort:    req.Sort,
-		Creator: ctxUser.Username,
+		Name:         req.Name,
+		Keyword:      req.Keyword,
+		Desc:         &req.Desc,
+		Status:       req.Status,
+		Sort:         req.Sort,
+		TotpRequired: req.TotpRequired,
+		AllProjects:  req.AllProjects,
+		Creator:      ctxUser.Username,
 	}
 
 	// 更新角色
-	err = rc.RoleRepository.UpdateRoleByID(uint(roleID), &role)
+	err = rc.RoleRepository.UpdateRoleByID(c.Request.Context(), uint(roleID), &role)
 	if err != nil {
 		response.Fail(c, nil, "更新角色失败: "+err.Error())
 		return
 	}
 
+	// 更新了角色的keyword, 同步更新角色的继承关系
+	if req.Keyword != roles[0].Keyword {
+		err = rc.RoleRepository.RenameRoleInheritance(roles[0].Keyword, req.Keyword)
+		if err != nil {
+			response.Fail(c, nil, "更新角色成功，但角色的继承关系更新失败: "+err.Error())
+			return
+		}
+	}
+
+	// 启用或禁用了角色, 同步暂停或恢复子角色对该角色的继承
+	if req.Status != roles[0].Status {
+		role.ID = uint(roleID)
+		err = rc.RoleRepository.UpdateRoleInheritanceStatus(c.Request.Context(), &role)
+		if err != nil {
+			response.Fail(c, nil, "更新角色成功，但角色的继承关系更新失败: "+err.Error())
+			return
+		}
+	}
+
 	// 如果更新成功，且更新了角色的keyword, 则更新casbin中policy
 	if req.Keyword != roles[0].Keyword {
 		// 获取policy
-		rolePolicies := common.CasbinEnforcer.GetFilteredPolicy(0, roles[0].Keyword)
+		rolePolicies := rc.Enforcer.GetFilteredPolicy(0, roles[0].Keyword)
 		if len(rolePolicies) == 0 {
 			response.Success(c, nil, "更新角色成功")
 			return
@@ -196,24 +248,24 @@ func (rc RoleController) UpdateRoleByID(c *gin.Context) {
 		}
 
 		//gormadapter未实现UpdatePolicies方法，等gorm更新---
-		//isUpdated, _ := common.CasbinEnforcer.UpdatePolicies(rolePoliciesCopy, rolePolicies)
+		//isUpdated, _ := rc.Enforcer.UpdatePolicies(rolePoliciesCopy, rolePolicies)
 		//if !isUpdated {
 		//	response.Fail(c, nil, "更新角色成功，但角色关键字关联的权限接口更新失败！")
 		//	return
 		//}
 
 		// 这里需要先新增再删除（先删除再增加会出错）
-		isAdded, _ := common.CasbinEnforcer.AddPolicies(rolePolicies)
+		isAdded, _ := rc.Enforcer.AddPolicies(rolePolicies)
 		if !isAdded {
 			response.Fail(c, nil, "更新角色成功，但角色关键字关联的权限接口更新失败")
 			return
 		}
-		isRemoved, _ := common.CasbinEnforcer.RemovePolicies(rolePoliciesCopy)
+		isRemoved, _ := rc.Enforcer.RemovePolicies(rolePoliciesCopy)
 		if !isRemoved {
 			response.Fail(c, nil, "更新角色成功，但角色关键字关联的权限接口更新失败")
 			return
 		}
-		err := common.CasbinEnforcer.LoadPolicy()
+		err := rc.Enforcer.LoadPolicy()
 		if err != nil {
 			response.Fail(c, nil, "更新角色成功，但角色关键字关联角色的权限接口策略加载失败")
 			return
@@ -238,7 +290,7 @@ func (rc RoleController) GetRoleMenusByID(c *gin.Context) {
 		response.Fail(c, nil, "角色ID不正确")
 		return
 	}
-	menus, err := rc.RoleRepository.GetRoleMenusByID(uint(roleID))
+	menus, err := rc.RoleRepository.GetRoleMenusByID(c.Request.Context(), uint(roleID))
 	if err != nil {
 		response.Fail(c, nil, "获取角色的权限菜单失败: "+err.Error())
 		return
@@ -267,7 +319,7 @@ func (rc RoleController) UpdateRoleMenusByID(c *gin.Context) {
 		return
 	}
 	// 根据path中的角色ID获取该角色信息
-	roles, err := rc.RoleRepository.GetRolesByIds([]uint{uint(roleID)})
+	roles, err := rc.RoleRepository.GetRolesByIds(c.Request.Context(), []uint{uint(roleID)})
 	if err != nil {
 		response.Fail(c, nil, err.Error())
 		return
@@ -278,7 +330,7 @@ func (rc RoleController) UpdateRoleMenusByID(c *gin.Context) {
 	}
 
 	// 当前用户角色排序最小值（最高等级角色）以及当前用户
-	ur := repository.NewAdminRepository()
+	ur := rc.AdminRepository
 	minSort, ctxUser, err := ur.GetCurrentAdminMinRoleSort(c)
 	if err != nil {
 		response.Fail(c, nil, err.Error())
@@ -294,8 +346,8 @@ func (rc RoleController) UpdateRoleMenusByID(c *gin.Context) {
 	}
 
 	// 获取当前用户所拥有的权限菜单
-	mr := repository.NewMenuRepository()
-	ctxUserMenus, err := mr.GetUserMenusByUserID(ctxUser.ID)
+	mr := rc.MenuRepository
+	ctxUserMenus, err := mr.GetUserMenusByUserID(c.Request.Context(), ctxUser.ID)
 	if err != nil {
 		response.Fail(c, nil, "获取当前用户的可访问菜单列表失败: "+err.Error())
 		return
@@ -333,7 +385,7 @@ func (rc RoleController) UpdateRoleMenusByID(c *gin.Context) {
 	} else {
 		// 管理员随意设置
 		// 根据menuIds查询查询菜单
-		menus, err := mr.GetMenus()
+		menus, err := mr.GetMenus(c.Request.Context())
 		if err != nil {
 			response.Fail(c, nil, "获取菜单列表失败: "+err.Error())
 			return
@@ -349,7 +401,7 @@ func (rc RoleController) UpdateRoleMenusByID(c *gin.Context) {
 
 	roles[0].Menus = reqMenus
 
-	err = rc.RoleRepository.UpdateRoleMenus(roles[0])
+	err = rc.RoleRepository.UpdateRoleMenus(c.Request.Context(), roles[0])
 	if err != nil {
 		response.Fail(c, nil, "更新角色的权限菜单失败: "+err.Error())
 		return
@@ -368,7 +420,7 @@ func (rc RoleController) GetRoleApisByID(c *gin.Context) {
 		return
 	}
 	// 根据path中的角色ID获取该角色信息
-	roles, err := rc.RoleRepository.GetRolesByIds([]uint{uint(roleID)})
+	roles, err := rc.RoleRepository.GetRolesByIds(c.Request.Context(), []uint{uint(roleID)})
 	if err != nil {
 		response.Fail(c, nil, err.Error())
 		return
@@ -379,7 +431,7 @@ func (rc RoleController) GetRoleApisByID(c *gin.Context) {
 	}
 	// 根据角色keyword获取casbin中policy
 	keyword := roles[0].Keyword
-	apis, err := rc.RoleRepository.GetRoleApisByRoleKeyword(keyword)
+	apis, err := rc.RoleRepository.GetRoleApisByRoleKeyword(c.Request.Context(), keyword)
 	if err != nil {
 		response.Fail(c, nil, err.Error())
 		return
@@ -409,7 +461,7 @@ func (rc RoleController) UpdateRoleApisByID(c *gin.Context) {
 		return
 	}
 	// 根据path中的角色ID获取该角色信息
-	roles, err := rc.RoleRepository.GetRolesByIds([]uint{uint(roleID)})
+	roles, err := rc.RoleRepository.GetRolesByIds(c.Request.Context(), []uint{uint(roleID)})
 	if err != nil {
 		response.Fail(c, nil, err.Error())
 		return
@@ -420,7 +472,7 @@ func (rc RoleController) UpdateRoleApisByID(c *gin.Context) {
 	}
 
 	// 当前用户角色排序最小值（最高等级角色）以及当前用户
-	ur := repository.NewAdminRepository()
+	ur := rc.AdminRepository
 	minSort, ctxUser, err := ur.GetCurrentAdminMinRoleSort(c)
 	if err != nil {
 		response.Fail(c, nil, err.Error())
@@ -439,7 +491,7 @@ func (rc RoleController) UpdateRoleApisByID(c *gin.Context) {
 	ctxRoles := ctxUser.Roles
 	ctxRolesPolicies := make([][]string, 0)
 	for _, role := range ctxRoles {
-		policy := common.CasbinEnforcer.GetFilteredPolicy(0, role.Keyword)
+		policy := rc.Enforcer.GetFilteredPolicy(0, role.Keyword)
 		ctxRolesPolicies = append(ctxRolesPolicies, policy...)
 	}
 	// 得到path中的角色ID对应角色能够设置的权限接口集合
@@ -450,8 +502,8 @@ func (rc RoleController) UpdateRoleApisByID(c *gin.Context) {
 	// 前端传来最新的ApiID集合
 	apiIds := req.ApiIds
 	// 根据apiID获取接口详情
-	ar := repository.NewApiRepository()
-	apis, err := ar.GetApisByID(apiIds)
+	ar := rc.ApiRepository
+	apis, err := ar.GetApisByID(c.Request.Context(), apiIds)
 	if err != nil {
 		response.Fail(c, nil, "根据接口ID获取接口信息失败")
 		return
@@ -475,7 +527,7 @@ func (rc RoleController) UpdateRoleApisByID(c *gin.Context) {
 	}
 
 	// 更新角色的权限接口
-	err = rc.RoleRepository.UpdateRoleApis(roles[0].Keyword, reqRolePolicies)
+	err = rc.RoleRepository.UpdateRoleApis(c.Request.Context(), roles[0].Keyword, reqRolePolicies)
 	if err != nil {
 		response.Fail(c, nil, err.Error())
 		return
@@ -501,7 +553,7 @@ func (rc RoleController) BatchDeleteRoleByIds(c *gin.Context) {
 	}
 
 	// 获取当前用户最高等级角色
-	ur := repository.NewAdminRepository()
+	ur := rc.AdminRepository
 	minSort, _, err := ur.GetCurrentAdminMinRoleSort(c)
 	if err != nil {
 		response.Fail(c, nil, err.Error())
@@ -511,7 +563,7 @@ func (rc RoleController) BatchDeleteRoleByIds(c *gin.Context) {
 	// 前端传来需要删除的角色ID
 	roleIds := req.RoleIds
 	// 获取角色信息
-	roles, err := rc.RoleRepository.GetRolesByIds(roleIds)
+	roles, err := rc.RoleRepository.GetRolesByIds(c.Request.Context(), roleIds)
 	if err != nil {
 		response.Fail(c, nil, "获取角色信息失败: "+err.Error())
 		return
@@ -530,7 +582,7 @@ func (rc RoleController) BatchDeleteRoleByIds(c *gin.Context) {
 	}
 
 	// 删除角色
-	err = rc.RoleRepository.BatchDeleteRoleByIds(roleIds)
+	err = rc.RoleRepository.BatchDeleteRoleByIds(c.Request.Context(), roleIds)
 	if err != nil {
 		response.Fail(c, nil, "删除角色失败")
 		return
@@ -541,3 +593,207 @@ func (rc RoleController) BatchDeleteRoleByIds(c *gin.Context) {
 	response.Success(c, nil, "删除角色成功")
 
 }
+
+// 获取角色可管理的项目
+func (rc RoleController) GetRoleProjectsByID(c *gin.Context) {
+	// 获取path中的roleID
+	roleID, _ := strconv.Atoi(c.Param("roleID"))
+	if roleID <= 0 {
+		response.Fail(c, nil, "角色ID不正确")
+		return
+	}
+	projects, err := rc.RoleRepository.GetRoleProjectsByID(c.Request.Context(), uint(roleID))
+	if err != nil {
+		response.Fail(c, nil, "获取角色可管理的项目失败: "+err.Error())
+		return
+	}
+	response.Success(c, gin.H{"projects": projects}, "获取角色可管理的项目成功")
+}
+
+// 更新角色可管理的项目
+func (rc RoleController) UpdateRoleProjectsByID(c *gin.Context) {
+	var req vo.UpdateRoleProjectsRequest
+	// 参数绑定
+	if err := c.ShouldBind(&req); err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	// 获取path中的roleID
+	roleID, _ := strconv.Atoi(c.Param("roleID"))
+	if roleID <= 0 {
+		response.Fail(c, nil, "角色ID不正确")
+		return
+	}
+	// 根据path中的角色ID获取该角色信息
+	roles, err := rc.RoleRepository.GetRolesByIds(c.Request.Context(), []uint{uint(roleID)})
+	if err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	if len(roles) == 0 {
+		response.Fail(c, nil, "未获取到角色信息")
+		return
+	}
+
+	// 当前用户角色排序最小值（最高等级角色）
+	minSort, _, err := rc.AdminRepository.GetCurrentAdminMinRoleSort(c)
+	if err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	// (非管理员)不能更新比自己角色等级高或相等角色的项目
+	if minSort != 1 {
+		if minSort >= roles[0].Sort {
+			response.Fail(c, nil, "不能更新比自己角色等级高或相等角色的项目")
+			return
+		}
+	}
+	// 只能设置自己可管理的项目
+	err = rc.RoleRepository.UpdateRoleProjects(c.Request.Context(), roles[0], funk.UniqString(req.ProjectIds))
+	if err != nil {
+		response.Fail(c, nil, "更新角色可管理的项目失败: "+err.Error())
+		return
+	}
+	response.Success(c, nil, "更新角色可管理的项目成功")
+}
+
+// 获取角色继承的父角色
+func (rc RoleController) GetRoleParentsByID(c *gin.Context) {
+	// 获取path中的roleID
+	roleID, _ := strconv.Atoi(c.Param("roleID"))
+	if roleID <= 0 {
+		response.Fail(c, nil, "角色ID不正确")
+		return
+	}
+	roles, err := rc.RoleRepository.GetRolesByIds(c.Request.Context(), []uint{uint(roleID)})
+	if err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	if len(roles) == 0 {
+		response.Fail(c, nil, "未获取到角色信息")
+		return
+	}
+	parents, err := rc.RoleRepository.GetRoleParents(c.Request.Context(), roles[0].Keyword)
+	if err != nil {
+		response.Fail(c, nil, "获取角色继承的父角色失败: "+err.Error())
+		return
+	}
+	response.Success(c, gin.H{"parents": parents}, "获取角色继承的父角色成功")
+}
+
+// 更新角色继承的父角色
+// 子角色拥有父角色及其上级角色的全部接口和菜单, 父角色被禁用时暂停继承, 启用后恢复
+func (rc RoleController) UpdateRoleParentsByID(c *gin.Context) {
+	var req vo.UpdateRoleParentsRequest
+	// 参数绑定
+	if err := c.ShouldBind(&req); err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	// 获取path中的roleID
+	roleID, _ := strconv.Atoi(c.Param("roleID"))
+	if roleID <= 0 {
+		response.Fail(c, nil, "角色ID不正确")
+		return
+	}
+	roles, err := rc.RoleRepository.GetRolesByIds(c.Request.Context(), []uint{uint(roleID)})
+	if err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	if len(roles) == 0 {
+		response.Fail(c, nil, "未获取到角色信息")
+		return
+	}
+	parentIds := funk.UniqUInt(req.ParentIds)
+	parents := make([]*model.Role, 0)
+	if len(parentIds) > 0 {
+		parents, err = rc.RoleRepository.GetRolesByIds(c.Request.Context(), parentIds)
+		if err != nil {
+			response.Fail(c, nil, err.Error())
+			return
+		}
+		if len(parents) != len(parentIds) {
+			response.Fail(c, nil, "未获取到全部父角色信息")
+			return
+		}
+	}
+
+	// 当前用户角色排序最小值（最高等级角色）
+	minSort, _, err := rc.AdminRepository.GetCurrentAdminMinRoleSort(c)
+	if err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	// (非管理员)不能更新比自己角色等级高或相等的角色, 也不能让角色继承比自己等级高或相等的角色
+	if minSort != 1 {
+		if minSort >= roles[0].Sort {
+			response.Fail(c, nil, "不能更新比自己角色等级高或相等角色的继承关系")
+			return
+		}
+		for _, parent := range parents {
+			ancestors, err := rc.RoleRepository.GetRoleAncestors(c.Request.Context(), parent.Keyword)
+			if err != nil {
+				response.Fail(c, nil, "获取父角色的上级角色失败: "+err.Error())
+				return
+			}
+			for _, role := range append([]*model.Role{parent}, ancestors...) {
+				if minSort >= role.Sort {
+					response.Fail(c, nil, fmt.Sprintf("无权继承角色%s", role.Name))
+					return
+				}
+			}
+		}
+	}
+
+	err = rc.RoleRepository.UpdateRoleParents(c.Request.Context(), roles[0], parents)
+	if err != nil {
+		response.Fail(c, nil, "更新角色继承的父角色失败: "+err.Error())
+		return
+	}
+	response.Success(c, nil, "更新角色继承的父角色成功")
+}
+
+// 获取角色的有效权限, 包括从上级角色继承的接口和菜单
+func (rc RoleController) GetRolePermissionsByID(c *gin.Context) {
+	// 获取path中的roleID
+	roleID, _ := strconv.Atoi(c.Param("roleID"))
+	if roleID <= 0 {
+		response.Fail(c, nil, "角色ID不正确")
+		return
+	}
+	roles, err := rc.RoleRepository.GetRolesByIds(c.Request.Context(), []uint{uint(roleID)})
+	if err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	if len(roles) == 0 {
+		response.Fail(c, nil, "未获取到角色信息")
+		return
+	}
+	ancestors, err := rc.RoleRepository.GetRoleAncestors(c.Request.Context(), roles[0].Keyword)
+	if err != nil {
+		response.Fail(c, nil, "获取角色继承的上级角色失败: "+err.Error())
+		return
+	}
+
+	grants := make([]dto.RoleGrant, 0, len(ancestors)+1)
+	for _, role := range append([]*model.Role{roles[0]}, ancestors...) {
+		apis, err := rc.RoleRepository.GetRoleApisByRoleKeyword(c.Request.Context(), role.Keyword)
+		if err != nil {
+			response.Fail(c, nil, "获取角色的权限接口失败: "+err.Error())
+			return
+		}
+		menus, err := rc.RoleRepository.GetRoleMenusByID(c.Request.Context(), role.ID)
+		if err != nil {
+			response.Fail(c, nil, "获取角色的权限菜单失败: "+err.Error())
+			return
+		}
+		grants = append(grants, dto.RoleGrant{Role: role, Apis: apis, Menus: menus})
+	}
+	response.Success(c, gin.H{
+		"ancestors":   ancestors,
+		"permissions": dto.ToRolePermissionsDto(roles[0].ID, grants),
+	}, "获取角色的有效权限成功")
+}
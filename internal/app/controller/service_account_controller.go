@@ -0,0 +1,345 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package controller
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gotribe-admin/internal/app/repository"
+	"gotribe-admin/internal/pkg/apikey"
+	"gotribe-admin/internal/pkg/common"
+	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/pkg/api/known"
+	"gotribe-admin/pkg/api/response"
+	"gotribe-admin/pkg/api/vo"
+)
+
+type IServiceAccountController interface {
+	GetServiceAccounts(c *gin.Context)             // 获取服务账号列表
+	CreateServiceAccount(c *gin.Context)           // 创建服务账号
+	UpdateServiceAccountByID(c *gin.Context)       // 更新服务账号
+	BatchDeleteServiceAccountByIds(c *gin.Context) // 批量删除服务账号
+	CreateApiKey(c *gin.Context)                   // 创建API Key
+	RevokeApiKeyByID(c *gin.Context)               // 吊销API Key
+}
+
+type ServiceAccountController struct {
+	ServiceAccountRepository repository.IServiceAccountRepository
+	AdminRepository          repository.IAdminRepository
+	RoleRepository           repository.IRoleRepository
+}
+
+// ServiceAccountController构造函数
+func NewServiceAccountController(serviceAccountRepository repository.IServiceAccountRepository, adminRepository repository.IAdminRepository, roleRepository repository.IRoleRepository) IServiceAccountController {
+	return ServiceAccountController{
+		ServiceAccountRepository: serviceAccountRepository,
+		AdminRepository:          adminRepository,
+		RoleRepository:           roleRepository,
+	}
+}
+
+// 获取服务账号列表
+func (sc ServiceAccountController) GetServiceAccounts(c *gin.Context) {
+	var req vo.ServiceAccountListRequest
+	// 参数绑定
+	if err := c.ShouldBind(&req); err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	// 参数校验
+	if err := common.Validate.Struct(&req); err != nil {
+		errStr := err.(validator.ValidationErrors)[0].Translate(common.Trans)
+		response.Fail(c, nil, errStr)
+		return
+	}
+
+	accounts, total, err := sc.ServiceAccountRepository.GetServiceAccounts(c.Request.Context(), &req)
+	if err != nil {
+		response.Fail(c, nil, "获取服务账号列表失败: "+err.Error())
+		return
+	}
+	response.Success(c, gin.H{"serviceAccounts": accounts, "total": total}, "获取服务账号列表成功")
+}
+
+// 创建服务账号
+func (sc ServiceAccountController) CreateServiceAccount(c *gin.Context) {
+	var req vo.CreateServiceAccountRequest
+	// 参数绑定
+	if err := c.ShouldBind(&req); err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	// 参数校验
+	if err := common.Validate.Struct(&req); err != nil {
+		errStr := err.(validator.ValidationErrors)[0].Translate(common.Trans)
+		response.Fail(c, nil, errStr)
+		return
+	}
+
+	minSort, ctxAdmin, err := sc.AdminRepository.GetCurrentAdminMinRoleSort(c)
+	if err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	role, err := sc.getRole(c, req.RoleID)
+	if err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	// 不能创建比自己等级高或相同等级的服务账号
+	if minSort >= role.Sort {
+		response.Fail(c, nil, "不能创建比自己等级高或相同等级的服务账号")
+		return
+	}
+
+	account := model.ServiceAccount{
+		Name:    req.Name,
+		Desc:    req.Desc,
+		RoleID:  role.ID,
+		Status:  req.Status,
+		Creator: ctxAdmin.Username,
+	}
+	err = sc.ServiceAccountRepository.CreateServiceAccount(c.Request.Context(), &account)
+	if err != nil {
+		response.Fail(c, nil, "创建服务账号失败: "+err.Error())
+		return
+	}
+	account.Role = role
+	response.Success(c, gin.H{"serviceAccount": account}, "创建服务账号成功")
+}
+
+// 更新服务账号
+func (sc ServiceAccountController) UpdateServiceAccountByID(c *gin.Context) {
+	var req vo.CreateServiceAccountRequest
+	// 参数绑定
+	if err := c.ShouldBind(&req); err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	// 参数校验
+	if err := common.Validate.Struct(&req); err != nil {
+		errStr := err.(validator.ValidationErrors)[0].Translate(common.Trans)
+		response.Fail(c, nil, errStr)
+		return
+	}
+	// 获取path中的serviceAccountID
+	accountID, _ := strconv.Atoi(c.Param("serviceAccountID"))
+	if accountID <= 0 {
+		response.Fail(c, nil, "服务账号ID不正确")
+		return
+	}
+
+	minSort, _, err := sc.AdminRepository.GetCurrentAdminMinRoleSort(c)
+	if err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	account, err := sc.ServiceAccountRepository.GetServiceAccountByID(c.Request.Context(), uint(accountID))
+	if err != nil {
+		response.Fail(c, nil, "获取服务账号失败: "+err.Error())
+		return
+	}
+	role, err := sc.getRole(c, req.RoleID)
+	if err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	// 原角色与新角色都需要比自己等级低
+	if (account.Role != nil && minSort >= account.Role.Sort) || minSort >= role.Sort {
+		response.Fail(c, nil, "不能更新比自己等级高或相同等级的服务账号")
+		return
+	}
+
+	err = sc.ServiceAccountRepository.UpdateServiceAccountByID(c.Request.Context(), account.ID, &model.ServiceAccount{
+		Name:   req.Name,
+		Desc:   req.Desc,
+		RoleID: role.ID,
+		Status: req.Status,
+	})
+	if err != nil {
+		response.Fail(c, nil, "更新服务账号失败: "+err.Error())
+		return
+	}
+	response.Success(c, nil, "更新服务账号成功")
+}
+
+// 批量删除服务账号
+func (sc ServiceAccountController) BatchDeleteServiceAccountByIds(c *gin.Context) {
+	var req vo.DeleteServiceAccountRequest
+	// 参数绑定
+	if err := c.ShouldBind(&req); err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	// 参数校验
+	if err := common.Validate.Struct(&req); err != nil {
+		errStr := err.(validator.ValidationErrors)[0].Translate(common.Trans)
+		response.Fail(c, nil, errStr)
+		return
+	}
+
+	minSort, _, err := sc.AdminRepository.GetCurrentAdminMinRoleSort(c)
+	if err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	accounts, err := sc.ServiceAccountRepository.GetServiceAccountsByIds(c.Request.Context(), req.ServiceAccountIds)
+	if err != nil {
+		response.Fail(c, nil, "获取服务账号失败: "+err.Error())
+		return
+	}
+	if len(accounts) == 0 {
+		response.Fail(c, nil, "未获取到服务账号信息")
+		return
+	}
+	for _, account := range accounts {
+		if account.Role != nil && minSort >= account.Role.Sort {
+			response.Fail(c, nil, "不能删除比自己等级高或相同等级的服务账号")
+			return
+		}
+	}
+
+	err = sc.ServiceAccountRepository.BatchDeleteServiceAccountByIds(c.Request.Context(), req.ServiceAccountIds)
+	if err != nil {
+		response.Fail(c, nil, "删除服务账号失败: "+err.Error())
+		return
+	}
+	response.Success(c, nil, "删除服务账号成功")
+}
+
+// 创建API Key, 明文只在本次响应中返回
+func (sc ServiceAccountController) CreateApiKey(c *gin.Context) {
+	var req vo.CreateApiKeyRequest
+	// 参数绑定
+	if err := c.ShouldBind(&req); err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	// 参数校验
+	if err := common.Validate.Struct(&req); err != nil {
+		errStr := err.(validator.ValidationErrors)[0].Translate(common.Trans)
+		response.Fail(c, nil, errStr)
+		return
+	}
+	// 获取path中的serviceAccountID
+	accountID, _ := strconv.Atoi(c.Param("serviceAccountID"))
+	if accountID <= 0 {
+		response.Fail(c, nil, "服务账号ID不正确")
+		return
+	}
+
+	account, ctxAdmin, ok := sc.manageableAccount(c, uint(accountID))
+	if !ok {
+		return
+	}
+
+	var expiresAt *time.Time
+	// 时间格式已由参数校验保证
+	if req.ExpiresAt != "" {
+		t, _ := time.ParseInLocation(known.TIME_FORMAT, req.ExpiresAt, time.Local)
+		if !t.After(time.Now()) {
+			response.Fail(c, nil, "过期时间需晚于当前时间")
+			return
+		}
+		expiresAt = &t
+	}
+	var allowIPs []string
+	for _, ip := range req.AllowIPs {
+		if ip = strings.TrimSpace(ip); ip != "" {
+			allowIPs = append(allowIPs, ip)
+		}
+	}
+	if _, err := apikey.ParseAllowIPs(allowIPs); err != nil {
+		response.Fail(c, nil, "IP白名单格式不正确: "+err.Error())
+		return
+	}
+	if len(strings.Join(allowIPs, ",")) > 500 {
+		response.Fail(c, nil, "IP白名单过长")
+		return
+	}
+
+	key, prefix, hash, err := apikey.Generate()
+	if err != nil {
+		response.Fail(c, nil, "生成API Key失败: "+err.Error())
+		return
+	}
+	apiKey := model.ApiKey{
+		ServiceAccountID: account.ID,
+		Name:             req.Name,
+		Prefix:           prefix,
+		KeyHash:          hash,
+		AllowIPs:         strings.Join(allowIPs, ","),
+		ExpiresAt:        expiresAt,
+		Creator:          ctxAdmin.Username,
+	}
+	err = sc.ServiceAccountRepository.CreateApiKey(c.Request.Context(), &apiKey)
+	if err != nil {
+		response.Fail(c, nil, "创建API Key失败: "+err.Error())
+		return
+	}
+	response.Success(c, gin.H{"apiKey": apiKey, "key": key}, "创建API Key成功, 请妥善保存, 之后将无法再次查看")
+}
+
+// 吊销API Key
+func (sc ServiceAccountController) RevokeApiKeyByID(c *gin.Context) {
+	// 获取path中的apiKeyID
+	apiKeyID, _ := strconv.Atoi(c.Param("apiKeyID"))
+	if apiKeyID <= 0 {
+		response.Fail(c, nil, "API Key ID不正确")
+		return
+	}
+	apiKey, err := sc.ServiceAccountRepository.GetApiKeyByID(c.Request.Context(), uint(apiKeyID))
+	if err != nil {
+		response.Fail(c, nil, "获取API Key失败: "+err.Error())
+		return
+	}
+	if _, _, ok := sc.manageableAccount(c, apiKey.ServiceAccountID); !ok {
+		return
+	}
+
+	err = sc.ServiceAccountRepository.RevokeApiKey(c.Request.Context(), apiKey.ID)
+	if err != nil {
+		response.Fail(c, nil, "吊销API Key失败: "+err.Error())
+		return
+	}
+	response.Success(c, nil, "吊销API Key成功")
+}
+
+// 获取当前用户可管理的服务账号, 服务账号绑定的角色需比当前用户等级低, 失败时已写入响应
+func (sc ServiceAccountController) manageableAccount(c *gin.Context, accountID uint) (*model.ServiceAccount, model.Admin, bool) {
+	minSort, ctxAdmin, err := sc.AdminRepository.GetCurrentAdminMinRoleSort(c)
+	if err != nil {
+		response.Fail(c, nil, err.Error())
+		return nil, ctxAdmin, false
+	}
+	account, err := sc.ServiceAccountRepository.GetServiceAccountByID(c.Request.Context(), accountID)
+	if err != nil {
+		response.Fail(c, nil, "获取服务账号失败: "+err.Error())
+		return nil, ctxAdmin, false
+	}
+	if account.Role != nil && minSort >= account.Role.Sort {
+		response.Fail(c, nil, "不能管理比自己等级高或相同等级的服务账号")
+		return nil, ctxAdmin, false
+	}
+	return account, ctxAdmin, true
+}
+
+// 根据角色ID获取角色
+func (sc ServiceAccountController) getRole(c *gin.Context, roleID uint) (*model.Role, error) {
+	roles, err := sc.RoleRepository.GetRolesByIds(c.Request.Context(), []uint{roleID})
+	if err != nil {
+		return nil, errors.New("根据角色ID获取角色信息失败: " + err.Error())
+	}
+	if len(roles) == 0 {
+		return nil, errors.New("未获取到角色信息")
+	}
+	return roles[0], nil
+}
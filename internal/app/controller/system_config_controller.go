@@ -25,15 +25,14 @@ type SystemConfigController struct {
 }
 
 // 构造函数
-func NewSystemConfigController() ISystemConfigController {
-	systemConfigRepository := repository.NewSystemConfigRepository()
+func NewSystemConfigController(systemConfigRepository repository.ISystemConfigRepository) ISystemConfigController {
 	systemConfigController := SystemConfigController{SystemConfigRepository: systemConfigRepository}
 	return systemConfigController
 }
 
 // 获取当前系统配置信息
 func (tc SystemConfigController) GetSystemConfigInfo(c *gin.Context) {
-	systemConfig, err := tc.SystemConfigRepository.GetSystemConfig()
+	systemConfig, err := tc.SystemConfigRepository.GetSystemConfig(c.Request.Context())
 	if err != nil {
 		response.Fail(c, nil, "获取当前系统配置信息失败: "+err.Error())
 		return
@@ -60,7 +59,7 @@ func (tc SystemConfigController) UpdateSystemConfigByID(c *gin.Context) {
 	}
 
 	// 根据path中的SystemConfigID获取系统配置信息
-	oldSystemConfig, err := tc.SystemConfigRepository.GetSystemConfig()
+	oldSystemConfig, err := tc.SystemConfigRepository.GetSystemConfig(c.Request.Context())
 	if err != nil {
 		response.Fail(c, nil, "获取需要更新的系统配置信息失败: "+err.Error())
 		return
@@ -71,7 +70,7 @@ func (tc SystemConfigController) UpdateSystemConfigByID(c *gin.Context) {
 	oldSystemConfig.Icon = req.Icon
 	oldSystemConfig.Logo = req.Logo
 	// 更新系统配置
-	err = tc.SystemConfigRepository.UpdateSystemConfig(&oldSystemConfig)
+	err = tc.SystemConfigRepository.UpdateSystemConfig(c.Request.Context(), &oldSystemConfig)
 	if err != nil {
 		response.Fail(c, nil, "更新系统配置失败: "+err.Error())
 		return
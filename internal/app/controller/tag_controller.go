@@ -30,15 +30,14 @@ type TagController struct {
 }
 
 // 构造函数
-func NewTagController() ITagController {
-	tagRepository := repository.NewTagRepository()
+func NewTagController(tagRepository repository.ITagRepository) ITagController {
 	tagController := TagController{TagRepository: tagRepository}
 	return tagController
 }
 
 // 获取当前标签信息
 func (tc TagController) GetTagInfo(c *gin.Context) {
-	tag, err := tc.TagRepository.GetTagByTagID(c.Param("tagID"))
+	tag, err := tc.TagRepository.GetTagByTagID(c.Request.Context(), c.Param("tagID"))
 	if err != nil {
 		response.Fail(c, nil, "获取当前标签信息失败: "+err.Error())
 		return
@@ -65,7 +64,7 @@ func (tc TagController) GetTags(c *gin.Context) {
 	}
 
 	// 获取
-	tag, total, err := tc.TagRepository.GetTags(&req)
+	tag, total, err := tc.TagRepository.GetTags(c.Request.Context(), &req)
 	if err != nil {
 		response.Fail(c, nil, "获取标签列表失败: "+err.Error())
 		return
@@ -94,7 +93,7 @@ func (tc TagController) CreateTag(c *gin.Context) {
 		Color:       req.Color,
 	}
 
-	tagInfo, err := tc.TagRepository.CreateTag(&tag)
+	tagInfo, err := tc.TagRepository.CreateTag(c.Request.Context(), &tag)
 	if err != nil {
 		response.Fail(c, nil, "创建标签失败: "+err.Error())
 		return
@@ -118,7 +117,7 @@ func (tc TagController) UpdateTagByID(c *gin.Context) {
 	}
 
 	// 根据path中的TagID获取标签信息
-	oldTag, err := tc.TagRepository.GetTagByTagID(c.Param("tagID"))
+	oldTag, err := tc.TagRepository.GetTagByTagID(c.Request.Context(), c.Param("tagID"))
 	if err != nil {
 		response.Fail(c, nil, "获取需要更新的标签信息失败: "+err.Error())
 		return
@@ -127,7 +126,7 @@ func (tc TagController) UpdateTagByID(c *gin.Context) {
 	oldTag.Description = req.Description
 	oldTag.Color = req.Color
 	// 更新标签
-	err = tc.TagRepository.UpdateTag(&oldTag)
+	err = tc.TagRepository.UpdateTag(c.Request.Context(), &oldTag)
 	if err != nil {
 		response.Fail(c, nil, "更新标签失败: "+err.Error())
 		return
@@ -152,7 +151,7 @@ func (tc TagController) BatchDeleteTagByIds(c *gin.Context) {
 
 	// 前端传来的标签ID
 	reqTagIds := strings.Split(req.TagIds, ",")
-	err := tc.TagRepository.BatchDeleteTagByIds(reqTagIds)
+	err := tc.TagRepository.BatchDeleteTagByIds(c.Request.Context(), reqTagIds)
 	if err != nil {
 		response.Fail(c, nil, "删除标签失败: "+err.Error())
 		return
@@ -0,0 +1,336 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/thoas/go-funk"
+	"gotribe-admin/internal/app/repository"
+	"gotribe-admin/internal/pkg/common"
+	"gotribe-admin/internal/pkg/loginguard"
+	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/internal/pkg/totp"
+	"gotribe-admin/pkg/api/known"
+	"gotribe-admin/pkg/api/response"
+	"gotribe-admin/pkg/api/vo"
+	"gotribe-admin/pkg/util"
+)
+
+type ITotpController interface {
+	Login(c *gin.Context)       // 提交两步验证码, 校验通过后签发token
+	LoginEnroll(c *gin.Context) // 角色要求两步验证但未启用时, 在登录过程中绑定验证器
+	Enroll(c *gin.Context)      // 当前用户绑定验证器
+	Activate(c *gin.Context)    // 当前用户提交验证码启用两步验证
+	Disable(c *gin.Context)     // 当前用户关闭两步验证
+	Reset(c *gin.Context)       // 重置其他用户的两步验证, 用于丢失验证器且没有恢复码的用户
+}
+
+type TotpController struct {
+	AdminRepository repository.IAdminRepository
+	Auth            *jwt.GinJWTMiddleware
+	Guard           *loginguard.Guard
+}
+
+// TotpController构造函数, 登录成功后通过jwt中间件签发token, 验证码错误与密码错误一样计入登录失败次数
+func NewTotpController(adminRepository repository.IAdminRepository, authMiddleware *jwt.GinJWTMiddleware, guard *loginguard.Guard) ITotpController {
+	return TotpController{AdminRepository: adminRepository, Auth: authMiddleware, Guard: guard}
+}
+
+// 提交两步验证码, 校验通过后签发token
+func (tc TotpController) Login(c *gin.Context) {
+	var req vo.TotpLoginRequest
+	// 参数绑定
+	if err := c.ShouldBind(&req); err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	// 参数校验
+	if err := common.Validate.Struct(&req); err != nil {
+		errStr := err.(validator.ValidationErrors)[0].Translate(common.Trans)
+		response.Fail(c, nil, errStr)
+		return
+	}
+
+	admin, err := tc.challengeAdmin(c, req.ChallengeToken)
+	if err != nil {
+		response.Response(c, http.StatusUnauthorized, http.StatusUnauthorized, nil, err.Error())
+		return
+	}
+	if admin.TotpSecret == "" {
+		response.Fail(c, nil, "请先绑定验证器")
+		return
+	}
+	if locked := tc.Guard.Check(c, admin.ID, admin.Username); locked != nil {
+		loginguard.Respond(c, locked)
+		return
+	}
+	// 绑定中的验证器在第一次校验通过时启用
+	if !tc.verify(&admin, req.Code) {
+		if locked := tc.Guard.Fail(c, admin.ID, admin.Username, "验证码错误"); locked != nil {
+			loginguard.Respond(c, locked)
+			return
+		}
+		response.Response(c, http.StatusUnauthorized, http.StatusUnauthorized, nil, "验证码错误")
+		return
+	}
+	admin.TotpEnabled = true
+	if err := tc.AdminRepository.UpdateAdminTotp(c.Request.Context(), &admin); err != nil {
+		response.Fail(c, nil, "更新两步验证信息失败: "+err.Error())
+		return
+	}
+
+	// 与密码登录的数据一致, payloadFunc会使用到
+	token, expire, err := tc.Auth.TokenGenerator(map[string]interface{}{
+		"user": util.Struct2Json(admin),
+	})
+	if err != nil {
+		response.Fail(c, nil, "签发token失败: "+err.Error())
+		return
+	}
+	tc.Guard.Succeed(c, admin.ID, admin.Username)
+	tc.Auth.LoginResponse(c, http.StatusOK, token, expire)
+}
+
+// 角色要求两步验证但未启用时, 在登录过程中绑定验证器
+func (tc TotpController) LoginEnroll(c *gin.Context) {
+	var req vo.TotpLoginEnrollRequest
+	// 参数绑定
+	if err := c.ShouldBind(&req); err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	// 参数校验
+	if err := common.Validate.Struct(&req); err != nil {
+		errStr := err.(validator.ValidationErrors)[0].Translate(common.Trans)
+		response.Fail(c, nil, errStr)
+		return
+	}
+
+	admin, err := tc.challengeAdmin(c, req.ChallengeToken)
+	if err != nil {
+		response.Response(c, http.StatusUnauthorized, http.StatusUnauthorized, nil, err.Error())
+		return
+	}
+	tc.enroll(c, &admin)
+}
+
+// 当前用户绑定验证器, 返回二维码内容与恢复码, 提交验证码后启用
+func (tc TotpController) Enroll(c *gin.Context) {
+	admin, ok := tc.currentAdmin(c)
+	if !ok {
+		return
+	}
+	tc.enroll(c, &admin)
+}
+
+// 当前用户提交验证码启用两步验证
+func (tc TotpController) Activate(c *gin.Context) {
+	var req vo.TotpCodeRequest
+	// 参数绑定
+	if err := c.ShouldBind(&req); err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	// 参数校验
+	if err := common.Validate.Struct(&req); err != nil {
+		errStr := err.(validator.ValidationErrors)[0].Translate(common.Trans)
+		response.Fail(c, nil, errStr)
+		return
+	}
+
+	admin, ok := tc.currentAdmin(c)
+	if !ok {
+		return
+	}
+	if admin.TotpEnabled {
+		response.Fail(c, nil, "已启用两步验证")
+		return
+	}
+	if admin.TotpSecret == "" {
+		response.Fail(c, nil, "请先绑定验证器")
+		return
+	}
+	if !tc.verify(&admin, req.Code) {
+		response.Fail(c, nil, "验证码错误")
+		return
+	}
+	admin.TotpEnabled = true
+	if err := tc.AdminRepository.UpdateAdminTotp(c.Request.Context(), &admin); err != nil {
+		response.Fail(c, nil, "启用两步验证失败: "+err.Error())
+		return
+	}
+	response.Success(c, nil, "启用两步验证成功")
+}
+
+// 当前用户关闭两步验证, 需要提交验证码或恢复码
+func (tc TotpController) Disable(c *gin.Context) {
+	var req vo.TotpCodeRequest
+	// 参数绑定
+	if err := c.ShouldBind(&req); err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	// 参数校验
+	if err := common.Validate.Struct(&req); err != nil {
+		errStr := err.(validator.ValidationErrors)[0].Translate(common.Trans)
+		response.Fail(c, nil, errStr)
+		return
+	}
+
+	admin, ok := tc.currentAdmin(c)
+	if !ok {
+		return
+	}
+	if !admin.TotpEnabled {
+		response.Fail(c, nil, "未启用两步验证")
+		return
+	}
+	if totp.Required(admin) {
+		response.Fail(c, nil, "角色要求启用两步验证, 不能关闭")
+		return
+	}
+	if !tc.verify(&admin, req.Code) {
+		response.Fail(c, nil, "验证码错误")
+		return
+	}
+	if err := tc.clear(c, &admin); err != nil {
+		response.Fail(c, nil, "关闭两步验证失败: "+err.Error())
+		return
+	}
+	response.Success(c, nil, "关闭两步验证成功")
+}
+
+// 重置其他用户的两步验证, 用户下次登录时重新绑定
+func (tc TotpController) Reset(c *gin.Context) {
+	// 获取path中的userID
+	userID, _ := strconv.Atoi(c.Param("userID"))
+	if userID <= 0 {
+		response.Fail(c, nil, "用户ID不正确")
+		return
+	}
+
+	minSort, _, err := tc.AdminRepository.GetCurrentAdminMinRoleSort(c)
+	if err != nil {
+		response.Fail(c, nil, err.Error())
+		return
+	}
+	admin, err := tc.AdminRepository.GetAdminByID(c.Request.Context(), uint(userID))
+	if err != nil {
+		response.Fail(c, nil, "获取用户信息失败: "+err.Error())
+		return
+	}
+	// 不能重置比自己等级高或相同等级的用户
+	var sorts []int
+	for _, role := range admin.Roles {
+		sorts = append(sorts, int(role.Sort))
+	}
+	if len(sorts) > 0 && minSort >= uint(funk.MinInt(sorts)) {
+		response.Fail(c, nil, "不能重置比自己等级高或相同等级的用户的两步验证")
+		return
+	}
+	if err := tc.clear(c, &admin); err != nil {
+		response.Fail(c, nil, "重置两步验证失败: "+err.Error())
+		return
+	}
+	response.Success(c, nil, "重置两步验证成功")
+}
+
+// 生成密钥与恢复码, 保存为绑定中, 第一次校验通过时启用
+func (tc TotpController) enroll(c *gin.Context, admin *model.Admin) {
+	if admin.TotpEnabled {
+		response.Fail(c, nil, "已启用两步验证, 请先关闭")
+		return
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		response.Fail(c, nil, "生成两步验证密钥失败: "+err.Error())
+		return
+	}
+	codes, hashes, err := totp.GenerateRecoveryCodes()
+	if err != nil {
+		response.Fail(c, nil, "生成恢复码失败: "+err.Error())
+		return
+	}
+	admin.TotpSecret = secret
+	admin.TotpRecoveryCodes = hashes
+	admin.TotpLastStep = 0
+	if err := tc.AdminRepository.UpdateAdminTotp(c.Request.Context(), admin); err != nil {
+		response.Fail(c, nil, "保存两步验证密钥失败: "+err.Error())
+		return
+	}
+	response.Success(c, gin.H{
+		"secret":        secret,
+		"uri":           totp.URI(common.TotpIssuer(), admin.Username, secret),
+		"recoveryCodes": codes,
+	}, "请使用验证器扫描二维码, 并妥善保存恢复码, 提交验证码后启用")
+}
+
+// 校验验证码或恢复码, 通过时更新admin中的周期或剩余恢复码, 由调用方保存
+func (tc TotpController) verify(admin *model.Admin, code string) bool {
+	if step, ok := totp.Verify(admin.TotpSecret, code, time.Now(), admin.TotpLastStep); ok {
+		admin.TotpLastStep = step
+		return true
+	}
+	// 恢复码只能在已启用后使用
+	if admin.TotpEnabled {
+		if remaining, ok := totp.UseRecoveryCode(admin.TotpRecoveryCodes, code); ok {
+			admin.TotpRecoveryCodes = remaining
+			return true
+		}
+	}
+	return false
+}
+
+// 清除两步验证信息
+func (tc TotpController) clear(c *gin.Context, admin *model.Admin) error {
+	admin.TotpSecret = ""
+	admin.TotpEnabled = false
+	admin.TotpRecoveryCodes = ""
+	admin.TotpLastStep = 0
+	return tc.AdminRepository.UpdateAdminTotp(c.Request.Context(), admin)
+}
+
+// challenge对应的用户, 用户状态与密码登录时一致
+func (tc TotpController) challengeAdmin(c *gin.Context, challenge string) (model.Admin, error) {
+	adminID, err := totp.ParseChallenge(challenge, common.TotpChallengeKey())
+	if err != nil {
+		return model.Admin{}, err
+	}
+	admin, err := tc.AdminRepository.GetAdminByID(c.Request.Context(), adminID)
+	if err != nil {
+		return admin, errors.New("用户不存在")
+	}
+	if admin.Status != 1 {
+		return admin, errors.New("用户被禁用")
+	}
+	return admin, nil
+}
+
+// 当前登录用户, 从数据库获取以取得最新的两步验证信息, 失败时已写入响应
+func (tc TotpController) currentAdmin(c *gin.Context) (model.Admin, bool) {
+	if _, ok := c.Get(known.SERVICE_ACCOUNT_KEY); ok {
+		response.Fail(c, nil, "服务账号不支持两步验证")
+		return model.Admin{}, false
+	}
+	ctxAdmin, err := tc.AdminRepository.GetCurrentAdmin(c)
+	if err != nil {
+		response.Fail(c, nil, err.Error())
+		return ctxAdmin, false
+	}
+	admin, err := tc.AdminRepository.GetAdminByID(c.Request.Context(), ctxAdmin.ID)
+	if err != nil {
+		response.Fail(c, nil, "获取用户信息失败: "+err.Error())
+		return admin, false
+	}
+	return admin, true
+}
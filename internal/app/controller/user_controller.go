@@ -32,15 +32,14 @@ type UserController struct {
 }
 
 // 构造函数
-func NewUserController() IUserController {
-	userRepository := repository.NewUserRepository()
+func NewUserController(userRepository repository.IUserRepository) IUserController {
 	userController := UserController{UserRepository: userRepository}
 	return userController
 }
 
 // 获取当前用户信息
 func (pc UserController) GetUserInfo(c *gin.Context) {
-	user, err := pc.UserRepository.GetUserByUserID(c.Param("userID"))
+	user, err := pc.UserRepository.GetUserByUserID(c.Request.Context(), c.Param("userID"))
 	if err != nil {
 		response.Fail(c, nil, "获取当前用户信息失败: "+err.Error())
 		return
@@ -67,7 +66,7 @@ func (pc UserController) GetUsers(c *gin.Context) {
 	}
 
 	// 获取
-	user, total, err := pc.UserRepository.GetUsers(&req)
+	user, total, err := pc.UserRepository.GetUsers(c.Request.Context(), &req)
 	if err != nil {
 		response.Fail(c, nil, "获取用户列表失败: "+err.Error())
 		return
@@ -98,7 +97,7 @@ func (pc UserController) CreateUser(c *gin.Context) {
 		ProjectID: req.ProjectID,
 	}
 
-	err := pc.UserRepository.CreateUser(&user)
+	err := pc.UserRepository.CreateUser(c.Request.Context(), &user)
 	if err != nil {
 		response.Fail(c, nil, "创建用户失败: "+err.Error())
 		return
@@ -123,7 +122,7 @@ func (pc UserController) UpdateUserByID(c *gin.Context) {
 	}
 
 	// 根据path中的UserID获取用户信息
-	oldUser, err := pc.UserRepository.GetUserByUserID(c.Param("userID"))
+	oldUser, err := pc.UserRepository.GetUserByUserID(c.Request.Context(), c.Param("userID"))
 	if err != nil {
 		response.Fail(c, nil, "获取需要更新的用户信息失败: "+err.Error())
 		return
@@ -137,7 +136,7 @@ func (pc UserController) UpdateUserByID(c *gin.Context) {
 	}
 
 	// 更新用户
-	err = pc.UserRepository.UpdateUser(&oldUser)
+	err = pc.UserRepository.UpdateUser(c.Request.Context(), &oldUser)
 	if err != nil {
 		response.Fail(c, nil, "更新用户失败: "+err.Error())
 		return
@@ -162,7 +161,7 @@ func (tc UserController) BatchDeleteUserByIds(c *gin.Context) {
 
 	// 前端传来的标签ID
 	reqUserIds := strings.Split(req.UserIds, ",")
-	err := tc.UserRepository.BatchDeleteUserByIds(reqUserIds)
+	err := tc.UserRepository.BatchDeleteUserByIds(c.Request.Context(), reqUserIds)
 	if err != nil {
 		response.Fail(c, nil, "删除用户失败: "+err.Error())
 		return
@@ -173,7 +172,7 @@ func (tc UserController) BatchDeleteUserByIds(c *gin.Context) {
 }
 
 func (tc UserController) SearchUserByUsername(c *gin.Context) {
-	user, err := tc.UserRepository.SearchUserByNickname(c.Param("nickname"))
+	user, err := tc.UserRepository.SearchUserByNickname(c.Request.Context(), c.Param("nickname"))
 	if err != nil {
 		response.Fail(c, nil, "获取需要更新的用户信息失败: "+err.Error())
 		return
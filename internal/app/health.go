@@ -0,0 +1,40 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package app
+
+import (
+	"context"
+	"errors"
+	"gotribe-admin/internal/pkg/common"
+)
+
+// 注册容器自身依赖的就绪检查, 定时任务由serve启动后单独注册
+func (c *Container) addHealthChecks() {
+	c.Health.Add("database", func(ctx context.Context) error {
+		sqlDB, err := c.DB.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+	c.Health.Add("casbin", func(ctx context.Context) error {
+		if c.Enforcer == nil {
+			return errors.New("casbin未初始化")
+		}
+		if len(c.Enforcer.GetPolicy()) == 0 {
+			return errors.New("casbin策略未加载")
+		}
+		return nil
+	})
+	// 上传配置支持热更新, 每次检查按当前配置创建存储服务
+	c.Health.Add("upload", func(ctx context.Context) error {
+		uploadService, err := common.NewUploadService()
+		if err != nil {
+			return err
+		}
+		return uploadService.Ping(ctx)
+	})
+}
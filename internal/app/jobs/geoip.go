@@ -0,0 +1,98 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"gotribe-admin/internal/app"
+	"gotribe-admin/internal/pkg/geoip"
+	"gotribe-admin/internal/pkg/model"
+)
+
+// 每次查询待回填记录的条数
+const geoIPBackfillBatch = 500
+
+// 需要回填归属地的表
+type geoIPBackfillTarget struct {
+	name   string
+	model  interface{}
+	column string // 归属地为空时回填, 用于筛选待处理记录
+	values func(location geoip.Location) map[string]interface{}
+	cursor uint // 已处理到的记录ID, 查不到归属地的记录不会被重复处理
+}
+
+// 历史记录IP归属地回填任务, 每次执行处理上次之后新增的、归属地为空的记录
+type GeoIPBackfill struct {
+	mu      sync.Mutex
+	targets []*geoIPBackfillTarget
+}
+
+// GeoIPBackfill构造函数
+func NewGeoIPBackfill() *GeoIPBackfill {
+	regionValues := func(location geoip.Location) map[string]interface{} {
+		return map[string]interface{}{"country": location.Country, "region_name": location.Region, "city": location.City}
+	}
+	locationValues := func(location geoip.Location) map[string]interface{} {
+		return map[string]interface{}{"ip_location": location.String()}
+	}
+	return &GeoIPBackfill{targets: []*geoIPBackfillTarget{
+		{name: "operation_log", model: &model.OperationLog{}, column: "ip_location", values: locationValues},
+		{name: "login_log", model: &model.LoginLog{}, column: "ip_location", values: locationValues},
+		{name: "comment", model: &model.Comment{}, column: "country", values: regionValues},
+		{name: "user_event", model: &model.UserEvent{}, column: "country", values: regionValues},
+	}}
+}
+
+// 执行回填
+func (b *GeoIPBackfill) Run(c *app.Container) error {
+	if geoip.IsNop(c.GeoIP) {
+		return nil
+	}
+	// 上次执行未结束时跳过
+	if !b.mu.TryLock() {
+		return nil
+	}
+	defer b.mu.Unlock()
+	ctx := context.Background()
+	for _, target := range b.targets {
+		if err := b.backfill(ctx, c, target); err != nil {
+			return fmt.Errorf("%s: %w", target.name, err)
+		}
+	}
+	return nil
+}
+
+func (b *GeoIPBackfill) backfill(ctx context.Context, c *app.Container, target *geoIPBackfillTarget) error {
+	for {
+		var rows []struct {
+			ID uint
+			IP string
+		}
+		err := c.DB.WithContext(ctx).Model(target.model).Select("id", "ip").
+			Where("id > ? AND ip <> '' AND "+target.column+" = ''", target.cursor).
+			Order("id").Limit(geoIPBackfillBatch).Find(&rows).Error
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			location := c.LookupIP(row.IP)
+			if !location.IsZero() {
+				// 只更新归属地字段, 不修改更新时间
+				err := c.DB.WithContext(ctx).Model(target.model).Where("id = ?", row.ID).UpdateColumns(target.values(location)).Error
+				if err != nil {
+					return err
+				}
+			}
+			target.cursor = row.ID
+		}
+		if len(rows) < geoIPBackfillBatch {
+			return nil
+		}
+	}
+}
@@ -5,26 +5,85 @@
 
 package jobs
 
-import "github.com/robfig/cron/v3"
+import (
+	"context"
+	"errors"
+	"sync/atomic"
 
-var Cron *cron.Cron
+	"github.com/robfig/cron/v3"
+	"gotribe-admin/internal/app"
+	"gotribe-admin/internal/pkg/geoip"
+)
 
-func InitCron() {
+// 定时任务调度器, 记录运行状态供就绪检查使用
+type Scheduler struct {
+	*cron.Cron
+	running atomic.Bool
+}
+
+// 启动调度
+func (s *Scheduler) Start() {
+	s.Cron.Start()
+	s.running.Store(true)
+}
+
+// 停止调度, 返回的context在运行中的任务结束后关闭
+func (s *Scheduler) Stop() context.Context {
+	s.running.Store(false)
+	return s.Cron.Stop()
+}
+
+// 就绪检查, 调度器未启动时失败
+func (s *Scheduler) Check(ctx context.Context) error {
+	if !s.running.Load() {
+		return errors.New("定时任务未运行")
+	}
+	return nil
+}
+
+// 初始化定时任务, 任务使用c中的依赖, 同时注册定时任务的就绪检查
+func InitCron(c *app.Container) *Scheduler {
 	secondParser := cron.NewParser(
 		cron.SecondOptional | cron.Minute | cron.Hour |
 			cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
 	)
 	job := cron.New(cron.WithParser(secondParser), cron.WithChain())
-	Cron = job
-	JobRun(job)
+	JobRun(job, c)
+	s := &Scheduler{Cron: job}
+	c.Health.Add("cron", s.Check)
+	return s
 }
 
-func JobRun(job *cron.Cron) {
+func JobRun(job *cron.Cron, c *app.Container) {
 	// 示例定时任务
 	//job.AddFunc("@every 5s", func() {
 	//	exampleJob()
 	//})
 	job.AddFunc("@every 1m", func() {
-		sitemapJob()
+		runJob(c, "sitemap", sitemapJob)
+	})
+	// 每天凌晨3:30清理超过保留天数的操作日志
+	job.AddFunc("0 30 3 * * *", func() {
+		runJob(c, "operation_log_retention", OperationLogRetention)
 	})
+	// 每小时清理过期的登录失败记录
+	job.AddFunc("@every 1h", func() {
+		runJob(c, "login_lock_cleanup", LoginLockCleanup)
+	})
+	// 配置了IP归属地数据库时回填历史记录的归属地
+	if !geoip.IsNop(c.GeoIP) {
+		backfill := NewGeoIPBackfill()
+		job.AddFunc("@every 10m", func() {
+			runJob(c, "geoip_backfill", backfill.Run)
+		})
+	}
+}
+
+// 执行定时任务, 记录执行次数与失败次数
+func runJob(c *app.Container, name string, fn func(c *app.Container) error) {
+	err := fn(c)
+	if err != nil {
+		c.Log.Errorf("定时任务%s执行失败: %v", name, err)
+	}
+	c.Metrics.ObserveJob(name, err)
 }
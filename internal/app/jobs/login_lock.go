@@ -0,0 +1,19 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"gotribe-admin/internal/app"
+)
+
+// 删除已过期的登录失败记录, 过期记录不再参与锁定判断
+func LoginLockCleanup(c *app.Container) error {
+	_, err := c.Repositories.LoginLock.DeleteExpiredLoginLocks(context.Background(), time.Now())
+	return err
+}
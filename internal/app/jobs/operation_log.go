@@ -0,0 +1,89 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package jobs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gotribe-admin/internal/app"
+	"gotribe-admin/internal/pkg/common"
+	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/pkg/api/known"
+	"gotribe-admin/pkg/util/upload"
+)
+
+// 每次清理的条数, 同时是单个归档文件的最大条数
+const operationLogRetentionBatch = 5000
+
+// 清理超过保留天数的操作日志, 开启归档时先上传归档文件, 上传成功后才删除
+func OperationLogRetention(c *app.Container) error {
+	days := common.OperationLogRetentionDays()
+	if days <= 0 {
+		return nil
+	}
+	archive, dir := common.OperationLogArchive()
+	var uploader *upload.Service
+	if archive {
+		var err error
+		if uploader, err = common.NewUploadService(); err != nil {
+			return err
+		}
+	}
+
+	ctx := context.Background()
+	repo := c.Repositories.OperationLog
+	cutoff := time.Now().AddDate(0, 0, -days)
+	for {
+		logs, err := repo.GetOperationLogsBefore(ctx, cutoff, operationLogRetentionBatch)
+		if err != nil {
+			return err
+		}
+		if len(logs) == 0 {
+			return nil
+		}
+		if archive {
+			if err := archiveOperationLogs(ctx, uploader, dir, logs); err != nil {
+				return fmt.Errorf("归档操作日志失败: %w", err)
+			}
+		}
+		ids := make([]uint, len(logs))
+		for i, log := range logs {
+			ids[i] = log.ID
+		}
+		if err := repo.BatchDeleteOperationLogByIds(ctx, ids); err != nil {
+			return err
+		}
+		if len(logs) < operationLogRetentionBatch {
+			return nil
+		}
+	}
+}
+
+// 将操作日志写为gzip压缩的json lines文件并上传
+// 文件名包含日期与ID范围, 删除失败后重试时覆盖同一文件
+func archiveOperationLogs(ctx context.Context, uploader *upload.Service, dir string, logs []model.OperationLog) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(gz)
+	for i := range logs {
+		if err := encoder.Encode(&logs[i]); err != nil {
+			return err
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	first, last := logs[0], logs[len(logs)-1]
+	key := fmt.Sprintf("%s/%s/operation_log_%d-%d.jsonl.gz", dir, first.StartTime.Format(known.TIME_FORMAT_DAY), first.ID, last.ID)
+	_, err := uploader.PutFile(ctx, key, buf.Bytes())
+	return err
+}
@@ -6,19 +6,20 @@
 package jobs
 
 import (
+	"context"
+	"fmt"
 	"github.com/dengmengmian/ghelper/gconvert"
 	"github.com/douyacun/gositemap"
-	"gotribe-admin/internal/app/repository"
-	"gotribe-admin/internal/pkg/common"
+	"gotribe-admin/internal/app"
 	"gotribe-admin/internal/pkg/model"
 )
 
-func sitemapJob() {
+func sitemapJob(c *app.Container) error {
 	// 查出 porject 信息
-	projects, err := repository.NewProjectRepository().GetProjectsBySitemap()
+	ctx := context.Background()
+	projects, err := c.Repositories.Project.GetProjectsBySitemap(ctx)
 	if err != nil {
-		common.Log.Error("sitemapJob:", err.Error())
-		return
+		return err
 	}
 	var posts []*model.Post
 	st := gositemap.NewSiteMap()
@@ -27,9 +28,8 @@ func sitemapJob() {
 	for idx, project := range projects {
 		st.SetFilename(project.ProjectID + gconvert.String(idx) + ".xml")
 
-		if err := common.DB.Model(&model.Post{}).Where("status = ? and type != ? and project_id = ?", 2, 2, project.ProjectID).Find(&posts).Error; err != nil {
-			common.Log.Error("post:", err.Error())
-			return
+		if err := c.DB.WithContext(ctx).Model(&model.Post{}).Where("status = ? and type != ? and project_id = ?", 2, 2, project.ProjectID).Find(&posts).Error; err != nil {
+			return fmt.Errorf("post: %w", err)
 		}
 		for _, post := range posts {
 			url := gositemap.NewUrl()
@@ -41,5 +41,5 @@ func sitemapJob() {
 		}
 	}
 	st.Storage()
-	return
+	return nil
 }
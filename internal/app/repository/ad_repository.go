@@ -6,42 +6,44 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/dengmengmian/ghelper/gconvert"
-	"gotribe-admin/internal/pkg/common"
+	"gorm.io/gorm"
 	"gotribe-admin/internal/pkg/model"
 	"gotribe-admin/pkg/api/vo"
 	"strings"
 )
 
 type IAdRepository interface {
-	CreateAd(ad *model.Ad) error                              // 创建推广场景
-	GetAdByAdID(adID string) (model.Ad, error)                // 获取单个推广场景
-	GetAds(req *vo.AdListRequest) ([]*model.Ad, int64, error) // 获取推广场景列表
-	UpdateAd(ad *model.Ad) error                              // 更新推广场景
-	BatchDeleteAdByIds(ids []string) error                    // 批量删除
+	CreateAd(ctx context.Context, ad *model.Ad) error                              // 创建推广场景
+	GetAdByAdID(ctx context.Context, adID string) (model.Ad, error)                // 获取单个推广场景
+	GetAds(ctx context.Context, req *vo.AdListRequest) ([]*model.Ad, int64, error) // 获取推广场景列表
+	UpdateAd(ctx context.Context, ad *model.Ad) error                              // 更新推广场景
+	BatchDeleteAdByIds(ctx context.Context, ids []string) error                    // 批量删除
 }
 
 type AdRepository struct {
+	db *gorm.DB
 }
 
 // AdRepository构造函数
-func NewAdRepository() IAdRepository {
-	return AdRepository{}
+func NewAdRepository(db *gorm.DB) IAdRepository {
+	return AdRepository{db: db}
 }
 
 // 获取单个推广场景
-func (cr AdRepository) GetAdByAdID(adID string) (model.Ad, error) {
+func (cr AdRepository) GetAdByAdID(ctx context.Context, adID string) (model.Ad, error) {
 	var ad model.Ad
-	err := common.DB.Where("ad_id = ?", adID).First(&ad).Error
+	err := cr.db.WithContext(ctx).Where("ad_id = ?", adID).First(&ad).Error
 	return ad, err
 }
 
 // 获取推广场景列表
-func (cr AdRepository) GetAds(req *vo.AdListRequest) ([]*model.Ad, int64, error) {
+func (cr AdRepository) GetAds(ctx context.Context, req *vo.AdListRequest) ([]*model.Ad, int64, error) {
 	var list []*model.Ad
-	db := common.DB.Model(&model.Ad{}).Order("created_at DESC")
+	db := cr.db.WithContext(ctx).Model(&model.Ad{}).Order("created_at DESC")
 
 	adSceneID := strings.TrimSpace(req.SceneID)
 	if !gconvert.IsEmpty(adSceneID) {
@@ -67,28 +69,28 @@ func (cr AdRepository) GetAds(req *vo.AdListRequest) ([]*model.Ad, int64, error)
 	} else {
 		err = db.Find(&list).Error
 	}
-	return GetAdOther(list), total, err
+	return cr.GetAdOther(ctx, list), total, err
 }
 
 // 获取推广场景其他信息
-func GetAdOther(ads []*model.Ad) []*model.Ad {
+func (cr AdRepository) GetAdOther(ctx context.Context, ads []*model.Ad) []*model.Ad {
 	for _, m := range ads {
 		var adScene *model.AdScene
-		_ = common.DB.Where("ad_scene_id = ?", m.SceneID).First(&adScene).Error
+		_ = cr.db.WithContext(ctx).Where("ad_scene_id = ?", m.SceneID).First(&adScene).Error
 		m.Scene = adScene
 	}
 	return ads
 }
 
 // 创建推广场景
-func (cr AdRepository) CreateAd(ad *model.Ad) error {
-	err := common.DB.Create(ad).Error
+func (cr AdRepository) CreateAd(ctx context.Context, ad *model.Ad) error {
+	err := cr.db.WithContext(ctx).Create(ad).Error
 	return err
 }
 
 // 更新推广场景
-func (cr AdRepository) UpdateAd(ad *model.Ad) error {
-	err := common.DB.Model(ad).Updates(ad).Error
+func (cr AdRepository) UpdateAd(ctx context.Context, ad *model.Ad) error {
+	err := cr.db.WithContext(ctx).Model(ad).Updates(ad).Error
 	if err != nil {
 		return err
 	}
@@ -97,18 +99,18 @@ func (cr AdRepository) UpdateAd(ad *model.Ad) error {
 }
 
 // 批量删除
-func (cr AdRepository) BatchDeleteAdByIds(ids []string) error {
+func (cr AdRepository) BatchDeleteAdByIds(ctx context.Context, ids []string) error {
 	var ads []model.Ad
 	for _, id := range ids {
 		// 根据ID获取标签
-		ad, err := cr.GetAdByAdID(id)
+		ad, err := cr.GetAdByAdID(ctx, id)
 		if err != nil {
 			return errors.New(fmt.Sprintf("未获取到ID为%s的推广场景", id))
 		}
 		ads = append(ads, ad)
 	}
 
-	err := common.DB.Unscoped().Delete(&ads).Error
+	err := cr.db.WithContext(ctx).Unscoped().Delete(&ads).Error
 
 	return err
 }
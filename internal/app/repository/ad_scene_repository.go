@@ -6,42 +6,44 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/dengmengmian/ghelper/gconvert"
-	"gotribe-admin/internal/pkg/common"
+	"gorm.io/gorm"
 	"gotribe-admin/internal/pkg/model"
 	"gotribe-admin/pkg/api/vo"
 	"strings"
 )
 
 type IAdSceneRepository interface {
-	CreateAdScene(adScene *model.AdScene) error                              // 创建推广场景
-	GetAdSceneByAdSceneID(adSceneID string) (model.AdScene, error)           // 获取单个推广场景
-	GetAdScenes(req *vo.AdSceneListRequest) ([]*model.AdScene, int64, error) // 获取推广场景列表
-	UpdateAdScene(adScene *model.AdScene) error                              // 更新推广场景
-	BatchDeleteAdSceneByIds(ids []string) error                              // 批量删除
+	CreateAdScene(ctx context.Context, adScene *model.AdScene) error                              // 创建推广场景
+	GetAdSceneByAdSceneID(ctx context.Context, adSceneID string) (model.AdScene, error)           // 获取单个推广场景
+	GetAdScenes(ctx context.Context, req *vo.AdSceneListRequest) ([]*model.AdScene, int64, error) // 获取推广场景列表
+	UpdateAdScene(ctx context.Context, adScene *model.AdScene) error                              // 更新推广场景
+	BatchDeleteAdSceneByIds(ctx context.Context, ids []string) error                              // 批量删除
 }
 
 type AdSceneRepository struct {
+	db *gorm.DB
 }
 
 // AdSceneRepository构造函数
-func NewAdSceneRepository() IAdSceneRepository {
-	return AdSceneRepository{}
+func NewAdSceneRepository(db *gorm.DB) IAdSceneRepository {
+	return AdSceneRepository{db: db}
 }
 
 // 获取单个推广场景
-func (cr AdSceneRepository) GetAdSceneByAdSceneID(adSceneID string) (model.AdScene, error) {
+func (cr AdSceneRepository) GetAdSceneByAdSceneID(ctx context.Context, adSceneID string) (model.AdScene, error) {
 	var adScene model.AdScene
-	err := common.DB.Where("ad_scene_id = ?", adSceneID).First(&adScene).Error
+	err := cr.db.WithContext(ctx).Where("ad_scene_id = ?", adSceneID).First(&adScene).Error
 	return adScene, err
 }
 
 // 获取推广场景列表
-func (cr AdSceneRepository) GetAdScenes(req *vo.AdSceneListRequest) ([]*model.AdScene, int64, error) {
+func (cr AdSceneRepository) GetAdScenes(ctx context.Context, req *vo.AdSceneListRequest) ([]*model.AdScene, int64, error) {
 	var list []*model.AdScene
-	db := common.DB.Model(&model.AdScene{}).Order("created_at DESC")
+	db := cr.db.WithContext(ctx).Model(&model.AdScene{}).Order("created_at DESC")
 
 	projectID := strings.TrimSpace(req.ProjectID)
 	if !gconvert.IsEmpty(projectID) {
@@ -62,28 +64,28 @@ func (cr AdSceneRepository) GetAdScenes(req *vo.AdSceneListRequest) ([]*model.Ad
 	} else {
 		err = db.Find(&list).Error
 	}
-	return GetAdSceneOther(list), total, err
+	return cr.GetAdSceneOther(ctx, list), total, err
 }
 
 // 获取推广场景其他信息
-func GetAdSceneOther(adScenes []*model.AdScene) []*model.AdScene {
+func (cr AdSceneRepository) GetAdSceneOther(ctx context.Context, adScenes []*model.AdScene) []*model.AdScene {
 	for _, m := range adScenes {
 		var project *model.Project
-		_ = common.DB.Where("project_id = ?", m.ProjectID).First(&project).Error
+		_ = cr.db.WithContext(ctx).Where("project_id = ?", m.ProjectID).First(&project).Error
 		m.Project = project
 	}
 	return adScenes
 }
 
 // 创建推广场景
-func (cr AdSceneRepository) CreateAdScene(adScene *model.AdScene) error {
-	err := common.DB.Create(adScene).Error
+func (cr AdSceneRepository) CreateAdScene(ctx context.Context, adScene *model.AdScene) error {
+	err := cr.db.WithContext(ctx).Create(adScene).Error
 	return err
 }
 
 // 更新推广场景
-func (cr AdSceneRepository) UpdateAdScene(adScene *model.AdScene) error {
-	err := common.DB.Model(adScene).Updates(adScene).Error
+func (cr AdSceneRepository) UpdateAdScene(ctx context.Context, adScene *model.AdScene) error {
+	err := cr.db.WithContext(ctx).Model(adScene).Updates(adScene).Error
 	if err != nil {
 		return err
 	}
@@ -92,18 +94,18 @@ func (cr AdSceneRepository) UpdateAdScene(adScene *model.AdScene) error {
 }
 
 // 批量删除
-func (cr AdSceneRepository) BatchDeleteAdSceneByIds(ids []string) error {
+func (cr AdSceneRepository) BatchDeleteAdSceneByIds(ctx context.Context, ids []string) error {
 	var adScenes []model.AdScene
 	for _, id := range ids {
 		// 根据ID获取标签
-		adScene, err := cr.GetAdSceneByAdSceneID(id)
+		adScene, err := cr.GetAdSceneByAdSceneID(ctx, id)
 		if err != nil {
 			return errors.New(fmt.Sprintf("未获取到ID为%s的推广场景", id))
 		}
 		adScenes = append(adScenes, adScene)
 	}
 
-	err := common.DB.Unscoped().Delete(&adScenes).Error
+	err := cr.db.WithContext(ctx).Unscoped().Delete(&adScenes).Error
 
 	return err
 }
@@ -6,15 +6,15 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"gotribe-admin/internal/pkg/common"
+	"gorm.io/gorm"
 	"gotribe-admin/internal/pkg/model"
 	"gotribe-admin/pkg/api/known"
 	"gotribe-admin/pkg/api/vo"
 	"gotribe-admin/pkg/util"
 	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/patrickmn/go-cache"
@@ -22,40 +22,43 @@ import (
 )
 
 type IAdminRepository interface {
-	Login(admin *model.Admin) (*model.Admin, error)    // 登录
-	ChangePwd(username string, newPasswd string) error // 更新密码
-
-	CreateAdmin(admin *model.Admin) error                              // 创建用户
-	GetAdminByID(id uint) (model.Admin, error)                         // 获取单个用户
-	GetAdmins(req *vo.AdminListRequest) ([]*model.Admin, int64, error) // 获取用户列表
-	UpdateAdmin(admin *model.Admin) error                              // 更新用户
-	BatchDeleteAdminByIds(ids []uint) error                            // 批量删除
-
-	GetCurrentAdmin(c *gin.Context) (model.Admin, error)                  // 获取当前登录用户信息
-	GetCurrentAdminMinRoleSort(c *gin.Context) (uint, model.Admin, error) // 获取当前用户角色排序最小值（最高等级角色）以及当前用户信息
-	GetAdminMinRoleSortsByIds(ids []uint) ([]int, error)                  // 根据用户ID获取用户角色排序最小值
-
-	SetAdminInfoCache(username string, admin model.Admin) // 设置用户信息缓存
-	UpdateAdminInfoCacheByRoleID(roleID uint) error       // 根据角色ID更新拥有该角色的用户信息缓存
-	ClearAdminInfoCache()                                 // 清理所有用户信息缓存
+	Login(ctx context.Context, admin *model.Admin) (*model.Admin, error)    // 登录
+	ChangePwd(ctx context.Context, username string, newPasswd string) error // 更新密码
+
+	CreateAdmin(ctx context.Context, admin *model.Admin) error                              // 创建用户
+	GetAdminByID(ctx context.Context, id uint) (model.Admin, error)                         // 获取单个用户
+	GetAdmins(ctx context.Context, req *vo.AdminListRequest) ([]*model.Admin, int64, error) // 获取用户列表
+	UpdateAdmin(ctx context.Context, admin *model.Admin) error                              // 更新用户
+	BatchDeleteAdminByIds(ctx context.Context, ids []uint) error                            // 批量删除
+
+	GetCurrentAdmin(c *gin.Context) (model.Admin, error)                      // 获取当前登录用户信息
+	GetCurrentAdminMinRoleSort(c *gin.Context) (uint, model.Admin, error)     // 获取当前用户角色排序最小值（最高等级角色）以及当前用户信息
+	GetAdminMinRoleSortsByIds(ctx context.Context, ids []uint) ([]int, error) // 根据用户ID获取用户角色排序最小值
+
+	GetAdminProjectScope(ctx context.Context, admin model.Admin) ([]string, bool, error)    // 获取用户可管理的项目, 第二个返回值为false时不限制
+	UpdateAdminProjects(ctx context.Context, admin *model.Admin, projectIDs []string) error // 更新用户可管理的项目
+	UpdateAdminTotp(ctx context.Context, admin *model.Admin) error                          // 更新用户的两步验证信息
+
+	SetAdminInfoCache(username string, admin model.Admin)                // 设置用户信息缓存
+	UpdateAdminInfoCacheByRoleID(ctx context.Context, roleID uint) error // 根据角色ID更新拥有该角色的用户信息缓存
+	ClearAdminInfoCache()                                                // 清理所有用户信息缓存
 }
 
 type AdminRepository struct {
+	db    *gorm.DB
+	cache *cache.Cache
 }
 
-// 当前用户信息缓存，避免频繁获取数据库
-var adminInfoCache = cache.New(24*time.Hour, 48*time.Hour)
-
 // AdminRepository构造函数
-func NewAdminRepository() IAdminRepository {
-	return AdminRepository{}
+func NewAdminRepository(db *gorm.DB, infoCache *cache.Cache) IAdminRepository {
+	return AdminRepository{db: db, cache: infoCache}
 }
 
 // 登录
-func (ar AdminRepository) Login(admin *model.Admin) (*model.Admin, error) {
+func (ar AdminRepository) Login(ctx context.Context, admin *model.Admin) (*model.Admin, error) {
 	// 根据用户名获取用户(正常状态:用户状态正常)
 	var firstAdmin model.Admin
-	err := common.DB.
+	err := ar.db.WithContext(ctx).
 		Where("username = ?", admin.Username).
 		Preload("Roles").
 		First(&firstAdmin).Error
@@ -101,9 +104,13 @@ func (ar AdminRepository) GetCurrentAdmin(c *gin.Context) (model.Admin, error) {
 		return newAdmin, errors.New("用户未登录")
 	}
 	u, _ := ctxAdmin.(model.Admin)
+	// 服务账号已由API Key认证中间件从数据库加载, 不使用按用户名的缓存, 避免与同名用户混淆
+	if _, ok := c.Get(known.SERVICE_ACCOUNT_KEY); ok {
+		return u, nil
+	}
 
 	// 先获取缓存
-	cacheAdmin, found := adminInfoCache.Get(u.Username)
+	cacheAdmin, found := ar.cache.Get(u.Username)
 	var admin model.Admin
 	var err error
 	if found {
@@ -111,12 +118,12 @@ func (ar AdminRepository) GetCurrentAdmin(c *gin.Context) (model.Admin, error) {
 		err = nil
 	} else {
 		// 缓存中没有就获取数据库
-		admin, err = ar.GetAdminByID(u.ID)
+		admin, err = ar.GetAdminByID(c.Request.Context(), u.ID)
 		// 获取成功就缓存
 		if err != nil {
-			adminInfoCache.Delete(u.Username)
+			ar.cache.Delete(u.Username)
 		} else {
-			adminInfoCache.Set(u.Username, admin, cache.DefaultExpiration)
+			ar.cache.Set(u.Username, admin, cache.DefaultExpiration)
 		}
 	}
 	return admin, err
@@ -143,16 +150,16 @@ func (ar AdminRepository) GetCurrentAdminMinRoleSort(c *gin.Context) (uint, mode
 }
 
 // 获取单个用户
-func (ar AdminRepository) GetAdminByID(id uint) (model.Admin, error) {
+func (ar AdminRepository) GetAdminByID(ctx context.Context, id uint) (model.Admin, error) {
 	var admin model.Admin
-	err := common.DB.Where("id = ?", id).Preload("Roles").First(&admin).Error
+	err := ar.db.WithContext(ctx).Where("id = ?", id).Preload("Roles").First(&admin).Error
 	return admin, err
 }
 
 // 获取用户列表
-func (ar AdminRepository) GetAdmins(req *vo.AdminListRequest) ([]*model.Admin, int64, error) {
+func (ar AdminRepository) GetAdmins(ctx context.Context, req *vo.AdminListRequest) ([]*model.Admin, int64, error) {
 	var list []*model.Admin
-	db := common.DB.Model(&model.Admin{}).Order("created_at DESC")
+	db := ar.db.WithContext(ctx).Model(&model.Admin{}).Order("created_at DESC")
 
 	username := strings.TrimSpace(req.Username)
 	if username != "" {
@@ -180,29 +187,29 @@ func (ar AdminRepository) GetAdmins(req *vo.AdminListRequest) ([]*model.Admin, i
 	pageNum := int(req.PageNum)
 	pageSize := int(req.PageSize)
 	if pageNum > 0 && pageSize > 0 {
-		err = db.Offset((pageNum - 1) * pageSize).Limit(pageSize).Preload("Roles").Find(&list).Error
+		err = db.Offset((pageNum - 1) * pageSize).Limit(pageSize).Preload("Roles").Preload("Projects").Find(&list).Error
 	} else {
-		err = db.Preload("Roles").Find(&list).Error
+		err = db.Preload("Roles").Preload("Projects").Find(&list).Error
 	}
 	return list, total, err
 }
 
 // 更新密码
-func (ar AdminRepository) ChangePwd(username string, hashNewPasswd string) error {
-	err := common.DB.Model(&model.Admin{}).Where("username = ?", username).Update("password", hashNewPasswd).Error
+func (ar AdminRepository) ChangePwd(ctx context.Context, username string, hashNewPasswd string) error {
+	err := ar.db.WithContext(ctx).Model(&model.Admin{}).Where("username = ?", username).Update("password", hashNewPasswd).Error
 	// 如果更新密码成功，则更新当前用户信息缓存
 	// 先获取缓存
-	cacheAdmin, found := adminInfoCache.Get(username)
+	cacheAdmin, found := ar.cache.Get(username)
 	if err == nil {
 		if found {
 			admin := cacheAdmin.(model.Admin)
 			admin.Password = hashNewPasswd
-			adminInfoCache.Set(username, admin, cache.DefaultExpiration)
+			ar.cache.Set(username, admin, cache.DefaultExpiration)
 		} else {
 			// 没有缓存就获取用户信息缓存
 			var admin model.Admin
-			common.DB.Where("username = ?", username).First(&admin)
-			adminInfoCache.Set(username, admin, cache.DefaultExpiration)
+			ar.db.WithContext(ctx).Where("username = ?", username).First(&admin)
+			ar.cache.Set(username, admin, cache.DefaultExpiration)
 		}
 	}
 
@@ -210,56 +217,56 @@ func (ar AdminRepository) ChangePwd(username string, hashNewPasswd string) error
 }
 
 // 创建用户
-func (ar AdminRepository) CreateAdmin(admin *model.Admin) error {
-	err := common.DB.Create(admin).Error
+func (ar AdminRepository) CreateAdmin(ctx context.Context, admin *model.Admin) error {
+	err := ar.db.WithContext(ctx).Create(admin).Error
 	return err
 }
 
 // 更新用户
-func (ar AdminRepository) UpdateAdmin(admin *model.Admin) error {
-	err := common.DB.Model(admin).Updates(admin).Error
+func (ar AdminRepository) UpdateAdmin(ctx context.Context, admin *model.Admin) error {
+	err := ar.db.WithContext(ctx).Model(admin).Updates(admin).Error
 	if err != nil {
 		return err
 	}
-	err = common.DB.Model(admin).Association("Roles").Replace(admin.Roles)
+	err = ar.db.WithContext(ctx).Model(admin).Association("Roles").Replace(admin.Roles)
 
-	//err := common.DB.Session(&gorm.Session{FullSaveAssociations: true}).Updates(&admin).Error
+	//err := ar.db.WithContext(ctx).Session(&gorm.Session{FullSaveAssociations: true}).Updates(&admin).Error
 
 	// 如果更新成功就更新用户信息缓存
 	if err == nil {
-		adminInfoCache.Set(admin.Username, *admin, cache.DefaultExpiration)
+		ar.cache.Set(admin.Username, *admin, cache.DefaultExpiration)
 	}
 	return err
 }
 
 // 批量删除
-func (ar AdminRepository) BatchDeleteAdminByIds(ids []uint) error {
+func (ar AdminRepository) BatchDeleteAdminByIds(ctx context.Context, ids []uint) error {
 	// 用户和角色存在多对多关联关系
 	var admins []model.Admin
 	for _, id := range ids {
 		// 根据ID获取用户
-		admin, err := ar.GetAdminByID(id)
+		admin, err := ar.GetAdminByID(ctx, id)
 		if err != nil {
 			return errors.New(fmt.Sprintf("未获取到ID为%d的用户", id))
 		}
 		admins = append(admins, admin)
 	}
 
-	err := common.DB.Select("Roles").Unscoped().Delete(&admins).Error
+	err := ar.db.WithContext(ctx).Select("Roles", "Projects").Unscoped().Delete(&admins).Error
 	// 删除用户成功，则删除用户信息缓存
 	if err == nil {
 		for _, admin := range admins {
-			adminInfoCache.Delete(admin.Username)
+			ar.cache.Delete(admin.Username)
 		}
 	}
 	return err
 }
 
 // 根据用户ID获取用户角色排序最小值
-func (ar AdminRepository) GetAdminMinRoleSortsByIds(ids []uint) ([]int, error) {
+func (ar AdminRepository) GetAdminMinRoleSortsByIds(ctx context.Context, ids []uint) ([]int, error) {
 	// 根据用户ID获取用户信息
 	var adminList []model.Admin
-	err := common.DB.Where("id IN (?)", ids).Preload("Roles").Find(&adminList).Error
+	err := ar.db.WithContext(ctx).Where("id IN (?)", ids).Preload("Roles").Find(&adminList).Error
 	if err != nil {
 		return []int{}, err
 	}
@@ -279,16 +286,76 @@ func (ar AdminRepository) GetAdminMinRoleSortsByIds(ids []uint) ([]int, error) {
 	return roleMinSortList, nil
 }
 
+// 获取用户可管理的项目
+// 超级管理员以及拥有可管理全部项目角色的用户不限制, 否则为用户与其角色所分配项目的并集, 未分配任何项目时无权管理任何项目
+func (ar AdminRepository) GetAdminProjectScope(ctx context.Context, admin model.Admin) ([]string, bool, error) {
+	if admin.ID == known.DEFAULT_ID {
+		return nil, false, nil
+	}
+	var roleIds []uint
+	for _, role := range admin.Roles {
+		if role.Status != known.DEFAULT_ID {
+			continue
+		}
+		// 排序为1表示超级管理员, 或角色设置为可管理全部项目
+		if role.Sort == 1 || role.AllProjects == known.DEFAULT_ID {
+			return nil, false, nil
+		}
+		roleIds = append(roleIds, role.ID)
+	}
+
+	var projectIDs []string
+	err := ar.db.WithContext(ctx).Table("admin_projects").
+		Where("admin_id = ?", admin.ID).
+		Pluck("project_id", &projectIDs).Error
+	if err != nil {
+		return nil, false, err
+	}
+	if len(roleIds) > 0 {
+		var roleProjectIDs []string
+		err = ar.db.WithContext(ctx).Table("role_projects").
+			Where("role_id IN (?)", roleIds).
+			Pluck("project_id", &roleProjectIDs).Error
+		if err != nil {
+			return nil, false, err
+		}
+		projectIDs = append(projectIDs, roleProjectIDs...)
+	}
+	return funk.UniqString(projectIDs), true, nil
+}
+
+// 更新用户的两步验证信息
+func (ar AdminRepository) UpdateAdminTotp(ctx context.Context, admin *model.Admin) error {
+	err := ar.db.WithContext(ctx).Model(&model.Admin{}).Where("id = ?", admin.ID).
+		Select("totp_secret", "totp_enabled", "totp_recovery_codes", "totp_last_step").
+		Updates(admin).Error
+	// 清理缓存, 下次获取时重新缓存
+	if err == nil {
+		ar.cache.Delete(admin.Username)
+	}
+	return err
+}
+
+// 更新用户可管理的项目
+func (ar AdminRepository) UpdateAdminProjects(ctx context.Context, admin *model.Admin, projectIDs []string) error {
+	projects, err := getProjectsByProjectIDs(ar.db.WithContext(ctx), projectIDs)
+	if err != nil {
+		return err
+	}
+	admin.Projects = projects
+	return ar.db.WithContext(ctx).Model(admin).Association("Projects").Replace(admin.Projects)
+}
+
 // 设置用户信息缓存
 func (ar AdminRepository) SetAdminInfoCache(username string, admin model.Admin) {
-	adminInfoCache.Set(username, admin, cache.DefaultExpiration)
+	ar.cache.Set(username, admin, cache.DefaultExpiration)
 }
 
 // 根据角色ID更新拥有该角色的用户信息缓存
-func (ar AdminRepository) UpdateAdminInfoCacheByRoleID(roleID uint) error {
+func (ar AdminRepository) UpdateAdminInfoCacheByRoleID(ctx context.Context, roleID uint) error {
 
 	var role model.Role
-	err := common.DB.Where("id = ?", roleID).Preload("Admins").First(&role).Error
+	err := ar.db.WithContext(ctx).Where("id = ?", roleID).Preload("Admins").First(&role).Error
 	if err != nil {
 		return errors.New("根据角色ID角色信息失败")
 	}
@@ -300,9 +367,9 @@ func (ar AdminRepository) UpdateAdminInfoCacheByRoleID(roleID uint) error {
 
 	// 更新用户信息缓存
 	for _, admin := range admins {
-		_, found := adminInfoCache.Get(admin.Username)
+		_, found := ar.cache.Get(admin.Username)
 		if found {
-			adminInfoCache.Set(admin.Username, *admin, cache.DefaultExpiration)
+			ar.cache.Set(admin.Username, *admin, cache.DefaultExpiration)
 		}
 	}
 
@@ -311,5 +378,5 @@ func (ar AdminRepository) UpdateAdminInfoCacheByRoleID(roleID uint) error {
 
 // 清理所有用户信息缓存
 func (ar AdminRepository) ClearAdminInfoCache() {
-	adminInfoCache.Flush()
+	ar.cache.Flush()
 }
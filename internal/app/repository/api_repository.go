@@ -6,38 +6,94 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"gotribe-admin/internal/pkg/common"
+	"github.com/casbin/casbin/v2"
+	"gorm.io/gorm"
 	"gotribe-admin/internal/pkg/model"
 	"gotribe-admin/pkg/api/dto"
 	"gotribe-admin/pkg/api/vo"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/thoas/go-funk"
 )
 
 type IApiRepository interface {
-	GetApis(req *vo.ApiListRequest) ([]*model.Api, int64, error) // 获取接口列表
-	GetApisByID(apiIds []uint) ([]*model.Api, error)             // 根据接口ID获取接口列表
-	GetApiTree() ([]*dto.ApiTreeDto, error)                      // 获取接口树(按接口Category字段分类)
-	CreateApi(api *model.Api) error                              // 创建接口
-	UpdateApiByID(apiID uint, api *model.Api) error              // 更新接口
-	BatchDeleteApiByIds(apiIds []uint) error                     // 批量删除接口
-	GetApiDescByPath(path string, method string) (string, error) // 根据接口路径和请求方式获取接口描述
+	GetApis(ctx context.Context, req *vo.ApiListRequest) ([]*model.Api, int64, error) // 获取接口列表
+	GetApisByID(ctx context.Context, apiIds []uint) ([]*model.Api, error)             // 根据接口ID获取接口列表
+	GetApiTree(ctx context.Context) ([]*dto.ApiTreeDto, error)                        // 获取接口树(按接口Category字段分类)
+	CreateApi(ctx context.Context, api *model.Api) error                              // 创建接口
+	UpdateApiByID(ctx context.Context, apiID uint, api *model.Api) error              // 更新接口
+	BatchDeleteApiByIds(ctx context.Context, apiIds []uint) error                     // 批量删除接口
+	GetApiDescByPath(ctx context.Context, path string, method string) (string, error) // 根据接口路径和请求方式获取接口描述
 }
 
 type ApiRepository struct {
+	db       *gorm.DB
+	enforcer *casbin.SyncedCachedEnforcer
+	descs    *apiDescCache
 }
 
-func NewApiRepository() IApiRepository {
-	return ApiRepository{}
+func NewApiRepository(db *gorm.DB, enforcer *casbin.SyncedCachedEnforcer) IApiRepository {
+	return ApiRepository{db: db, enforcer: enforcer, descs: &apiDescCache{}}
+}
+
+// 接口描述缓存的有效期, 其他实例或api sync命令修改的接口描述最迟在过期后生效
+const apiDescCacheTTL = time.Minute
+
+// 接口描述的内存缓存, 操作日志中间件每个请求都要读取, 避免逐次查库
+// 本实例接口增删改后或超过有效期后失效, 下次读取时整表重新加载
+type apiDescCache struct {
+	mu      sync.RWMutex
+	expires time.Time         // 过期时间, 零值表示未加载或已失效
+	descs   map[string]string // key为"METHOD path"
+}
+
+func apiDescKey(method, path string) string {
+	return method + " " + path
+}
+
+// 获取接口描述, 缓存失效时先从数据库加载
+func (c *apiDescCache) get(ctx context.Context, db *gorm.DB, method, path string) (string, bool, error) {
+	c.mu.RLock()
+	if time.Now().Before(c.expires) {
+		desc, ok := c.descs[apiDescKey(method, path)]
+		c.mu.RUnlock()
+		return desc, ok, nil
+	}
+	c.mu.RUnlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !time.Now().Before(c.expires) {
+		var apis []model.Api
+		if err := db.WithContext(ctx).Select("path", "method", "desc").Find(&apis).Error; err != nil {
+			return "", false, err
+		}
+		c.descs = make(map[string]string, len(apis))
+		for _, api := range apis {
+			c.descs[apiDescKey(api.Method, api.Path)] = api.Desc
+		}
+		c.expires = time.Now().Add(apiDescCacheTTL)
+	}
+	desc, ok := c.descs[apiDescKey(method, path)]
+	return desc, ok, nil
+}
+
+// 使缓存失效
+func (c *apiDescCache) invalidate() {
+	c.mu.Lock()
+	c.expires = time.Time{}
+	c.mu.Unlock()
 }
 
 // 获取接口列表
-func (a ApiRepository) GetApis(req *vo.ApiListRequest) ([]*model.Api, int64, error) {
+func (a ApiRepository) GetApis(ctx context.Context, req *vo.ApiListRequest) ([]*model.Api, int64, error) {
 	var list []*model.Api
-	db := common.DB.Model(&model.Api{}).Order("created_at DESC")
+	db := a.db.WithContext(ctx).Model(&model.Api{}).Order("created_at DESC")
 
 	method := strings.TrimSpace(req.Method)
 	if method != "" {
@@ -74,16 +130,16 @@ func (a ApiRepository) GetApis(req *vo.ApiListRequest) ([]*model.Api, int64, err
 }
 
 // 根据接口ID获取接口列表
-func (a ApiRepository) GetApisByID(apiIds []uint) ([]*model.Api, error) {
+func (a ApiRepository) GetApisByID(ctx context.Context, apiIds []uint) ([]*model.Api, error) {
 	var apis []*model.Api
-	err := common.DB.Where("id IN (?)", apiIds).Find(&apis).Error
+	err := a.db.WithContext(ctx).Where("id IN (?)", apiIds).Find(&apis).Error
 	return apis, err
 }
 
 // 获取接口树(按接口Category字段分类)
-func (a ApiRepository) GetApiTree() ([]*dto.ApiTreeDto, error) {
+func (a ApiRepository) GetApiTree(ctx context.Context) ([]*dto.ApiTreeDto, error) {
 	var apiList []*model.Api
-	err := common.DB.Order("category").Order("created_at").Find(&apiList).Error
+	err := a.db.WithContext(ctx).Order("category").Order("created_at").Find(&apiList).Error
 	// 获取所有的分类
 	var categoryList []string
 	for _, api := range apiList {
@@ -112,30 +168,34 @@ func (a ApiRepository) GetApiTree() ([]*dto.ApiTreeDto, error) {
 }
 
 // 创建接口
-func (a ApiRepository) CreateApi(api *model.Api) error {
-	err := common.DB.Create(api).Error
+func (a ApiRepository) CreateApi(ctx context.Context, api *model.Api) error {
+	err := a.db.WithContext(ctx).Create(api).Error
+	if err == nil {
+		a.descs.invalidate()
+	}
 	return err
 }
 
 // 更新接口
-func (a ApiRepository) UpdateApiByID(apiID uint, api *model.Api) error {
+func (a ApiRepository) UpdateApiByID(ctx context.Context, apiID uint, api *model.Api) error {
 	// 根据id获取接口信息
 	var oldApi model.Api
-	err := common.DB.First(&oldApi, apiID).Error
+	err := a.db.WithContext(ctx).First(&oldApi, apiID).Error
 	if err != nil {
 		return errors.New("根据接口ID获取接口信息失败")
 	}
-	err = common.DB.Model(api).Where("id = ?", apiID).Updates(api).Error
+	err = a.db.WithContext(ctx).Model(api).Where("id = ?", apiID).Updates(api).Error
 	if err != nil {
 		return err
 	}
+	a.descs.invalidate()
 	// 更新了method和path就更新casbin中policy
 	if oldApi.Path != api.Path || oldApi.Method != api.Method {
-		policies := common.CasbinEnforcer.GetFilteredPolicy(1, oldApi.Path, oldApi.Method)
+		policies := a.enforcer.GetFilteredPolicy(1, oldApi.Path, oldApi.Method)
 		// 接口在casbin的policy中存在才进行操作
 		if len(policies) > 0 {
 			// 先删除
-			isRemoved, _ := common.CasbinEnforcer.RemovePolicies(policies)
+			isRemoved, _ := a.enforcer.RemovePolicies(policies)
 			if !isRemoved {
 				return errors.New("更新权限接口失败")
 			}
@@ -144,12 +204,12 @@ func (a ApiRepository) UpdateApiByID(apiID uint, api *model.Api) error {
 				policy[2] = api.Method
 			}
 			// 新增
-			isAdded, _ := common.CasbinEnforcer.AddPolicies(policies)
+			isAdded, _ := a.enforcer.AddPolicies(policies)
 			if !isAdded {
 				return errors.New("更新权限接口失败")
 			}
 			// 加载policy
-			err := common.CasbinEnforcer.LoadPolicy()
+			err := a.enforcer.LoadPolicy()
 			if err != nil {
 				return errors.New("更新权限接口成功，权限接口策略加载失败")
 			} else {
@@ -161,9 +221,9 @@ func (a ApiRepository) UpdateApiByID(apiID uint, api *model.Api) error {
 }
 
 // 批量删除接口
-func (a ApiRepository) BatchDeleteApiByIds(apiIds []uint) error {
+func (a ApiRepository) BatchDeleteApiByIds(ctx context.Context, apiIds []uint) error {
 
-	apis, err := a.GetApisByID(apiIds)
+	apis, err := a.GetApisByID(ctx, apiIds)
 	if err != nil {
 		return errors.New("根据接口ID获取接口列表失败")
 	}
@@ -171,20 +231,21 @@ func (a ApiRepository) BatchDeleteApiByIds(apiIds []uint) error {
 		return errors.New("根据接口ID未获取到接口列表")
 	}
 
-	err = common.DB.Where("id IN (?)", apiIds).Unscoped().Delete(&model.Api{}).Error
+	err = a.db.WithContext(ctx).Where("id IN (?)", apiIds).Unscoped().Delete(&model.Api{}).Error
 	// 如果删除成功，删除casbin中policy
 	if err == nil {
+		a.descs.invalidate()
 		for _, api := range apis {
-			policies := common.CasbinEnforcer.GetFilteredPolicy(1, api.Path, api.Method)
+			policies := a.enforcer.GetFilteredPolicy(1, api.Path, api.Method)
 			if len(policies) > 0 {
-				isRemoved, _ := common.CasbinEnforcer.RemovePolicies(policies)
+				isRemoved, _ := a.enforcer.RemovePolicies(policies)
 				if !isRemoved {
 					return errors.New("删除权限接口失败")
 				}
 			}
 		}
 		// 重新加载策略
-		err := common.CasbinEnforcer.LoadPolicy()
+		err := a.enforcer.LoadPolicy()
 		if err != nil {
 			return errors.New("删除权限接口成功，权限接口策略加载失败")
 		} else {
@@ -194,10 +255,14 @@ func (a ApiRepository) BatchDeleteApiByIds(apiIds []uint) error {
 	return err
 }
 
-// 根据接口路径和请求方式获取接口描述
-func (a ApiRepository) GetApiDescByPath(path string, method string) (string, error) {
-	var api model.Api
-
-	err := common.DB.Where("path = ?", path).Where("method = ?", method).First(&api).Error
-	return api.Desc, err
+// 根据接口路径和请求方式获取接口描述, 读取内存缓存
+func (a ApiRepository) GetApiDescByPath(ctx context.Context, path string, method string) (string, error) {
+	desc, ok, err := a.descs.get(ctx, a.db, method, path)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", gorm.ErrRecordNotFound
+	}
+	return desc, nil
 }
@@ -6,7 +6,9 @@
 package repository
 
 import (
+	"context"
 	"errors"
+	"go.uber.org/zap"
 	"gotribe-admin/internal/pkg/common"
 	"gotribe-admin/internal/pkg/model"
 	"gotribe-admin/pkg/api/known"
@@ -15,39 +17,41 @@ import (
 )
 
 type ICategoryRepository interface {
-	GetConfigByCategoryID(categoryID string) (model.Category, error)
-	GetCategorys() ([]*model.Category, error)                             // 获取分类列表
-	GetCategoryTree() ([]*model.Category, error)                          // 获取分类树
-	CreateCategory(category *model.Category) error                        // 创建分类
-	UpdateCategoryByID(categoryID string, category *model.Category) error // 更新分类
-	BatchDeleteCategoryByIds(categoryIds []string) error                  // 批量删除分类
+	GetConfigByCategoryID(ctx context.Context, categoryID string) (model.Category, error)
+	GetCategorys(ctx context.Context) ([]*model.Category, error)                               // 获取分类列表
+	GetCategoryTree(ctx context.Context) ([]*model.Category, error)                            // 获取分类树
+	CreateCategory(ctx context.Context, category *model.Category) error                        // 创建分类
+	UpdateCategoryByID(ctx context.Context, categoryID string, category *model.Category) error // 更新分类
+	BatchDeleteCategoryByIds(ctx context.Context, categoryIds []string) error                  // 批量删除分类
 }
 
 type CategoryRepository struct {
+	db  *gorm.DB
+	log *zap.SugaredLogger
 }
 
-func NewCategoryRepository() ICategoryRepository {
-	return CategoryRepository{}
+func NewCategoryRepository(db *gorm.DB, log *zap.SugaredLogger) ICategoryRepository {
+	return CategoryRepository{db: db, log: log}
 }
 
 // 获取单个分类详情
-func (cr CategoryRepository) GetConfigByCategoryID(categoryID string) (model.Category, error) {
+func (cr CategoryRepository) GetConfigByCategoryID(ctx context.Context, categoryID string) (model.Category, error) {
 	var category model.Category
-	err := common.DB.Where("category_id = ?", categoryID).First(&category).Error
+	err := cr.db.WithContext(ctx).Where("category_id = ?", categoryID).First(&category).Error
 	return category, err
 }
 
 // 获取分类列表
-func (cr CategoryRepository) GetCategorys() ([]*model.Category, error) {
+func (cr CategoryRepository) GetCategorys(ctx context.Context) ([]*model.Category, error) {
 	var categorys []*model.Category
-	err := common.DB.Order("sort").Find(&categorys).Error
+	err := cr.db.WithContext(ctx).Order("sort").Find(&categorys).Error
 	return categorys, err
 }
 
 // 获取分类树
-func (cr CategoryRepository) GetCategoryTree() ([]*model.Category, error) {
+func (cr CategoryRepository) GetCategoryTree(ctx context.Context) ([]*model.Category, error) {
 	var categorys []*model.Category
-	err := common.DB.Order("sort").Find(&categorys).Error
+	err := cr.db.WithContext(ctx).Order("sort").Find(&categorys).Error
 	return GenCategoryTree(0, categorys), err
 }
 
@@ -65,47 +69,47 @@ func GenCategoryTree(parentID uint, categorys []*model.Category) []*model.Catego
 }
 
 // 创建分类
-func (cr CategoryRepository) CreateCategory(category *model.Category) error {
-	err := common.DB.Create(category).Error
+func (cr CategoryRepository) CreateCategory(ctx context.Context, category *model.Category) error {
+	err := cr.db.WithContext(ctx).Create(category).Error
 	return err
 }
 
 // 更新分类
-func (cr CategoryRepository) UpdateCategoryByID(categoryID string, category *model.Category) error {
-	err := common.DB.Model(category).Where("category_id = ?", categoryID).Updates(category).Error
+func (cr CategoryRepository) UpdateCategoryByID(ctx context.Context, categoryID string, category *model.Category) error {
+	err := cr.db.WithContext(ctx).Model(category).Where("category_id = ?", categoryID).Updates(category).Error
 	return err
 }
 
 // 批量删除分类
-func (cr CategoryRepository) BatchDeleteCategoryByIds(categoryIds []string) error {
+func (cr CategoryRepository) BatchDeleteCategoryByIds(ctx context.Context, categoryIds []string) error {
 	var categorys []*model.Category
 
-	err := common.DB.Where("category_id IN (?)", categoryIds).Find(&categorys).Error
+	err := cr.db.WithContext(ctx).Where("category_id IN (?)", categoryIds).Find(&categorys).Error
 	if err != nil {
 		return err
 	}
 	j := 0
 	for _, category := range categorys {
-		if category.ID != known.DEFAULT_ID && !isPID(int64((category.ID))) {
+		if category.ID != known.DEFAULT_ID && !cr.isPID(ctx, int64((category.ID))) {
 			categorys[j] = category
 			j++
 		}
 	}
 	// Slice categorys to new size.
 	categorys = categorys[:j]
-	err = common.DB.Unscoped().Delete(&categorys).Error
+	err = cr.db.WithContext(ctx).Unscoped().Delete(&categorys).Error
 	return err
 }
 
 // isPID 判断是否为别人的父类 ID
 // 存在 true 不存在 false
-func isPID(ID int64) bool {
+func (cr CategoryRepository) isPID(ctx context.Context, ID int64) bool {
 	var category model.Category
-	if err := common.DB.Where("parent_id = ?", ID).First(&category).Error; err != nil {
+	if err := cr.db.WithContext(ctx).Where("parent_id = ?", ID).First(&category).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return false
 		} else {
-			common.Log.Error(err.Error())
+			common.LogWithContext(ctx, cr.log).Error(err.Error())
 			return false
 		}
 	}
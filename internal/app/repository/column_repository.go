@@ -6,41 +6,43 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"gotribe-admin/internal/pkg/common"
+	"gorm.io/gorm"
 	"gotribe-admin/internal/pkg/model"
 	"gotribe-admin/pkg/api/vo"
 	"strings"
 )
 
 type IColumnRepository interface {
-	CreateColumn(column *model.Column) error                              // 创建专栏
-	GetColumnByColumnID(columnID string) (model.Column, error)            // 获取单个专栏
-	GetColumns(req *vo.ColumnListRequest) ([]*model.Column, int64, error) // 获取专栏列表
-	UpdateColumn(column *model.Column) error                              // 更新专栏
-	BatchDeleteColumnByIds(ids []string) error                            // 批量删除专栏
+	CreateColumn(ctx context.Context, column *model.Column) error                              // 创建专栏
+	GetColumnByColumnID(ctx context.Context, columnID string) (model.Column, error)            // 获取单个专栏
+	GetColumns(ctx context.Context, req *vo.ColumnListRequest) ([]*model.Column, int64, error) // 获取专栏列表
+	UpdateColumn(ctx context.Context, column *model.Column) error                              // 更新专栏
+	BatchDeleteColumnByIds(ctx context.Context, ids []string) error                            // 批量删除专栏
 }
 
 type ColumnRepository struct {
+	db *gorm.DB
 }
 
 // ColumnRepository构造函数
-func NewColumnRepository() IColumnRepository {
-	return ColumnRepository{}
+func NewColumnRepository(db *gorm.DB) IColumnRepository {
+	return ColumnRepository{db: db}
 }
 
 // 获取单个专栏
-func (cr ColumnRepository) GetColumnByColumnID(columnID string) (model.Column, error) {
+func (cr ColumnRepository) GetColumnByColumnID(ctx context.Context, columnID string) (model.Column, error) {
 	var column model.Column
-	err := common.DB.Where("column_id = ?", columnID).First(&column).Error
+	err := cr.db.WithContext(ctx).Where("column_id = ?", columnID).First(&column).Error
 	return column, err
 }
 
 // 获取专栏列表
-func (cr ColumnRepository) GetColumns(req *vo.ColumnListRequest) ([]*model.Column, int64, error) {
+func (cr ColumnRepository) GetColumns(ctx context.Context, req *vo.ColumnListRequest) ([]*model.Column, int64, error) {
 	var list []*model.Column
-	db := common.DB.Model(&model.Column{}).Order("created_at DESC")
+	db := cr.db.WithContext(ctx).Model(&model.Column{}).Order("created_at DESC")
 
 	title := strings.TrimSpace(req.Title)
 	if title != "" {
@@ -72,14 +74,14 @@ func (cr ColumnRepository) GetColumns(req *vo.ColumnListRequest) ([]*model.Colum
 }
 
 // 创建专栏
-func (cr ColumnRepository) CreateColumn(column *model.Column) error {
-	err := common.DB.Create(column).Error
+func (cr ColumnRepository) CreateColumn(ctx context.Context, column *model.Column) error {
+	err := cr.db.WithContext(ctx).Create(column).Error
 	return err
 }
 
 // 更新专栏
-func (cr ColumnRepository) UpdateColumn(column *model.Column) error {
-	err := common.DB.Model(column).Updates(column).Error
+func (cr ColumnRepository) UpdateColumn(ctx context.Context, column *model.Column) error {
+	err := cr.db.WithContext(ctx).Model(column).Updates(column).Error
 	if err != nil {
 		return err
 	}
@@ -88,18 +90,18 @@ func (cr ColumnRepository) UpdateColumn(column *model.Column) error {
 }
 
 // 批量删除
-func (cr ColumnRepository) BatchDeleteColumnByIds(ids []string) error {
+func (cr ColumnRepository) BatchDeleteColumnByIds(ctx context.Context, ids []string) error {
 	var columns []model.Column
 	for _, id := range ids {
 		// 根据ID获取标签
-		column, err := cr.GetColumnByColumnID(id)
+		column, err := cr.GetColumnByColumnID(ctx, id)
 		if err != nil {
 			return errors.New(fmt.Sprintf("未获取到ID为%s的专栏", id))
 		}
 		columns = append(columns, column)
 	}
 
-	err := common.DB.Delete(&columns).Error
+	err := cr.db.WithContext(ctx).Delete(&columns).Error
 
 	return err
 }
@@ -6,38 +6,40 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 	"github.com/dengmengmian/ghelper/gconvert"
-	"gotribe-admin/internal/pkg/common"
+	"gorm.io/gorm"
 	"gotribe-admin/internal/pkg/model"
 	"gotribe-admin/pkg/api/vo"
 	"strings"
 )
 
 type ICommentRepository interface {
-	GetCommentByComentID(commentID string) (model.Comment, error)            //获取单条评论
-	GetComments(req *vo.CommentListRequest) ([]*model.Comment, int64, error) // 获取评论列表
-	UpdateComment(comment *model.Comment) error                              // 更新评论
+	GetCommentByComentID(ctx context.Context, commentID string) (model.Comment, error)            //获取单条评论
+	GetComments(ctx context.Context, req *vo.CommentListRequest) ([]*model.Comment, int64, error) // 获取评论列表
+	UpdateComment(ctx context.Context, comment *model.Comment) error                              // 更新评论
 }
 
 type CommentRepository struct {
+	db *gorm.DB
 }
 
 // CommentRepository构造函数
-func NewCommentRepository() ICommentRepository {
-	return CommentRepository{}
+func NewCommentRepository(db *gorm.DB) ICommentRepository {
+	return CommentRepository{db: db}
 }
 
-func (cr CommentRepository) GetCommentByComentID(commentID string) (model.Comment, error) {
+func (cr CommentRepository) GetCommentByComentID(ctx context.Context, commentID string) (model.Comment, error) {
 	var comment model.Comment
-	err := common.DB.Where("comment_id = ?", commentID).First(&comment).Error
+	err := cr.db.WithContext(ctx).Where("comment_id = ?", commentID).First(&comment).Error
 	return comment, err
 }
 
 // 获取评论列表
-func (cr CommentRepository) GetComments(req *vo.CommentListRequest) ([]*model.Comment, int64, error) {
+func (cr CommentRepository) GetComments(ctx context.Context, req *vo.CommentListRequest) ([]*model.Comment, int64, error) {
 	var list []*model.Comment
-	db := common.DB.Model(&model.Comment{}).Order("created_at DESC")
+	db := cr.db.WithContext(ctx).Model(&model.Comment{}).Order("created_at DESC")
 
 	objectID := strings.TrimSpace(req.ObjectID)
 	if !gconvert.IsEmpty(objectID) {
@@ -52,10 +54,15 @@ func (cr CommentRepository) GetComments(req *vo.CommentListRequest) ([]*model.Co
 	if !gconvert.IsEmpty(req.ProjectID) {
 		db = db.Where("project_id = ?", req.ProjectID)
 	}
+	region := strings.TrimSpace(req.Region)
+	if region != "" {
+		like := fmt.Sprintf("%%%s%%", region)
+		db = db.Where("country LIKE ? OR region_name LIKE ? OR city LIKE ?", like, like, like)
+	}
 	if !gconvert.IsEmpty(req.Nickname) {
 		// 查出用户 ID。再用用户 ID 去筛选
 		var user model.User
-		if result := common.DB.Model(&model.User{}).Where("nickname like ?", fmt.Sprintf("%%%s%%", req.Nickname)).First(&user); result.Error != nil {
+		if result := cr.db.WithContext(ctx).Model(&model.User{}).Where("nickname like ?", fmt.Sprintf("%%%s%%", req.Nickname)).First(&user); result.Error != nil {
 			return nil, 0, result.Error
 		}
 		db = db.Where("user_id = ?", user.UserID)
@@ -75,22 +82,22 @@ func (cr CommentRepository) GetComments(req *vo.CommentListRequest) ([]*model.Co
 	} else {
 		err = db.Find(&list).Error
 	}
-	return GetCommentOther(list), total, err
+	return cr.GetCommentOther(ctx, list), total, err
 }
 
 // 获取评论其他信息
-func GetCommentOther(comments []*model.Comment) []*model.Comment {
+func (cr CommentRepository) GetCommentOther(ctx context.Context, comments []*model.Comment) []*model.Comment {
 	for _, m := range comments {
 		var user *model.User
-		_ = common.DB.Where("user_id = ?", m.UserID).First(&user).Error
+		_ = cr.db.WithContext(ctx).Where("user_id = ?", m.UserID).First(&user).Error
 		m.User = user
 	}
 	return comments
 }
 
 // 更新评论
-func (cr CommentRepository) UpdateComment(comment *model.Comment) error {
-	err := common.DB.Model(comment).Updates(comment).Error
+func (cr CommentRepository) UpdateComment(ctx context.Context, comment *model.Comment) error {
+	err := cr.db.WithContext(ctx).Model(comment).Updates(comment).Error
 	if err != nil {
 		return err
 	}
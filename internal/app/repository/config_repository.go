@@ -6,42 +6,44 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/dengmengmian/ghelper/gconvert"
-	"gotribe-admin/internal/pkg/common"
+	"gorm.io/gorm"
 	"gotribe-admin/internal/pkg/model"
 	"gotribe-admin/pkg/api/vo"
 	"strings"
 )
 
 type IConfigRepository interface {
-	CreateConfig(config *model.Config) error                              // 创建配置
-	GetConfigByConfigID(configID string) (model.Config, error)            // 获取单个配置
-	GetConfigs(req *vo.ConfigListRequest) ([]*model.Config, int64, error) // 获取配置列表
-	UpdateConfig(config *model.Config) error                              // 更新配置
-	BatchDeleteConfigByIds(ids []string) error                            // 批量删除
+	CreateConfig(ctx context.Context, config *model.Config) error                              // 创建配置
+	GetConfigByConfigID(ctx context.Context, configID string) (model.Config, error)            // 获取单个配置
+	GetConfigs(ctx context.Context, req *vo.ConfigListRequest) ([]*model.Config, int64, error) // 获取配置列表
+	UpdateConfig(ctx context.Context, config *model.Config) error                              // 更新配置
+	BatchDeleteConfigByIds(ctx context.Context, ids []string) error                            // 批量删除
 }
 
 type ConfigRepository struct {
+	db *gorm.DB
 }
 
 // ConfigRepository构造函数
-func NewConfigRepository() IConfigRepository {
-	return ConfigRepository{}
+func NewConfigRepository(db *gorm.DB) IConfigRepository {
+	return ConfigRepository{db: db}
 }
 
 // 获取单个配置
-func (cr ConfigRepository) GetConfigByConfigID(configID string) (model.Config, error) {
+func (cr ConfigRepository) GetConfigByConfigID(ctx context.Context, configID string) (model.Config, error) {
 	var config model.Config
-	err := common.DB.Where("config_id = ?", configID).First(&config).Error
+	err := cr.db.WithContext(ctx).Where("config_id = ?", configID).First(&config).Error
 	return config, err
 }
 
 // 获取配置列表
-func (cr ConfigRepository) GetConfigs(req *vo.ConfigListRequest) ([]*model.Config, int64, error) {
+func (cr ConfigRepository) GetConfigs(ctx context.Context, req *vo.ConfigListRequest) ([]*model.Config, int64, error) {
 	var list []*model.Config
-	db := common.DB.Model(&model.Config{}).Order("created_at DESC")
+	db := cr.db.WithContext(ctx).Model(&model.Config{}).Order("created_at DESC")
 
 	title := strings.TrimSpace(req.Title)
 	if !gconvert.IsEmpty(title) {
@@ -74,28 +76,28 @@ func (cr ConfigRepository) GetConfigs(req *vo.ConfigListRequest) ([]*model.Confi
 	} else {
 		err = db.Find(&list).Error
 	}
-	return GetConfigOther(list), total, err
+	return cr.GetConfigOther(ctx, list), total, err
 }
 
 // 获取配置其他信息
-func GetConfigOther(configs []*model.Config) []*model.Config {
+func (cr ConfigRepository) GetConfigOther(ctx context.Context, configs []*model.Config) []*model.Config {
 	for _, m := range configs {
 		var project *model.Project
-		_ = common.DB.Where("project_id = ?", m.ProjectID).First(&project).Error
+		_ = cr.db.WithContext(ctx).Where("project_id = ?", m.ProjectID).First(&project).Error
 		m.Project = project
 	}
 	return configs
 }
 
 // 创建配置
-func (cr ConfigRepository) CreateConfig(config *model.Config) error {
-	err := common.DB.Create(config).Error
+func (cr ConfigRepository) CreateConfig(ctx context.Context, config *model.Config) error {
+	err := cr.db.WithContext(ctx).Create(config).Error
 	return err
 }
 
 // 更新配置
-func (cr ConfigRepository) UpdateConfig(config *model.Config) error {
-	err := common.DB.Model(config).Updates(config).Error
+func (cr ConfigRepository) UpdateConfig(ctx context.Context, config *model.Config) error {
+	err := cr.db.WithContext(ctx).Model(config).Updates(config).Error
 	if err != nil {
 		return err
 	}
@@ -104,18 +106,18 @@ func (cr ConfigRepository) UpdateConfig(config *model.Config) error {
 }
 
 // 批量删除
-func (cr ConfigRepository) BatchDeleteConfigByIds(ids []string) error {
+func (cr ConfigRepository) BatchDeleteConfigByIds(ctx context.Context, ids []string) error {
 	var configs []model.Config
 	for _, id := range ids {
 		// 根据ID获取标签
-		config, err := cr.GetConfigByConfigID(id)
+		config, err := cr.GetConfigByConfigID(ctx, id)
 		if err != nil {
 			return errors.New(fmt.Sprintf("未获取到ID为%s的配置", id))
 		}
 		configs = append(configs, config)
 	}
 
-	err := common.DB.Unscoped().Delete(&configs).Error
+	err := cr.db.WithContext(ctx).Unscoped().Delete(&configs).Error
 
 	return err
 }
@@ -6,29 +6,33 @@
 package repository
 
 import (
+	"context"
 	"fmt"
-	"gotribe-admin/internal/pkg/common"
+	"github.com/thoas/go-funk"
+	"gorm.io/gorm"
 	"gotribe-admin/internal/pkg/model"
 	"gotribe-admin/pkg/api/vo"
 	"strings"
 )
 
 type IFeedbackRepository interface {
-	GetFeedbacks(req *vo.FeedbackListRequest) ([]*model.Feedback, int64, error) // 获取标签列表
+	GetFeedbacks(ctx context.Context, req *vo.FeedbackListRequest) ([]*model.Feedback, int64, error) // 获取标签列表
+	GetFeedbackOther(ctx context.Context, feedbacks []*model.Feedback) ([]*model.Feedback, error)    // 获取反馈的用户与项目信息
 }
 
 type FeedbackRepository struct {
+	db *gorm.DB
 }
 
 // FeedbackRepository构造函数
-func NewFeedbackRepository() IFeedbackRepository {
-	return FeedbackRepository{}
+func NewFeedbackRepository(db *gorm.DB) IFeedbackRepository {
+	return FeedbackRepository{db: db}
 }
 
 // 获取标签列表
-func (tr FeedbackRepository) GetFeedbacks(req *vo.FeedbackListRequest) ([]*model.Feedback, int64, error) {
+func (tr FeedbackRepository) GetFeedbacks(ctx context.Context, req *vo.FeedbackListRequest) ([]*model.Feedback, int64, error) {
 	var list []*model.Feedback
-	db := common.DB.Model(&model.Feedback{}).Order("created_at DESC")
+	db := tr.db.WithContext(ctx).Model(&model.Feedback{}).Order("created_at DESC")
 
 	projectID := strings.TrimSpace(req.ProjectID)
 	if req.ProjectID != "" {
@@ -50,3 +54,58 @@ func (tr FeedbackRepository) GetFeedbacks(req *vo.FeedbackListRequest) ([]*model
 	}
 	return list, total, err
 }
+
+// 获取反馈的用户与项目信息
+func (tr FeedbackRepository) GetFeedbackOther(ctx context.Context, feedbacks []*model.Feedback) ([]*model.Feedback, error) {
+	// 遍历feedback获取所有用户ID,查询用户信息并加进去
+	userIds := make([]string, 0)
+	for _, feedback := range feedbacks {
+		userIds = append(userIds, feedback.UserID)
+	}
+	userIds = funk.UniqString(userIds)
+	var users []model.User
+	if len(userIds) > 0 {
+		if err := tr.db.WithContext(ctx).Where("user_id in (?)", userIds).Find(&users).Error; err != nil {
+			return feedbacks, err
+		}
+	}
+
+	// 创建用户映射以提高查找效率
+	userMap := make(map[string]*model.User)
+	for _, user := range users {
+		userMap[user.UserID] = &user
+	}
+
+	// 将用户信息附加到反馈中
+	for _, feedback := range feedbacks {
+		if user, ok := userMap[feedback.UserID]; ok {
+			feedback.User = user
+		}
+	}
+
+	// 追加项目信息
+	projectIds := funk.UniqString(funk.Map(feedbacks, func(feedback *model.Feedback) string {
+		return feedback.ProjectID
+	}).([]string))
+	var projects []model.Project
+	if len(projectIds) > 0 {
+		if err := tr.db.WithContext(ctx).Where("project_id in (?)", projectIds).Find(&projects).Error; err != nil {
+			return feedbacks, err
+		}
+	}
+
+	// 创建项目映射以提高查找效率
+	projectMap := make(map[string]*model.Project)
+	for _, project := range projects {
+		projectMap[project.ProjectID] = &project
+	}
+
+	// 将项目信息附加到反馈中
+	for _, feedback := range feedbacks {
+		if project, ok := projectMap[feedback.ProjectID]; ok {
+			feedback.Project = project
+		}
+	}
+
+	return feedbacks, nil
+}
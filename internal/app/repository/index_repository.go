@@ -6,27 +6,34 @@
 package repository
 
 import (
+	"context"
 	"fmt"
+	"gorm.io/gorm"
 	"gotribe-admin/internal/pkg/common"
 	"gotribe-admin/pkg/util"
 	"time"
 )
 
 type IIndexRepository interface {
-	GetIndexData(projectID string) (map[string]interface{}, error)                             // 获取首页数据
-	GetTimeRangeData(projectID, timeRange string) (map[string][]map[string]interface{}, error) // 获取时间范围数据
+	GetIndexData(ctx context.Context, projectID string) (map[string]interface{}, error)                             // 获取首页数据
+	GetTimeRangeData(ctx context.Context, projectID, timeRange string) (map[string][]map[string]interface{}, error) // 获取时间范围数据
 }
 
 type IndexRepository struct {
+	db *gorm.DB
 }
 
 // IndexRepository构造函数
-func NewIndexRepository() IIndexRepository {
-	return IndexRepository{}
+func NewIndexRepository(db *gorm.DB) IIndexRepository {
+	return IndexRepository{db: db}
 }
 
 // 获取当日 销售额，订单量，新增用户，访问量
-func (r IndexRepository) GetIndexData(projectID string) (map[string]interface{}, error) {
+func (r IndexRepository) GetIndexData(ctx context.Context, projectID string) (map[string]interface{}, error) {
+	// 统计查询直接指定表名, 不会经过项目隔离, 需要单独校验
+	if !common.ProjectAllowed(ctx, projectID) {
+		return nil, common.ErrProjectForbidden
+	}
 	// 动态生成当天的时间范围
 	today := time.Now()
 	startOfDay := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
@@ -36,26 +43,26 @@ func (r IndexRepository) GetIndexData(projectID string) (map[string]interface{},
 		TotalSales  int64
 		TotalOrders int64
 	}
-	err := common.DB.Table("order").
+	err := r.db.WithContext(ctx).Table("order").
 		Select("SUM(amount_pay) as total_sales, COUNT(*) as total_orders").
 		Where("created_at >= ? AND status = 2 AND project_id = ?", startOfDay, projectID).
 		Scan(&result).Error
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch order data: %v", err)
+		return nil, fmt.Errorf("failed to fetch order data: %w", err)
 	}
 
 	// 获取新增用户数
 	var totalUsers int64
-	err = common.DB.Table("user").
+	err = r.db.WithContext(ctx).Table("user").
 		Select("COUNT(*)").
 		Where("created_at >= ? AND project_id = ?", startOfDay, projectID).
 		Count(&totalUsers).Error
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch user data: %v", err)
+		return nil, fmt.Errorf("failed to fetch user data: %w", err)
 	}
 	// 获取浏览数据
 	var visitCount int64
-	err = common.DB.Table("user_event").
+	err = r.db.WithContext(ctx).Table("user_event").
 		Select("COUNT(*)").
 		Where("event_type = 1 AND created_at >= ? AND project_id = ?", startOfDay, projectID).
 		Count(&visitCount).Error
@@ -81,7 +88,10 @@ func (r IndexRepository) GetIndexData(projectID string) (map[string]interface{},
 // Returns:
 // - A map with keys "orders" and "users", each containing a slice of maps with date and corresponding statistics.
 // - An error if the time range is invalid or if there is a failure in fetching data from the database.
-func (r IndexRepository) GetTimeRangeData(projectID, timeRange string) (map[string][]map[string]interface{}, error) {
+func (r IndexRepository) GetTimeRangeData(ctx context.Context, projectID, timeRange string) (map[string][]map[string]interface{}, error) {
+	if !common.ProjectAllowed(ctx, projectID) {
+		return nil, common.ErrProjectForbidden
+	}
 	var startDate time.Time
 	today := time.Now()
 
@@ -103,19 +113,20 @@ func (r IndexRepository) GetTimeRangeData(projectID, timeRange string) (map[stri
 		TotalSales  int64  `gorm:"column:total_sales"`
 		TotalOrders int64  `gorm:"column:total_orders"`
 	}
+	// The grouping expression comes from the database dialect and yields a formatted date string
 	var groupByField string
 	if timeRange == "year" {
-		groupByField = "DATE_FORMAT(created_at, '%Y-%m')"
+		groupByField = common.SQLMonth("created_at")
 	} else {
-		groupByField = "DATE(created_at)"
+		groupByField = common.SQLDay("created_at")
 	}
-	err := common.DB.Table("order").
+	err := r.db.WithContext(ctx).Table("order").
 		Select(fmt.Sprintf("%s as date, SUM(amount_pay) as total_sales, COUNT(*) as total_orders", groupByField)).
 		Where("created_at >= ? AND status = 2 AND project_id = ?", startDate, projectID).
 		Group(groupByField).
 		Scan(&orderResults).Error
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch order data: %v", err)
+		return nil, fmt.Errorf("failed to fetch order data: %w", err)
 	}
 
 	// Fetch user statistics from the database
@@ -123,31 +134,20 @@ func (r IndexRepository) GetTimeRangeData(projectID, timeRange string) (map[stri
 		Date       string `gorm:"column:date"`
 		TotalUsers int64  `gorm:"column:total_users"`
 	}
-	err = common.DB.Table("user").
+	err = r.db.WithContext(ctx).Table("user").
 		Select(fmt.Sprintf("%s as date, COUNT(*) as total_users", groupByField)).
 		Where("created_at >= ? AND project_id = ?", startDate, projectID).
 		Group(groupByField).
 		Scan(&userResults).Error
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch user data: %v", err)
+		return nil, fmt.Errorf("failed to fetch user data: %w", err)
 	}
 
 	// Construct the result map for order data
 	orderData := make([]map[string]interface{}, len(orderResults))
 	for i, res := range orderResults {
-		dateStr := res.Date
-		if timeRange == "year" {
-			// For year, the date is already in the format "2006-01"
-		} else {
-			// For week and month, parse the date and format it to "2006-01-02"
-			parsedDate, err := time.Parse("2006-01-02T15:04:05Z07:00", res.Date)
-			if err != nil {
-				return nil, fmt.Errorf("failed to parse date: %v", err)
-			}
-			dateStr = parsedDate.Format("2006-01-02")
-		}
 		orderData[i] = map[string]interface{}{
-			"date":        dateStr,
+			"date":        res.Date,
 			"totalSales":  util.FenToYuan(int(res.TotalSales)),
 			"totalOrders": res.TotalOrders,
 		}
@@ -156,19 +156,8 @@ func (r IndexRepository) GetTimeRangeData(projectID, timeRange string) (map[stri
 	// Construct the result map for user data
 	userData := make([]map[string]interface{}, len(userResults))
 	for i, res := range userResults {
-		dateStr := res.Date
-		if timeRange == "year" {
-			// For year, the date is already in the format "2006-01"
-		} else {
-			// For week and month, parse the date and format it to "2006-01-02"
-			parsedDate, err := time.Parse("2006-01-02T15:04:05Z07:00", res.Date)
-			if err != nil {
-				return nil, fmt.Errorf("failed to parse date: %v", err)
-			}
-			dateStr = parsedDate.Format("2006-01-02")
-		}
 		userData[i] = map[string]interface{}{
-			"date":       dateStr,
+			"date":       res.Date,
 			"totalUsers": res.TotalUsers,
 		}
 	}
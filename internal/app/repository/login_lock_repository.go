@@ -0,0 +1,68 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gotribe-admin/internal/pkg/model"
+)
+
+type ILoginLockRepository interface {
+	GetLoginLocks(ctx context.Context, keys []string) ([]model.LoginLock, error)           // 获取多条失败记录
+	UpdateLoginLock(ctx context.Context, key string, fn func(lock *model.LoginLock)) error // 加锁读取失败记录, 不存在时创建, 由fn修改后保存
+	DeleteLoginLock(ctx context.Context, key string) error                                 // 删除失败记录
+	DeleteExpiredLoginLocks(ctx context.Context, now time.Time) (int64, error)             // 删除已过期的失败记录
+}
+
+type LoginLockRepository struct {
+	db *gorm.DB
+}
+
+// LoginLockRepository构造函数
+func NewLoginLockRepository(db *gorm.DB) ILoginLockRepository {
+	return LoginLockRepository{db: db}
+}
+
+// 获取多条失败记录
+func (l LoginLockRepository) GetLoginLocks(ctx context.Context, keys []string) ([]model.LoginLock, error) {
+	var list []model.LoginLock
+	err := l.db.WithContext(ctx).Where("lock_key IN ?", keys).Find(&list).Error
+	return list, err
+}
+
+// 加锁读取失败记录, 不存在时创建, 由fn修改后保存
+// 多个实例同时记录同一用户名或IP的失败时依次执行, 不会丢失计数
+func (l LoginLockRepository) UpdateLoginLock(ctx context.Context, key string, fn func(lock *model.LoginLock)) error {
+	return l.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 先插入空记录, 已存在时忽略, 保证后续的加锁查询一定能锁到记录
+		err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "lock_key"}}, DoNothing: true}).
+			Create(&model.LoginLock{LockKey: key, ExpiresAt: time.Now()}).Error
+		if err != nil {
+			return err
+		}
+		var lock model.LoginLock
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("lock_key = ?", key).First(&lock).Error; err != nil {
+			return err
+		}
+		fn(&lock)
+		return tx.Save(&lock).Error
+	})
+}
+
+// 删除失败记录
+func (l LoginLockRepository) DeleteLoginLock(ctx context.Context, key string) error {
+	return l.db.WithContext(ctx).Where("lock_key = ?", key).Delete(&model.LoginLock{}).Error
+}
+
+// 删除已过期的失败记录, 返回删除的条数
+func (l LoginLockRepository) DeleteExpiredLoginLocks(ctx context.Context, now time.Time) (int64, error) {
+	result := l.db.WithContext(ctx).Where("expires_at < ?", now).Delete(&model.LoginLock{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,91 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/pkg/api/known"
+	"gotribe-admin/pkg/api/vo"
+)
+
+type ILoginLogRepository interface {
+	GetLoginLogs(ctx context.Context, req *vo.LoginLogListRequest) ([]model.LoginLog, int64, error) // 获取登录日志列表
+	CreateLoginLog(ctx context.Context, log *model.LoginLog) error                                  // 写入登录日志
+}
+
+type LoginLogRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginLogRepository(db *gorm.DB) ILoginLogRepository {
+	return LoginLogRepository{db: db}
+}
+
+// 获取登录日志列表
+func (l LoginLogRepository) GetLoginLogs(ctx context.Context, req *vo.LoginLogListRequest) ([]model.LoginLog, int64, error) {
+	var list []model.LoginLog
+	db := l.filter(l.db.WithContext(ctx).Model(&model.LoginLog{}).Order("created_at DESC, id DESC"), req)
+
+	// 分页
+	var total int64
+	err := db.Count(&total).Error
+	if err != nil {
+		return list, total, err
+	}
+	pageNum := req.PageNum
+	pageSize := req.PageSize
+	if pageNum > 0 && pageSize > 0 {
+		err = db.Offset((pageNum - 1) * pageSize).Limit(pageSize).Find(&list).Error
+	} else {
+		err = db.Find(&list).Error
+	}
+	return list, total, err
+}
+
+// 按请求参数筛选登录日志
+func (l LoginLogRepository) filter(db *gorm.DB, req *vo.LoginLogListRequest) *gorm.DB {
+	if req.AdminID != 0 {
+		db = db.Where("admin_id = ?", req.AdminID)
+	}
+	username := strings.TrimSpace(req.Username)
+	if username != "" {
+		db = db.Where("username LIKE ?", fmt.Sprintf("%%%s%%", username))
+	}
+	ip := strings.TrimSpace(req.Ip)
+	if ip != "" {
+		db = db.Where("ip LIKE ?", fmt.Sprintf("%%%s%%", ip))
+	}
+	if req.Status != 0 {
+		db = db.Where("status = ?", req.Status)
+	}
+	// 时间格式已由参数校验保证
+	if req.StartTime != "" {
+		t, _ := time.ParseInLocation(known.TIME_FORMAT, req.StartTime, time.Local)
+		db = db.Where("created_at >= ?", t)
+	}
+	if req.EndTime != "" {
+		t, _ := time.ParseInLocation(known.TIME_FORMAT, req.EndTime, time.Local)
+		db = db.Where("created_at <= ?", t)
+	}
+	return db
+}
+
+// 写入登录日志, 未指定管理员ID时按用户名补充, 用户不存在时为0
+func (l LoginLogRepository) CreateLoginLog(ctx context.Context, log *model.LoginLog) error {
+	db := l.db.WithContext(ctx)
+	if log.AdminID == 0 && log.Username != "" {
+		if err := db.Model(&model.Admin{}).Select("id").Where("username = ?", log.Username).Limit(1).Scan(&log.AdminID).Error; err != nil {
+			return err
+		}
+	}
+	return db.Create(log).Error
+}
@@ -6,31 +6,61 @@
 package repository
 
 import (
+	"context"
 	"fmt"
-	"gotribe-admin/internal/pkg/common"
+	"gorm.io/gorm"
 	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/pkg/api/known"
 	"gotribe-admin/pkg/api/vo"
 
 	"strings"
+	"time"
 )
 
 type IOperationLogRepository interface {
-	GetOperationLogs(req *vo.OperationLogListRequest) ([]model.OperationLog, int64, error)
-	BatchDeleteOperationLogByIds(ids []uint) error
-	SaveOperationLogChannel(olc <-chan *model.OperationLog) //处理OperationLogChan将日志记录到数据库
+	GetOperationLogs(ctx context.Context, req *vo.OperationLogListRequest) ([]model.OperationLog, int64, error)
+	BatchDeleteOperationLogByIds(ctx context.Context, ids []uint) error
+	CreateOperationLogs(ctx context.Context, logs []model.OperationLog) error                                               // 批量写入操作日志
+	ExportOperationLogs(ctx context.Context, req *vo.OperationLogListRequest, fn func(log *model.OperationLog) error) error // 逐条读取筛选后的全部操作日志
+	GetOperationLogsBefore(ctx context.Context, before time.Time, limit int) ([]model.OperationLog, error)                  // 按ID升序获取发起时间早于before的操作日志
 }
 
 type OperationLogRepository struct {
+	db *gorm.DB
 }
 
-func NewOperationLogRepository() IOperationLogRepository {
-	return OperationLogRepository{}
+func NewOperationLogRepository(db *gorm.DB) IOperationLogRepository {
+	return OperationLogRepository{db: db}
 }
 
-func (o OperationLogRepository) GetOperationLogs(req *vo.OperationLogListRequest) ([]model.OperationLog, int64, error) {
+func (o OperationLogRepository) GetOperationLogs(ctx context.Context, req *vo.OperationLogListRequest) ([]model.OperationLog, int64, error) {
 	var list []model.OperationLog
-	db := common.DB.Model(&model.OperationLog{}).Order("start_time DESC")
+	db := o.filter(o.db.WithContext(ctx).Model(&model.OperationLog{}).Order("start_time DESC"), req)
 
+	// 分页
+	var total int64
+	err := db.Count(&total).Error
+	if err != nil {
+		return list, total, err
+	}
+	pageNum := req.PageNum
+	pageSize := req.PageSize
+	if pageNum > 0 && pageSize > 0 {
+		err = db.Offset((pageNum - 1) * pageSize).Limit(pageSize).Find(&list).Error
+	} else {
+		err = db.Find(&list).Error
+	}
+
+	return list, total, err
+
+}
+
+// 按请求参数筛选操作日志
+func (o OperationLogRepository) filter(db *gorm.DB, req *vo.OperationLogListRequest) *gorm.DB {
+	requestID := strings.TrimSpace(req.RequestID)
+	if requestID != "" {
+		db = db.Where("request_id = ?", requestID)
+	}
 	username := strings.TrimSpace(req.Username)
 	if username != "" {
 		db = db.Where("username LIKE ?", fmt.Sprintf("%%%s%%", username))
@@ -47,43 +77,70 @@ func (o OperationLogRepository) GetOperationLogs(req *vo.OperationLogListRequest
 	if status != 0 {
 		db = db.Where("status = ?", status)
 	}
-
-	// 分页
-	var total int64
-	err := db.Count(&total).Error
-	if err != nil {
-		return list, total, err
+	if req.StatusMin != 0 {
+		db = db.Where("status >= ?", req.StatusMin)
 	}
-	pageNum := req.PageNum
-	pageSize := req.PageSize
-	if pageNum > 0 && pageSize > 0 {
-		err = db.Offset((pageNum - 1) * pageSize).Limit(pageSize).Find(&list).Error
-	} else {
-		err = db.Find(&list).Error
+	if req.StatusMax != 0 {
+		db = db.Where("status <= ?", req.StatusMax)
 	}
-
-	return list, total, err
-
+	// 时间格式已由参数校验保证
+	if req.StartTime != "" {
+		t, _ := time.ParseInLocation(known.TIME_FORMAT, req.StartTime, time.Local)
+		db = db.Where("start_time >= ?", t)
+	}
+	if req.EndTime != "" {
+		t, _ := time.ParseInLocation(known.TIME_FORMAT, req.EndTime, time.Local)
+		db = db.Where("start_time <= ?", t)
+	}
+	region := strings.TrimSpace(req.Region)
+	if region != "" {
+		db = db.Where("ip_location LIKE ?", fmt.Sprintf("%%%s%%", region))
+	}
+	body := strings.TrimSpace(req.Body)
+	if body != "" {
+		like := fmt.Sprintf("%%%s%%", body)
+		db = db.Where("request_body LIKE ? OR response_body LIKE ?", like, like)
+	}
+	return db
 }
 
-func (o OperationLogRepository) BatchDeleteOperationLogByIds(ids []uint) error {
-	err := common.DB.Where("id IN (?)", ids).Unscoped().Delete(&model.OperationLog{}).Error
+func (o OperationLogRepository) BatchDeleteOperationLogByIds(ctx context.Context, ids []uint) error {
+	err := o.db.WithContext(ctx).Where("id IN (?)", ids).Unscoped().Delete(&model.OperationLog{}).Error
 	return err
 }
 
-// var Logs []model.OperationLog //全局变量多个线程需要加锁，所以每个线程自己维护一个
-// 处理OperationLogChan将日志记录到数据库
-func (o OperationLogRepository) SaveOperationLogChannel(olc <-chan *model.OperationLog) {
-	// 只会在线程开启的时候执行一次
-	Logs := make([]model.OperationLog, 0)
-
-	// 一直执行--收到olc就会执行
-	for log := range olc {
-		Logs = append(Logs, *log)
-		// 每10条记录到数据库
-		if len(Logs) > 5 {
-			common.DB.Create(&Logs)
-			Logs = make([]model.OperationLog, 0)
+// 批量写入操作日志
+func (o OperationLogRepository) CreateOperationLogs(ctx context.Context, logs []model.OperationLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	return o.db.WithContext(ctx).Create(&logs).Error
+}
+
+// 逐条读取筛选后的全部操作日志, 不分页, fn返回错误时停止
+func (o OperationLogRepository) ExportOperationLogs(ctx context.Context, req *vo.OperationLogListRequest, fn func(log *model.OperationLog) error) error {
+	db := o.filter(o.db.WithContext(ctx).Model(&model.OperationLog{}).Order("start_time DESC"), req)
+	rows, err := db.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var log model.OperationLog
+		if err := db.ScanRows(rows, &log); err != nil {
+			return err
+		}
+		if err := fn(&log); err != nil {
+			return err
 		}
 	}
+	return rows.Err()
+}
+
+// 按ID升序获取发起时间早于before的操作日志
+func (o OperationLogRepository) GetOperationLogsBefore(ctx context.Context, before time.Time, limit int) ([]model.OperationLog, error) {
+	var list []model.OperationLog
+	err := o.db.WithContext(ctx).Where("start_time < ?", before).Order("id").Limit(limit).Find(&list).Error
+	return list, err
 }
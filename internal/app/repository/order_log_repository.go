@@ -6,35 +6,37 @@
 package repository
 
 import (
+	"context"
 	"fmt"
-	"gotribe-admin/internal/pkg/common"
+	"gorm.io/gorm"
 	"gotribe-admin/internal/pkg/model"
 )
 
 type IOrderLogRepository interface {
-	GetOrderLogs(orderID string) ([]*model.OrderLog, int64, error) // 获取订单记录列表
-	CreateOrderLog(orderID, remark string) error
+	GetOrderLogs(ctx context.Context, orderID string) ([]*model.OrderLog, int64, error) // 获取订单记录列表
+	CreateOrderLog(ctx context.Context, orderID, remark string) error
 }
 
 type OrderLogRepository struct {
+	db *gorm.DB
 }
 
 // OrderLogRepository构造函数
-func NewOrderLogRepository() IOrderLogRepository {
-	return OrderLogRepository{}
+func NewOrderLogRepository(db *gorm.DB) IOrderLogRepository {
+	return OrderLogRepository{db: db}
 }
 
 // 获取单个订单记录
-func (tr OrderLogRepository) GetOrderLogByOrderLogID(orderLogID string) (model.OrderLog, error) {
+func (tr OrderLogRepository) GetOrderLogByOrderLogID(ctx context.Context, orderLogID string) (model.OrderLog, error) {
 	var orderLog model.OrderLog
-	err := common.DB.Where("orderLog_id = ?", orderLogID).First(&orderLog).Error
+	err := tr.db.WithContext(ctx).Where("orderLog_id = ?", orderLogID).First(&orderLog).Error
 	return orderLog, err
 }
 
 // 获取订单记录列表
-func (tr OrderLogRepository) GetOrderLogs(orderID string) ([]*model.OrderLog, int64, error) {
+func (tr OrderLogRepository) GetOrderLogs(ctx context.Context, orderID string) ([]*model.OrderLog, int64, error) {
 	var list []*model.OrderLog
-	db := common.DB.Model(&model.OrderLog{}).Order("created_at DESC")
+	db := tr.db.WithContext(ctx).Model(&model.OrderLog{}).Order("created_at DESC")
 
 	if orderID != "" {
 		db = db.Where("order_id = ?", fmt.Sprintf("%s", orderID))
@@ -50,8 +52,8 @@ func (tr OrderLogRepository) GetOrderLogs(orderID string) ([]*model.OrderLog, in
 	return list, total, err
 }
 
-func (tr OrderLogRepository) CreateOrderLog(orderID, remark string) error {
-	return common.DB.Create(&model.OrderLog{
+func (tr OrderLogRepository) CreateOrderLog(ctx context.Context, orderID, remark string) error {
+	return tr.db.WithContext(ctx).Create(&model.OrderLog{
 		OrderID: orderID,
 		Remark:  remark,
 	}).Error
@@ -6,10 +6,11 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/thoas/go-funk"
-	"gotribe-admin/internal/pkg/common"
+	"gorm.io/gorm"
 	"gotribe-admin/internal/pkg/model"
 	"gotribe-admin/pkg/api/known"
 	"gotribe-admin/pkg/api/vo"
@@ -18,32 +19,33 @@ import (
 )
 
 type IOrderRepository interface {
-	GetOrderByOrderID(orderID string) (*model.Order, error)            // 获取单个订单
-	GetOrders(req *vo.OrderListRequest) ([]*model.Order, int64, error) // 获取订单列表
-	UpdateOrder(order *model.Order) error                              // 更新订单
-	BatchDeleteOrderByIds(ids []string) error                          // 批量删除
+	GetOrderByOrderID(ctx context.Context, orderID string) (*model.Order, error)            // 获取单个订单
+	GetOrders(ctx context.Context, req *vo.OrderListRequest) ([]*model.Order, int64, error) // 获取订单列表
+	UpdateOrder(ctx context.Context, order *model.Order) error                              // 更新订单
+	BatchDeleteOrderByIds(ctx context.Context, ids []string) error                          // 批量删除
 }
 
 type OrderRepository struct {
+	db *gorm.DB
 }
 
 // OrderRepository构造函数
-func NewOrderRepository() IOrderRepository {
-	return OrderRepository{}
+func NewOrderRepository(db *gorm.DB) IOrderRepository {
+	return OrderRepository{db: db}
 }
 
 // 获取单个订单
-func (tr OrderRepository) GetOrderByOrderID(orderID string) (*model.Order, error) {
+func (tr OrderRepository) GetOrderByOrderID(ctx context.Context, orderID string) (*model.Order, error) {
 	var order model.Order
-	err := common.DB.Where("order_id = ?", orderID).First(&order).Error
-	return getOrdertUser(&order), err
+	err := tr.db.WithContext(ctx).Where("order_id = ?", orderID).First(&order).Error
+	return tr.getOrdertUser(ctx, &order), err
 }
 
 // 获取订单详情里的用户信息
-func getOrdertUser(order *model.Order) *model.Order {
+func (tr OrderRepository) getOrdertUser(ctx context.Context, order *model.Order) *model.Order {
 	// 通过 order.userID 获取用户信息
 	var user model.User
-	err := common.DB.Where("user_id = ?", order.UserID).First(&user).Error
+	err := tr.db.WithContext(ctx).Where("user_id = ?", order.UserID).First(&user).Error
 	if err != nil {
 		return order
 	}
@@ -52,9 +54,9 @@ func getOrdertUser(order *model.Order) *model.Order {
 }
 
 // 获取订单列表
-func (tr OrderRepository) GetOrders(req *vo.OrderListRequest) ([]*model.Order, int64, error) {
+func (tr OrderRepository) GetOrders(ctx context.Context, req *vo.OrderListRequest) ([]*model.Order, int64, error) {
 	var list []*model.Order
-	db := common.DB.Model(&model.Order{}).Order("created_at DESC")
+	db := tr.db.WithContext(ctx).Model(&model.Order{}).Order("created_at DESC")
 
 	orderID := strings.TrimSpace(req.OrderNumber)
 	if req.OrderNumber != "" {
@@ -91,10 +93,10 @@ func (tr OrderRepository) GetOrders(req *vo.OrderListRequest) ([]*model.Order, i
 	} else {
 		err = db.Find(&list).Error
 	}
-	return getOrdertOther(list), total, err
+	return tr.getOrdertOther(ctx, list), total, err
 }
 
-func getOrdertOther(orders []*model.Order) []*model.Order {
+func (tr OrderRepository) getOrdertOther(ctx context.Context, orders []*model.Order) []*model.Order {
 	// 拿出所有用户ID，去重后去 user表查出用户信息
 	var userIDs []string
 	for _, order := range orders {
@@ -102,7 +104,7 @@ func getOrdertOther(orders []*model.Order) []*model.Order {
 	}
 	userIDs = funk.UniqString(userIDs)
 	var users []model.User
-	err := common.DB.Where("user_id in (?)", userIDs).Find(&users).Error
+	err := tr.db.WithContext(ctx).Where("user_id in (?)", userIDs).Find(&users).Error
 	if err != nil {
 		return orders
 	}
@@ -123,8 +125,8 @@ func getOrdertOther(orders []*model.Order) []*model.Order {
 }
 
 // 更新订单
-func (tr OrderRepository) UpdateOrder(order *model.Order) error {
-	err := common.DB.Model(order).Updates(order).Error
+func (tr OrderRepository) UpdateOrder(ctx context.Context, order *model.Order) error {
+	err := tr.db.WithContext(ctx).Model(order).Updates(order).Error
 	if err != nil {
 		return err
 	}
@@ -132,18 +134,18 @@ func (tr OrderRepository) UpdateOrder(order *model.Order) error {
 }
 
 // 批量删除
-func (tr OrderRepository) BatchDeleteOrderByIds(ids []string) error {
+func (tr OrderRepository) BatchDeleteOrderByIds(ctx context.Context, ids []string) error {
 	var orders []*model.Order
 	for _, id := range ids {
 		// 根据ID获取订单
-		order, err := tr.GetOrderByOrderID(id)
+		order, err := tr.GetOrderByOrderID(ctx, id)
 		if err != nil {
 			return errors.New(fmt.Sprintf("未获取到ID为%s的订单", id))
 		}
 		orders = append(orders, order)
 	}
 
-	err := common.DB.Unscoped().Delete(&orders).Error
+	err := tr.db.WithContext(ctx).Unscoped().Delete(&orders).Error
 
 	return err
 }
@@ -6,10 +6,11 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/dengmengmian/ghelper/gconvert"
-	"gotribe-admin/internal/pkg/common"
+	"gorm.io/gorm"
 	"gotribe-admin/internal/pkg/model"
 	"gotribe-admin/pkg/api/vo"
 	"strings"
@@ -17,22 +18,23 @@ import (
 )
 
 type IPointLogRepository interface {
-	CreatePoint(userID, types, reason, eventID, ProjectID string, points float64) error // 新增积分
-	GetPointLogs(req *vo.PointLogListRequest) ([]*model.PointLog, int64, error)         // 获取积分列表
+	CreatePoint(ctx context.Context, userID, types, reason, eventID, ProjectID string, points float64) error // 新增积分
+	GetPointLogs(ctx context.Context, req *vo.PointLogListRequest) ([]*model.PointLog, int64, error)         // 获取积分列表
 }
 
 type PointLogRepository struct {
+	db *gorm.DB
 }
 
 // PointLogRepository构造函数
-func NewPointLogRepository() IPointLogRepository {
-	return PointLogRepository{}
+func NewPointLogRepository(db *gorm.DB) IPointLogRepository {
+	return PointLogRepository{db: db}
 }
 
 // 获取推广场景列表
-func (cr PointLogRepository) GetPointLogs(req *vo.PointLogListRequest) ([]*model.PointLog, int64, error) {
+func (cr PointLogRepository) GetPointLogs(ctx context.Context, req *vo.PointLogListRequest) ([]*model.PointLog, int64, error) {
 	var list []*model.PointLog
-	db := common.DB.Model(&model.PointLog{}).Order("created_at DESC")
+	db := cr.db.WithContext(ctx).Model(&model.PointLog{}).Order("created_at DESC")
 
 	projectID := strings.TrimSpace(req.ProjectID)
 	if !gconvert.IsEmpty(projectID) {
@@ -44,7 +46,7 @@ func (cr PointLogRepository) GetPointLogs(req *vo.PointLogListRequest) ([]*model
 	if !gconvert.IsEmpty(req.Nickname) {
 		// 查出用户 ID。再用用户 ID 去筛选
 		var user model.User
-		if result := common.DB.Model(&model.User{}).Where("nickname like ?", fmt.Sprintf("%%%s%%", req.Nickname)).First(&user); result.Error != nil {
+		if result := cr.db.WithContext(ctx).Model(&model.User{}).Where("nickname like ?", fmt.Sprintf("%%%s%%", req.Nickname)).First(&user); result.Error != nil {
 			return nil, 0, errors.New("用户不存在")
 		}
 		db = db.Where("user_id = ?", user.UserID)
@@ -63,21 +65,21 @@ func (cr PointLogRepository) GetPointLogs(req *vo.PointLogListRequest) ([]*model
 	} else {
 		err = db.Find(&list).Error
 	}
-	return GetPointLogOther(list), total, err
+	return cr.GetPointLogOther(ctx, list), total, err
 }
 
 // 获取其他信息
-func GetPointLogOther(pointLogs []*model.PointLog) []*model.PointLog {
+func (cr PointLogRepository) GetPointLogOther(ctx context.Context, pointLogs []*model.PointLog) []*model.PointLog {
 	for _, m := range pointLogs {
 		var user *model.User
-		_ = common.DB.Where("user_id = ?", m.UserID).First(&user).Error
+		_ = cr.db.WithContext(ctx).Where("user_id = ?", m.UserID).First(&user).Error
 		m.User = user
 	}
 	return pointLogs
 }
 
 // 创建推广场景
-func (cr PointLogRepository) CreatePoint(userID, types, reason, eventID, ProjectID string, points float64) error {
+func (cr PointLogRepository) CreatePoint(ctx context.Context, userID, types, reason, eventID, ProjectID string, points float64) error {
 	pointLog := &model.PointLog{
 		UserID:    userID,
 		Type:      types,
@@ -86,7 +88,7 @@ func (cr PointLogRepository) CreatePoint(userID, types, reason, eventID, Project
 		Points:    points,
 		ProjectID: ProjectID,
 	}
-	result := common.DB.Create(pointLog)
+	result := cr.db.WithContext(ctx).Create(pointLog)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -99,6 +101,6 @@ func (cr PointLogRepository) CreatePoint(userID, types, reason, eventID, Project
 		ExpirationDate: time.Now().AddDate(1, 0, 0), // 当前时间往后推一年
 	}
 
-	err := common.DB.Create(userPoint).Error
+	err := cr.db.WithContext(ctx).Create(userPoint).Error
 	return err
 }
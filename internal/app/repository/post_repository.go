@@ -6,9 +6,12 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/dengmengmian/ghelper/gconvert"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
 	"gotribe-admin/internal/pkg/common"
 	"gotribe-admin/internal/pkg/model"
 	"gotribe-admin/pkg/api/vo"
@@ -16,35 +19,37 @@ import (
 )
 
 type IPostRepository interface {
-	CreatePost(post *model.Post) error                              // 创建内容
-	GetPostByPostID(postID string) (model.Post, error)              // 获取单个内容
-	GetPosts(req *vo.PostListRequest) ([]*model.Post, int64, error) // 获取内容列表
-	UpdatePost(post *model.Post) error                              // 更新内容
-	BatchDeletePostByIds(ids []string) error                        // 批量删除内容
+	CreatePost(ctx context.Context, post *model.Post) error                              // 创建内容
+	GetPostByPostID(ctx context.Context, postID string) (model.Post, error)              // 获取单个内容
+	GetPosts(ctx context.Context, req *vo.PostListRequest) ([]*model.Post, int64, error) // 获取内容列表
+	UpdatePost(ctx context.Context, post *model.Post) error                              // 更新内容
+	BatchDeletePostByIds(ctx context.Context, ids []string) error                        // 批量删除内容
 }
 
 type PostRepository struct {
+	db  *gorm.DB
+	log *zap.SugaredLogger
 }
 
 // PostRepository构造函数
-func NewPostRepository() IPostRepository {
-	return PostRepository{}
+func NewPostRepository(db *gorm.DB, log *zap.SugaredLogger) IPostRepository {
+	return PostRepository{db: db, log: log}
 }
 
 // 获取单个内容
-func (pr PostRepository) GetPostByPostID(postID string) (model.Post, error) {
+func (pr PostRepository) GetPostByPostID(ctx context.Context, postID string) (model.Post, error) {
 	var post model.Post
-	err := common.DB.Where("post_id = ?", postID).First(&post).Error
+	err := pr.db.WithContext(ctx).Where("post_id = ?", postID).First(&post).Error
 	//var category model.Category
-	//err = common.DB.Where("category_id = ?", post.CategoryID).First(&category).Error
+	//err = pr.db.WithContext(ctx).Where("category_id = ?", post.CategoryID).First(&category).Error
 	//post.Category = &category
 	return post, err
 }
 
 // 获取内容列表
-func (pr PostRepository) GetPosts(req *vo.PostListRequest) ([]*model.Post, int64, error) {
+func (pr PostRepository) GetPosts(ctx context.Context, req *vo.PostListRequest) ([]*model.Post, int64, error) {
 	var list []*model.Post
-	db := common.DB.Model(&model.Post{}).Order("created_at DESC")
+	db := pr.db.WithContext(ctx).Model(&model.Post{}).Order("created_at DESC")
 
 	title := strings.TrimSpace(req.Title)
 	if !gconvert.IsEmpty(title) {
@@ -73,11 +78,11 @@ func (pr PostRepository) GetPosts(req *vo.PostListRequest) ([]*model.Post, int64
 		err = db.Find(&list).Error
 	}
 	// 调用 GetPostOther 并处理返回值
-	list, err = GetPostOther(list)
+	list, err = pr.GetPostOther(ctx, list)
 	return list, total, err
 }
 
-func GetPostOther(posts []*model.Post) ([]*model.Post, error) {
+func (pr PostRepository) GetPostOther(ctx context.Context, posts []*model.Post) ([]*model.Post, error) {
 	// 收集所有需要查询的 CategoryID, Tag, ProjectID
 	categoryIDs := make([]string, 0, len(posts))
 	projectIDs := make([]string, 0, len(posts))
@@ -105,7 +110,7 @@ func GetPostOther(posts []*model.Post) ([]*model.Post, error) {
 
 	// 批量查询 Category
 	var categories []*model.Category
-	if err := common.DB.Where("category_id IN (?)", categoryIDs).Find(&categories).Error; err != nil {
+	if err := pr.db.WithContext(ctx).Where("category_id IN (?)", categoryIDs).Find(&categories).Error; err != nil {
 		return nil, err
 	}
 	// 批量查询 Tag
@@ -114,20 +119,20 @@ func GetPostOther(posts []*model.Post) ([]*model.Post, error) {
 	for tag := range allTagsSet {
 		tagIDs = append(tagIDs, tag)
 	}
-	if err := common.DB.Where("tag_id IN (?)", tagIDs).Find(&allTags).Error; err != nil {
+	if err := pr.db.WithContext(ctx).Where("tag_id IN (?)", tagIDs).Find(&allTags).Error; err != nil {
 		return nil, err
 	}
 
 	// 批量查询 Project
 	var projects []*model.Project
-	if err := common.DB.Where("project_id IN (?)", projectIDs).Find(&projects).Error; err != nil {
+	if err := pr.db.WithContext(ctx).Where("project_id IN (?)", projectIDs).Find(&projects).Error; err != nil {
 		return nil, err
 	}
 
 	// 将查询结果赋值给 posts
 	categoryMap := make(map[string]*model.Category)
 	for _, category := range categories {
-		common.Log.Info("category", "category", category.CategoryID)
+		common.LogWithContext(ctx, pr.log).Info("category", "category", category.CategoryID)
 		categoryMap[category.CategoryID] = category
 	}
 
@@ -160,14 +165,14 @@ func GetPostOther(posts []*model.Post) ([]*model.Post, error) {
 }
 
 // 创建内容
-func (pr PostRepository) CreatePost(post *model.Post) error {
-	err := common.DB.Create(post).Error
+func (pr PostRepository) CreatePost(ctx context.Context, post *model.Post) error {
+	err := pr.db.WithContext(ctx).Create(post).Error
 	return err
 }
 
 // 更新内容
-func (pr PostRepository) UpdatePost(post *model.Post) error {
-	err := common.DB.Model(post).Updates(post).Error
+func (pr PostRepository) UpdatePost(ctx context.Context, post *model.Post) error {
+	err := pr.db.WithContext(ctx).Model(post).Updates(post).Error
 	if err != nil {
 		return err
 	}
@@ -176,18 +181,18 @@ func (pr PostRepository) UpdatePost(post *model.Post) error {
 }
 
 // 批量删除
-func (pr PostRepository) BatchDeletePostByIds(ids []string) error {
+func (pr PostRepository) BatchDeletePostByIds(ctx context.Context, ids []string) error {
 	var posts []model.Post
 	for _, id := range ids {
 		// 根据ID获取标签
-		post, err := pr.GetPostByPostID(id)
+		post, err := pr.GetPostByPostID(ctx, id)
 		if err != nil {
 			return errors.New(fmt.Sprintf("未获取到ID为%s的内容", id))
 		}
 		posts = append(posts, post)
 	}
 
-	err := common.DB.Delete(&posts).Error
+	err := pr.db.WithContext(ctx).Delete(&posts).Error
 
 	return err
 }
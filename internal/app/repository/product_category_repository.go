@@ -6,7 +6,9 @@
 package repository
 
 import (
+	"context"
 	"errors"
+	"go.uber.org/zap"
 	"gotribe-admin/internal/pkg/common"
 	"gotribe-admin/internal/pkg/model"
 	"gotribe-admin/pkg/api/known"
@@ -15,39 +17,41 @@ import (
 )
 
 type IProductCategoryRepository interface {
-	GetConfigByProductCategoryID(productCategoryID string) (model.ProductCategory, error)
-	GetProductCategorys() ([]*model.ProductCategory, error)                                           // 获取分类列表
-	GetProductCategoryTree() ([]*model.ProductCategory, error)                                        // 获取分类树
-	CreateProductCategory(productCategory *model.ProductCategory) error                               // 创建分类
-	UpdateProductCategoryByID(productCategoryID string, productCategory *model.ProductCategory) error // 更新分类
-	BatchDeleteProductCategoryByIds(productCategoryIds []string) error                                // 批量删除分类
+	GetConfigByProductCategoryID(ctx context.Context, productCategoryID string) (model.ProductCategory, error)
+	GetProductCategorys(ctx context.Context) ([]*model.ProductCategory, error)                                             // 获取分类列表
+	GetProductCategoryTree(ctx context.Context) ([]*model.ProductCategory, error)                                          // 获取分类树
+	CreateProductCategory(ctx context.Context, productCategory *model.ProductCategory) error                               // 创建分类
+	UpdateProductCategoryByID(ctx context.Context, productCategoryID string, productCategory *model.ProductCategory) error // 更新分类
+	BatchDeleteProductCategoryByIds(ctx context.Context, productCategoryIds []string) error                                // 批量删除分类
 }
 
 type ProductCategoryRepository struct {
+	db  *gorm.DB
+	log *zap.SugaredLogger
 }
 
-func NewProductCategoryRepository() IProductCategoryRepository {
-	return ProductCategoryRepository{}
+func NewProductCategoryRepository(db *gorm.DB, log *zap.SugaredLogger) IProductCategoryRepository {
+	return ProductCategoryRepository{db: db, log: log}
 }
 
 // 获取单个分类详情
-func (cr ProductCategoryRepository) GetConfigByProductCategoryID(productCategoryID string) (model.ProductCategory, error) {
+func (cr ProductCategoryRepository) GetConfigByProductCategoryID(ctx context.Context, productCategoryID string) (model.ProductCategory, error) {
 	var productCategory model.ProductCategory
-	err := common.DB.Where("product_category_id = ?", productCategoryID).First(&productCategory).Error
+	err := cr.db.WithContext(ctx).Where("product_category_id = ?", productCategoryID).First(&productCategory).Error
 	return productCategory, err
 }
 
 // 获取分类列表
-func (cr ProductCategoryRepository) GetProductCategorys() ([]*model.ProductCategory, error) {
+func (cr ProductCategoryRepository) GetProductCategorys(ctx context.Context) ([]*model.ProductCategory, error) {
 	var productCategorys []*model.ProductCategory
-	err := common.DB.Order("sort").Find(&productCategorys).Error
+	err := cr.db.WithContext(ctx).Order("sort").Find(&productCategorys).Error
 	return productCategorys, err
 }
 
 // 获取分类树
-func (cr ProductCategoryRepository) GetProductCategoryTree() ([]*model.ProductCategory, error) {
+func (cr ProductCategoryRepository) GetProductCategoryTree(ctx context.Context) ([]*model.ProductCategory, error) {
 	var productCategorys []*model.ProductCategory
-	err := common.DB.Order("sort").Find(&productCategorys).Error
+	err := cr.db.WithContext(ctx).Order("sort").Find(&productCategorys).Error
 	return GenProductCategoryTree(0, productCategorys), err
 }
 
@@ -65,47 +69,47 @@ func GenProductCategoryTree(parentID uint, productCategorys []*model.ProductCate
 }
 
 // 创建分类
-func (cr ProductCategoryRepository) CreateProductCategory(productCategory *model.ProductCategory) error {
-	err := common.DB.Create(productCategory).Error
+func (cr ProductCategoryRepository) CreateProductCategory(ctx context.Context, productCategory *model.ProductCategory) error {
+	err := cr.db.WithContext(ctx).Create(productCategory).Error
 	return err
 }
 
 // 更新分类
-func (cr ProductCategoryRepository) UpdateProductCategoryByID(productCategoryID string, productCategory *model.ProductCategory) error {
-	err := common.DB.Model(productCategory).Where("productCategory_id = ?", productCategoryID).Updates(productCategory).Error
+func (cr ProductCategoryRepository) UpdateProductCategoryByID(ctx context.Context, productCategoryID string, productCategory *model.ProductCategory) error {
+	err := cr.db.WithContext(ctx).Model(productCategory).Where("productCategory_id = ?", productCategoryID).Updates(productCategory).Error
 	return err
 }
 
 // 批量删除分类
-func (cr ProductCategoryRepository) BatchDeleteProductCategoryByIds(productCategoryIds []string) error {
+func (cr ProductCategoryRepository) BatchDeleteProductCategoryByIds(ctx context.Context, productCategoryIds []string) error {
 	var productCategorys []*model.ProductCategory
 
-	err := common.DB.Where("product_category_id IN (?)", productCategoryIds).Find(&productCategorys).Error
+	err := cr.db.WithContext(ctx).Where("product_category_id IN (?)", productCategoryIds).Find(&productCategorys).Error
 	if err != nil {
 		return err
 	}
 	j := 0
 	for _, productCategory := range productCategorys {
-		if productCategory.ID != known.DEFAULT_ID && !isProductCategoryPID(int64((productCategory.ID))) {
+		if productCategory.ID != known.DEFAULT_ID && !cr.isProductCategoryPID(ctx, int64((productCategory.ID))) {
 			productCategorys[j] = productCategory
 			j++
 		}
 	}
 	// Slice productCategorys to new size.
 	productCategorys = productCategorys[:j]
-	err = common.DB.Unscoped().Delete(&productCategorys).Error
+	err = cr.db.WithContext(ctx).Unscoped().Delete(&productCategorys).Error
 	return err
 }
 
 // isPID 判断是否为别人的父类 ID
 // 存在 true 不存在 false
-func isProductCategoryPID(ID int64) bool {
+func (cr ProductCategoryRepository) isProductCategoryPID(ctx context.Context, ID int64) bool {
 	var productCategory model.ProductCategory
-	if err := common.DB.Where("parent_id = ?", ID).First(&productCategory).Error; err != nil {
+	if err := cr.db.WithContext(ctx).Where("parent_id = ?", ID).First(&productCategory).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return false
 		} else {
-			common.Log.Error(err.Error())
+			common.LogWithContext(ctx, cr.log).Error(err.Error())
 			return false
 		}
 	}
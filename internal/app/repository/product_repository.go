@@ -6,23 +6,23 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/common"
 	"gotribe-admin/internal/pkg/model"
 	"gotribe-admin/pkg/api/vo"
 	"strings"
 )
 
 type IProductRepository interface {
-	BeginTx() (*gorm.DB, error)
+	BeginTx(ctx context.Context) (*gorm.DB, error)
 	CreateProduct(tx *gorm.DB, product *model.Product) (*model.Product, error)
 	CreateProductSku(tx *gorm.DB, productSku *model.ProductSku) (*model.ProductSku, error)
-	GetProductByProductID(productID string) (model.Product, error)           // 获取单个产品
-	GetProducts(req *vo.ProductListRequest) ([]*model.Product, int64, error) // 获取产品列表
-	UpdateProduct(tx *gorm.DB, product *model.Product) error                 // 更新产品
-	BatchDeleteProductByIds(ids []string) error                              // 批量删除
+	GetProductByProductID(ctx context.Context, productID string) (model.Product, error)           // 获取单个产品
+	GetProducts(ctx context.Context, req *vo.ProductListRequest) ([]*model.Product, int64, error) // 获取产品列表
+	UpdateProduct(tx *gorm.DB, product *model.Product) error                                      // 更新产品
+	BatchDeleteProductByIds(ctx context.Context, ids []string) error                              // 批量删除
 	GetProductSkuByProductSkuID(tx *gorm.DB, productSkuID string) (*model.ProductSku, error)
 	UpdateProductSku(tx *gorm.DB, product *model.ProductSku) error
 }
@@ -36,21 +36,22 @@ func NewProductRepository(db *gorm.DB) IProductRepository {
 	return &ProductRepository{db: db}
 }
 
-func (pr *ProductRepository) BeginTx() (*gorm.DB, error) {
-	return pr.db.Begin(), nil
+// 开启事务, 事务内的操作均使用ctx, 接收tx的方法无需再传入ctx
+func (pr *ProductRepository) BeginTx(ctx context.Context) (*gorm.DB, error) {
+	return pr.db.WithContext(ctx).Begin(), nil
 }
 
 // 获取单个产品
-func (tr ProductRepository) GetProductByProductID(productID string) (model.Product, error) {
+func (tr ProductRepository) GetProductByProductID(ctx context.Context, productID string) (model.Product, error) {
 	var product model.Product
-	err := common.DB.Where("product_id = ?", productID).First(&product).Error
+	err := tr.db.WithContext(ctx).Where("product_id = ?", productID).First(&product).Error
 	return product, err
 }
 
 // 获取产品列表
-func (tr ProductRepository) GetProducts(req *vo.ProductListRequest) ([]*model.Product, int64, error) {
+func (tr ProductRepository) GetProducts(ctx context.Context, req *vo.ProductListRequest) ([]*model.Product, int64, error) {
 	var list []*model.Product
-	db := common.DB.Model(&model.Product{}).Order("created_at DESC")
+	db := tr.db.WithContext(ctx).Model(&model.Product{}).Order("created_at DESC")
 
 	title := strings.TrimSpace(req.Title)
 	if title != "" {
@@ -84,18 +85,18 @@ func (tr ProductRepository) UpdateProduct(tx *gorm.DB, product *model.Product) e
 }
 
 // 批量删除
-func (tr ProductRepository) BatchDeleteProductByIds(ids []string) error {
+func (tr ProductRepository) BatchDeleteProductByIds(ctx context.Context, ids []string) error {
 	var products []model.Product
 	for _, id := range ids {
 		// 根据ID获取产品
-		product, err := tr.GetProductByProductID(id)
+		product, err := tr.GetProductByProductID(ctx, id)
 		if err != nil {
 			return errors.New(fmt.Sprintf("未获取到ID为%s的产品", id))
 		}
 		products = append(products, product)
 	}
 
-	err := common.DB.Unscoped().Delete(&products).Error
+	err := tr.db.WithContext(ctx).Unscoped().Delete(&products).Error
 
 	return err
 }
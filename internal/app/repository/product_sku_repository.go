@@ -6,45 +6,47 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"gotribe-admin/internal/pkg/common"
+	"gorm.io/gorm"
 	"gotribe-admin/internal/pkg/model"
 )
 
 type IProductSkuRepository interface {
-	CreateProductSku(productSku *model.ProductSku) (*model.ProductSku, error)  // 创建sku
-	GetProductSkuByProductSkuID(productSkuID string) (model.ProductSku, error) // 获取单个sku
-	UpdateProductSku(productSku *model.ProductSku) error                       // 更新sku
-	BatchDeleteProductSkuByIds(ids []string) error                             // 批量删除
-	GetProductSkuByProductID(productID string) ([]*model.ProductSku, error)
+	CreateProductSku(ctx context.Context, productSku *model.ProductSku) (*model.ProductSku, error)  // 创建sku
+	GetProductSkuByProductSkuID(ctx context.Context, productSkuID string) (model.ProductSku, error) // 获取单个sku
+	UpdateProductSku(ctx context.Context, productSku *model.ProductSku) error                       // 更新sku
+	BatchDeleteProductSkuByIds(ctx context.Context, ids []string) error                             // 批量删除
+	GetProductSkuByProductID(ctx context.Context, productID string) ([]*model.ProductSku, error)
 }
 
 type ProductSkuRepository struct {
+	db *gorm.DB
 }
 
 // ProductSkuRepository构造函数
-func NewProductSkuRepository() IProductSkuRepository {
-	return ProductSkuRepository{}
+func NewProductSkuRepository(db *gorm.DB) IProductSkuRepository {
+	return ProductSkuRepository{db: db}
 }
 
 // 获取单个sku
-func (tr ProductSkuRepository) GetProductSkuByProductSkuID(productSkuID string) (model.ProductSku, error) {
+func (tr ProductSkuRepository) GetProductSkuByProductSkuID(ctx context.Context, productSkuID string) (model.ProductSku, error) {
 	var productSku model.ProductSku
-	err := common.DB.Where("sku_id = ?", productSkuID).First(&productSku).Error
+	err := tr.db.WithContext(ctx).Where("sku_id = ?", productSkuID).First(&productSku).Error
 	return productSku, err
 }
 
 // 通过商品ID获取sku
-func (tr ProductSkuRepository) GetProductSkuByProductID(productID string) ([]*model.ProductSku, error) {
+func (tr ProductSkuRepository) GetProductSkuByProductID(ctx context.Context, productID string) ([]*model.ProductSku, error) {
 	var productSkus []*model.ProductSku
-	err := common.DB.Where("product_id = ?", productID).Find(&productSkus).Error
+	err := tr.db.WithContext(ctx).Where("product_id = ?", productID).Find(&productSkus).Error
 	return productSkus, err
 }
 
 // 创建sku
-func (tr ProductSkuRepository) CreateProductSku(productSku *model.ProductSku) (*model.ProductSku, error) {
-	result := common.DB.Create(productSku)
+func (tr ProductSkuRepository) CreateProductSku(ctx context.Context, productSku *model.ProductSku) (*model.ProductSku, error) {
+	result := tr.db.WithContext(ctx).Create(productSku)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -52,8 +54,8 @@ func (tr ProductSkuRepository) CreateProductSku(productSku *model.ProductSku) (*
 }
 
 // 更新sku
-func (tr ProductSkuRepository) UpdateProductSku(productSku *model.ProductSku) error {
-	err := common.DB.Model(productSku).Updates(productSku).Error
+func (tr ProductSkuRepository) UpdateProductSku(ctx context.Context, productSku *model.ProductSku) error {
+	err := tr.db.WithContext(ctx).Model(productSku).Updates(productSku).Error
 	if err != nil {
 		return err
 	}
@@ -62,18 +64,18 @@ func (tr ProductSkuRepository) UpdateProductSku(productSku *model.ProductSku) er
 }
 
 // 批量删除
-func (tr ProductSkuRepository) BatchDeleteProductSkuByIds(ids []string) error {
+func (tr ProductSkuRepository) BatchDeleteProductSkuByIds(ctx context.Context, ids []string) error {
 	var productSkus []model.ProductSku
 	for _, id := range ids {
 		// 根据ID获取sku
-		productSku, err := tr.GetProductSkuByProductSkuID(id)
+		productSku, err := tr.GetProductSkuByProductSkuID(ctx, id)
 		if err != nil {
 			return errors.New(fmt.Sprintf("未获取到ID为%s的sku", id))
 		}
 		productSkus = append(productSkus, productSku)
 	}
 
-	err := common.DB.Unscoped().Delete(&productSkus).Error
+	err := tr.db.WithContext(ctx).Unscoped().Delete(&productSkus).Error
 
 	return err
 }
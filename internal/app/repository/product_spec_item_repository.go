@@ -6,43 +6,44 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/dengmengmian/ghelper/gconvert"
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/common"
 	"gotribe-admin/internal/pkg/model"
 	"gotribe-admin/pkg/api/vo"
 	"strings"
 )
 
 type IProductSpecItemRepository interface {
-	CreateProductSpecItem(productSpecItem *model.ProductSpecItem) (*model.ProductSpecItem, error)    // 创建商品规格值
-	GetProductSpecItemByItemID(productSpecItemID string) (model.ProductSpecItem, error)              // 获取单个商品规格值
-	GetProductSpecItems(req *vo.ProductSpecItemListRequest) ([]*model.ProductSpecItem, int64, error) // 获取商品规格值列表
-	UpdateProductSpecItem(productSpecItem *model.ProductSpecItem) error                              // 更新商品规格值
-	BatchDeleteProductSpecItemByIds(ids []string) error                                              // 批量删除
+	CreateProductSpecItem(ctx context.Context, productSpecItem *model.ProductSpecItem) (*model.ProductSpecItem, error)    // 创建商品规格值
+	GetProductSpecItemByItemID(ctx context.Context, productSpecItemID string) (model.ProductSpecItem, error)              // 获取单个商品规格值
+	GetProductSpecItems(ctx context.Context, req *vo.ProductSpecItemListRequest) ([]*model.ProductSpecItem, int64, error) // 获取商品规格值列表
+	UpdateProductSpecItem(ctx context.Context, productSpecItem *model.ProductSpecItem) error                              // 更新商品规格值
+	BatchDeleteProductSpecItemByIds(ctx context.Context, ids []string) error                                              // 批量删除
 }
 
 type ProductSpecItemRepository struct {
+	db *gorm.DB
 }
 
 // ProductSpecItemRepository构造函数
-func NewProductSpecItemRepository() IProductSpecItemRepository {
-	return ProductSpecItemRepository{}
+func NewProductSpecItemRepository(db *gorm.DB) IProductSpecItemRepository {
+	return ProductSpecItemRepository{db: db}
 }
 
 // 获取单个商品规格值
-func (tr ProductSpecItemRepository) GetProductSpecItemByItemID(productSpecItemID string) (model.ProductSpecItem, error) {
+func (tr ProductSpecItemRepository) GetProductSpecItemByItemID(ctx context.Context, productSpecItemID string) (model.ProductSpecItem, error) {
 	var productSpecItem model.ProductSpecItem
-	err := common.DB.Where("item_id = ?", productSpecItemID).First(&productSpecItem).Error
+	err := tr.db.WithContext(ctx).Where("item_id = ?", productSpecItemID).First(&productSpecItem).Error
 	return productSpecItem, err
 }
 
 // 获取商品规格值列表
-func (tr ProductSpecItemRepository) GetProductSpecItems(req *vo.ProductSpecItemListRequest) ([]*model.ProductSpecItem, int64, error) {
+func (tr ProductSpecItemRepository) GetProductSpecItems(ctx context.Context, req *vo.ProductSpecItemListRequest) ([]*model.ProductSpecItem, int64, error) {
 	var list []*model.ProductSpecItem
-	db := common.DB.Model(&model.ProductSpecItem{}).Order("created_at DESC")
+	db := tr.db.WithContext(ctx).Model(&model.ProductSpecItem{}).Order("created_at DESC")
 
 	specID := strings.TrimSpace(req.SpecID)
 	if !gconvert.IsEmpty(specID) {
@@ -66,11 +67,11 @@ func (tr ProductSpecItemRepository) GetProductSpecItems(req *vo.ProductSpecItemL
 }
 
 // 创建商品规格值
-func (tr ProductSpecItemRepository) CreateProductSpecItem(productSpecItem *model.ProductSpecItem) (*model.ProductSpecItem, error) {
-	if isProductSpecItemExist(productSpecItem.Title) {
+func (tr ProductSpecItemRepository) CreateProductSpecItem(ctx context.Context, productSpecItem *model.ProductSpecItem) (*model.ProductSpecItem, error) {
+	if tr.isProductSpecItemExist(ctx, productSpecItem.Title) {
 		return nil, errors.New(fmt.Sprintf("%s商品规格值已存在", productSpecItem.Title))
 	}
-	result := common.DB.Create(productSpecItem)
+	result := tr.db.WithContext(ctx).Create(productSpecItem)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -78,8 +79,8 @@ func (tr ProductSpecItemRepository) CreateProductSpecItem(productSpecItem *model
 }
 
 // 更新商品规格值
-func (tr ProductSpecItemRepository) UpdateProductSpecItem(productSpecItem *model.ProductSpecItem) error {
-	err := common.DB.Model(productSpecItem).Updates(productSpecItem).Error
+func (tr ProductSpecItemRepository) UpdateProductSpecItem(ctx context.Context, productSpecItem *model.ProductSpecItem) error {
+	err := tr.db.WithContext(ctx).Model(productSpecItem).Updates(productSpecItem).Error
 	if err != nil {
 		return err
 	}
@@ -88,25 +89,25 @@ func (tr ProductSpecItemRepository) UpdateProductSpecItem(productSpecItem *model
 }
 
 // 批量删除
-func (tr ProductSpecItemRepository) BatchDeleteProductSpecItemByIds(ids []string) error {
+func (tr ProductSpecItemRepository) BatchDeleteProductSpecItemByIds(ctx context.Context, ids []string) error {
 	var productSpecItems []model.ProductSpecItem
 	for _, id := range ids {
 		// 根据ID获取商品规格值
-		productSpecItem, err := tr.GetProductSpecItemByItemID(id)
+		productSpecItem, err := tr.GetProductSpecItemByItemID(ctx, id)
 		if err != nil {
 			return errors.New(fmt.Sprintf("未获取到ID为%s的商品规格值", id))
 		}
 		productSpecItems = append(productSpecItems, productSpecItem)
 	}
 
-	err := common.DB.Unscoped().Delete(&productSpecItems).Error
+	err := tr.db.WithContext(ctx).Unscoped().Delete(&productSpecItems).Error
 
 	return err
 }
 
-func isProductSpecItemExist(title string) bool {
+func (tr ProductSpecItemRepository) isProductSpecItemExist(ctx context.Context, title string) bool {
 	var productSpecItem model.ProductSpecItem
-	result := common.DB.Where("title = ?", title).First(&productSpecItem)
+	result := tr.db.WithContext(ctx).Where("title = ?", title).First(&productSpecItem)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return false
 	}
@@ -6,44 +6,45 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/common"
 	"gotribe-admin/internal/pkg/model"
 	"gotribe-admin/pkg/api/vo"
 	"strings"
 )
 
 type IProductSpecRepository interface {
-	CreateProductSpec(productSpec *model.ProductSpec) (*model.ProductSpec, error)          // 创建商品规格
-	GetProductSpecByProductSpecID(productSpecID string) (model.ProductSpec, error)         // 获取单个商品规格
-	GetProductSpecs(req *vo.ProductSpecListRequest) ([]*model.ProductSpec, int64, error)   // 获取商品规格列表
-	UpdateProductSpec(productSpec *model.ProductSpec) error                                // 更新商品规格
-	BatchDeleteProductSpecByIds(ids []string) error                                        // 批量删除
-	GetProductSpecsByProductSpecIDs(productSpecIDs []string) ([]*model.ProductSpec, error) // 获取多个商品规格
-	GetProductSpecAndItem(categoryID string) ([]*model.ProductSpec, error)                 // 通过商品分类获取商品规格和规格项
+	CreateProductSpec(ctx context.Context, productSpec *model.ProductSpec) (*model.ProductSpec, error)          // 创建商品规格
+	GetProductSpecByProductSpecID(ctx context.Context, productSpecID string) (model.ProductSpec, error)         // 获取单个商品规格
+	GetProductSpecs(ctx context.Context, req *vo.ProductSpecListRequest) ([]*model.ProductSpec, int64, error)   // 获取商品规格列表
+	UpdateProductSpec(ctx context.Context, productSpec *model.ProductSpec) error                                // 更新商品规格
+	BatchDeleteProductSpecByIds(ctx context.Context, ids []string) error                                        // 批量删除
+	GetProductSpecsByProductSpecIDs(ctx context.Context, productSpecIDs []string) ([]*model.ProductSpec, error) // 获取多个商品规格
+	GetProductSpecAndItem(ctx context.Context, categoryID string) ([]*model.ProductSpec, error)                 // 通过商品分类获取商品规格和规格项
 }
 
 type ProductSpecRepository struct {
+	db *gorm.DB
 }
 
 // ProductSpecRepository构造函数
-func NewProductSpecRepository() IProductSpecRepository {
-	return ProductSpecRepository{}
+func NewProductSpecRepository(db *gorm.DB) IProductSpecRepository {
+	return ProductSpecRepository{db: db}
 }
 
 // 获取单个商品规格
-func (tr ProductSpecRepository) GetProductSpecByProductSpecID(productSpecID string) (model.ProductSpec, error) {
+func (tr ProductSpecRepository) GetProductSpecByProductSpecID(ctx context.Context, productSpecID string) (model.ProductSpec, error) {
 	var productSpec model.ProductSpec
-	err := common.DB.Where("product_spec_id = ?", productSpecID).First(&productSpec).Error
+	err := tr.db.WithContext(ctx).Where("product_spec_id = ?", productSpecID).First(&productSpec).Error
 	return productSpec, err
 }
 
 // 获取商品规格列表
-func (tr ProductSpecRepository) GetProductSpecs(req *vo.ProductSpecListRequest) ([]*model.ProductSpec, int64, error) {
+func (tr ProductSpecRepository) GetProductSpecs(ctx context.Context, req *vo.ProductSpecListRequest) ([]*model.ProductSpec, int64, error) {
 	var list []*model.ProductSpec
-	db := common.DB.Model(&model.ProductSpec{}).Order("created_at DESC")
+	db := tr.db.WithContext(ctx).Model(&model.ProductSpec{}).Order("created_at DESC")
 
 	title := strings.TrimSpace(req.Title)
 	if title != "" {
@@ -67,11 +68,11 @@ func (tr ProductSpecRepository) GetProductSpecs(req *vo.ProductSpecListRequest)
 }
 
 // 创建商品规格
-func (tr ProductSpecRepository) CreateProductSpec(productSpec *model.ProductSpec) (*model.ProductSpec, error) {
-	if isProductSpecExist(productSpec.Title) {
+func (tr ProductSpecRepository) CreateProductSpec(ctx context.Context, productSpec *model.ProductSpec) (*model.ProductSpec, error) {
+	if tr.isProductSpecExist(ctx, productSpec.Title) {
 		return nil, errors.New(fmt.Sprintf("%s商品规格已存在", productSpec.Title))
 	}
-	result := common.DB.Create(productSpec)
+	result := tr.db.WithContext(ctx).Create(productSpec)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -79,8 +80,8 @@ func (tr ProductSpecRepository) CreateProductSpec(productSpec *model.ProductSpec
 }
 
 // 更新商品规格
-func (tr ProductSpecRepository) UpdateProductSpec(productSpec *model.ProductSpec) error {
-	err := common.DB.Model(productSpec).Updates(productSpec).Error
+func (tr ProductSpecRepository) UpdateProductSpec(ctx context.Context, productSpec *model.ProductSpec) error {
+	err := tr.db.WithContext(ctx).Model(productSpec).Updates(productSpec).Error
 	if err != nil {
 		return err
 	}
@@ -89,25 +90,25 @@ func (tr ProductSpecRepository) UpdateProductSpec(productSpec *model.ProductSpec
 }
 
 // 批量删除
-func (tr ProductSpecRepository) BatchDeleteProductSpecByIds(ids []string) error {
+func (tr ProductSpecRepository) BatchDeleteProductSpecByIds(ctx context.Context, ids []string) error {
 	var productSpecs []model.ProductSpec
 	for _, id := range ids {
 		// 根据ID获取商品规格
-		productSpec, err := tr.GetProductSpecByProductSpecID(id)
+		productSpec, err := tr.GetProductSpecByProductSpecID(ctx, id)
 		if err != nil {
 			return errors.New(fmt.Sprintf("未获取到ID为%s的商品规格", id))
 		}
 		productSpecs = append(productSpecs, productSpec)
 	}
 
-	err := common.DB.Unscoped().Delete(&productSpecs).Error
+	err := tr.db.WithContext(ctx).Unscoped().Delete(&productSpecs).Error
 
 	return err
 }
 
-func isProductSpecExist(title string) bool {
+func (tr ProductSpecRepository) isProductSpecExist(ctx context.Context, title string) bool {
 	var productSpec model.ProductSpec
-	result := common.DB.Where("title = ?", title).First(&productSpec)
+	result := tr.db.WithContext(ctx).Where("title = ?", title).First(&productSpec)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return false
 	}
@@ -115,16 +116,16 @@ func isProductSpecExist(title string) bool {
 }
 
 // 获取多个商品规格
-func (tr ProductSpecRepository) GetProductSpecsByProductSpecIDs(productSpecIDs []string) ([]*model.ProductSpec, error) {
+func (tr ProductSpecRepository) GetProductSpecsByProductSpecIDs(ctx context.Context, productSpecIDs []string) ([]*model.ProductSpec, error) {
 	var productSpecs []*model.ProductSpec
-	err := common.DB.Where("product_spec_id IN (?)", productSpecIDs).Find(&productSpecs).Error
+	err := tr.db.WithContext(ctx).Where("product_spec_id IN (?)", productSpecIDs).Find(&productSpecs).Error
 	return productSpecs, err
 }
 
-func (tr ProductSpecRepository) GetProductSpecAndItem(categoryID string) ([]*model.ProductSpec, error) {
+func (tr ProductSpecRepository) GetProductSpecAndItem(ctx context.Context, categoryID string) ([]*model.ProductSpec, error) {
 	// 通过分类获取商品类型里的spec_ids
 	var productType model.ProductType
-	err := common.DB.Where("product_category_id = ?", categoryID).First(&productType).Error
+	err := tr.db.WithContext(ctx).Where("product_category_id = ?", categoryID).First(&productType).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil // 或者根据业务需求返回适当的错误信息
@@ -139,7 +140,7 @@ func (tr ProductSpecRepository) GetProductSpecAndItem(categoryID string) ([]*mod
 	}
 
 	var productSpecList []*model.ProductSpec
-	err = common.DB.Where("product_spec_id in (?)", specIDs).Find(&productSpecList).Error
+	err = tr.db.WithContext(ctx).Where("product_spec_id in (?)", specIDs).Find(&productSpecList).Error
 	if err != nil {
 		return nil, err
 	}
@@ -152,7 +153,7 @@ func (tr ProductSpecRepository) GetProductSpecAndItem(categoryID string) ([]*mod
 
 	var productSpecItemList []model.ProductSpecItem
 	if len(specIDsForItems) > 0 {
-		err = common.DB.Where("spec_id IN (?)", specIDsForItems).Find(&productSpecItemList).Error
+		err = tr.db.WithContext(ctx).Where("spec_id IN (?)", specIDsForItems).Find(&productSpecItemList).Error
 		if err != nil {
 			return nil, err
 		}
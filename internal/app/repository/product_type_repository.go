@@ -6,42 +6,43 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/common"
 	"gotribe-admin/internal/pkg/model"
 	"gotribe-admin/pkg/api/vo"
 	"strings"
 )
 
 type IProductTypeRepository interface {
-	CreateProductType(productType *model.ProductType) (*model.ProductType, error)        // 创建商品类型
-	GetProductTypeByProductTypeID(productTypeID string) (model.ProductType, error)       // 获取单个商品类型
-	GetProductTypes(req *vo.ProductTypeListRequest) ([]*model.ProductType, int64, error) // 获取商品类型列表
-	UpdateProductType(productType *model.ProductType) error                              // 更新商品类型
-	BatchDeleteProductTypeByIds(ids []string) error                                      // 批量删除
+	CreateProductType(ctx context.Context, productType *model.ProductType) (*model.ProductType, error)        // 创建商品类型
+	GetProductTypeByProductTypeID(ctx context.Context, productTypeID string) (model.ProductType, error)       // 获取单个商品类型
+	GetProductTypes(ctx context.Context, req *vo.ProductTypeListRequest) ([]*model.ProductType, int64, error) // 获取商品类型列表
+	UpdateProductType(ctx context.Context, productType *model.ProductType) error                              // 更新商品类型
+	BatchDeleteProductTypeByIds(ctx context.Context, ids []string) error                                      // 批量删除
 }
 
 type ProductTypeRepository struct {
+	db *gorm.DB
 }
 
 // ProductTypeRepository构造函数
-func NewProductTypeRepository() IProductTypeRepository {
-	return ProductTypeRepository{}
+func NewProductTypeRepository(db *gorm.DB) IProductTypeRepository {
+	return ProductTypeRepository{db: db}
 }
 
 // 获取单个商品类型
-func (tr ProductTypeRepository) GetProductTypeByProductTypeID(productTypeID string) (model.ProductType, error) {
+func (tr ProductTypeRepository) GetProductTypeByProductTypeID(ctx context.Context, productTypeID string) (model.ProductType, error) {
 	var productType model.ProductType
-	err := common.DB.Where("product_type_id = ?", productTypeID).First(&productType).Error
+	err := tr.db.WithContext(ctx).Where("product_type_id = ?", productTypeID).First(&productType).Error
 	return productType, err
 }
 
 // 获取商品类型列表
-func (tr ProductTypeRepository) GetProductTypes(req *vo.ProductTypeListRequest) ([]*model.ProductType, int64, error) {
+func (tr ProductTypeRepository) GetProductTypes(ctx context.Context, req *vo.ProductTypeListRequest) ([]*model.ProductType, int64, error) {
 	var list []*model.ProductType
-	db := common.DB.Model(&model.ProductType{}).Order("created_at DESC")
+	db := tr.db.WithContext(ctx).Model(&model.ProductType{}).Order("created_at DESC")
 
 	title := strings.TrimSpace(req.Title)
 	if title != "" {
@@ -69,11 +70,11 @@ func (tr ProductTypeRepository) GetProductTypes(req *vo.ProductTypeListRequest)
 }
 
 // 创建商品类型
-func (tr ProductTypeRepository) CreateProductType(productType *model.ProductType) (*model.ProductType, error) {
-	if isProductTypeExist(productType.Title) {
+func (tr ProductTypeRepository) CreateProductType(ctx context.Context, productType *model.ProductType) (*model.ProductType, error) {
+	if tr.isProductTypeExist(ctx, productType.Title) {
 		return nil, errors.New(fmt.Sprintf("%s商品类型已存在", productType.Title))
 	}
-	result := common.DB.Create(productType)
+	result := tr.db.WithContext(ctx).Create(productType)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -81,8 +82,8 @@ func (tr ProductTypeRepository) CreateProductType(productType *model.ProductType
 }
 
 // 更新商品类型
-func (tr ProductTypeRepository) UpdateProductType(productType *model.ProductType) error {
-	err := common.DB.Model(productType).Updates(productType).Error
+func (tr ProductTypeRepository) UpdateProductType(ctx context.Context, productType *model.ProductType) error {
+	err := tr.db.WithContext(ctx).Model(productType).Updates(productType).Error
 	if err != nil {
 		return err
 	}
@@ -91,25 +92,25 @@ func (tr ProductTypeRepository) UpdateProductType(productType *model.ProductType
 }
 
 // 批量删除
-func (tr ProductTypeRepository) BatchDeleteProductTypeByIds(ids []string) error {
+func (tr ProductTypeRepository) BatchDeleteProductTypeByIds(ctx context.Context, ids []string) error {
 	var productTypes []model.ProductType
 	for _, id := range ids {
 		// 根据ID获取商品类型
-		productType, err := tr.GetProductTypeByProductTypeID(id)
+		productType, err := tr.GetProductTypeByProductTypeID(ctx, id)
 		if err != nil {
 			return errors.New(fmt.Sprintf("未获取到ID为%s的商品类型", id))
 		}
 		productTypes = append(productTypes, productType)
 	}
 
-	err := common.DB.Unscoped().Delete(&productTypes).Error
+	err := tr.db.WithContext(ctx).Unscoped().Delete(&productTypes).Error
 
 	return err
 }
 
-func isProductTypeExist(title string) bool {
+func (tr ProductTypeRepository) isProductTypeExist(ctx context.Context, title string) bool {
 	var productType model.ProductType
-	result := common.DB.Where("title = ?", title).First(&productType)
+	result := tr.db.WithContext(ctx).Where("title = ?", title).First(&productType)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return false
 	}
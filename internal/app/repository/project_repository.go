@@ -6,42 +6,44 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"gotribe-admin/internal/pkg/common"
+	"gorm.io/gorm"
 	"gotribe-admin/internal/pkg/model"
 	"gotribe-admin/pkg/api/vo"
 	"strings"
 )
 
 type IProjectRepository interface {
-	CreateProject(project *model.Project) error                              // 创建项目
-	GetProjectByProjectID(projectID string) (model.Project, error)           // 获取单个项目
-	GetProjects(req *vo.ProjectListRequest) ([]*model.Project, int64, error) // 获取项目列表
-	UpdateProject(project *model.Project) error                              // 更新项目
-	BatchDeleteProjectByIds(ids []string) error                              // 批量删除项目
-	GetProjectsBySitemap() ([]*model.Project, error)
+	CreateProject(ctx context.Context, project *model.Project) error                              // 创建项目
+	GetProjectByProjectID(ctx context.Context, projectID string) (model.Project, error)           // 获取单个项目
+	GetProjects(ctx context.Context, req *vo.ProjectListRequest) ([]*model.Project, int64, error) // 获取项目列表
+	UpdateProject(ctx context.Context, project *model.Project) error                              // 更新项目
+	BatchDeleteProjectByIds(ctx context.Context, ids []string) error                              // 批量删除项目
+	GetProjectsBySitemap(ctx context.Context) ([]*model.Project, error)
 }
 
 type ProjectRepository struct {
+	db *gorm.DB
 }
 
 // ProjectRepository构造函数
-func NewProjectRepository() IProjectRepository {
-	return ProjectRepository{}
+func NewProjectRepository(db *gorm.DB) IProjectRepository {
+	return ProjectRepository{db: db}
 }
 
 // 获取单个项目
-func (pr ProjectRepository) GetProjectByProjectID(projectID string) (model.Project, error) {
+func (pr ProjectRepository) GetProjectByProjectID(ctx context.Context, projectID string) (model.Project, error) {
 	var project model.Project
-	err := common.DB.Where("project_id = ?", projectID).First(&project).Error
+	err := pr.db.WithContext(ctx).Where("project_id = ?", projectID).First(&project).Error
 	return project, err
 }
 
 // 获取项目列表
-func (pr ProjectRepository) GetProjects(req *vo.ProjectListRequest) ([]*model.Project, int64, error) {
+func (pr ProjectRepository) GetProjects(ctx context.Context, req *vo.ProjectListRequest) ([]*model.Project, int64, error) {
 	var list []*model.Project
-	db := common.DB.Model(&model.Project{}).Order("created_at DESC")
+	db := pr.db.WithContext(ctx).Model(&model.Project{}).Order("created_at DESC")
 
 	title := strings.TrimSpace(req.Title)
 	if title != "" {
@@ -69,14 +71,14 @@ func (pr ProjectRepository) GetProjects(req *vo.ProjectListRequest) ([]*model.Pr
 }
 
 // 创建项目
-func (pr ProjectRepository) CreateProject(project *model.Project) error {
-	err := common.DB.Create(project).Error
+func (pr ProjectRepository) CreateProject(ctx context.Context, project *model.Project) error {
+	err := pr.db.WithContext(ctx).Create(project).Error
 	return err
 }
 
 // 更新项目
-func (pr ProjectRepository) UpdateProject(project *model.Project) error {
-	err := common.DB.Model(project).Updates(project).Error
+func (pr ProjectRepository) UpdateProject(ctx context.Context, project *model.Project) error {
+	err := pr.db.WithContext(ctx).Model(project).Updates(project).Error
 	if err != nil {
 		return err
 	}
@@ -85,25 +87,47 @@ func (pr ProjectRepository) UpdateProject(project *model.Project) error {
 }
 
 // 批量删除
-func (pr ProjectRepository) BatchDeleteProjectByIds(ids []string) error {
+func (pr ProjectRepository) BatchDeleteProjectByIds(ctx context.Context, ids []string) error {
 	var projects []model.Project
 	for _, id := range ids {
 		// 根据ID获取标签
-		project, err := pr.GetProjectByProjectID(id)
+		project, err := pr.GetProjectByProjectID(ctx, id)
 		if err != nil {
 			return errors.New(fmt.Sprintf("未获取到ID为%s的项目", id))
 		}
 		projects = append(projects, project)
 	}
 
-	err := common.DB.Delete(&projects).Error
+	err := pr.db.WithContext(ctx).Delete(&projects).Error
 
 	return err
 }
 
 // 获取sitmap所需要的 projects 信息
-func (pr ProjectRepository) GetProjectsBySitemap() ([]*model.Project, error) {
+func (pr ProjectRepository) GetProjectsBySitemap(ctx context.Context) ([]*model.Project, error) {
 	var list []*model.Project
-	err := common.DB.Model(&model.Project{}).Order("created_at DESC").Find(&list).Error
+	err := pr.db.WithContext(ctx).Model(&model.Project{}).Order("created_at DESC").Find(&list).Error
 	return list, err
 }
+
+// 根据项目ID获取项目, 查询受项目隔离限制, 存在无法获取的项目时返回错误
+func getProjectsByProjectIDs(db *gorm.DB, projectIDs []string) ([]*model.Project, error) {
+	projects := make([]*model.Project, 0)
+	if len(projectIDs) == 0 {
+		return projects, nil
+	}
+	err := db.Where("project_id IN (?)", projectIDs).Find(&projects).Error
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]bool, len(projects))
+	for _, project := range projects {
+		found[project.ProjectID] = true
+	}
+	for _, id := range projectIDs {
+		if !found[id] {
+			return nil, fmt.Errorf("无权设置项目%s", id)
+		}
+	}
+	return projects, nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package repository
+
+import (
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/patrickmn/go-cache"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 全部仓储, 由NewRepositories统一创建, 同一实例内的仓储共享数据库、casbin、日志与缓存
+type Repositories struct {
+	Ad              IAdRepository
+	AdScene         IAdSceneRepository
+	Admin           IAdminRepository
+	Api             IApiRepository
+	Category        ICategoryRepository
+	Column          IColumnRepository
+	Comment         ICommentRepository
+	Config          IConfigRepository
+	Feedback        IFeedbackRepository
+	Index           IIndexRepository
+	LoginLock       ILoginLockRepository
+	LoginLog        ILoginLogRepository
+	Menu            IMenuRepository
+	OperationLog    IOperationLogRepository
+	OrderLog        IOrderLogRepository
+	Order           IOrderRepository
+	PointLog        IPointLogRepository
+	Post            IPostRepository
+	ProductCategory IProductCategoryRepository
+	Product         IProductRepository
+	ProductSku      IProductSkuRepository
+	ProductSpecItem IProductSpecItemRepository
+	ProductSpec     IProductSpecRepository
+	ProductType     IProductTypeRepository
+	Project         IProjectRepository
+	Resource        IResourceRepository
+	Role            IRoleRepository
+	ServiceAccount  IServiceAccountRepository
+	SystemConfig    ISystemConfigRepository
+	Tag             ITagRepository
+	User            IUserRepository
+}
+
+// Repositories构造函数
+func NewRepositories(db *gorm.DB, enforcer *casbin.SyncedCachedEnforcer, log *zap.SugaredLogger) *Repositories {
+	// 当前用户信息缓存，避免频繁获取数据库
+	adminInfoCache := cache.New(24*time.Hour, 48*time.Hour)
+	return &Repositories{
+		Ad:              NewAdRepository(db),
+		AdScene:         NewAdSceneRepository(db),
+		Admin:           NewAdminRepository(db, adminInfoCache),
+		Api:             NewApiRepository(db, enforcer),
+		Category:        NewCategoryRepository(db, log),
+		Column:          NewColumnRepository(db),
+		Comment:         NewCommentRepository(db),
+		Config:          NewConfigRepository(db),
+		Feedback:        NewFeedbackRepository(db),
+		Index:           NewIndexRepository(db),
+		LoginLock:       NewLoginLockRepository(db),
+		LoginLog:        NewLoginLogRepository(db),
+		Menu:            NewMenuRepository(db, enforcer),
+		OperationLog:    NewOperationLogRepository(db),
+		OrderLog:        NewOrderLogRepository(db),
+		Order:           NewOrderRepository(db),
+		PointLog:        NewPointLogRepository(db),
+		Post:            NewPostRepository(db, log),
+		ProductCategory: NewProductCategoryRepository(db, log),
+		Product:         NewProductRepository(db),
+		ProductSku:      NewProductSkuRepository(db),
+		ProductSpecItem: NewProductSpecItemRepository(db),
+		ProductSpec:     NewProductSpecRepository(db),
+		ProductType:     NewProductTypeRepository(db),
+		Project:         NewProjectRepository(db),
+		Resource:        NewResourceRepository(db),
+		Role:            NewRoleRepository(db, enforcer),
+		ServiceAccount:  NewServiceAccountRepository(db),
+		SystemConfig:    NewSystemConfigRepository(db),
+		Tag:             NewTagRepository(db),
+		User:            NewUserRepository(db),
+	}
+}
@@ -6,43 +6,44 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"gotribe-admin/config"
+	"gorm.io/gorm"
 	"gotribe-admin/internal/pkg/common"
 	"gotribe-admin/internal/pkg/model"
 	"gotribe-admin/pkg/api/vo"
-	"gotribe-admin/pkg/util/upload"
 	"strings"
 )
 
 type IResourceRepository interface {
-	CreateResource(resource *model.Resource) error                              // 创建资源
-	GetResourceByResourceID(resourceID string) (model.Resource, error)          // 获取单个资源
-	GetResources(req *vo.ResourceListRequest) ([]*model.Resource, int64, error) // 获取资源列表
-	UpdateResource(resource *model.Resource) error                              // 更新资源
-	DeleteResourceByID(id string) error                                         // 删除资源
+	CreateResource(ctx context.Context, resource *model.Resource) error                              // 创建资源
+	GetResourceByResourceID(ctx context.Context, resourceID string) (model.Resource, error)          // 获取单个资源
+	GetResources(ctx context.Context, req *vo.ResourceListRequest) ([]*model.Resource, int64, error) // 获取资源列表
+	UpdateResource(ctx context.Context, resource *model.Resource) error                              // 更新资源
+	DeleteResourceByID(ctx context.Context, id string) error                                         // 删除资源
 }
 
 type ResourceRepository struct {
+	db *gorm.DB
 }
 
 // ResourceRepository构造函数
-func NewResourceRepository() IResourceRepository {
-	return ResourceRepository{}
+func NewResourceRepository(db *gorm.DB) IResourceRepository {
+	return ResourceRepository{db: db}
 }
 
 // 获取单个资源
-func (rr ResourceRepository) GetResourceByResourceID(resourceID string) (model.Resource, error) {
+func (rr ResourceRepository) GetResourceByResourceID(ctx context.Context, resourceID string) (model.Resource, error) {
 	var resource model.Resource
-	err := common.DB.Where("resource_id = ?", resourceID).First(&resource).Error
+	err := rr.db.WithContext(ctx).Where("resource_id = ?", resourceID).First(&resource).Error
 	return resource, err
 }
 
 // 获取资源列表
-func (rr ResourceRepository) GetResources(req *vo.ResourceListRequest) ([]*model.Resource, int64, error) {
+func (rr ResourceRepository) GetResources(ctx context.Context, req *vo.ResourceListRequest) ([]*model.Resource, int64, error) {
 	var list []*model.Resource
-	db := common.DB.Model(&model.Resource{}).Order("created_at DESC")
+	db := rr.db.WithContext(ctx).Model(&model.Resource{}).Order("created_at DESC")
 
 	if int(req.Type) > 0 {
 		db = db.Where("file_type = ?", req.Type)
@@ -70,14 +71,14 @@ func (rr ResourceRepository) GetResources(req *vo.ResourceListRequest) ([]*model
 }
 
 // 创建资源
-func (rr ResourceRepository) CreateResource(resource *model.Resource) error {
-	err := common.DB.Create(resource).Error
+func (rr ResourceRepository) CreateResource(ctx context.Context, resource *model.Resource) error {
+	err := rr.db.WithContext(ctx).Create(resource).Error
 	return err
 }
 
 // 更新资源
-func (rr ResourceRepository) UpdateResource(resource *model.Resource) error {
-	err := common.DB.Model(resource).Updates(resource).Error
+func (rr ResourceRepository) UpdateResource(ctx context.Context, resource *model.Resource) error {
+	err := rr.db.WithContext(ctx).Model(resource).Updates(resource).Error
 	if err != nil {
 		return err
 	}
@@ -86,22 +87,22 @@ func (rr ResourceRepository) UpdateResource(resource *model.Resource) error {
 }
 
 // 删除文件
-func (rr ResourceRepository) DeleteResourceByID(id string) error {
-	project, err := rr.GetResourceByResourceID(id)
+func (rr ResourceRepository) DeleteResourceByID(ctx context.Context, id string) error {
+	project, err := rr.GetResourceByResourceID(ctx, id)
 	if err != nil {
 		return errors.New(fmt.Sprintf("未获取到ID为%s的项目", id))
 	}
 
 	// 硬删除
-	err = common.DB.Unscoped().Delete(&project).Error
+	err = rr.db.WithContext(ctx).Unscoped().Delete(&project).Error
+	if err != nil {
+		return err
+	}
 	// 删除 cdn 文件
-	upload, err := upload.NewUploadFile(
-		config.Conf.UploadFile.Endpoint,
-		config.Conf.UploadFile.Accesskey,
-		config.Conf.UploadFile.Secretkey,
-		config.Conf.UploadFile.Bucket,
-		config.Conf.System.EnableOss,
-	)
+	uploadService, err := common.NewUploadService()
+	if err != nil {
+		return err
+	}
 
-	return upload.DeleteFile(project.Path)
+	return uploadService.DeleteFile(project.Path)
 }
@@ -6,38 +6,53 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"gotribe-admin/internal/pkg/common"
+	"github.com/casbin/casbin/v2"
+	"github.com/thoas/go-funk"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/pkg/api/known"
 	"gotribe-admin/pkg/api/vo"
 
 	"strings"
 )
 
 type IRoleRepository interface {
-	GetRoles(req *vo.RoleListRequest) ([]model.Role, int64, error)       // 获取角色列表
-	GetRolesByIds(roleIds []uint) ([]*model.Role, error)                 // 根据角色ID获取角色
-	CreateRole(role *model.Role) error                                   // 创建角色
-	UpdateRoleByID(roleID uint, role *model.Role) error                  // 更新角色
-	GetRoleMenusByID(roleID uint) ([]*model.Menu, error)                 // 获取角色的权限菜单
-	UpdateRoleMenus(role *model.Role) error                              // 更新角色的权限菜单
-	GetRoleApisByRoleKeyword(roleKeyword string) ([]*model.Api, error)   // 根据角色关键字获取角色的权限接口
-	UpdateRoleApis(roleKeyword string, reqRolePolicies [][]string) error // 更新角色的权限接口（先全部删除再新增）
-	BatchDeleteRoleByIds(roleIds []uint) error                           // 删除角色
+	GetRoles(ctx context.Context, req *vo.RoleListRequest) ([]model.Role, int64, error)       // 获取角色列表
+	GetRolesByIds(ctx context.Context, roleIds []uint) ([]*model.Role, error)                 // 根据角色ID获取角色
+	CreateRole(ctx context.Context, role *model.Role) error                                   // 创建角色
+	UpdateRoleByID(ctx context.Context, roleID uint, role *model.Role) error                  // 更新角色
+	GetRoleMenusByID(ctx context.Context, roleID uint) ([]*model.Menu, error)                 // 获取角色的权限菜单
+	UpdateRoleMenus(ctx context.Context, role *model.Role) error                              // 更新角色的权限菜单
+	GetRoleApisByRoleKeyword(ctx context.Context, roleKeyword string) ([]*model.Api, error)   // 根据角色关键字获取角色的权限接口
+	UpdateRoleApis(ctx context.Context, roleKeyword string, reqRolePolicies [][]string) error // 更新角色的权限接口（先全部删除再新增）
+	BatchDeleteRoleByIds(ctx context.Context, roleIds []uint) error                           // 删除角色
+	GetRoleProjectsByID(ctx context.Context, roleID uint) ([]*model.Project, error)           // 获取角色可管理的项目
+	UpdateRoleProjects(ctx context.Context, role *model.Role, projectIDs []string) error      // 更新角色可管理的项目
+	GetRolesByKeywords(ctx context.Context, keywords []string) ([]*model.Role, error)         // 根据角色关键字获取角色
+	GetRoleParents(ctx context.Context, roleKeyword string) ([]*model.Role, error)            // 获取角色直接继承的父角色
+	GetRoleAncestors(ctx context.Context, roleKeyword string) ([]*model.Role, error)          // 获取角色继承的全部上级角色, 由近到远
+	UpdateRoleParents(ctx context.Context, role *model.Role, parents []*model.Role) error     // 更新角色继承的父角色
+	RenameRoleInheritance(oldKeyword string, newKeyword string) error                         // 角色关键字变更时更新继承关系
+	UpdateRoleInheritanceStatus(ctx context.Context, role *model.Role) error                  // 角色启用或禁用后暂停或恢复子角色的继承关系
 }
 
 type RoleRepository struct {
+	db       *gorm.DB
+	enforcer *casbin.SyncedCachedEnforcer
 }
 
-func NewRoleRepository() IRoleRepository {
-	return RoleRepository{}
+func NewRoleRepository(db *gorm.DB, enforcer *casbin.SyncedCachedEnforcer) IRoleRepository {
+	return RoleRepository{db: db, enforcer: enforcer}
 }
 
 // 获取角色列表
-func (r RoleRepository) GetRoles(req *vo.RoleListRequest) ([]model.Role, int64, error) {
+func (r RoleRepository) GetRoles(ctx context.Context, req *vo.RoleListRequest) ([]model.Role, int64, error) {
 	var list []model.Role
-	db := common.DB.Model(&model.Role{}).Order("created_at DESC")
+	db := r.db.WithContext(ctx).Model(&model.Role{}).Order("created_at DESC")
 
 	name := strings.TrimSpace(req.Name)
 	if name != "" {
@@ -69,44 +84,61 @@ func (r RoleRepository) GetRoles(req *vo.RoleListRequest) ([]model.Role, int64,
 }
 
 // 根据角色ID获取角色
-func (r RoleRepository) GetRolesByIds(roleIds []uint) ([]*model.Role, error) {
+func (r RoleRepository) GetRolesByIds(ctx context.Context, roleIds []uint) ([]*model.Role, error) {
 	var list []*model.Role
-	err := common.DB.Where("id IN (?)", roleIds).Find(&list).Error
+	err := r.db.WithContext(ctx).Where("id IN (?)", roleIds).Find(&list).Error
 	return list, err
 }
 
 // 创建角色
-func (r RoleRepository) CreateRole(role *model.Role) error {
-	err := common.DB.Create(role).Error
+func (r RoleRepository) CreateRole(ctx context.Context, role *model.Role) error {
+	err := r.db.WithContext(ctx).Create(role).Error
 	return err
 }
 
 // 更新角色
-func (r RoleRepository) UpdateRoleByID(roleID uint, role *model.Role) error {
-	err := common.DB.Model(&model.Role{}).Where("id = ?", roleID).Updates(role).Error
+func (r RoleRepository) UpdateRoleByID(ctx context.Context, roleID uint, role *model.Role) error {
+	err := r.db.WithContext(ctx).Model(&model.Role{}).Where("id = ?", roleID).Updates(role).Error
 	return err
 }
 
 // 获取角色的权限菜单
-func (r RoleRepository) GetRoleMenusByID(roleID uint) ([]*model.Menu, error) {
+func (r RoleRepository) GetRoleMenusByID(ctx context.Context, roleID uint) ([]*model.Menu, error) {
 	var role model.Role
-	err := common.DB.Where("id = ?", roleID).Preload("Menus").First(&role).Error
+	err := r.db.WithContext(ctx).Where("id = ?", roleID).Preload("Menus").First(&role).Error
 	return role.Menus, err
 }
 
 // 更新角色的权限菜单
-func (r RoleRepository) UpdateRoleMenus(role *model.Role) error {
-	err := common.DB.Model(role).Association("Menus").Replace(role.Menus)
+func (r RoleRepository) UpdateRoleMenus(ctx context.Context, role *model.Role) error {
+	err := r.db.WithContext(ctx).Model(role).Association("Menus").Replace(role.Menus)
 	return err
 }
 
+// 获取角色可管理的项目
+func (r RoleRepository) GetRoleProjectsByID(ctx context.Context, roleID uint) ([]*model.Project, error) {
+	var role model.Role
+	err := r.db.WithContext(ctx).Where("id = ?", roleID).Preload("Projects").First(&role).Error
+	return role.Projects, err
+}
+
+// 更新角色可管理的项目
+func (r RoleRepository) UpdateRoleProjects(ctx context.Context, role *model.Role, projectIDs []string) error {
+	projects, err := getProjectsByProjectIDs(r.db.WithContext(ctx), projectIDs)
+	if err != nil {
+		return err
+	}
+	role.Projects = projects
+	return r.db.WithContext(ctx).Model(role).Association("Projects").Replace(role.Projects)
+}
+
 // 根据角色关键字获取角色的权限接口
-func (r RoleRepository) GetRoleApisByRoleKeyword(roleKeyword string) ([]*model.Api, error) {
-	policies := common.CasbinEnforcer.GetFilteredPolicy(0, roleKeyword)
+func (r RoleRepository) GetRoleApisByRoleKeyword(ctx context.Context, roleKeyword string) ([]*model.Api, error) {
+	policies := r.enforcer.GetFilteredPolicy(0, roleKeyword)
 
 	// 获取所有接口
 	var apis []*model.Api
-	err := common.DB.Find(&apis).Error
+	err := r.db.WithContext(ctx).Find(&apis).Error
 	if err != nil {
 		return apis, errors.New("获取角色的权限接口失败")
 	}
@@ -129,24 +161,24 @@ func (r RoleRepository) GetRoleApisByRoleKeyword(roleKeyword string) ([]*model.A
 }
 
 // 更新角色的权限接口（先全部删除再新增）
-func (r RoleRepository) UpdateRoleApis(roleKeyword string, reqRolePolicies [][]string) error {
+func (r RoleRepository) UpdateRoleApis(ctx context.Context, roleKeyword string, reqRolePolicies [][]string) error {
 	// 先获取path中的角色ID对应角色已有的police(需要先删除的)
-	err := common.CasbinEnforcer.LoadPolicy()
+	err := r.enforcer.LoadPolicy()
 	if err != nil {
 		return errors.New("角色的权限接口策略加载失败")
 	}
-	rmPolicies := common.CasbinEnforcer.GetFilteredPolicy(0, roleKeyword)
+	rmPolicies := r.enforcer.GetFilteredPolicy(0, roleKeyword)
 	if len(rmPolicies) > 0 {
-		isRemoved, _ := common.CasbinEnforcer.RemovePolicies(rmPolicies)
+		isRemoved, _ := r.enforcer.RemovePolicies(rmPolicies)
 		if !isRemoved {
 			return errors.New("更新角色的权限接口失败")
 		}
 	}
-	isAdded, _ := common.CasbinEnforcer.AddPolicies(reqRolePolicies)
+	isAdded, _ := r.enforcer.AddPolicies(reqRolePolicies)
 	if !isAdded {
 		return errors.New("更新角色的权限接口失败")
 	}
-	err = common.CasbinEnforcer.LoadPolicy()
+	err = r.enforcer.LoadPolicy()
 	if err != nil {
 		return errors.New("更新角色的权限接口成功，角色的权限接口策略加载失败")
 	} else {
@@ -155,26 +187,251 @@ func (r RoleRepository) UpdateRoleApis(roleKeyword string, reqRolePolicies [][]s
 }
 
 // 删除角色
-func (r RoleRepository) BatchDeleteRoleByIds(roleIds []uint) error {
+func (r RoleRepository) BatchDeleteRoleByIds(ctx context.Context, roleIds []uint) error {
 	var roles []*model.Role
-	err := common.DB.Where("id IN (?)", roleIds).Find(&roles).Error
+	err := r.db.WithContext(ctx).Where("id IN (?)", roleIds).Find(&roles).Error
 	if err != nil {
 		return err
 	}
-	err = common.DB.Select("Users", "Menus").Unscoped().Delete(&roles).Error
+	err = r.db.WithContext(ctx).Select("Users", "Menus", "Projects").Unscoped().Delete(&roles).Error
+	if err == nil {
+		err = r.db.WithContext(ctx).Where("role_id IN (?) OR parent_id IN (?)", roleIds, roleIds).Delete(&model.RoleSuspendedParent{}).Error
+	}
 	// 删除成功就删除casbin policy
 	if err == nil {
 		for _, role := range roles {
 			roleKeyword := role.Keyword
-			rmPolicies := common.CasbinEnforcer.GetFilteredPolicy(0, roleKeyword)
+			rmPolicies := r.enforcer.GetFilteredPolicy(0, roleKeyword)
 			if len(rmPolicies) > 0 {
-				isRemoved, _ := common.CasbinEnforcer.RemovePolicies(rmPolicies)
+				isRemoved, _ := r.enforcer.RemovePolicies(rmPolicies)
 				if !isRemoved {
 					return errors.New("删除角色成功, 删除角色关联权限接口失败")
 				}
 			}
+			// 删除角色的继承关系, 继承该角色的子角色不再拥有其权限
+			if _, err := r.enforcer.RemoveFilteredGroupingPolicy(0, roleKeyword); err != nil {
+				return errors.New("删除角色成功, 删除角色继承关系失败")
+			}
+			if _, err := r.enforcer.RemoveFilteredGroupingPolicy(1, roleKeyword); err != nil {
+				return errors.New("删除角色成功, 删除角色继承关系失败")
+			}
+		}
+		// 重新加载策略, 同时清空校验结果缓存, 子角色立即失去被删除角色的权限
+		if err := r.enforcer.LoadPolicy(); err != nil {
+			return errors.New("删除角色成功, 角色的权限接口策略加载失败")
 		}
-
 	}
 	return err
 }
+
+// 获取角色直接继承的父角色, 包括被禁用而暂停继承的父角色
+func (r RoleRepository) GetRoleParents(ctx context.Context, roleKeyword string) ([]*model.Role, error) {
+	var keywords []string
+	for _, rule := range r.enforcer.GetFilteredGroupingPolicy(0, roleKeyword) {
+		keywords = append(keywords, rule[1])
+	}
+	parents, err := r.GetRolesByKeywords(ctx, keywords)
+	if err != nil {
+		return nil, err
+	}
+	var suspended []*model.Role
+	err = r.db.WithContext(ctx).
+		Where("id IN (?)", r.db.Model(&model.RoleSuspendedParent{}).Select("parent_id").
+			Where("role_id IN (?)", r.db.Model(&model.Role{}).Select("id").Where("keyword = ?", roleKeyword))).
+		Find(&suspended).Error
+	if err != nil {
+		return nil, err
+	}
+	return append(parents, suspended...), nil
+}
+
+// 获取角色继承的全部上级角色, 由近到远
+func (r RoleRepository) GetRoleAncestors(ctx context.Context, roleKeyword string) ([]*model.Role, error) {
+	keywords, err := r.enforcer.GetImplicitRolesForUser(roleKeyword)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetRolesByKeywords(ctx, keywords)
+}
+
+// 更新角色继承的父角色（先全部删除再新增）
+func (r RoleRepository) UpdateRoleParents(ctx context.Context, role *model.Role, parents []*model.Role) error {
+	rules := make([][]string, 0, len(parents))
+	suspended := make([]model.RoleSuspendedParent, 0)
+	for _, parent := range parents {
+		if parent.Keyword == role.Keyword {
+			return errors.New("角色不能继承自己")
+		}
+		// 父角色已经继承了当前角色时会形成循环
+		ancestors, err := r.enforcer.GetImplicitRolesForUser(parent.Keyword)
+		if err != nil {
+			return err
+		}
+		if funk.ContainsString(ancestors, role.Keyword) {
+			return fmt.Errorf("角色%s已继承%s, 不能循环继承", parent.Name, role.Name)
+		}
+		// 被禁用的父角色暂停继承, 启用后恢复
+		if parent.Status != known.DEFAULT_ID {
+			suspended = append(suspended, model.RoleSuspendedParent{RoleID: role.ID, ParentID: parent.ID})
+			continue
+		}
+		rules = append(rules, []string{role.Keyword, parent.Keyword})
+	}
+
+	if _, err := r.enforcer.RemoveFilteredGroupingPolicy(0, role.Keyword); err != nil {
+		return errors.New("更新角色继承的父角色失败")
+	}
+	if err := r.db.WithContext(ctx).Where("role_id = ?", role.ID).Delete(&model.RoleSuspendedParent{}).Error; err != nil {
+		return err
+	}
+	if len(suspended) > 0 {
+		if err := r.db.WithContext(ctx).Create(&suspended).Error; err != nil {
+			return err
+		}
+	}
+	if len(rules) > 0 {
+		isAdded, _ := r.enforcer.AddGroupingPolicies(rules)
+		if !isAdded {
+			return errors.New("更新角色继承的父角色失败")
+		}
+	}
+	// 重新加载策略, 同时清空校验结果缓存
+	err := r.enforcer.LoadPolicy()
+	if err != nil {
+		return errors.New("更新角色继承的父角色成功，角色的权限接口策略加载失败")
+	}
+	return nil
+}
+
+// 角色关键字变更时更新继承关系, 包括作为子角色和作为父角色的关系
+func (r RoleRepository) RenameRoleInheritance(oldKeyword string, newKeyword string) error {
+	var rmRules, addRules [][]string
+	for _, rule := range r.enforcer.GetFilteredGroupingPolicy(0, oldKeyword) {
+		rmRules = append(rmRules, rule)
+		addRules = append(addRules, []string{newKeyword, rule[1]})
+	}
+	for _, rule := range r.enforcer.GetFilteredGroupingPolicy(1, oldKeyword) {
+		rmRules = append(rmRules, rule)
+		addRules = append(addRules, []string{rule[0], newKeyword})
+	}
+	if len(rmRules) == 0 {
+		return nil
+	}
+	isRemoved, _ := r.enforcer.RemoveGroupingPolicies(rmRules)
+	if !isRemoved {
+		return errors.New("更新角色继承关系失败")
+	}
+	isAdded, _ := r.enforcer.AddGroupingPolicies(addRules)
+	if !isAdded {
+		return errors.New("更新角色继承关系失败")
+	}
+	return r.enforcer.LoadPolicy()
+}
+
+// 角色启用或禁用后同步子角色的继承关系
+// 禁用时暂停以该角色为父角色的继承关系, 子角色不再拥有该角色及其上级角色的接口和菜单; 启用时恢复
+func (r RoleRepository) UpdateRoleInheritanceStatus(ctx context.Context, role *model.Role) error {
+	if role.Status == known.DEFAULT_ID {
+		return r.resumeRoleChildren(ctx, role)
+	}
+	return r.suspendRoleChildren(ctx, role)
+}
+
+// 暂停子角色对该角色的继承
+func (r RoleRepository) suspendRoleChildren(ctx context.Context, role *model.Role) error {
+	rules := r.enforcer.GetFilteredGroupingPolicy(1, role.Keyword)
+	if len(rules) == 0 {
+		return nil
+	}
+	keywords := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		keywords = append(keywords, rule[0])
+	}
+	children, err := r.GetRolesByKeywords(ctx, keywords)
+	if err != nil {
+		return err
+	}
+	suspended := make([]model.RoleSuspendedParent, 0, len(children))
+	for _, child := range children {
+		suspended = append(suspended, model.RoleSuspendedParent{RoleID: child.ID, ParentID: role.ID})
+	}
+	if len(suspended) > 0 {
+		err = r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&suspended).Error
+		if err != nil {
+			return err
+		}
+	}
+	isRemoved, _ := r.enforcer.RemoveGroupingPolicies(rules)
+	if !isRemoved {
+		return errors.New("暂停角色的继承关系失败")
+	}
+	// 重新加载策略, 同时清空校验结果缓存
+	if err := r.enforcer.LoadPolicy(); err != nil {
+		return errors.New("暂停角色的继承关系成功，角色的权限接口策略加载失败")
+	}
+	return nil
+}
+
+// 恢复子角色对该角色的继承
+func (r RoleRepository) resumeRoleChildren(ctx context.Context, role *model.Role) error {
+	var suspended []model.RoleSuspendedParent
+	err := r.db.WithContext(ctx).Where("parent_id = ?", role.ID).Find(&suspended).Error
+	if err != nil || len(suspended) == 0 {
+		return err
+	}
+	childIds := make([]uint, 0, len(suspended))
+	for _, s := range suspended {
+		childIds = append(childIds, s.RoleID)
+	}
+	children, err := r.GetRolesByIds(ctx, childIds)
+	if err != nil {
+		return err
+	}
+	ancestors, err := r.enforcer.GetImplicitRolesForUser(role.Keyword)
+	if err != nil {
+		return err
+	}
+	rules := make([][]string, 0, len(children))
+	for _, child := range children {
+		// 暂停期间该角色继承了子角色时不再恢复, 避免循环继承
+		if funk.ContainsString(ancestors, child.Keyword) || r.enforcer.HasGroupingPolicy(child.Keyword, role.Keyword) {
+			continue
+		}
+		rules = append(rules, []string{child.Keyword, role.Keyword})
+	}
+	if len(rules) > 0 {
+		isAdded, _ := r.enforcer.AddGroupingPolicies(rules)
+		if !isAdded {
+			return errors.New("恢复角色的继承关系失败")
+		}
+	}
+	if err := r.db.WithContext(ctx).Where("parent_id = ?", role.ID).Delete(&model.RoleSuspendedParent{}).Error; err != nil {
+		return err
+	}
+	if err := r.enforcer.LoadPolicy(); err != nil {
+		return errors.New("恢复角色的继承关系成功，角色的权限接口策略加载失败")
+	}
+	return nil
+}
+
+// 根据关键字获取角色, 按关键字顺序返回
+func (r RoleRepository) GetRolesByKeywords(ctx context.Context, keywords []string) ([]*model.Role, error) {
+	roles := make([]*model.Role, 0, len(keywords))
+	if len(keywords) == 0 {
+		return roles, nil
+	}
+	var list []*model.Role
+	err := r.db.WithContext(ctx).Where("keyword IN (?)", keywords).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, keyword := range keywords {
+		for _, role := range list {
+			if role.Keyword == keyword {
+				roles = append(roles, role)
+				break
+			}
+		}
+	}
+	return roles, nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/pkg/api/vo"
+)
+
+type IServiceAccountRepository interface {
+	GetServiceAccounts(ctx context.Context, req *vo.ServiceAccountListRequest) ([]*model.ServiceAccount, int64, error) // 获取服务账号列表
+	GetServiceAccountByID(ctx context.Context, id uint) (*model.ServiceAccount, error)                                 // 获取单个服务账号
+	GetServiceAccountsByIds(ctx context.Context, ids []uint) ([]*model.ServiceAccount, error)                          // 根据ID获取服务账号
+	CreateServiceAccount(ctx context.Context, account *model.ServiceAccount) error                                     // 创建服务账号
+	UpdateServiceAccountByID(ctx context.Context, id uint, account *model.ServiceAccount) error                        // 更新服务账号
+	BatchDeleteServiceAccountByIds(ctx context.Context, ids []uint) error                                              // 批量删除服务账号, 同时吊销其API Key
+	CreateApiKey(ctx context.Context, key *model.ApiKey) error                                                         // 创建API Key
+	GetApiKeyByID(ctx context.Context, id uint) (*model.ApiKey, error)                                                 // 获取单个API Key
+	GetApiKeyByPrefix(ctx context.Context, prefix string) (*model.ApiKey, error)                                       // 根据前缀获取API Key
+	RevokeApiKey(ctx context.Context, id uint) error                                                                   // 吊销API Key
+	UpdateApiKeyLastUsed(ctx context.Context, key *model.ApiKey, ip string) error                                      // 记录API Key最近使用时间与IP
+}
+
+type ServiceAccountRepository struct {
+	db *gorm.DB
+}
+
+// ServiceAccountRepository构造函数
+func NewServiceAccountRepository(db *gorm.DB) IServiceAccountRepository {
+	return ServiceAccountRepository{db: db}
+}
+
+// 获取服务账号列表
+func (r ServiceAccountRepository) GetServiceAccounts(ctx context.Context, req *vo.ServiceAccountListRequest) ([]*model.ServiceAccount, int64, error) {
+	var list []*model.ServiceAccount
+	db := r.db.WithContext(ctx).Model(&model.ServiceAccount{}).Order("created_at DESC")
+
+	name := strings.TrimSpace(req.Name)
+	if name != "" {
+		db = db.Where("name LIKE ?", fmt.Sprintf("%%%s%%", name))
+	}
+	if req.Status != 0 {
+		db = db.Where("status = ?", req.Status)
+	}
+	var total int64
+	err := db.Count(&total).Error
+	if err != nil {
+		return list, total, err
+	}
+	db = db.Preload("Role").Preload("ApiKeys", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at DESC")
+	})
+	pageNum := int(req.PageNum)
+	pageSize := int(req.PageSize)
+	if pageNum > 0 && pageSize > 0 {
+		err = db.Offset((pageNum - 1) * pageSize).Limit(pageSize).Find(&list).Error
+	} else {
+		err = db.Find(&list).Error
+	}
+	return list, total, err
+}
+
+// 获取单个服务账号
+func (r ServiceAccountRepository) GetServiceAccountByID(ctx context.Context, id uint) (*model.ServiceAccount, error) {
+	var account model.ServiceAccount
+	err := r.db.WithContext(ctx).Where("id = ?", id).Preload("Role").First(&account).Error
+	return &account, err
+}
+
+// 根据ID获取服务账号
+func (r ServiceAccountRepository) GetServiceAccountsByIds(ctx context.Context, ids []uint) ([]*model.ServiceAccount, error) {
+	var list []*model.ServiceAccount
+	err := r.db.WithContext(ctx).Where("id IN (?)", ids).Preload("Role").Find(&list).Error
+	return list, err
+}
+
+// 创建服务账号
+func (r ServiceAccountRepository) CreateServiceAccount(ctx context.Context, account *model.ServiceAccount) error {
+	return r.db.WithContext(ctx).Create(account).Error
+}
+
+// 更新服务账号
+func (r ServiceAccountRepository) UpdateServiceAccountByID(ctx context.Context, id uint, account *model.ServiceAccount) error {
+	return r.db.WithContext(ctx).Model(&model.ServiceAccount{}).Where("id = ?", id).
+		Select("name", "desc", "role_id", "status").Updates(account).Error
+}
+
+// 批量删除服务账号, 同时吊销其API Key
+func (r ServiceAccountRepository) BatchDeleteServiceAccountByIds(ctx context.Context, ids []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.ApiKey{}).
+			Where("service_account_id IN (?) AND revoked_at IS NULL", ids).
+			Update("revoked_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("id IN (?)", ids).Delete(&model.ServiceAccount{}).Error
+	})
+}
+
+// 创建API Key
+func (r ServiceAccountRepository) CreateApiKey(ctx context.Context, key *model.ApiKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+// 获取单个API Key
+func (r ServiceAccountRepository) GetApiKeyByID(ctx context.Context, id uint) (*model.ApiKey, error) {
+	var key model.ApiKey
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&key).Error
+	return &key, err
+}
+
+// 根据前缀获取API Key
+func (r ServiceAccountRepository) GetApiKeyByPrefix(ctx context.Context, prefix string) (*model.ApiKey, error) {
+	var key model.ApiKey
+	err := r.db.WithContext(ctx).Where("prefix = ?", prefix).First(&key).Error
+	return &key, err
+}
+
+// 吊销API Key, 已吊销的保持原吊销时间
+func (r ServiceAccountRepository) RevokeApiKey(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Model(&model.ApiKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// 记录API Key最近使用时间与IP
+func (r ServiceAccountRepository) UpdateApiKeyLastUsed(ctx context.Context, key *model.ApiKey, ip string) error {
+	now := time.Now()
+	err := r.db.WithContext(ctx).Model(&model.ApiKey{}).Where("id = ?", key.ID).
+		UpdateColumns(map[string]interface{}{"last_used_at": now, "last_used_ip": ip}).Error
+	if err == nil {
+		key.LastUsedAt = &now
+		key.LastUsedIP = ip
+	}
+	return err
+}
@@ -6,33 +6,35 @@
 package repository
 
 import (
-	"gotribe-admin/internal/pkg/common"
+	"context"
+	"gorm.io/gorm"
 	"gotribe-admin/internal/pkg/model"
 )
 
 type ISystemConfigRepository interface {
-	GetSystemConfig() (model.SystemConfig, error)              // 获取单个标签
-	UpdateSystemConfig(systemConfig *model.SystemConfig) error // 更新标签
+	GetSystemConfig(ctx context.Context) (model.SystemConfig, error)                // 获取单个标签
+	UpdateSystemConfig(ctx context.Context, systemConfig *model.SystemConfig) error // 更新标签
 }
 
 type SystemConfigRepository struct {
+	db *gorm.DB
 }
 
 // SystemConfigRepository构造函数
-func NewSystemConfigRepository() ISystemConfigRepository {
-	return SystemConfigRepository{}
+func NewSystemConfigRepository(db *gorm.DB) ISystemConfigRepository {
+	return SystemConfigRepository{db: db}
 }
 
 // 获取单个
-func (tr SystemConfigRepository) GetSystemConfig() (model.SystemConfig, error) {
+func (tr SystemConfigRepository) GetSystemConfig(ctx context.Context) (model.SystemConfig, error) {
 	var systemConfig model.SystemConfig
-	err := common.DB.First(&systemConfig).Error
+	err := tr.db.WithContext(ctx).First(&systemConfig).Error
 	return systemConfig, err
 }
 
 // 更新
-func (tr SystemConfigRepository) UpdateSystemConfig(systemConfig *model.SystemConfig) error {
-	err := common.DB.Model(systemConfig).Updates(systemConfig).Error
+func (tr SystemConfigRepository) UpdateSystemConfig(ctx context.Context, systemConfig *model.SystemConfig) error {
+	err := tr.db.WithContext(ctx).Model(systemConfig).Updates(systemConfig).Error
 	if err != nil {
 		return err
 	}
@@ -6,42 +6,43 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/common"
 	"gotribe-admin/internal/pkg/model"
 	"gotribe-admin/pkg/api/vo"
 	"strings"
 )
 
 type ITagRepository interface {
-	CreateTag(tag *model.Tag) (*model.Tag, error)                // 创建标签
-	GetTagByTagID(tagID string) (model.Tag, error)               // 获取单个标签
-	GetTags(req *vo.TagListRequest) ([]*model.Tag, int64, error) // 获取标签列表
-	UpdateTag(tag *model.Tag) error                              // 更新标签
-	BatchDeleteTagByIds(ids []string) error                      // 批量删除
+	CreateTag(ctx context.Context, tag *model.Tag) (*model.Tag, error)                // 创建标签
+	GetTagByTagID(ctx context.Context, tagID string) (model.Tag, error)               // 获取单个标签
+	GetTags(ctx context.Context, req *vo.TagListRequest) ([]*model.Tag, int64, error) // 获取标签列表
+	UpdateTag(ctx context.Context, tag *model.Tag) error                              // 更新标签
+	BatchDeleteTagByIds(ctx context.Context, ids []string) error                      // 批量删除
 }
 
 type TagRepository struct {
+	db *gorm.DB
 }
 
 // TagRepository构造函数
-func NewTagRepository() ITagRepository {
-	return TagRepository{}
+func NewTagRepository(db *gorm.DB) ITagRepository {
+	return TagRepository{db: db}
 }
 
 // 获取单个标签
-func (tr TagRepository) GetTagByTagID(tagID string) (model.Tag, error) {
+func (tr TagRepository) GetTagByTagID(ctx context.Context, tagID string) (model.Tag, error) {
 	var tag model.Tag
-	err := common.DB.Where("tag_id = ?", tagID).First(&tag).Error
+	err := tr.db.WithContext(ctx).Where("tag_id = ?", tagID).First(&tag).Error
 	return tag, err
 }
 
 // 获取标签列表
-func (tr TagRepository) GetTags(req *vo.TagListRequest) ([]*model.Tag, int64, error) {
+func (tr TagRepository) GetTags(ctx context.Context, req *vo.TagListRequest) ([]*model.Tag, int64, error) {
 	var list []*model.Tag
-	db := common.DB.Model(&model.Tag{}).Order("created_at DESC")
+	db := tr.db.WithContext(ctx).Model(&model.Tag{}).Order("created_at DESC")
 
 	title := strings.TrimSpace(req.Title)
 	if title != "" {
@@ -69,11 +70,11 @@ func (tr TagRepository) GetTags(req *vo.TagListRequest) ([]*model.Tag, int64, er
 }
 
 // 创建标签
-func (tr TagRepository) CreateTag(tag *model.Tag) (*model.Tag, error) {
-	if isTagExist(tag.Title) {
+func (tr TagRepository) CreateTag(ctx context.Context, tag *model.Tag) (*model.Tag, error) {
+	if tr.isTagExist(ctx, tag.Title) {
 		return nil, errors.New(fmt.Sprintf("%s标签已存在", tag.Title))
 	}
-	result := common.DB.Create(tag)
+	result := tr.db.WithContext(ctx).Create(tag)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -81,8 +82,8 @@ func (tr TagRepository) CreateTag(tag *model.Tag) (*model.Tag, error) {
 }
 
 // 更新标签
-func (tr TagRepository) UpdateTag(tag *model.Tag) error {
-	err := common.DB.Model(tag).Updates(tag).Error
+func (tr TagRepository) UpdateTag(ctx context.Context, tag *model.Tag) error {
+	err := tr.db.WithContext(ctx).Model(tag).Updates(tag).Error
 	if err != nil {
 		return err
 	}
@@ -91,25 +92,25 @@ func (tr TagRepository) UpdateTag(tag *model.Tag) error {
 }
 
 // 批量删除
-func (tr TagRepository) BatchDeleteTagByIds(ids []string) error {
+func (tr TagRepository) BatchDeleteTagByIds(ctx context.Context, ids []string) error {
 	var tags []model.Tag
 	for _, id := range ids {
 		// 根据ID获取标签
-		tag, err := tr.GetTagByTagID(id)
+		tag, err := tr.GetTagByTagID(ctx, id)
 		if err != nil {
 			return errors.New(fmt.Sprintf("未获取到ID为%s的标签", id))
 		}
 		tags = append(tags, tag)
 	}
 
-	err := common.DB.Unscoped().Delete(&tags).Error
+	err := tr.db.WithContext(ctx).Unscoped().Delete(&tags).Error
 
 	return err
 }
 
-func isTagExist(title string) bool {
+func (tr TagRepository) isTagExist(ctx context.Context, title string) bool {
 	var tag model.Tag
-	result := common.DB.Where("title = ?", title).First(&tag)
+	result := tr.db.WithContext(ctx).Where("title = ?", title).First(&tag)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return false
 	}
@@ -6,44 +6,45 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/common"
 	"gotribe-admin/internal/pkg/model"
 	"gotribe-admin/pkg/api/vo"
 	"strings"
 )
 
 type IUserRepository interface {
-	CreateUser(user *model.User) error                              // 创建用户
-	GetUserByUserID(userID string) (model.User, error)              // 获取单个用户
-	GetUsers(req *vo.UserListRequest) ([]*model.User, int64, error) // 获取用户列表
-	UpdateUser(user *model.User) error                              // 更新用户
-	BatchDeleteUserByIds(ids []string) error                        // 批量删除用户
-	SearchUserByNickname(nickname string) ([]*model.User, error)
+	CreateUser(ctx context.Context, user *model.User) error                              // 创建用户
+	GetUserByUserID(ctx context.Context, userID string) (model.User, error)              // 获取单个用户
+	GetUsers(ctx context.Context, req *vo.UserListRequest) ([]*model.User, int64, error) // 获取用户列表
+	UpdateUser(ctx context.Context, user *model.User) error                              // 更新用户
+	BatchDeleteUserByIds(ctx context.Context, ids []string) error                        // 批量删除用户
+	SearchUserByNickname(ctx context.Context, nickname string) ([]*model.User, error)
 }
 
 type UserRepository struct {
+	db *gorm.DB
 }
 
 // UserRepository构造函数
-func NewUserRepository() IUserRepository {
-	return UserRepository{}
+func NewUserRepository(db *gorm.DB) IUserRepository {
+	return UserRepository{db: db}
 }
 
 // 获取单个用户
-func (ur UserRepository) GetUserByUserID(userID string) (model.User, error) {
+func (ur UserRepository) GetUserByUserID(ctx context.Context, userID string) (model.User, error) {
 	var user model.User
-	err := common.DB.Where("user_id = ?", userID).First(&user).Error
+	err := ur.db.WithContext(ctx).Where("user_id = ?", userID).First(&user).Error
 	return user, err
 }
 
 // 获取用户列表
-func (ur UserRepository) GetUsers(req *vo.UserListRequest) ([]*model.User, int64, error) {
+func (ur UserRepository) GetUsers(ctx context.Context, req *vo.UserListRequest) ([]*model.User, int64, error) {
 	var list []*model.User
-	db := common.DB.Model(&model.User{}).Order("created_at DESC")
+	db := ur.db.WithContext(ctx).Model(&model.User{}).Order("created_at DESC")
 
 	username := strings.TrimSpace(req.Username)
 	if username != "" {
@@ -71,21 +72,21 @@ func (ur UserRepository) GetUsers(req *vo.UserListRequest) ([]*model.User, int64
 	} else {
 		err = db.Find(&list).Error
 	}
-	return GetUserOther(list), total, err
+	return ur.GetUserOther(ctx, list), total, err
 }
 
-func GetUserOther(user []*model.User) []*model.User {
+func (ur UserRepository) GetUserOther(ctx context.Context, user []*model.User) []*model.User {
 	for _, m := range user {
-		userPoint := GetUserPoint(m.UserID)
+		userPoint := ur.GetUserPoint(ctx, m.UserID)
 		m.Point = userPoint
 	}
 	return user
 }
 
-func GetUserPoint(userID string) float64 {
+func (ur UserRepository) GetUserPoint(ctx context.Context, userID string) float64 {
 	var sum sql.NullFloat64
 	var pointAvailable *model.PointAvailable
-	row := common.DB.Model(&pointAvailable).Select("SUM(points)").Where("user_id = ?", userID).Row()
+	row := ur.db.WithContext(ctx).Model(&pointAvailable).Select("SUM(points)").Where("user_id = ?", userID).Row()
 	err := row.Scan(&sum)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -102,14 +103,14 @@ func GetUserPoint(userID string) float64 {
 }
 
 // 创建用户
-func (ur UserRepository) CreateUser(user *model.User) error {
-	err := common.DB.Create(user).Error
+func (ur UserRepository) CreateUser(ctx context.Context, user *model.User) error {
+	err := ur.db.WithContext(ctx).Create(user).Error
 	return err
 }
 
 // 更新用户
-func (ur UserRepository) UpdateUser(user *model.User) error {
-	err := common.DB.Model(user).Updates(user).Error
+func (ur UserRepository) UpdateUser(ctx context.Context, user *model.User) error {
+	err := ur.db.WithContext(ctx).Model(user).Updates(user).Error
 	if err != nil {
 		return err
 	}
@@ -118,26 +119,26 @@ func (ur UserRepository) UpdateUser(user *model.User) error {
 }
 
 // 批量删除
-func (ur UserRepository) BatchDeleteUserByIds(ids []string) error {
+func (ur UserRepository) BatchDeleteUserByIds(ctx context.Context, ids []string) error {
 	var users []model.User
 	for _, id := range ids {
 		// 根据ID获取用户
-		user, err := ur.GetUserByUserID(id)
+		user, err := ur.GetUserByUserID(ctx, id)
 		if err != nil {
 			return errors.New(fmt.Sprintf("未获取到ID为%s的用户", id))
 		}
 		users = append(users, user)
 	}
 
-	err := common.DB.Delete(&users).Error
+	err := ur.db.WithContext(ctx).Delete(&users).Error
 
 	return err
 }
 
 // 搜索用户
-func (ur UserRepository) SearchUserByNickname(nickname string) ([]*model.User, error) {
+func (ur UserRepository) SearchUserByNickname(ctx context.Context, nickname string) ([]*model.User, error) {
 	var list []*model.User
-	db := common.DB.Model(&model.User{}).Order("created_at DESC")
+	db := ur.db.WithContext(ctx).Model(&model.User{}).Order("created_at DESC")
 
 	if strings.TrimSpace(nickname) != "" {
 		db = db.Where("nickname LIKE ?", fmt.Sprintf("%%%s%%", nickname))
@@ -8,18 +8,21 @@ package routes
 import (
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
+	"gotribe-admin/internal/app"
 	"gotribe-admin/internal/app/controller"
 	"gotribe-admin/internal/pkg/middleware"
 )
 
 // 注册推广位管理路由
-func InitAdRoutes(r *gin.RouterGroup, authMiddleware *jwt.GinJWTMiddleware) gin.IRoutes {
-	adController := controller.NewAdController()
+func InitAdRoutes(r *gin.RouterGroup, authMiddleware *jwt.GinJWTMiddleware, c *app.Container) gin.IRoutes {
+	adController := controller.NewAdController(c.Repositories.Ad)
 	router := r.Group("/ad")
-	// 开启jwt认证中间件
-	router.Use(authMiddleware.MiddlewareFunc())
+	// 开启jwt认证中间件, 同时支持服务账号的API Key认证
+	router.Use(middleware.ApiKeyMiddleware(c.Repositories.ServiceAccount, authMiddleware.MiddlewareFunc()))
 	// 开启casbin鉴权中间件
-	router.Use(middleware.CasbinMiddleware())
+	router.Use(middleware.CasbinMiddleware(c.Repositories.Admin, c.Enforcer, c.Metrics))
+	// 开启项目隔离中间件
+	router.Use(middleware.ProjectScopeMiddleware(c.Repositories.Admin))
 	{
 		router.GET(":adID", adController.GetAdInfo)
 		router.GET("", adController.GetAds)
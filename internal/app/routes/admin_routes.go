@@ -6,6 +6,7 @@
 package routes
 
 import (
+	"gotribe-admin/internal/app"
 	"gotribe-admin/internal/app/controller"
 	"gotribe-admin/internal/pkg/middleware"
 
@@ -14,13 +15,17 @@ import (
 )
 
 // 注册用户路由
-func InitAdminRoutes(r *gin.RouterGroup, authMiddleware *jwt.GinJWTMiddleware) gin.IRoutes {
-	userController := controller.NewAdminController()
+func InitAdminRoutes(r *gin.RouterGroup, authMiddleware *jwt.GinJWTMiddleware, c *app.Container) gin.IRoutes {
+	userController := controller.NewAdminController(c.Repositories.Admin, c.Repositories.Role, c.LoginGuard)
+	loginLogController := controller.NewLoginLogController(c.Repositories.LoginLog, c.Repositories.Admin)
+	totpController := controller.NewTotpController(c.Repositories.Admin, authMiddleware, c.LoginGuard)
 	router := r.Group("/admin")
-	// 开启jwt认证中间件
-	router.Use(authMiddleware.MiddlewareFunc())
+	// 开启jwt认证中间件, 同时支持服务账号的API Key认证
+	router.Use(middleware.ApiKeyMiddleware(c.Repositories.ServiceAccount, authMiddleware.MiddlewareFunc()))
 	// 开启casbin鉴权中间件
-	router.Use(middleware.CasbinMiddleware())
+	router.Use(middleware.CasbinMiddleware(c.Repositories.Admin, c.Enforcer, c.Metrics))
+	// 开启项目隔离中间件
+	router.Use(middleware.ProjectScopeMiddleware(c.Repositories.Admin))
 	{
 		router.POST("/info", userController.GetAdminInfo)
 		router.GET("/list", userController.GetAdmins)
@@ -28,6 +33,14 @@ func InitAdminRoutes(r *gin.RouterGroup, authMiddleware *jwt.GinJWTMiddleware) g
 		router.POST("/create", userController.CreateAdmin)
 		router.PATCH("/update/:userID", userController.UpdateAdminByID)
 		router.DELETE("/delete/batch", userController.BatchDeleteAdminByIds)
+		router.PATCH("/projects/update/:userID", userController.UpdateAdminProjectsByID)
+		// 两步验证的密钥、恢复码与验证码不记录到操作日志
+		router.POST("/totp/enroll", middleware.SkipBodyCaptureMiddleware(), totpController.Enroll)
+		router.POST("/totp/activate", middleware.SkipBodyCaptureMiddleware(), totpController.Activate)
+		router.POST("/totp/disable", middleware.SkipBodyCaptureMiddleware(), totpController.Disable)
+		router.PATCH("/totp/reset/:userID", totpController.Reset)
+		router.PATCH("/unlock/:userID", userController.UnlockAdminByID)
+		router.GET("/loginLog/list", loginLogController.GetCurrentAdminLoginLogs)
 	}
 	return r
 }
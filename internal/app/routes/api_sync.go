@@ -0,0 +1,197 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package routes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gotribe-admin/config"
+	"gotribe-admin/internal/app"
+	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/internal/pkg/permission"
+	"gotribe-admin/internal/pkg/seed"
+	"gotribe-admin/pkg/api/vo"
+)
+
+// 启动时接口表同步方式, 对应system.api-sync配置
+const (
+	ApiSyncModeOff    = "off"    // 不同步(默认)
+	ApiSyncModeReport = "report" // 只在日志中输出差异
+	ApiSyncModeInsert = "insert" // 补齐缺少的接口, 多余的接口与策略只输出
+)
+
+// 已注册路由与接口表、casbin策略的差异
+type ApiSyncReport struct {
+	Missing       []*model.Api // 已注册但未登记的路由
+	Stale         []*model.Api // 已登记但未注册的接口
+	StalePolicies [][]string   // 不能匹配任何已注册路由的策略
+}
+
+// 是否没有差异
+func (r *ApiSyncReport) Empty() bool {
+	return len(r.Missing) == 0 && len(r.Stale) == 0 && len(r.StalePolicies) == 0
+}
+
+// 接口前缀下的已注册路由, 路径去掉接口前缀, 所属类别与说明取自初始数据, 未登记时按路径与处理函数推断
+func RegisteredApis(r *gin.Engine) ([]*model.Api, error) {
+	catalog, err := seed.ApiCatalog()
+	if err != nil {
+		return nil, err
+	}
+	prefix := "/" + config.Conf.System.UrlPathPrefix
+	var apis []*model.Api
+	for _, route := range r.Routes() {
+		if !strings.HasPrefix(route.Path, prefix+"/") {
+			continue
+		}
+		path := permission.Object(route.Path)
+		api, ok := catalog[route.Method+" "+path]
+		if !ok {
+			api = model.Api{Category: routeCategory(path), Desc: handlerName(route.Handler)}
+		}
+		api.Method = route.Method
+		api.Path = path
+		apis = append(apis, &api)
+	}
+	sort.Slice(apis, func(i, j int) bool {
+		if apis[i].Path != apis[j].Path {
+			return apis[i].Path < apis[j].Path
+		}
+		return apis[i].Method < apis[j].Method
+	})
+	return apis, nil
+}
+
+// 比较已注册路由与接口表、casbin策略
+func DiffApis(ctx context.Context, r *gin.Engine, c *app.Container) (*ApiSyncReport, error) {
+	routes, err := RegisteredApis(r)
+	if err != nil {
+		return nil, fmt.Errorf("读取接口初始数据失败: %v", err)
+	}
+	apis, _, err := c.Repositories.Api.GetApis(ctx, &vo.ApiListRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("获取接口列表失败: %v", err)
+	}
+
+	report := &ApiSyncReport{}
+	registered := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		registered[route.Method+" "+route.Path] = true
+	}
+	existing := make(map[string]bool, len(apis))
+	for _, api := range apis {
+		existing[api.Method+" "+api.Path] = true
+		if !registered[api.Method+" "+api.Path] {
+			report.Stale = append(report.Stale, api)
+		}
+	}
+	for _, route := range routes {
+		if !existing[route.Method+" "+route.Path] {
+			report.Missing = append(report.Missing, route)
+		}
+	}
+	// 策略可以使用通配路径, 能匹配任一已注册路由即有效
+	for _, policy := range c.Enforcer.GetPolicy() {
+		if len(policy) < 3 || !matchAnyRoute(routes, policy[1], policy[2]) {
+			report.StalePolicies = append(report.StalePolicies, policy)
+		}
+	}
+	return report, nil
+}
+
+// 登记缺少的接口, prune为true时同时删除多余的接口与策略
+func ApplyApiSync(ctx context.Context, c *app.Container, report *ApiSyncReport, creator string, prune bool) error {
+	for _, api := range report.Missing {
+		api.Creator = creator
+		if err := c.Repositories.Api.CreateApi(ctx, api); err != nil {
+			return fmt.Errorf("登记接口%s %s失败: %v", api.Method, api.Path, err)
+		}
+	}
+	if !prune {
+		return nil
+	}
+	if len(report.Stale) > 0 {
+		ids := make([]uint, 0, len(report.Stale))
+		for _, api := range report.Stale {
+			ids = append(ids, api.ID)
+		}
+		// 同时删除指向这些接口的策略
+		if err := c.Repositories.Api.BatchDeleteApiByIds(ctx, ids); err != nil {
+			return fmt.Errorf("删除多余的接口失败: %v", err)
+		}
+	}
+	var policies [][]string
+	for _, policy := range report.StalePolicies {
+		if c.Enforcer.HasPolicy(policy) {
+			policies = append(policies, policy)
+		}
+	}
+	if len(policies) > 0 {
+		if _, err := c.Enforcer.RemovePolicies(policies); err != nil {
+			return fmt.Errorf("删除多余的策略失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// 启动时按system.api-sync配置同步接口表, 失败时只记录日志
+func SyncApisOnStartup(r *gin.Engine, c *app.Container) {
+	mode := config.Conf.System.ApiSync
+	if mode == "" || mode == ApiSyncModeOff {
+		return
+	}
+	if mode != ApiSyncModeReport && mode != ApiSyncModeInsert {
+		c.Log.Warnf("未知的接口表同步方式: %s, 可选: %s/%s/%s", mode, ApiSyncModeOff, ApiSyncModeReport, ApiSyncModeInsert)
+		return
+	}
+	ctx := context.Background()
+	report, err := DiffApis(ctx, r, c)
+	if err != nil {
+		c.Log.Errorf("比较接口表与已注册路由失败: %v", err)
+		return
+	}
+	for _, api := range report.Missing {
+		c.Log.Warnf("路由%s %s未登记到接口表", api.Method, api.Path)
+	}
+	for _, api := range report.Stale {
+		c.Log.Warnf("接口%s %s(ID: %d)没有对应的路由", api.Method, api.Path, api.ID)
+	}
+	for _, policy := range report.StalePolicies {
+		c.Log.Warnf("策略%v没有对应的路由", policy)
+	}
+	if mode == ApiSyncModeInsert && len(report.Missing) > 0 {
+		if err := ApplyApiSync(ctx, c, report, "系统", false); err != nil {
+			c.Log.Errorf("同步接口表失败: %v", err)
+			return
+		}
+		c.Log.Infof("已登记%d个接口, 可使用 gotribe-admin casbin sync 为角色分配权限", len(report.Missing))
+	}
+}
+
+func matchAnyRoute(routes []*model.Api, path string, method string) bool {
+	for _, route := range routes {
+		if (method == route.Method || method == "*") && (path == route.Path || permission.PathMatch(route.Path, path)) {
+			return true
+		}
+	}
+	return false
+}
+
+// 未登记时以路径第一段作为所属类别
+func routeCategory(path string) string {
+	category, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return category
+}
+
+// 未登记时以处理函数名作为说明, 如 gotribe-admin/internal/app/controller.PostController.GetPosts-fm 取 GetPosts
+func handlerName(handler string) string {
+	handler = strings.TrimSuffix(handler, "-fm")
+	return handler[strings.LastIndex(handler, ".")+1:]
+}
@@ -8,18 +8,24 @@ package routes
 import (
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
+	"gotribe-admin/internal/app"
 	"gotribe-admin/internal/app/controller"
+	"gotribe-admin/internal/pkg/middleware"
 )
 
 // 注册基础路由
-func InitBaseRoutes(r *gin.RouterGroup, authMiddleware *jwt.GinJWTMiddleware) gin.IRoutes {
-	systemConfigController := controller.NewSystemConfigController()
+func InitBaseRoutes(r *gin.RouterGroup, authMiddleware *jwt.GinJWTMiddleware, c *app.Container) gin.IRoutes {
+	systemConfigController := controller.NewSystemConfigController(c.Repositories.SystemConfig)
+	totpController := controller.NewTotpController(c.Repositories.Admin, authMiddleware, c.LoginGuard)
 	router := r.Group("/base")
 	{
-		// 登录登出刷新token无需鉴权
-		router.POST("/login", authMiddleware.LoginHandler)
+		// 登录登出刷新token无需鉴权, 签发token的接口不记录请求体与响应体
+		router.POST("/login", middleware.SkipBodyCaptureMiddleware(), authMiddleware.LoginHandler)
+		// 两步验证登录, 凭密码登录返回的challenge提交验证码, 同样不记录请求体与响应体
+		router.POST("/login/totp", middleware.SkipBodyCaptureMiddleware(), totpController.Login)
+		router.POST("/login/totp/enroll", middleware.SkipBodyCaptureMiddleware(), totpController.LoginEnroll)
 		router.POST("/logout", authMiddleware.LogoutHandler)
-		router.POST("/refreshToken", authMiddleware.RefreshHandler)
+		router.POST("/refreshToken", middleware.SkipBodyCaptureMiddleware(), authMiddleware.RefreshHandler)
 		router.GET("/config", systemConfigController.GetSystemConfigInfo)
 
 	}
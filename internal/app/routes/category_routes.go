@@ -8,17 +8,20 @@ package routes
 import (
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
+	"gotribe-admin/internal/app"
 	"gotribe-admin/internal/app/controller"
 	"gotribe-admin/internal/pkg/middleware"
 )
 
-func InitCategoryRoutes(r *gin.RouterGroup, authMiddleware *jwt.GinJWTMiddleware) gin.IRoutes {
-	categoryController := controller.NewCategoryController()
+func InitCategoryRoutes(r *gin.RouterGroup, authMiddleware *jwt.GinJWTMiddleware, c *app.Container) gin.IRoutes {
+	categoryController := controller.NewCategoryController(c.Repositories.Category)
 	router := r.Group("/category")
-	// 开启jwt认证中间件
-	router.Use(authMiddleware.MiddlewareFunc())
+	// 开启jwt认证中间件, 同时支持服务账号的API Key认证
+	router.Use(middleware.ApiKeyMiddleware(c.Repositories.ServiceAccount, authMiddleware.MiddlewareFunc()))
 	// 开启casbin鉴权中间件
-	router.Use(middleware.CasbinMiddleware())
+	router.Use(middleware.CasbinMiddleware(c.Repositories.Admin, c.Enforcer, c.Metrics))
+	// 开启项目隔离中间件
+	router.Use(middleware.ProjectScopeMiddleware(c.Repositories.Admin))
 	{
 		router.GET("/tree", categoryController.GetCategoryTree)
 		router.GET("", categoryController.GetCategorys)
@@ -0,0 +1,20 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"gotribe-admin/internal/app"
+	"gotribe-admin/internal/app/controller"
+)
+
+// 注册存活与就绪检查路由, 不在url-path-prefix下, 不需要jwt认证中间件,不需要casbin中间件
+func InitHealthRoutes(r *gin.Engine, c *app.Container) gin.IRoutes {
+	healthController := controller.NewHealthController(c.Health)
+	r.GET("/healthz", healthController.Healthz)
+	r.GET("/readyz", healthController.Readyz)
+	return r
+}
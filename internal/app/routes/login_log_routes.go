@@ -0,0 +1,29 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package routes
+
+import (
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+	"gotribe-admin/internal/app"
+	"gotribe-admin/internal/app/controller"
+	"gotribe-admin/internal/pkg/middleware"
+)
+
+func InitLoginLogRoutes(r *gin.RouterGroup, authMiddleware *jwt.GinJWTMiddleware, c *app.Container) gin.IRoutes {
+	loginLogController := controller.NewLoginLogController(c.Repositories.LoginLog, c.Repositories.Admin)
+	router := r.Group("/log")
+	// 开启jwt认证中间件, 同时支持服务账号的API Key认证
+	router.Use(middleware.ApiKeyMiddleware(c.Repositories.ServiceAccount, authMiddleware.MiddlewareFunc()))
+	// 开启casbin鉴权中间件
+	router.Use(middleware.CasbinMiddleware(c.Repositories.Admin, c.Enforcer, c.Metrics))
+	// 开启项目隔离中间件
+	router.Use(middleware.ProjectScopeMiddleware(c.Repositories.Admin))
+	{
+		router.GET("/login/list", loginLogController.GetLoginLogs)
+	}
+	return r
+}
@@ -0,0 +1,251 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package routes
+
+import (
+	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/internal/pkg/openapi"
+	"gotribe-admin/pkg/api/dto"
+	"gotribe-admin/pkg/api/vo"
+)
+
+// 列表接口返回的总数
+var listTotal = int64(0)
+
+// 首页数据的查询参数
+type indexQuery struct {
+	ProjectID string `form:"projectID"`
+}
+
+// 首页时间数据的查询参数
+type indexDataQuery struct {
+	ProjectID string `form:"projectID"`
+	TimeRange string `form:"timeRange"`
+}
+
+// 登录与刷新token的响应
+var tokenResponse = map[string]interface{}{"token": "", "expires": ""}
+
+// 密码登录的响应, 需要两步验证时不返回token, 返回challenge
+var loginResponse = map[string]interface{}{"token": "", "expires": "", "totpRequired": false, "totpEnabled": false, "challengeToken": ""}
+
+// 绑定两步验证器的响应
+var totpEnrollResponse = map[string]interface{}{"secret": "", "uri": "", "recoveryCodes": []string{}}
+
+// 各路由的请求与响应结构, key为"METHOD path", path不含接口前缀
+// 新增路由时在此补充, 未补充的路由在文档中只有路径参数与统一响应结构
+var apiDocs = map[string]openapi.Doc{
+	// 基础
+	"POST /base/login":             {Request: vo.RegisterAndLoginRequest{}, Response: loginResponse, Public: true},
+	"POST /base/logout":            {Public: true},
+	"POST /base/refreshToken":      {Response: tokenResponse, Public: true},
+	"POST /base/login/totp":        {Request: vo.TotpLoginRequest{}, Response: tokenResponse, Public: true},
+	"POST /base/login/totp/enroll": {Request: vo.TotpLoginEnrollRequest{}, Response: totpEnrollResponse, Public: true},
+	"GET /base/config":             {Response: map[string]interface{}{"systemConfig": dto.SystemConfigDto{}}, Public: true},
+	"GET /openapi.json":            {Produces: "application/json", Public: true},
+	"GET /docs/*filepath":          {Produces: "text/html", Public: true},
+
+	// 用户
+	"POST /admin/info":                     {Response: map[string]interface{}{"admin": dto.AdminInfoDto{}}},
+	"GET /admin/list":                      {Request: vo.AdminListRequest{}, Response: map[string]interface{}{"admins": []dto.AdminsDto{}, "total": listTotal}},
+	"PUT /admin/changePwd":                 {Request: vo.ChangePwdRequest{}},
+	"POST /admin/create":                   {Request: vo.CreateAdminRequest{}},
+	"PATCH /admin/update/:userID":          {Request: vo.CreateAdminRequest{}},
+	"DELETE /admin/delete/batch":           {Request: vo.DeleteAdminRequest{}},
+	"PATCH /admin/projects/update/:userID": {Request: vo.UpdateAdminProjectsRequest{}},
+	"POST /admin/totp/enroll":              {Response: totpEnrollResponse},
+	"POST /admin/totp/activate":            {Request: vo.TotpCodeRequest{}},
+	"POST /admin/totp/disable":             {Request: vo.TotpCodeRequest{}},
+	"PATCH /admin/totp/reset/:userID":      {},
+	"PATCH /admin/unlock/:userID":          {},
+	"GET /admin/loginLog/list":             {Request: vo.LoginLogListRequest{}, Response: map[string]interface{}{"logs": []model.LoginLog{}, "total": listTotal}},
+
+	// 角色
+	"GET /role/list":                      {Request: vo.RoleListRequest{}, Response: map[string]interface{}{"roles": []model.Role{}, "total": listTotal}},
+	"POST /role/create":                   {Request: vo.CreateRoleRequest{}},
+	"PATCH /role/update/:roleID":          {Request: vo.CreateRoleRequest{}},
+	"GET /role/menus/get/:roleID":         {Response: map[string]interface{}{"menus": []*model.Menu{}}},
+	"PATCH /role/menus/update/:roleID":    {Request: vo.UpdateRoleMenusRequest{}},
+	"GET /role/apis/get/:roleID":          {Response: map[string]interface{}{"apis": []*model.Api{}}},
+	"PATCH /role/apis/update/:roleID":     {Request: vo.UpdateRoleApisRequest{}},
+	"GET /role/projects/get/:roleID":      {Response: map[string]interface{}{"projects": []*model.Project{}}},
+	"PATCH /role/projects/update/:roleID": {Request: vo.UpdateRoleProjectsRequest{}},
+	"GET /role/parents/get/:roleID":       {Response: map[string]interface{}{"parents": []*model.Role{}}},
+	"PATCH /role/parents/update/:roleID":  {Request: vo.UpdateRoleParentsRequest{}},
+	"GET /role/permissions/get/:roleID":   {Response: map[string]interface{}{"ancestors": []*model.Role{}, "permissions": dto.RolePermissionsDto{}}},
+	"DELETE /role/delete/batch":           {Request: vo.DeleteRoleRequest{}},
+
+	// 菜单
+	"GET /menu/tree":                {Response: map[string]interface{}{"menuTree": []*model.Menu{}}},
+	"GET /menu/list":                {Response: map[string]interface{}{"menus": []*model.Menu{}}},
+	"POST /menu/create":             {Request: vo.CreateMenuRequest{}},
+	"PATCH /menu/update/:menuID":    {Request: vo.UpdateMenuRequest{}},
+	"DELETE /menu/delete/batch":     {Request: vo.DeleteMenuRequest{}},
+	"GET /menu/access/list/:userID": {Response: map[string]interface{}{"menus": []*model.Menu{}}},
+	"GET /menu/access/tree/:userID": {Response: map[string]interface{}{"menuTree": []*model.Menu{}}},
+
+	// 接口
+	"GET /api/list":            {Request: vo.ApiListRequest{}, Response: map[string]interface{}{"apis": []*model.Api{}, "total": listTotal}},
+	"GET /api/tree":            {Response: map[string]interface{}{"apiTree": []*dto.ApiTreeDto{}}},
+	"POST /api/create":         {Request: vo.CreateApiRequest{}},
+	"PATCH /api/update/:apiID": {Request: vo.UpdateApiRequest{}},
+	"DELETE /api/delete/batch": {Request: vo.DeleteApiRequest{}},
+
+	// 权限诊断
+	"GET /permission/explain":               {Request: vo.PermissionExplainRequest{}, Response: map[string]interface{}{"explain": dto.PermissionExplainDto{}}},
+	"GET /permission/matrix/:userID":        {Response: map[string]interface{}{"matrix": dto.PermissionMatrixDto{}}},
+	"GET /permission/matrix/export/:userID": {Produces: "text/csv"},
+
+	// 服务账号
+	"GET /serviceAccount/list":                          {Request: vo.ServiceAccountListRequest{}, Response: map[string]interface{}{"serviceAccounts": []*model.ServiceAccount{}, "total": listTotal}},
+	"POST /serviceAccount/create":                       {Request: vo.CreateServiceAccountRequest{}, Response: map[string]interface{}{"serviceAccount": model.ServiceAccount{}}},
+	"PATCH /serviceAccount/update/:serviceAccountID":    {Request: vo.CreateServiceAccountRequest{}},
+	"DELETE /serviceAccount/delete/batch":               {Request: vo.DeleteServiceAccountRequest{}},
+	"POST /serviceAccount/key/create/:serviceAccountID": {Request: vo.CreateApiKeyRequest{}, Response: map[string]interface{}{"apiKey": model.ApiKey{}, "key": ""}},
+	"PATCH /serviceAccount/key/revoke/:apiKeyID":        {},
+
+	// 操作日志
+	"GET /log/operation/list":            {Request: vo.OperationLogListRequest{}, Response: map[string]interface{}{"logs": []model.OperationLog{}, "total": listTotal}},
+	"GET /log/operation/export":          {Request: vo.OperationLogListRequest{}, Produces: "text/csv"},
+	"DELETE /log/operation/delete/batch": {Request: vo.DeleteOperationLogRequest{}},
+	"GET /log/login/list":                {Request: vo.LoginLogListRequest{}, Response: map[string]interface{}{"logs": []model.LoginLog{}, "total": listTotal}},
+
+	// 项目
+	"GET /project":              {Request: vo.ProjectListRequest{}, Response: map[string]interface{}{"projects": []dto.ProjectDto{}, "total": listTotal}},
+	"GET /project/:projectID":   {Response: map[string]interface{}{"project": dto.ProjectDto{}}},
+	"POST /project":             {Request: vo.CreateProjectRequest{}},
+	"PATCH /project/:projectID": {Request: vo.CreateProjectRequest{}},
+	"DELETE /project":           {Request: vo.DeleteProjectsRequest{}},
+
+	// 配置
+	"GET /config":             {Request: vo.ConfigListRequest{}, Response: map[string]interface{}{"configs": []dto.ConfigDto{}, "total": listTotal}},
+	"GET /config/:configID":   {Response: map[string]interface{}{"config": dto.ConfigDto{}}},
+	"POST /config":            {Request: vo.CreateConfigRequest{}},
+	"PATCH /config/:configID": {Request: vo.UpdateConfigRequest{}},
+	"DELETE /config":          {Request: vo.DeleteConfigsRequest{}},
+
+	// 标签
+	"GET /tag":          {Request: vo.TagListRequest{}, Response: map[string]interface{}{"tags": []dto.TagDto{}, "total": listTotal}},
+	"GET /tag/:tagID":   {Response: map[string]interface{}{"tag": dto.TagDto{}}},
+	"POST /tag":         {Request: vo.CreateTagRequest{}, Response: map[string]interface{}{"tag": dto.TagDto{}}},
+	"PATCH /tag/:tagID": {Request: vo.CreateTagRequest{}},
+	"DELETE /tag":       {Request: vo.DeleteTagsRequest{}},
+
+	// 分类
+	"GET /category":               {Response: map[string]interface{}{"categorys": []*model.Category{}}},
+	"GET /category/tree":          {Response: map[string]interface{}{"categoryTree": []*model.Category{}}},
+	"GET /category/:categoryID":   {Response: map[string]interface{}{"category": model.Category{}}},
+	"POST /category":              {Request: vo.CreateCategoryRequest{}},
+	"PATCH /category/:categoryID": {Request: vo.UpdateCategoryRequest{}},
+	"DELETE /category":            {Request: vo.DeleteCategoryRequest{}},
+
+	// 内容
+	"GET /post":           {Request: vo.PostListRequest{}, Response: map[string]interface{}{"posts": []dto.PostsDto{}, "total": listTotal}},
+	"GET /post/:postID":   {Response: map[string]interface{}{"post": dto.PostsDto{}}},
+	"POST /post":          {Request: vo.CreatePostRequest{}},
+	"PATCH /post/:postID": {Request: vo.UpdatePostRequest{}},
+	"PUT /post/:postID":   {},
+	"DELETE /post":        {Request: vo.DeletePostsRequest{}},
+
+	// 用户管理
+	"GET /user":           {Request: vo.UserListRequest{}, Response: map[string]interface{}{"users": []dto.UserDto{}, "total": listTotal}},
+	"GET /user/search":    {Response: map[string]interface{}{"users": []dto.UserDto{}}},
+	"GET /user/:userID":   {Response: map[string]interface{}{"user": dto.UserDto{}}},
+	"POST /user":          {Request: vo.CreateUserRequest{}},
+	"PATCH /user/:userID": {Request: vo.UpdateUserRequest{}},
+	"DELETE /user":        {Request: vo.DeleteUsersRequest{}},
+
+	// 资源
+	"GET /resource":               {Request: vo.ResourceListRequest{}, Response: map[string]interface{}{"resources": []dto.ResourceDto{}, "total": listTotal}},
+	"GET /resource/:resourceID":   {Response: map[string]interface{}{"resource": dto.ResourceDto{}}},
+	"PATCH /resource/:resourceID": {Request: vo.CreateResourceRequest{}},
+	"POST /resource/upload":       {Files: []string{"file"}, Response: map[string]interface{}{"upload": dto.UploadResourceDto{}}},
+	"DELETE /resource":            {Request: vo.DeleteResourcesRequest{}},
+
+	// 专栏
+	"GET /column":             {Request: vo.ColumnListRequest{}, Response: map[string]interface{}{"columns": []dto.ColumnDto{}, "total": listTotal}},
+	"GET /column/:columnID":   {Response: map[string]interface{}{"column": dto.ColumnDto{}}},
+	"POST /column":            {Request: vo.CreateColumnRequest{}},
+	"PATCH /column/:columnID": {Request: vo.UpdateColumnRequest{}},
+	"DELETE /column":          {Request: vo.DeleteColumnsRequest{}},
+
+	// 推广场景
+	"GET /ad/scene":              {Request: vo.AdSceneListRequest{}, Response: map[string]interface{}{"adScenes": []dto.AdSceneDto{}, "total": listTotal}},
+	"GET /ad/scene/:adSceneID":   {Response: map[string]interface{}{"adScene": dto.AdSceneDto{}}},
+	"POST /ad/scene":             {Request: vo.CreateAdSceneRequest{}},
+	"PATCH /ad/scene/:adSceneID": {Request: vo.UpdateAdSceneRequest{}},
+	"DELETE /ad/scene":           {Request: vo.DeleteAdScenesRequest{}},
+
+	// 广告位
+	"GET /ad":         {Request: vo.AdListRequest{}, Response: map[string]interface{}{"ads": []dto.AdDto{}, "total": listTotal}},
+	"GET /ad/:adID":   {Response: map[string]interface{}{"ad": dto.AdDto{}}},
+	"POST /ad":        {Request: vo.CreateAdRequest{}},
+	"PATCH /ad/:adID": {Request: vo.UpdateAdRequest{}},
+	"DELETE /ad":      {Request: vo.DeleteAdsRequest{}},
+
+	// 评论
+	"GET /comment":              {Request: vo.CommentListRequest{}, Response: map[string]interface{}{"comments": []dto.CommentDto{}, "total": listTotal}},
+	"PATCH /comment/:commentID": {},
+
+	// 积分
+	"GET /point":  {Request: vo.PointLogListRequest{}, Response: map[string]interface{}{"points": []dto.PointDto{}, "total": listTotal}},
+	"POST /point": {Request: vo.CreatePointLogRequest{}},
+
+	// 商品分类
+	"GET /product/category":                      {Response: map[string]interface{}{"productCategorys": []*model.ProductCategory{}}},
+	"GET /product/category/tree":                 {Response: map[string]interface{}{"productCategoryTree": []*model.ProductCategory{}}},
+	"GET /product/category/:productCategoryID":   {Response: map[string]interface{}{"productCategory": model.ProductCategory{}}},
+	"POST /product/category":                     {Request: vo.CreateProductCategoryRequest{}},
+	"PATCH /product/category/:productCategoryID": {Request: vo.UpdateProductCategoryRequest{}},
+	"DELETE /product/category":                   {Request: vo.DeleteProductCategoryRequest{}},
+
+	// 商品类型
+	"GET /product/type":                  {Request: vo.ProductTypeListRequest{}, Response: map[string]interface{}{"productTypes": []dto.ProductTypeDto{}, "total": listTotal}},
+	"GET /product/type/:productTypeID":   {Response: map[string]interface{}{"productType": dto.ProductTypeDto{}}},
+	"POST /product/type":                 {Request: vo.CreateProductTypeRequest{}, Response: map[string]interface{}{"productType": dto.ProductTypeDto{}}},
+	"PATCH /product/type/:productTypeID": {Request: vo.CreateProductTypeRequest{}},
+	"DELETE /product/type":               {Request: vo.DeleteProductTypesRequest{}},
+
+	// 商品规格
+	"GET /product/spec":                  {Request: vo.ProductSpecListRequest{}, Response: map[string]interface{}{"productSpecs": []dto.ProductSpecDto{}, "total": listTotal}},
+	"GET /product/spec/:productSpecID":   {Response: map[string]interface{}{"productSpec": dto.ProductSpecDto{}}},
+	"GET /product/spec/info/:categoryID": {Response: map[string]interface{}{"productSpecAndItem": []dto.ProductSpecDto{}}},
+	"POST /product/spec":                 {Request: vo.CreateProductSpecRequest{}, Response: map[string]interface{}{"productSpec": dto.ProductSpecDto{}}},
+	"PATCH /product/spec/:productSpecID": {Request: vo.CreateProductSpecRequest{}},
+	"DELETE /product/spec":               {Request: vo.DeleteProductSpecRequest{}},
+
+	// 商品规格项
+	"GET /product/spec/item":                      {Request: vo.ProductSpecItemListRequest{}, Response: map[string]interface{}{"productSpecItems": []dto.ProductSpecItemDto{}, "total": listTotal}},
+	"GET /product/spec/item/:productSpecItemID":   {Response: map[string]interface{}{"productSpecItem": dto.ProductSpecItemDto{}}},
+	"POST /product/spec/item":                     {Request: vo.CreateProductSpecItemRequest{}, Response: map[string]interface{}{"productSpecItem": dto.ProductSpecItemDto{}}},
+	"PATCH /product/spec/item/:productSpecItemID": {Request: vo.CreateProductSpecItemRequest{}},
+	"DELETE /product/spec/item":                   {Request: vo.DeleteProductSpecItemsRequest{}},
+
+	// 商品
+	"GET /product":              {Request: vo.ProductListRequest{}, Response: map[string]interface{}{"products": []dto.ProductDto{}, "total": listTotal}},
+	"GET /product/:productID":   {Response: map[string]interface{}{"product": dto.ProductDto{}}},
+	"POST /product":             {Request: vo.CreateProductRequest{}, Response: map[string]interface{}{"product": dto.ProductDto{}}},
+	"PATCH /product/:productID": {Request: vo.CreateProductRequest{}},
+	"DELETE /product":           {Request: vo.DeleteProductsRequest{}},
+
+	// 订单
+	"GET /order":                      {Request: vo.OrderListRequest{}, Response: map[string]interface{}{"orders": []dto.OrderDto{}, "total": listTotal}},
+	"GET /order/:orderID":             {Response: map[string]interface{}{"order": dto.OrderDto{}}},
+	"GET /order/log/:orderID":         {Response: map[string]interface{}{"orderLogs": []dto.OrderLogDto{}, "total": listTotal}},
+	"PATCH /order/:orderID":           {Request: vo.CreateOrderRequest{}},
+	"PATCH /order/logistics/:orderID": {Request: vo.CreateOrderLogisticsRequest{}},
+	"DELETE /order":                   {Request: vo.DeleteOrdersRequest{}},
+
+	// 系统配置
+	"PATCH /system": {Request: vo.CreateSystemConfigRequest{}},
+
+	// 反馈
+	"GET /feedback": {Request: vo.FeedbackListRequest{}, Response: map[string]interface{}{"feedbacks": []dto.FeedbackDto{}, "total": listTotal}},
+
+	// 首页
+	"GET /index":      {Request: indexQuery{}, Response: map[string]interface{}{"indexDate": map[string]interface{}{}}},
+	"GET /index/data": {Request: indexDataQuery{}, Response: map[string]interface{}{"timeRangeData": map[string][]map[string]interface{}{}}},
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files/v2"
+	"gotribe-admin/config"
+	"gotribe-admin/internal/app"
+	"gotribe-admin/internal/pkg/openapi"
+	"gotribe-admin/pkg/api/response"
+)
+
+// 文档中的服务信息
+const (
+	openApiTitle   = "gotribe-admin"
+	openApiVersion = "1.0.0"
+)
+
+// Swagger UI的初始化脚本, 替换内置的示例文档地址
+const swaggerInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: %q,
+    dom_id: '#swagger-ui',
+    deepLinking: true,
+    persistAuthorization: true,
+    presets: [
+      SwaggerUIBundle.presets.apis,
+      SwaggerUIStandalonePreset
+    ],
+    plugins: [
+      SwaggerUIBundle.plugins.DownloadUrl
+    ],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+// 注册OpenAPI文档与Swagger UI路由, 不需要jwt认证中间件,不需要casbin中间件
+// 文档在第一次请求时根据已注册的全部路由生成
+func InitOpenApiRoutes(r *gin.Engine, c *app.Container) gin.IRoutes {
+	prefix := "/" + config.Conf.System.UrlPathPrefix
+	var (
+		once sync.Once
+		doc  *openapi.Document
+		err  error
+	)
+	router := r.Group(prefix)
+	router.GET("/openapi.json", func(ctx *gin.Context) {
+		once.Do(func() {
+			doc, err = BuildOpenApi(r)
+		})
+		if err != nil {
+			c.Log.Errorf("生成OpenAPI文档失败: %v", err)
+			response.Response(ctx, http.StatusInternalServerError, http.StatusInternalServerError, nil, "生成OpenAPI文档失败")
+			return
+		}
+		ctx.JSON(http.StatusOK, doc)
+	})
+	initializer := fmt.Sprintf(swaggerInitializer, prefix+"/openapi.json")
+	router.GET("/docs/*filepath", func(ctx *gin.Context) {
+		file := ctx.Param("filepath")
+		if file == "/swagger-initializer.js" {
+			ctx.Data(http.StatusOK, "application/javascript; charset=utf-8", []byte(initializer))
+			return
+		}
+		ctx.FileFromFS(file, http.FS(swaggerFiles.FS))
+	})
+	return r
+}
+
+// 根据已注册的路由生成OpenAPI文档, 接口说明与分类取自接口初始数据, 请求与响应结构取自apiDocs
+func BuildOpenApi(r *gin.Engine) (*openapi.Document, error) {
+	apis, err := RegisteredApis(r)
+	if err != nil {
+		return nil, err
+	}
+	g := openapi.NewGenerator(openApiTitle, openApiVersion, "/"+config.Conf.System.UrlPathPrefix)
+	for _, api := range apis {
+		g.Add(api.Method, api.Path, api.Desc, api.Category, apiDocs[api.Method+" "+api.Path])
+	}
+	return g.Document(), nil
+}
@@ -8,17 +8,20 @@ package routes
 import (
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
+	"gotribe-admin/internal/app"
 	"gotribe-admin/internal/app/controller"
 	"gotribe-admin/internal/pkg/middleware"
 )
 
-func InitRoleRoutes(r *gin.RouterGroup, authMiddleware *jwt.GinJWTMiddleware) gin.IRoutes {
-	roleController := controller.NewRoleController()
+func InitRoleRoutes(r *gin.RouterGroup, authMiddleware *jwt.GinJWTMiddleware, c *app.Container) gin.IRoutes {
+	roleController := controller.NewRoleController(c.Repositories.Role, c.Repositories.Admin, c.Repositories.Menu, c.Repositories.Api, c.Enforcer)
 	router := r.Group("/role")
-	// 开启jwt认证中间件
-	router.Use(authMiddleware.MiddlewareFunc())
+	// 开启jwt认证中间件, 同时支持服务账号的API Key认证
+	router.Use(middleware.ApiKeyMiddleware(c.Repositories.ServiceAccount, authMiddleware.MiddlewareFunc()))
 	// 开启casbin鉴权中间件
-	router.Use(middleware.CasbinMiddleware())
+	router.Use(middleware.CasbinMiddleware(c.Repositories.Admin, c.Enforcer, c.Metrics))
+	// 开启项目隔离中间件
+	router.Use(middleware.ProjectScopeMiddleware(c.Repositories.Admin))
 	{
 		router.GET("/list", roleController.GetRoles)
 		router.POST("/create", roleController.CreateRole)
@@ -27,6 +30,11 @@ func InitRoleRoutes(r *gin.RouterGroup, authMiddleware *jwt.GinJWTMiddleware) gi
 		router.PATCH("/menus/update/:roleID", roleController.UpdateRoleMenusByID)
 		router.GET("/apis/get/:roleID", roleController.GetRoleApisByID)
 		router.PATCH("/apis/update/:roleID", roleController.UpdateRoleApisByID)
+		router.GET("/projects/get/:roleID", roleController.GetRoleProjectsByID)
+		router.PATCH("/projects/update/:roleID", roleController.UpdateRoleProjectsByID)
+		router.GET("/parents/get/:roleID", roleController.GetRoleParentsByID)
+		router.PATCH("/parents/update/:roleID", roleController.UpdateRoleParentsByID)
+		router.GET("/permissions/get/:roleID", roleController.GetRolePermissionsByID)
 		router.DELETE("/delete/batch", roleController.BatchDeleteRoleByIds)
 	}
 	return r
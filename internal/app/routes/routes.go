@@ -11,22 +11,46 @@ import (
 	"github.com/gin-contrib/static"
 	"github.com/gin-gonic/gin"
 	"gotribe-admin/config"
+	"gotribe-admin/internal/app"
 	"gotribe-admin/internal/pkg/common"
 	"gotribe-admin/internal/pkg/middleware"
+	"gotribe-admin/internal/pkg/oplog"
+	"gotribe-admin/pkg/api/response"
+	"gotribe-admin/pkg/util/upload"
 	"net/http"
-
+	"strings"
 	"time"
 )
 
-// 初始化
-func InitRoutes(fs embed.FS) *gin.Engine {
+// 初始化, 路由、控制器与中间件的依赖均来自c
+func InitRoutes(fs embed.FS, c *app.Container) *gin.Engine {
 	//设置模式
 	gin.SetMode(config.Conf.System.Mode)
 
-	// 创建带有默认中间件的路由:
-	// 日志与恢复中间件
-	r := gin.Default()
-	// 创建不带中间件的路由:
+	// 创建不带中间件的路由, 使用恢复中间件与基于zap的访问日志中间件
+	r := gin.New()
+	r.Use(gin.Recovery())
+
+	// 只信任配置的反向代理, 其余请求的X-Forwarded-For不作为客户端IP, 避免伪造IP
+	if err := r.SetTrustedProxies(config.Conf.System.TrustedProxies); err != nil {
+		c.Log.Panicf("设置可信代理失败：%v", err)
+		panic(fmt.Sprintf("设置可信代理失败：%v", err))
+	}
+
+	// 启用请求ID中间件, 在访问日志之前注册, 保证每条日志都带有请求ID
+	r.Use(middleware.RequestIDMiddleware())
+	r.Use(middleware.AccessLogMiddleware(c.Log))
+
+	// 启用监控指标中间件, 最先注册以便统计被限流等中间件拦截的请求
+	r.Use(middleware.MetricsMiddleware(c.Metrics))
+
+	// 监控指标接口, 不在url-path-prefix下, 在限流与操作日志中间件之前注册, 抓取请求不受其影响
+	if metricsConf := config.Conf.Metrics; metricsConf != nil && metricsConf.Enable {
+		r.GET(common.MetricsPath(), middleware.MetricsAuthMiddleware(metricsConf.Token, metricsConf.AllowIPs), gin.WrapH(c.Metrics.Handler()))
+	}
+
+	// 存活与就绪检查, 同样在限流与操作日志中间件之前注册, 探针请求不会被限流或记录
+	InitHealthRoutes(r, c)
 
 	// 启用限流中间件
 	// 默认每50毫秒填充一个令牌，最多填充200个
@@ -34,22 +58,34 @@ func InitRoutes(fs embed.FS) *gin.Engine {
 	capacity := config.Conf.RateLimit.Capacity
 	r.Use(middleware.RateLimitMiddleware(time.Millisecond*fillInterval, capacity))
 
+	// 启用数据库查询超时中间件, 需在操作日志中间件之前注册, 保证记录日志时查询仍可用
+	r.Use(middleware.QueryTimeoutMiddleware(common.DBQueryTimeout()))
+
 	// 启用全局跨域中间件
 	r.Use(middleware.CORSMiddleware())
 
 	// 启用操作日志中间件
-	r.Use(middleware.OperationLogMiddleware())
+	r.Use(middleware.OperationLogMiddleware(c.Repositories.Api, c.OperationLogs, oplog.NewCapture(common.OperationLogCaptureOptions())))
 
 	// 初始化JWT认证中间件
-	authMiddleware, err := middleware.InitAuth()
+	authMiddleware, err := middleware.InitAuth(c.Repositories.Admin, c.LoginGuard, c.Log)
 	if err != nil {
-		common.Log.Panicf("初始化JWT中间件失败：%v", err)
+		c.Log.Panicf("初始化JWT中间件失败：%v", err)
 		panic(fmt.Sprintf("初始化JWT中间件失败：%v", err))
 	}
 	r.Use(static.Serve("/", static.EmbedFolder(fs, "web/admin/dist")))
-	r.NoRoute(func(c *gin.Context) {
-		common.Log.Infof("A 404 error occurred, but the specific URL path is not logged to prevent log injection.")
-		c.Redirect(http.StatusMovedPermanently, "/")
+	// 本地存储时通过静态路由访问上传的文件
+	if common.UploadDriver() == upload.DriverLocal {
+		r.Static(common.LocalUploadRoute(), common.LocalUploadPath())
+	}
+	r.NoRoute(func(ctx *gin.Context) {
+		common.LogWithContext(ctx.Request.Context(), c.Log).Infof("A 404 error occurred, but the specific URL path is not logged to prevent log injection.")
+		// 接口与探针路径返回404, 避免跳转到首页后被调用方或探针误判为成功
+		if isNotFoundPath(ctx.Request.URL.Path) {
+			response.Response(ctx, http.StatusNotFound, http.StatusNotFound, nil, "接口不存在")
+			return
+		}
+		ctx.Redirect(http.StatusMovedPermanently, "/")
 	})
 	// end
 
@@ -57,33 +93,51 @@ func InitRoutes(fs embed.FS) *gin.Engine {
 	apiGroup := r.Group("/" + config.Conf.System.UrlPathPrefix)
 
 	// 注册路由
-	InitBaseRoutes(apiGroup, authMiddleware)            // 注册基础路由, 不需要jwt认证中间件,不需要casbin中间件
-	InitAdminRoutes(apiGroup, authMiddleware)           // 注册用户路由, jwt认证中间件,casbin鉴权中间件
-	InitRoleRoutes(apiGroup, authMiddleware)            // 注册角色路由, jwt认证中间件,casbin鉴权中间件
-	InitMenuRoutes(apiGroup, authMiddleware)            // 注册菜单路由, jwt认证中间件,casbin鉴权中间件
-	InitApiRoutes(apiGroup, authMiddleware)             // 注册接口路由, jwt认证中间件,casbin鉴权中间件
-	InitOperationLogRoutes(apiGroup, authMiddleware)    // 注册操作日志路由, jwt认证中间件,casbin鉴权中间件
-	InitProjectRoutes(apiGroup, authMiddleware)         // 注册项目管理路由, jwt认证中间件,casbin鉴权中间件
-	InitConfigRoutes(apiGroup, authMiddleware)          // 注册配置管理路由, jwt认证中间件,casbin鉴权中间件
-	InitTagRoutes(apiGroup, authMiddleware)             // 注册标签管理路由, jwt认证中间件,casbin鉴权中间件
-	InitCategoryRoutes(apiGroup, authMiddleware)        // 注册分类管理路由, jwt认证中间件,casbin鉴权中间件
-	InitPostRoutes(apiGroup, authMiddleware)            // 注册内容管理路由, jwt认证中间件,casbin鉴权中间件
-	InitUserRoutes(apiGroup, authMiddleware)            // 注册用户管理路由, jwt认证中间件,casbin鉴权中间件
-	InitResourceRoutes(apiGroup, authMiddleware)        // 注册资源管理路由, jwt认证中间件,casbin鉴权中间件
-	InitColumnRoutes(apiGroup, authMiddleware)          // 注册专栏管理路由, jwt认证中间件,casbin鉴权中间件
-	InitAdSceneRoutes(apiGroup, authMiddleware)         // 注册推广场景管理路由, jwt认证中间件,casbin鉴权中间件
-	InitAdRoutes(apiGroup, authMiddleware)              // 注册广告位管理路由, jwt认证中间件,casbin鉴权中间件
-	InitCommentRoutes(apiGroup, authMiddleware)         // 注册评论管理路由, jwt认证中间件,casbin鉴权中间件
-	InitPointRoutes(apiGroup, authMiddleware)           // 注册积分管理路由, jwt认证中间件,casbin鉴权中间件
-	InitProductCategoryRoutes(apiGroup, authMiddleware) // 注册商品分类管理路由, jwt认证中间件,casbin鉴权中间件
-	InitProductTypeRoutes(apiGroup, authMiddleware)     // 注册商品类型管理路由, jwt认证中间件,casbin鉴权中间件
-	InitProductSpecRoutes(apiGroup, authMiddleware)     // 注册商品规格管理路由, jwt认证中间件,casbin鉴权中间件
-	InitProductSpecItemRoutes(apiGroup, authMiddleware) // 注册商品规格项管理路由, jwt认证中间件,casbin鉴权中间件
-	InitProductRoutes(apiGroup, authMiddleware)         // 注册商品管理路由, jwt认证中间件,casbin鉴权中间件
-	InitOrderRoutes(apiGroup, authMiddleware)           // 注册订单管理路由, jwt认证中间件,casbin鉴权中间件
-	InitSystemConfigRoutes(apiGroup, authMiddleware)    // 注册系统配置管理路由, jwt认证中间件,casbin鉴权中间件
-	InitFeedbackRoutes(apiGroup, authMiddleware)        // 注册反馈管理路由, jwt认证中间件,casbin鉴权中间件
-	InitIndexRoutes(apiGroup, authMiddleware)           // 注册首页数据路由, jwt认证中间件,casbin鉴权中间件
-	common.Log.Info("初始化路由完成！")
+	InitBaseRoutes(apiGroup, authMiddleware, c)            // 注册基础路由, 不需要jwt认证中间件,不需要casbin中间件
+	InitOpenApiRoutes(r, c)                                // 注册OpenAPI文档路由, 不需要jwt认证中间件,不需要casbin中间件
+	InitAdminRoutes(apiGroup, authMiddleware, c)           // 注册用户路由, jwt认证中间件,casbin鉴权中间件
+	InitRoleRoutes(apiGroup, authMiddleware, c)            // 注册角色路由, jwt认证中间件,casbin鉴权中间件
+	InitMenuRoutes(apiGroup, authMiddleware, c)            // 注册菜单路由, jwt认证中间件,casbin鉴权中间件
+	InitApiRoutes(apiGroup, authMiddleware, c)             // 注册接口路由, jwt认证中间件,casbin鉴权中间件
+	InitPermissionRoutes(apiGroup, authMiddleware, c)      // 注册权限诊断路由, jwt认证中间件,casbin鉴权中间件
+	InitServiceAccountRoutes(apiGroup, authMiddleware, c)  // 注册服务账号路由, jwt认证中间件,casbin鉴权中间件
+	InitOperationLogRoutes(apiGroup, authMiddleware, c)    // 注册操作日志路由, jwt认证中间件,casbin鉴权中间件
+	InitLoginLogRoutes(apiGroup, authMiddleware, c)        // 注册登录日志路由, jwt认证中间件,casbin鉴权中间件
+	InitProjectRoutes(apiGroup, authMiddleware, c)         // 注册项目管理路由, jwt认证中间件,casbin鉴权中间件
+	InitConfigRoutes(apiGroup, authMiddleware, c)          // 注册配置管理路由, jwt认证中间件,casbin鉴权中间件
+	InitTagRoutes(apiGroup, authMiddleware, c)             // 注册标签管理路由, jwt认证中间件,casbin鉴权中间件
+	InitCategoryRoutes(apiGroup, authMiddleware, c)        // 注册分类管理路由, jwt认证中间件,casbin鉴权中间件
+	InitPostRoutes(apiGroup, authMiddleware, c)            // 注册内容管理路由, jwt认证中间件,casbin鉴权中间件
+	InitUserRoutes(apiGroup, authMiddleware, c)            // 注册用户管理路由, jwt认证中间件,casbin鉴权中间件
+	InitResourceRoutes(apiGroup, authMiddleware, c)        // 注册资源管理路由, jwt认证中间件,casbin鉴权中间件
+	InitColumnRoutes(apiGroup, authMiddleware, c)          // 注册专栏管理路由, jwt认证中间件,casbin鉴权中间件
+	InitAdSceneRoutes(apiGroup, authMiddleware, c)         // 注册推广场景管理路由, jwt认证中间件,casbin鉴权中间件
+	InitAdRoutes(apiGroup, authMiddleware, c)              // 注册广告位管理路由, jwt认证中间件,casbin鉴权中间件
+	InitCommentRoutes(apiGroup, authMiddleware, c)         // 注册评论管理路由, jwt认证中间件,casbin鉴权中间件
+	InitPointRoutes(apiGroup, authMiddleware, c)           // 注册积分管理路由, jwt认证中间件,casbin鉴权中间件
+	InitProductCategoryRoutes(apiGroup, authMiddleware, c) // 注册商品分类管理路由, jwt认证中间件,casbin鉴权中间件
+	InitProductTypeRoutes(apiGroup, authMiddleware, c)     // 注册商品类型管理路由, jwt认证中间件,casbin鉴权中间件
+	InitProductSpecRoutes(apiGroup, authMiddleware, c)     // 注册商品规格管理路由, jwt认证中间件,casbin鉴权中间件
+	InitProductSpecItemRoutes(apiGroup, authMiddleware, c) // 注册商品规格项管理路由, jwt认证中间件,casbin鉴权中间件
+	InitProductRoutes(apiGroup, authMiddleware, c)         // 注册商品管理路由, jwt认证中间件,casbin鉴权中间件
+	InitOrderRoutes(apiGroup, authMiddleware, c)           // 注册订单管理路由, jwt认证中间件,casbin鉴权中间件
+	InitSystemConfigRoutes(apiGroup, authMiddleware, c)    // 注册系统配置管理路由, jwt认证中间件,casbin鉴权中间件
+	InitFeedbackRoutes(apiGroup, authMiddleware, c)        // 注册反馈管理路由, jwt认证中间件,casbin鉴权中间件
+	InitIndexRoutes(apiGroup, authMiddleware, c)           // 注册首页数据路由, jwt认证中间件,casbin鉴权中间件
+	c.Log.Info("初始化路由完成！")
 	return r
 }
+
+// 未匹配时返回404的路径: url-path-prefix下的接口, 以及存活、就绪检查与监控指标的相近路径
+func isNotFoundPath(path string) bool {
+	prefix := "/" + strings.Trim(config.Conf.System.UrlPathPrefix, "/")
+	if prefix != "/" && (path == prefix || strings.HasPrefix(path, prefix+"/")) {
+		return true
+	}
+	for _, probe := range []string{"/health", "/ready", "/readiness", "/live", common.MetricsPath()} {
+		if strings.HasPrefix(strings.ToLower(path), probe) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package routes
+
+import (
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+	"gotribe-admin/internal/app"
+	"gotribe-admin/internal/app/controller"
+	"gotribe-admin/internal/pkg/middleware"
+)
+
+// 注册服务账号路由
+func InitServiceAccountRoutes(r *gin.RouterGroup, authMiddleware *jwt.GinJWTMiddleware, c *app.Container) gin.IRoutes {
+	serviceAccountController := controller.NewServiceAccountController(c.Repositories.ServiceAccount, c.Repositories.Admin, c.Repositories.Role)
+	router := r.Group("/serviceAccount")
+	// 开启jwt认证中间件, 同时支持服务账号的API Key认证
+	router.Use(middleware.ApiKeyMiddleware(c.Repositories.ServiceAccount, authMiddleware.MiddlewareFunc()))
+	// 开启casbin鉴权中间件
+	router.Use(middleware.CasbinMiddleware(c.Repositories.Admin, c.Enforcer, c.Metrics))
+	// 开启项目隔离中间件
+	router.Use(middleware.ProjectScopeMiddleware(c.Repositories.Admin))
+	{
+		router.GET("/list", serviceAccountController.GetServiceAccounts)
+		router.POST("/create", serviceAccountController.CreateServiceAccount)
+		router.PATCH("/update/:serviceAccountID", serviceAccountController.UpdateServiceAccountByID)
+		router.DELETE("/delete/batch", serviceAccountController.BatchDeleteServiceAccountByIds)
+		// 响应中包含API Key明文, 不记录请求体与响应体
+		router.POST("/key/create/:serviceAccountID", middleware.SkipBodyCaptureMiddleware(), serviceAccountController.CreateApiKey)
+		router.PATCH("/key/revoke/:apiKeyID", serviceAccountController.RevokeApiKeyByID)
+	}
+	return r
+}
@@ -0,0 +1,121 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+// Package apikey 服务账号API Key的生成、解析与校验
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// API Key格式为 gt_<12位十六进制前缀>_<随机密钥>, 前缀用于查找与识别, 整体的哈希用于校验
+const (
+	scheme    = "gt_"
+	prefixLen = len(scheme) + 12
+)
+
+// 请求头, 也可以使用 Authorization: ApiKey <key>
+const (
+	Header        = "X-Api-Key"
+	authorization = "ApiKey "
+)
+
+// 生成API Key, 返回明文、前缀与哈希, 明文只在创建时返回给调用方
+func Generate() (key string, prefix string, hash string, err error) {
+	id := make([]byte, (prefixLen-len(scheme))/2)
+	if _, err = rand.Read(id); err != nil {
+		return "", "", "", err
+	}
+	secret := make([]byte, 32)
+	if _, err = rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+	prefix = scheme + hex.EncodeToString(id)
+	key = prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	return key, prefix, Hash(key), nil
+}
+
+// 明文的sha256哈希, API Key为高强度随机值, 不需要加盐与慢哈希
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// 校验明文与哈希是否一致
+func Verify(key string, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(Hash(key)), []byte(hash)) == 1
+}
+
+// 明文中的前缀, 格式不正确时返回false
+func Prefix(key string) (string, bool) {
+	if !strings.HasPrefix(key, scheme) || len(key) <= prefixLen+1 || key[prefixLen] != '_' {
+		return "", false
+	}
+	return key[:prefixLen], true
+}
+
+// 请求中携带的API Key, 未携带时为空
+func FromRequest(c *gin.Context) string {
+	if key := c.GetHeader(Header); key != "" {
+		return strings.TrimSpace(key)
+	}
+	if auth := c.GetHeader("Authorization"); strings.HasPrefix(auth, authorization) {
+		return strings.TrimSpace(strings.TrimPrefix(auth, authorization))
+	}
+	return ""
+}
+
+// 解析IP白名单, 单个IP视为/32或/128的网段
+func ParseAllowIPs(allowIPs []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, allow := range allowIPs {
+		allow = strings.TrimSpace(allow)
+		if allow == "" {
+			continue
+		}
+		if !strings.Contains(allow, "/") {
+			if strings.Contains(allow, ":") {
+				allow += "/128"
+			} else {
+				allow += "/32"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(allow)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// IP是否在白名单内, 白名单为空时不限制
+func IPAllowed(allowIPs []string, ip string) bool {
+	nets, err := ParseAllowIPs(allowIPs)
+	if err != nil {
+		return false
+	}
+	if len(nets) == 0 {
+		return true
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, ipNet := range nets {
+		if ipNet.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
@@ -8,34 +8,62 @@ package common
 import (
 	"fmt"
 	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/persist/cache"
 	gormadapter "github.com/casbin/gorm-adapter/v3"
+	"gorm.io/gorm"
 	"gotribe-admin/config"
+	"time"
 )
 
-// 全局CasbinEnforcer
-var CasbinEnforcer *casbin.Enforcer
+// 默认轮询策略版本的间隔
+const defaultCasbinWatchInterval = 5 * time.Second
+
+// 校验结果缓存的有效期, 重新加载策略时与之并发的校验写入的旧结果最迟在过期后失效
+const casbinDecisionExpire = time.Minute
 
 // 初始化casbin策略管理器
-func InitCasbinEnforcer() {
-	e, err := mysqlCasbin()
+// 使用带决策缓存的并发安全enforcer, 并通过数据库版本号在多个实例间同步策略
+func InitCasbinEnforcer(db *gorm.DB) *casbin.SyncedCachedEnforcer {
+	e, err := mysqlCasbin(db)
 	if err != nil {
 		Log.Panicf("初始化Casbin失败：%v", err)
 		panic(fmt.Sprintf("初始化Casbin失败：%v", err))
 	}
 
-	CasbinEnforcer = e
+	if interval := CasbinWatchInterval(); interval > 0 {
+		if err := watchCasbin(db, e, interval); err != nil {
+			Log.Errorf("开启Casbin策略同步失败, 其他实例修改的权限需重启后生效: %v", err)
+		}
+	}
+
 	Log.Info("初始化Casbin完成!")
+	return e
 }
 
-func mysqlCasbin() (*casbin.Enforcer, error) {
-	a, err := gormadapter.NewAdapterByDB(DB)
+// 轮询策略版本的间隔, 小于等于0时不同步
+func CasbinWatchInterval() time.Duration {
+	conf := config.Conf.Casbin
+	if conf == nil || conf.WatchInterval == 0 {
+		return defaultCasbinWatchInterval
+	}
+	return time.Duration(conf.WatchInterval) * time.Second
+}
+
+func mysqlCasbin(db *gorm.DB) (*casbin.SyncedCachedEnforcer, error) {
+	a, err := gormadapter.NewAdapterByDB(db)
+	if err != nil {
+		return nil, err
+	}
+	e, err := casbin.NewSyncedCachedEnforcer(config.Conf.Casbin.ModelPath, a)
 	if err != nil {
 		return nil, err
 	}
-	e, err := casbin.NewEnforcer(config.Conf.Casbin.ModelPath, a)
+	decisions, err := cache.NewSyncCache()
 	if err != nil {
 		return nil, err
 	}
+	e.SetCache(decisionCache{decisions})
+	e.SetExpireTime(casbinDecisionExpire)
 
 	err = e.LoadPolicy()
 	if err != nil {
@@ -43,3 +71,36 @@ func mysqlCasbin() (*casbin.Enforcer, error) {
 	}
 	return e, nil
 }
+
+// 开启策略同步, 本实例修改策略时自动通知其他实例
+func watchCasbin(db *gorm.DB, e *casbin.SyncedCachedEnforcer, interval time.Duration) error {
+	w, err := NewCasbinWatcher(db, interval)
+	if err != nil {
+		return err
+	}
+	if err := e.SetWatcher(w); err != nil {
+		w.Close()
+		return err
+	}
+	// 默认回调绕过了SyncedCachedEnforcer的锁与决策缓存, 需要重新设置
+	// SyncedCachedEnforcer.LoadPolicy在加锁加载前清空缓存, 期间的校验会把旧结果写回缓存, 因此加载完成后再清空
+	return w.SetUpdateCallback(func(string) {
+		if err := e.SyncedEnforcer.LoadPolicy(); err != nil {
+			Log.Errorf("重新加载Casbin策略失败: %v", err)
+			return
+		}
+		if err := e.InvalidateCache(); err != nil {
+			Log.Errorf("清空Casbin校验结果缓存失败: %v", err)
+		}
+	})
+}
+
+// 校验结果缓存
+// 角色存在继承关系时一条策略会影响所有子角色的校验结果, 策略变更时清空全部缓存而不是只删除对应的一条
+type decisionCache struct {
+	cache.Cache
+}
+
+func (c decisionCache) Delete(string) error {
+	return c.Clear()
+}
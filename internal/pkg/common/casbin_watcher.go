@@ -0,0 +1,125 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package common
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gotribe-admin/internal/pkg/model"
+)
+
+// 策略版本记录的ID, 全局只有一条
+const casbinVersionID = 1
+
+// 基于数据库版本号的casbin策略同步器, 实现persist.Watcher
+// 本实例修改策略时递增版本号, 轮询发现其他实例修改了版本号时回调重新加载策略
+type CasbinWatcher struct {
+	db       *gorm.DB
+	interval time.Duration
+	// 本实例已加载的策略版本
+	version atomic.Uint64
+
+	mu       sync.Mutex
+	callback func(string)
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// CasbinWatcher构造函数, 以当前版本为起点开始轮询
+func NewCasbinWatcher(db *gorm.DB, interval time.Duration) (*CasbinWatcher, error) {
+	w := &CasbinWatcher{
+		db:       db,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	row := model.CasbinVersion{ID: casbinVersionID}
+	if err := db.Where(model.CasbinVersion{ID: casbinVersionID}).FirstOrCreate(&row).Error; err != nil {
+		return nil, err
+	}
+	w.version.Store(row.Version)
+	go w.run()
+	return w, nil
+}
+
+// 设置策略变更时的回调
+func (w *CasbinWatcher) SetUpdateCallback(callback func(string)) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.callback = callback
+	return nil
+}
+
+// 本实例修改策略后递增版本号, 通知其他实例
+// 在enforcer持有锁时调用, 不能同步回调重新加载
+func (w *CasbinWatcher) Update() error {
+	var version uint64
+	err := w.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.CasbinVersion{}).Where("id = ?", casbinVersionID).Updates(map[string]interface{}{
+			"version":    gorm.Expr("version + 1"),
+			"updated_at": time.Now(),
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&model.CasbinVersion{}).Where("id = ?", casbinVersionID).Pluck("version", &version).Error
+	})
+	if err != nil {
+		return err
+	}
+	// 期间只有本实例的修改时本地策略已是最新, 否则等待下次轮询重新加载
+	w.version.CompareAndSwap(version-1, version)
+	return nil
+}
+
+// 停止轮询
+func (w *CasbinWatcher) Close() {
+	w.once.Do(func() {
+		close(w.stop)
+		<-w.done
+	})
+}
+
+func (w *CasbinWatcher) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.poll()
+		}
+	}
+}
+
+// 版本号变化时回调重新加载策略
+func (w *CasbinWatcher) poll() {
+	var version uint64
+	err := w.db.Model(&model.CasbinVersion{}).Where("id = ?", casbinVersionID).Pluck("version", &version).Error
+	if err != nil {
+		Log.Warnf("查询casbin策略版本失败: %v", err)
+		return
+	}
+	current := w.version.Load()
+	if version == current || !w.version.CompareAndSwap(current, version) {
+		return
+	}
+	w.mu.Lock()
+	callback := w.callback
+	w.mu.Unlock()
+	if callback != nil {
+		Log.Infof("casbin策略版本由%d变为%d, 重新加载策略", current, version)
+		callback(strconv.FormatUint(version, 10))
+	}
+}
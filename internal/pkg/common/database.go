@@ -7,7 +7,6 @@ package common
 
 import (
 	"fmt"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	"gorm.io/gorm/schema"
@@ -18,35 +17,17 @@ import (
 	"time"
 )
 
-// 全局mysql数据库变量
-var DB *gorm.DB
-
-// 初始化mysql数据库
-func InitMysql() {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&collation=%s&%s",
-		config.Conf.Mysql.Username,
-		config.Conf.Mysql.Password,
-		config.Conf.Mysql.Host,
-		config.Conf.Mysql.Port,
-		config.Conf.Mysql.Database,
-		config.Conf.Mysql.Charset,
-		config.Conf.Mysql.Collation,
-		config.Conf.Mysql.Query,
-	)
-	// 隐藏密码
-	showDsn := fmt.Sprintf(
-		"%s:******@tcp(%s:%d)/%s?charset=%s&collation=%s&%s",
-		config.Conf.Mysql.Username,
-		config.Conf.Mysql.Host,
-		config.Conf.Mysql.Port,
-		config.Conf.Mysql.Database,
-		config.Conf.Mysql.Charset,
-		config.Conf.Mysql.Collation,
-		config.Conf.Mysql.Query,
-	)
+// 初始化数据库, 按database.driver选择mysql/postgres/sqlite
+func InitDB() *gorm.DB {
+	driver := DBDriver()
+	dialector, showDsn, logMode, err := newDialector(driver)
+	if err != nil {
+		Log.Panicf("初始化数据库异常: %v", err)
+		panic(fmt.Errorf("初始化数据库异常: %v", err))
+	}
 
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		// 禁用外键(指定外键时不会在mysql创建真实的外键约束)
+	db, err := gorm.Open(dialector, &gorm.Config{
+		// 禁用外键(指定外键时不会在数据库创建真实的外键约束)
 		DisableForeignKeyConstraintWhenMigrating: true,
 		// 使用单数表名
 		NamingStrategy: schema.NamingStrategy{
@@ -54,12 +35,18 @@ func InitMysql() {
 		},
 	})
 	if err != nil {
-		Log.Panicf("初始化mysql数据库异常: %v", err)
-		panic(fmt.Errorf("初始化mysql数据库异常: %v", err))
+		Log.Panicf("初始化%s数据库异常: %v", driver, err)
+		panic(fmt.Errorf("初始化%s数据库异常: %v", driver, err))
+	}
+
+	// 按管理员可访问的项目隔离数据
+	if err := RegisterProjectScope(db); err != nil {
+		Log.Panicf("注册项目数据隔离回调失败: %v", err)
+		panic(fmt.Errorf("注册项目数据隔离回调失败: %v", err))
 	}
 
-	// 开启mysql日志
-	if config.Conf.Mysql.LogMode {
+	// 开启数据库日志
+	if logMode {
 		newLogger := logger.New(
 			log.New(os.Stdout, "\r\n", log.LstdFlags),
 			logger.Config{
@@ -71,11 +58,21 @@ func InitMysql() {
 		db.Debug()
 		db.Logger = newLogger
 	}
-	// 全局DB赋值
-	DB = db
-	// 自动迁移表结构
-	if config.Conf.System.EnableMigrate {
-		migrate.DBAutoMigrate(DB)
-		Log.Infof("mysql数据库迁移完成! dsn: %s", showDsn)
+	Log.Infof("初始化%s数据库完成! dsn: %s", driver, showDsn)
+	return db
+}
+
+// 开启enable-migrate时, 启动时自动执行未应用的数据库迁移
+func AutoMigrate(db *gorm.DB) {
+	if !config.Conf.System.EnableMigrate {
+		return
+	}
+	done, err := migrate.Up(db)
+	if err != nil {
+		Log.Panicf("数据库迁移失败: %v", err)
+		panic(fmt.Errorf("数据库迁移失败: %v", err))
+	}
+	for _, m := range done {
+		Log.Infof("数据库迁移%d(%s)完成!", m.Version, m.Description)
 	}
 }
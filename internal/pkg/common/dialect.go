@@ -0,0 +1,200 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/migrator"
+	"gorm.io/gorm/schema"
+	"gotribe-admin/config"
+)
+
+// 支持的数据库驱动
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// 当前配置的数据库驱动, 未配置时默认mysql
+func DBDriver() string {
+	if config.Conf.Database == nil || config.Conf.Database.Driver == "" {
+		return DriverMySQL
+	}
+	return strings.ToLower(config.Conf.Database.Driver)
+}
+
+// 单个请求内数据库查询的超时时间, 未配置或不大于0时不限制
+func DBQueryTimeout() time.Duration {
+	if config.Conf.Database == nil || config.Conf.Database.QueryTimeout <= 0 {
+		return 0
+	}
+	return time.Duration(config.Conf.Database.QueryTimeout) * time.Second
+}
+
+// 按驱动生成gorm方言, 同时返回隐藏密码后的dsn与是否打印日志
+func newDialector(driver string) (gorm.Dialector, string, bool, error) {
+	switch driver {
+	case DriverMySQL:
+		conf := config.Conf.Mysql
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&collation=%s&%s",
+			conf.Username, conf.Password, conf.Host, conf.Port,
+			conf.Database, conf.Charset, conf.Collation, conf.Query,
+		)
+		// 隐藏密码
+		showDsn := fmt.Sprintf("%s:******@tcp(%s:%d)/%s?charset=%s&collation=%s&%s",
+			conf.Username, conf.Host, conf.Port,
+			conf.Database, conf.Charset, conf.Collation, conf.Query,
+		)
+		return mysql.Open(dsn), showDsn, conf.LogMode, nil
+	case DriverPostgres:
+		conf := config.Conf.Postgres
+		if conf == nil {
+			return nil, "", false, fmt.Errorf("缺少postgres配置")
+		}
+		sslMode := conf.SSLMode
+		if sslMode == "" {
+			sslMode = "disable"
+		}
+		dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+			conf.Host, conf.Port, conf.Username, conf.Password, conf.Database, sslMode)
+		showDsn := fmt.Sprintf("host=%s port=%d user=%s password=****** dbname=%s sslmode=%s",
+			conf.Host, conf.Port, conf.Username, conf.Database, sslMode)
+		if conf.TimeZone != "" {
+			dsn += " TimeZone=" + conf.TimeZone
+			showDsn += " TimeZone=" + conf.TimeZone
+		}
+		dialector := postgresDialector{postgres.Dialector{Config: &postgres.Config{DSN: dsn}}}
+		return dialector, showDsn, conf.LogMode, nil
+	case DriverSQLite:
+		conf := config.Conf.Sqlite
+		if conf == nil || conf.Path == "" {
+			return nil, "", false, fmt.Errorf("缺少sqlite数据库文件路径配置")
+		}
+		if !strings.HasPrefix(conf.Path, ":memory:") && !strings.HasPrefix(conf.Path, "file:") {
+			// 数据库文件所在目录不存在时自动创建
+			if err := os.MkdirAll(filepath.Dir(conf.Path), os.ModePerm); err != nil {
+				return nil, "", false, err
+			}
+		}
+		dsn := conf.Path
+		if conf.Query != "" {
+			dsn += "?" + conf.Query
+		}
+		return sqliteDialector{sqlite.Dialector{DSN: dsn}}, dsn, conf.LogMode, nil
+	default:
+		return nil, "", false, fmt.Errorf("不支持的数据库驱动: %s", driver)
+	}
+}
+
+// 按天分组的日期表达式, 结果格式为 2006-01-02
+func SQLDay(column string) string {
+	switch DBDriver() {
+	case DriverPostgres:
+		return fmt.Sprintf("TO_CHAR(%s, 'YYYY-MM-DD')", column)
+	case DriverSQLite:
+		return fmt.Sprintf("STRFTIME('%%Y-%%m-%%d', %s)", column)
+	default:
+		return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-%%d')", column)
+	}
+}
+
+// 按月分组的日期表达式, 结果格式为 2006-01
+func SQLMonth(column string) string {
+	switch DBDriver() {
+	case DriverPostgres:
+		return fmt.Sprintf("TO_CHAR(%s, 'YYYY-MM')", column)
+	case DriverSQLite:
+		return fmt.Sprintf("STRFTIME('%%Y-%%m', %s)", column)
+	default:
+		return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m')", column)
+	}
+}
+
+// 模型中的列类型按MySQL编写, 以下方言在迁移时将其转换为目标数据库支持的类型
+var (
+	unsignedRe = regexp.MustCompile(`(?i)\s+unsigned$`)
+	tinyintRe  = regexp.MustCompile(`(?i)^tinyint(\(\d+\))?$`)
+	intRe      = regexp.MustCompile(`(?i)^int(\(\d+\))?$`)
+	datetimeRe = regexp.MustCompile(`(?i)^datetime(\((\d+)\))?$`)
+	floatRe    = regexp.MustCompile(`(?i)^float\((\d+),\s*(\d+)\)$`)
+)
+
+// 将MySQL列类型转换为PostgreSQL列类型
+func postgresColumnType(sqlType string) string {
+	sqlType = unsignedRe.ReplaceAllString(strings.TrimSpace(sqlType), "")
+	switch {
+	case tinyintRe.MatchString(sqlType):
+		return "smallint"
+	case intRe.MatchString(sqlType):
+		return "integer"
+	case strings.EqualFold(sqlType, "longtext"):
+		return "text"
+	case datetimeRe.MatchString(sqlType):
+		if m := datetimeRe.FindStringSubmatch(sqlType); m[2] != "" {
+			return fmt.Sprintf("timestamptz(%s)", m[2])
+		}
+		return "timestamptz"
+	case floatRe.MatchString(sqlType):
+		m := floatRe.FindStringSubmatch(sqlType)
+		return fmt.Sprintf("numeric(%s,%s)", m[1], m[2])
+	}
+	return sqlType
+}
+
+// PostgreSQL方言, 仅覆盖列类型的生成
+type postgresDialector struct {
+	postgres.Dialector
+}
+
+func (d postgresDialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return postgres.Migrator{Migrator: migrator.Migrator{Config: migrator.Config{
+		DB:                          db,
+		Dialector:                   d,
+		CreateIndexAfterCreateTable: true,
+	}}}
+}
+
+func (d postgresDialector) DataTypeOf(field *schema.Field) string {
+	return postgresColumnType(d.Dialector.DataTypeOf(field))
+}
+
+// 将MySQL列类型转换为SQLite列类型
+// datetime(3)等带精度的类型不会被驱动识别为时间, 读取时无法扫描到time.Time
+func sqliteColumnType(sqlType string) string {
+	sqlType = unsignedRe.ReplaceAllString(strings.TrimSpace(sqlType), "")
+	if datetimeRe.MatchString(sqlType) {
+		return "datetime"
+	}
+	return sqlType
+}
+
+// SQLite方言, SQLite不支持unsigned修饰与带精度的datetime
+type sqliteDialector struct {
+	sqlite.Dialector
+}
+
+func (d sqliteDialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return sqlite.Migrator{Migrator: migrator.Migrator{Config: migrator.Config{
+		DB:                          db,
+		Dialector:                   d,
+		CreateIndexAfterCreateTable: true,
+	}}}
+}
+
+func (d sqliteDialector) DataTypeOf(field *schema.Field) string {
+	return sqliteColumnType(d.Dialector.DataTypeOf(field))
+}
@@ -0,0 +1,20 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package common
+
+import (
+	"gotribe-admin/config"
+	"gotribe-admin/internal/pkg/geoip"
+)
+
+// 按配置打开IP归属地数据库, 未配置时返回不查询的Resolver
+func NewGeoIPResolver() (geoip.Resolver, error) {
+	conf := config.Conf.GeoIP
+	if conf == nil {
+		return geoip.Nop(), nil
+	}
+	return geoip.New(conf.Driver, conf.Path, conf.Language)
+}
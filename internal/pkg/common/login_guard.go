@@ -0,0 +1,28 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package common
+
+import (
+	"time"
+
+	"gotribe-admin/config"
+	"gotribe-admin/internal/pkg/loginguard"
+)
+
+// 登录保护配置, 未配置时使用默认值; 时长单位为秒, 未配置的时长使用默认值
+func LoginGuardOptions() loginguard.Options {
+	conf := config.Conf.LoginGuard
+	if conf == nil {
+		return loginguard.DefaultOptions()
+	}
+	return loginguard.Options{
+		MaxAttempts:     conf.MaxAttempts,
+		IPMaxAttempts:   conf.IPMaxAttempts,
+		LockDuration:    time.Duration(conf.LockDuration) * time.Second,
+		MaxLockDuration: time.Duration(conf.MaxLockDuration) * time.Second,
+		Window:          time.Duration(conf.Window) * time.Second,
+	}
+}
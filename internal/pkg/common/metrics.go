@@ -0,0 +1,23 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package common
+
+import (
+	"gotribe-admin/config"
+	"strings"
+)
+
+// 监控指标接口路径, 未配置时默认/metrics
+func MetricsPath() string {
+	if config.Conf.Metrics == nil {
+		return "/metrics"
+	}
+	path := "/" + strings.Trim(config.Conf.Metrics.Path, "/")
+	if path == "/" {
+		return "/metrics"
+	}
+	return path
+}
@@ -0,0 +1,62 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package common
+
+import (
+	"gotribe-admin/config"
+	"gotribe-admin/internal/pkg/oplog"
+	"strings"
+	"time"
+)
+
+// 操作日志写入器配置, 未配置的项使用默认值
+func OperationLogOptions() oplog.Options {
+	conf := config.Conf.OperationLog
+	if conf == nil {
+		return oplog.Options{}
+	}
+	return oplog.Options{
+		BatchSize:     conf.BatchSize,
+		FlushInterval: time.Duration(conf.FlushInterval) * time.Millisecond,
+		QueueSize:     conf.QueueSize,
+		Overflow:      conf.Overflow,
+		SpillPath:     conf.SpillPath,
+	}
+}
+
+// 操作日志请求体与响应体记录配置, 未配置的项使用默认值
+func OperationLogCaptureOptions() oplog.CaptureOptions {
+	conf := config.Conf.OperationLog
+	if conf == nil {
+		return oplog.CaptureOptions{}
+	}
+	return oplog.CaptureOptions{
+		RequestBodyLimit:  conf.RequestBodyLimit,
+		ResponseBodyLimit: conf.ResponseBodyLimit,
+		MaskFields:        conf.MaskFields,
+	}
+}
+
+// 操作日志保留天数, 0为永久保留
+func OperationLogRetentionDays() int {
+	if config.Conf.OperationLog == nil {
+		return 0
+	}
+	return config.Conf.OperationLog.RetentionDays
+}
+
+// 操作日志清理前是否归档, 以及归档文件在存储中的目录
+func OperationLogArchive() (bool, string) {
+	conf := config.Conf.OperationLog
+	if conf == nil || !conf.Archive {
+		return false, ""
+	}
+	dir := strings.Trim(conf.ArchivePath, "/")
+	if dir == "" {
+		dir = "archive/operation_log"
+	}
+	return true, dir
+}
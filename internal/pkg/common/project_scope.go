@@ -0,0 +1,219 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package common
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// 项目字段名, 带有该字符串字段的模型按项目隔离
+const projectIDField = "ProjectID"
+
+// 没有项目字段、通过所属数据关联项目的模型, key为表名
+// 如广告通过场景ID关联推广场景, 按推广场景的项目隔离; 规格、分类、标签等全局数据不按项目隔离
+var projectParents = map[string]projectParent{
+	"ad":                   {field: "SceneID", table: "ad_scene", column: "ad_scene_id"},
+	"order_log":            {field: "OrderID", table: "order", column: "order_id"},
+	"product_type":         {field: "ProductCategoryID", table: "product_category", column: "product_category_id"},
+	"third_party_accounts": {field: "UserID", table: "user", column: "user_id"},
+}
+
+// 所属数据
+type projectParent struct {
+	field  string // 模型中关联所属数据的字段
+	table  string // 所属数据的表
+	column string // 所属数据中被关联的列
+}
+
+// 写入不在可访问范围内的项目时返回
+var ErrProjectForbidden = errors.New("无权操作该项目的数据")
+
+type projectScopeKey struct{}
+
+// 将可访问的项目写入context, 之后使用该context的查询、更新、删除只作用于这些项目, 新增时校验项目
+// 没有写入项目范围的context(如定时任务、命令行)不受限制
+func WithProjectScope(ctx context.Context, projectIDs []string) context.Context {
+	return context.WithValue(ctx, projectScopeKey{}, projectIDs)
+}
+
+// 从context中获取可访问的项目, restricted为false时不限制
+func ProjectScope(ctx context.Context) (projectIDs []string, restricted bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	projectIDs, restricted = ctx.Value(projectScopeKey{}).([]string)
+	return projectIDs, restricted
+}
+
+// 是否可以访问该项目
+func ProjectAllowed(ctx context.Context, projectID string) bool {
+	projectIDs, restricted := ProjectScope(ctx)
+	if !restricted {
+		return true
+	}
+	for _, id := range projectIDs {
+		if id == projectID {
+			return true
+		}
+	}
+	return false
+}
+
+// 注册按项目隔离数据的gorm回调, 集中处理所有带ProjectID字段的模型
+func RegisterProjectScope(db *gorm.DB) error {
+	callback := db.Callback()
+	if err := callback.Query().Before("gorm:query").Register("project_scope:query", projectScopeWhere); err != nil {
+		return err
+	}
+	if err := callback.Row().Before("gorm:row").Register("project_scope:row", projectScopeWhere); err != nil {
+		return err
+	}
+	if err := callback.Update().Before("gorm:update").Register("project_scope:update", projectScopeUpdate); err != nil {
+		return err
+	}
+	if err := callback.Delete().Before("gorm:delete").Register("project_scope:delete", projectScopeWhere); err != nil {
+		return err
+	}
+	// 在BeforeCreate钩子之后校验, 钩子中可能生成项目ID
+	return callback.Create().After("gorm:before_create").Before("gorm:create").Register("project_scope:create", projectScopeCreate)
+}
+
+// 返回当前语句的项目字段与可访问的项目, 不需要限制时field为nil
+// 通过所属数据关联项目的模型, field为关联字段, parent不为nil
+func projectScopeField(db *gorm.DB) (*schema.Field, *projectParent, []string) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return nil, nil, nil
+	}
+	projectIDs, restricted := ProjectScope(db.Statement.Context)
+	if !restricted {
+		return nil, nil, nil
+	}
+	name := projectIDField
+	parent, ok := projectParents[db.Statement.Schema.Table]
+	if ok {
+		name = parent.field
+	}
+	field := db.Statement.Schema.LookUpField(name)
+	if field == nil || field.DBName == "" || field.IndirectFieldType.Kind() != reflect.String {
+		return nil, nil, nil
+	}
+	if ok {
+		return field, &parent, projectIDs
+	}
+	return field, nil, projectIDs
+}
+
+// 查询、删除只作用于可访问的项目
+func projectScopeWhere(db *gorm.DB) {
+	field, parent, projectIDs := projectScopeField(db)
+	if field == nil {
+		return
+	}
+	values := toValues(projectIDs)
+	column := clause.Column{Table: clause.CurrentTable, Name: field.DBName}
+	if parent == nil {
+		db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: values}}})
+		return
+	}
+	// 所属数据在可访问的项目中
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{clause.Expr{
+		SQL:  "? IN (SELECT ? FROM ? WHERE ? IN ?)",
+		Vars: []interface{}{column, clause.Column{Name: parent.column}, clause.Table{Name: parent.table}, clause.Column{Name: "project_id"}, values},
+	}}})
+}
+
+// 是否可以写入该值, 通过所属数据关联项目时查询所属数据的项目
+func projectValueAllowed(db *gorm.DB, parent *projectParent, value string) bool {
+	if parent == nil {
+		return ProjectAllowed(db.Statement.Context, value)
+	}
+	if value == "" {
+		return false
+	}
+	projectIDs, _ := ProjectScope(db.Statement.Context)
+	var count int64
+	err := db.Session(&gorm.Session{NewDB: true}).Table(parent.table).
+		Where(clause.Eq{Column: clause.Column{Name: parent.column}, Value: value}).
+		Where(clause.IN{Column: clause.Column{Name: "project_id"}, Values: toValues(projectIDs)}).
+		Count(&count).Error
+	if err != nil {
+		db.AddError(err)
+		return false
+	}
+	return count > 0
+}
+
+func toValues(ids []string) []interface{} {
+	values := make([]interface{}, len(ids))
+	for i, id := range ids {
+		values[i] = id
+	}
+	return values
+}
+
+// 更新只作用于可访问的项目, 同时不能把数据改到其他项目
+func projectScopeUpdate(db *gorm.DB) {
+	field, parent, _ := projectScopeField(db)
+	if field == nil {
+		return
+	}
+	projectScopeWhere(db)
+
+	ctx := db.Statement.Context
+	switch dest := db.Statement.Dest.(type) {
+	case map[string]interface{}:
+		for _, key := range []string{field.Name, field.DBName} {
+			if value, ok := dest[key].(string); ok && !projectValueAllowed(db, parent, value) {
+				db.AddError(ErrProjectForbidden)
+				return
+			}
+		}
+	default:
+		value := reflect.Indirect(reflect.ValueOf(dest))
+		if value.Kind() != reflect.Struct || value.Type() != db.Statement.Schema.ModelType {
+			return
+		}
+		// 零值字段不会被更新
+		if fieldValue, zero := field.ValueOf(ctx, value); !zero && !projectValueAllowed(db, parent, fieldValue.(string)) {
+			db.AddError(ErrProjectForbidden)
+		}
+	}
+}
+
+// 只能新增可访问项目的数据
+func projectScopeCreate(db *gorm.DB) {
+	field, parent, _ := projectScopeField(db)
+	if field == nil {
+		return
+	}
+	ctx := db.Statement.Context
+	check := func(value reflect.Value) bool {
+		fieldValue, _ := field.ValueOf(ctx, value)
+		id, _ := fieldValue.(string)
+		return projectValueAllowed(db, parent, id)
+	}
+
+	value := db.Statement.ReflectValue
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if !check(reflect.Indirect(value.Index(i))) {
+				db.AddError(ErrProjectForbidden)
+				return
+			}
+		}
+	case reflect.Struct:
+		if !check(value) {
+			db.AddError(ErrProjectForbidden)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package common
+
+import (
+	"context"
+	"go.uber.org/zap"
+)
+
+type requestIDKey struct{}
+
+// 将请求ID写入context, 随context传递到仓储与日志
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// 从context中获取请求ID, 不存在时返回空字符串
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// 返回附带请求ID字段的日志, context中没有请求ID时原样返回log
+func LogWithContext(ctx context.Context, log *zap.SugaredLogger) *zap.SugaredLogger {
+	if requestID := RequestID(ctx); requestID != "" {
+		return log.With("requestId", requestID)
+	}
+	return log
+}
@@ -0,0 +1,37 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package common
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"time"
+
+	"gotribe-admin/config"
+)
+
+// 两步验证challenge的有效期, 未配置时默认5分钟
+func TotpChallengeTimeout() time.Duration {
+	if minutes := config.Conf.Jwt.TotpChallengeTimeout; minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	return 5 * time.Minute
+}
+
+// 两步验证challenge的签名密钥, 由jwt密钥派生, 与jwt的签名密钥不同, 避免challenge被当作token使用
+func TotpChallengeKey() []byte {
+	mac := hmac.New(sha256.New, []byte(config.Conf.Jwt.Key))
+	mac.Write([]byte("totp-challenge"))
+	return mac.Sum(nil)
+}
+
+// 验证器App中显示的发行方, 取jwt标识
+func TotpIssuer() string {
+	if config.Conf.Jwt.Realm == "" {
+		return "gotribe-admin"
+	}
+	return config.Conf.Jwt.Realm
+}
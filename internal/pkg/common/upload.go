@@ -0,0 +1,65 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package common
+
+import (
+	"gotribe-admin/config"
+	"gotribe-admin/pkg/util/upload"
+	"net/url"
+	"strings"
+)
+
+// 当前配置的存储驱动
+// 未配置upload-file.driver时兼容旧的system.enable-oss配置
+func UploadDriver() string {
+	if driver := strings.ToLower(config.Conf.UploadFile.Driver); driver != "" {
+		return driver
+	}
+	if config.Conf.System.EnableOss {
+		return upload.DriverOSS
+	}
+	return upload.DriverQiniu
+}
+
+// 按配置创建文件上传服务
+func NewUploadService() (*upload.Service, error) {
+	conf := config.Conf.UploadFile
+	localURL := conf.LocalURL
+	if localURL == "" {
+		localURL = LocalUploadRoute()
+	}
+	return upload.NewUploadFile(upload.Options{
+		Driver:          UploadDriver(),
+		Endpoint:        conf.Endpoint,
+		AccessKeyId:     conf.Accesskey,
+		AccessKeySecret: conf.Secretkey,
+		Bucket:          conf.Bucket,
+		Region:          conf.Region,
+		LocalPath:       LocalUploadPath(),
+		LocalURL:        localURL,
+	})
+}
+
+// 本地存储目录, 默认uploads
+func LocalUploadPath() string {
+	if config.Conf.UploadFile.LocalPath == "" {
+		return "uploads"
+	}
+	return config.Conf.UploadFile.LocalPath
+}
+
+// 本地存储的静态路由路径, 取local-url的路径部分
+func LocalUploadRoute() string {
+	route := config.Conf.UploadFile.LocalURL
+	if u, err := url.Parse(route); err == nil && u.Host != "" {
+		route = u.Path
+	}
+	route = "/" + strings.Trim(route, "/")
+	if route == "/" {
+		return "/uploads"
+	}
+	return route
+}
@@ -0,0 +1,241 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package gen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/token"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm/schema"
+)
+
+// 实体定义
+type Entity struct {
+	Name          string  `yaml:"name"`          // 模型名, 大驼峰, 如 Article
+	Title         string  `yaml:"title"`         // 中文名称, 用于注释与提示信息, 如 文章
+	Plural        string  `yaml:"plural"`        // 复数形式, 默认按英文规则生成
+	Route         string  `yaml:"route"`         // 路由分组, 默认由模型名生成, 如 ProductBrand 为 /product/brand
+	ProjectScoped bool    `yaml:"projectScoped"` // 是否按项目隔离, 是则增加project_id字段
+	Menu          *Menu   `yaml:"menu"`          // 后台菜单, 不填则不生成菜单
+	Fields        []Field `yaml:"fields"`        // 字段
+
+	// 以下为生成代码时使用的派生名称
+	Snake       string `yaml:"-"` // product_brand
+	Lower       string `yaml:"-"` // productBrand
+	Var         string `yaml:"-"` // 局部变量名, 与关键字或包名冲突时增加Info后缀
+	Recv        string `yaml:"-"` // 方法接收者首字母, p
+	IDField     string `yaml:"-"` // ProductBrandID
+	IDColumn    string `yaml:"-"` // product_brand_id
+	IDParam     string `yaml:"-"` // productBrandID
+	PluralLower string `yaml:"-"` // productBrands
+	Version     uint   `yaml:"-"` // 迁移版本号
+}
+
+// 后台菜单
+type Menu struct {
+	Parent    string `yaml:"parent"`    // 父菜单完整路径, 如 /content
+	Path      string `yaml:"path"`      // 菜单路径, 默认为 父菜单路径/模型名
+	Component string `yaml:"component"` // 前端组件路径, 默认为 菜单路径/index
+	Icon      string `yaml:"icon"`
+	Sort      uint   `yaml:"sort"`
+}
+
+// 字段定义
+type Field struct {
+	Name     string `yaml:"name"`     // 字段名, 大驼峰
+	Type     string `yaml:"type"`     // 类型: string text int uint tinyint float
+	Size     int    `yaml:"size"`     // string类型的长度, 默认255
+	Comment  string `yaml:"comment"`  // 字段说明
+	Default  string `yaml:"default"`  // 数据库默认值
+	Index    bool   `yaml:"index"`    // 是否建立索引
+	Unique   bool   `yaml:"unique"`   // 是否唯一
+	Validate string `yaml:"validate"` // 创建、更新时的validator校验规则, 如 required,min=2,max=20
+	Search   string `yaml:"search"`   // 列表查询方式: like 模糊查询, eq 精确查询, 不填则不参与查询
+
+	GoType  string `yaml:"-"`
+	GormTag string `yaml:"-"`
+	Column  string `yaml:"-"`
+	JSON    string `yaml:"-"`
+}
+
+var (
+	nameRe = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+	naming = schema.NamingStrategy{SingularTable: true}
+	// 生成代码中用到的包名与变量名
+	reservedVars = map[string]bool{
+		"app": true, "model": true, "vo": true, "dto": true, "known": true, "common": true, "repository": true,
+		"controller": true, "middleware": true, "response": true, "validator": true, "gin": true,
+		"jwt": true, "errors": true, "fmt": true, "strings": true, "gid": true, "gorm": true,
+		"db": true, "err": true, "list": true, "total": true, "req": true, "c": true, "r": true, "router": true,
+	}
+)
+
+// 读取实体定义文件
+func Load(file string) (*Entity, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var e Entity
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&e); err != nil {
+		return nil, fmt.Errorf("解析实体定义%s失败: %v", file, err)
+	}
+	if err := e.normalize(); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// 校验实体定义并生成派生名称
+func (e *Entity) normalize() error {
+	if !nameRe.MatchString(e.Name) {
+		return fmt.Errorf("模型名%q需为大驼峰格式", e.Name)
+	}
+	if e.Title == "" {
+		return errors.New("缺少中文名称title")
+	}
+	if len(e.Fields) == 0 {
+		return errors.New("至少需要定义一个字段")
+	}
+	e.Snake = snakeCase(e.Name)
+	e.Lower = lowerFirst(e.Name)
+	e.Var = e.Lower
+	if token.IsKeyword(e.Var) || reservedVars[e.Var] {
+		e.Var += "Info"
+	}
+	e.Recv = e.Lower[:1]
+	e.IDField = e.Name + "ID"
+	e.IDColumn = e.Snake + "_id"
+	e.IDParam = e.Lower + "ID"
+	if e.Plural == "" {
+		e.Plural = plural(e.Name)
+	}
+	e.PluralLower = lowerFirst(e.Plural)
+	if e.Route == "" {
+		e.Route = "/" + strings.ReplaceAll(e.Snake, "_", "/")
+	}
+	e.Route = "/" + strings.Trim(e.Route, "/")
+
+	if e.Menu != nil {
+		if e.Menu.Path == "" {
+			e.Menu.Path = path.Join("/", e.Menu.Parent, strings.ReplaceAll(e.Snake, "_", "-"))
+		}
+		if e.Menu.Component == "" {
+			e.Menu.Component = path.Join(e.Menu.Path, "index")
+		}
+		if e.Menu.Sort == 0 {
+			e.Menu.Sort = 999
+		}
+	}
+
+	reserved := map[string]bool{"ID": true, "CreatedAt": true, "UpdatedAt": true, "DeletedAt": true, e.IDField: true}
+	if e.ProjectScoped {
+		reserved["ProjectID"] = true
+	}
+	for i := range e.Fields {
+		f := &e.Fields[i]
+		if !nameRe.MatchString(f.Name) {
+			return fmt.Errorf("字段名%q需为大驼峰格式", f.Name)
+		}
+		if reserved[f.Name] {
+			return fmt.Errorf("字段%s与自动生成的字段重复", f.Name)
+		}
+		reserved[f.Name] = true
+		if err := f.normalize(e.Snake); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 根据字段类型生成Go类型与gorm标签
+func (f *Field) normalize(table string) error {
+	f.Column = snakeCase(f.Name)
+	f.JSON = lowerFirst(f.Name)
+	var dbType string
+	switch f.Type {
+	case "", "string":
+		f.Type = "string"
+		if f.Size == 0 {
+			f.Size = 255
+		}
+		f.GoType, dbType = "string", fmt.Sprintf("varchar(%d)", f.Size)
+	case "text":
+		f.GoType, dbType = "string", "longtext"
+	case "int":
+		f.GoType, dbType = "int", "int"
+	case "uint":
+		f.GoType, dbType = "uint", "int unsigned"
+	case "tinyint":
+		f.GoType, dbType = "uint8", "tinyint"
+	case "float":
+		f.GoType, dbType = "float64", "decimal(10,2)"
+	default:
+		return fmt.Errorf("字段%s的类型%s不支持, 可选: string text int uint tinyint float", f.Name, f.Type)
+	}
+	switch f.Search {
+	case "", "eq", "like":
+	default:
+		return fmt.Errorf("字段%s的查询方式%s不支持, 可选: like eq", f.Name, f.Search)
+	}
+	if f.Search == "like" && f.GoType != "string" {
+		return fmt.Errorf("字段%s不是字符串类型, 不能使用模糊查询", f.Name)
+	}
+
+	tags := []string{"type:" + dbType, "not null"}
+	if f.Default != "" {
+		tags = append(tags, "default:"+f.Default)
+	}
+	switch {
+	case f.Unique:
+		tags = append(tags, fmt.Sprintf("uniqueIndex:idx_%s_%s", table, f.Column))
+	case f.Index:
+		tags = append(tags, fmt.Sprintf("index:idx_%s_%s", table, f.Column))
+	}
+	if f.Comment != "" {
+		tags = append(tags, "comment:"+f.Comment)
+	}
+	f.GormTag = strings.Join(tags, ";")
+	return nil
+}
+
+// 与gorm保持一致的命名, ProductBrand -> product_brand
+func snakeCase(s string) string {
+	return naming.ColumnName("", s)
+}
+
+// ProductBrand -> productBrand
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	runes := []rune(s)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+// 英文复数
+func plural(s string) string {
+	lower := strings.ToLower(s)
+	switch {
+	case strings.HasSuffix(lower, "y") && len(s) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return s[:len(s)-1] + "ies"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return s + "es"
+	}
+	return s + "s"
+}
@@ -0,0 +1,206 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+// Package gen 根据实体定义生成增删改查模块代码
+package gen
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+var templates = template.Must(template.ParseFS(templateFS, "templates/*.tmpl"))
+
+// 生成的文件
+type File struct {
+	Path    string // 相对项目根目录的路径
+	Content []byte
+	Exists  bool // 文件已存在(修改已有文件或覆盖)
+}
+
+const (
+	migrateFile    = "internal/pkg/model/migrate/migrate.go"
+	repositoryFile = "internal/app/repository/repository.go"
+	routesFile     = "internal/app/routes/routes.go"
+)
+
+var (
+	versionFileRe  = regexp.MustCompile(`^v(\d+)_.*\.go$`)
+	migrationsRe   = regexp.MustCompile(`(?s)var migrations = \[\]\*Migration\{\n(.*?)\n\}`)
+	repositoriesRe = regexp.MustCompile(`(?s)type Repositories struct \{\n(.*?)\n\}`)
+	newReposRe     = regexp.MustCompile(`(?s)return &Repositories\{\n(.*?)\n\t\}`)
+	routesAnchor   = "\tc.Log.Info(\"初始化路由完成！\")"
+)
+
+// 在root目录(项目根目录)下生成实体的模型、迁移、仓储、控制器、路由、请求、响应及初始数据文件,
+// 并在迁移列表、仓储集合与路由注册中追加新模块, 返回全部新增或修改的文件, 不写入磁盘
+func Generate(e *Entity, root string) ([]*File, error) {
+	if _, err := os.Stat(filepath.Join(root, "go.mod")); err != nil {
+		return nil, fmt.Errorf("%s不是项目根目录: %v", root, err)
+	}
+	version, err := nextVersion(filepath.Join(root, filepath.Dir(migrateFile)), e.Snake)
+	if err != nil {
+		return nil, err
+	}
+	e.Version = version
+
+	targets := []struct{ tmpl, path string }{
+		{"model.go.tmpl", "internal/pkg/model/" + e.Snake + ".go"},
+		{"migrate.go.tmpl", "internal/pkg/model/migrate/" + e.Snake + ".go"},
+		{"version.go.tmpl", fmt.Sprintf("internal/pkg/model/migrate/v%04d_create_%s.go", version, e.Snake)},
+		{"repository.go.tmpl", "internal/app/repository/" + e.Snake + "_repository.go"},
+		{"controller.go.tmpl", "internal/app/controller/" + e.Snake + "_controller.go"},
+		{"routes.go.tmpl", "internal/app/routes/" + e.Snake + "_routes.go"},
+		{"vo.go.tmpl", "pkg/api/vo/" + e.Snake + "_request.go"},
+		{"dto.go.tmpl", "pkg/api/dto/" + e.Snake + "_dto.go"},
+		{"fixture.yml.tmpl", "internal/pkg/seed/fixtures/" + e.Snake + ".yml"},
+	}
+	var files []*File
+	for _, t := range targets {
+		var buf bytes.Buffer
+		if err := templates.ExecuteTemplate(&buf, t.tmpl, e); err != nil {
+			return nil, err
+		}
+		content := buf.Bytes()
+		if strings.HasSuffix(t.path, ".go") {
+			if content, err = format.Source(content); err != nil {
+				return nil, fmt.Errorf("格式化%s失败: %v", t.path, err)
+			}
+		}
+		files = append(files, &File{Path: t.path, Content: content, Exists: exists(root, t.path)})
+	}
+
+	// 追加到迁移列表
+	migrations, err := patch(root, migrateFile, func(src string) (string, error) {
+		m := migrationsRe.FindStringSubmatchIndex(src)
+		if m == nil {
+			return "", errors.New("未找到迁移列表 var migrations = []*Migration{...}")
+		}
+		name := "create" + e.Name + "Migration"
+		if strings.Contains(src[m[2]:m[3]], name+",") {
+			return src, nil
+		}
+		return src[:m[3]] + "\n\t" + name + "," + src[m[3]:], nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// 加入仓储集合
+	repositories, err := patch(root, repositoryFile, func(src string) (string, error) {
+		field := fmt.Sprintf("\t%s I%sRepository", e.Name, e.Name)
+		if strings.Contains(src, field+"\n") {
+			return src, nil
+		}
+		s := repositoriesRe.FindStringSubmatchIndex(src)
+		n := newReposRe.FindStringSubmatchIndex(src)
+		if s == nil || n == nil || n[3] < s[3] {
+			return "", errors.New("未找到仓储集合 type Repositories struct{...} 或 NewRepositories")
+		}
+		init := fmt.Sprintf("\n\t\t%s: New%sRepository(db),", e.Name, e.Name)
+		return src[:s[3]] + "\n" + field + src[s[3]:n[3]] + init + src[n[3]:], nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// 注册路由
+	routes, err := patch(root, routesFile, func(src string) (string, error) {
+		call := "Init" + e.Name + "Routes(apiGroup, authMiddleware, c)"
+		if strings.Contains(src, call) {
+			return src, nil
+		}
+		i := strings.Index(src, routesAnchor)
+		if i < 0 {
+			return "", fmt.Errorf("未找到路由注册位置: %s", strings.TrimSpace(routesAnchor))
+		}
+		line := fmt.Sprintf("\t%s // 注册%s管理路由, jwt认证中间件,casbin鉴权中间件\n", call, e.Title)
+		return src[:i] + line + src[i:], nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(files, migrations, repositories, routes), nil
+}
+
+// 写入生成的文件, force为false时已存在的文件(除追加注册的文件外)不会被覆盖
+func Write(root string, files []*File, force bool) error {
+	if !force {
+		for _, f := range files {
+			if f.Exists && f.Path != migrateFile && f.Path != repositoryFile && f.Path != routesFile {
+				return fmt.Errorf("文件%s已存在, 如需覆盖请使用-force参数", f.Path)
+			}
+		}
+	}
+	for _, f := range files {
+		target := filepath.Join(root, filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, f.Content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 修改已有的Go文件并格式化
+func patch(root, path string, fn func(src string) (string, error)) (*File, error) {
+	src, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(path)))
+	if err != nil {
+		return nil, err
+	}
+	out, err := fn(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("修改%s失败: %v", path, err)
+	}
+	content, err := format.Source([]byte(out))
+	if err != nil {
+		return nil, fmt.Errorf("格式化%s失败: %v", path, err)
+	}
+	return &File{Path: path, Content: content, Exists: true}, nil
+}
+
+// 下一个迁移版本号, 取已有 vNNNN_*.go 文件中的最大版本号加一
+// 已生成过该实体的建表迁移时沿用原版本号
+func nextVersion(dir, snake string) (uint, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	var max uint64
+	for _, entry := range entries {
+		m := versionFileRe.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		v, err := strconv.ParseUint(m[1], 10, 32)
+		if err != nil {
+			return 0, err
+		}
+		if strings.HasSuffix(entry.Name(), "_create_"+snake+".go") {
+			return uint(v), nil
+		}
+		if v > max {
+			max = v
+		}
+	}
+	return uint(max + 1), nil
+}
+
+func exists(root, path string) bool {
+	_, err := os.Stat(filepath.Join(root, filepath.FromSlash(path)))
+	return err == nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+// Package geoip 基于本地数据库文件的IP归属地查询
+package geoip
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// 支持的数据库格式
+const (
+	DriverIP2Region = "ip2region" // ip2region xdb格式
+	DriverMMDB      = "mmdb"      // MaxMind mmdb格式, 如GeoLite2-City.mmdb
+)
+
+// 内网地址的归属地
+const Intranet = "内网IP"
+
+var ErrInvalidIP = errors.New("无效的IP地址")
+
+// IP归属地, 未查到的部分为空
+type Location struct {
+	Country string `json:"country"`
+	Region  string `json:"region"`
+	City    string `json:"city"`
+}
+
+// 是否未查到任何信息
+func (l Location) IsZero() bool {
+	return l.Country == "" && l.Region == "" && l.City == ""
+}
+
+// 以空格连接的归属地, 如"中国 广东省 深圳市"
+func (l Location) String() string {
+	var parts []string
+	for _, part := range []string{l.Country, l.Region, l.City} {
+		// 直辖市等省份与城市相同时只保留一个
+		if part != "" && (len(parts) == 0 || parts[len(parts)-1] != part) {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// IP归属地查询, 实现需支持并发调用
+type Resolver interface {
+	Lookup(ip string) (Location, error)
+	Close() error
+}
+
+// 按数据库格式打开本地数据库文件, driver为空时返回不查询的Resolver
+func New(driver, path, language string) (Resolver, error) {
+	var s searcher
+	var err error
+	switch driver {
+	case "":
+		return Nop(), nil
+	case DriverIP2Region:
+		s, err = openXdb(path)
+	case DriverMMDB:
+		s, err = openMMDB(path, language)
+	default:
+		return nil, fmt.Errorf("不支持的IP数据库格式: %s", driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resolver{searcher: s}, nil
+}
+
+// 不查询归属地的Resolver, 未配置数据库时使用
+func Nop() Resolver {
+	return nop{}
+}
+
+// 是否为不查询归属地的Resolver
+func IsNop(r Resolver) bool {
+	_, ok := r.(nop)
+	return ok
+}
+
+type nop struct{}
+
+func (nop) Lookup(ip string) (Location, error) {
+	return Location{}, nil
+}
+
+func (nop) Close() error {
+	return nil
+}
+
+// 具体格式的数据库查询
+type searcher interface {
+	search(ip net.IP) (Location, error)
+	Close() error
+}
+
+// 统一处理IP解析与内网地址
+type resolver struct {
+	searcher
+}
+
+func (r resolver) Lookup(ip string) (Location, error) {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return Location{}, ErrInvalidIP
+	}
+	if parsed.IsPrivate() || parsed.IsLoopback() || parsed.IsLinkLocalUnicast() || parsed.IsUnspecified() {
+		return Location{Country: Intranet}, nil
+	}
+	return r.search(parsed)
+}
@@ -0,0 +1,74 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package geoip
+
+import (
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// 未配置语言时使用简体中文, 没有对应语言时使用英文
+const (
+	defaultLanguage  = "zh-CN"
+	fallbackLanguage = "en"
+)
+
+// MaxMind mmdb格式查询
+type mmdb struct {
+	reader   *maxminddb.Reader
+	language string
+}
+
+// mmdb中需要的字段
+type mmdbRecord struct {
+	Country struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+func openMMDB(path, language string) (searcher, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if language == "" {
+		language = defaultLanguage
+	}
+	return &mmdb{reader: reader, language: language}, nil
+}
+
+func (m *mmdb) search(ip net.IP) (Location, error) {
+	var record mmdbRecord
+	if err := m.reader.Lookup(ip, &record); err != nil {
+		return Location{}, err
+	}
+	location := Location{
+		Country: m.name(record.Country.Names),
+		City:    m.name(record.City.Names),
+	}
+	if len(record.Subdivisions) > 0 {
+		location.Region = m.name(record.Subdivisions[0].Names)
+	}
+	return location, nil
+}
+
+func (m *mmdb) name(names map[string]string) string {
+	if name, ok := names[m.language]; ok {
+		return name
+	}
+	return names[fallbackLanguage]
+}
+
+func (m *mmdb) Close() error {
+	return m.reader.Close()
+}
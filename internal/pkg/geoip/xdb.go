@@ -0,0 +1,97 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package geoip
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"strings"
+)
+
+// ip2region xdb文件结构: 256字节文件头, 256*256个向量索引(每个8字节, 指向该/16网段的索引区间),
+// 之后是按起始IP排序的索引(每个14字节: 起始IP、结束IP、数据长度、数据偏移)与"国家|区域|省份|城市|ISP"格式的数据
+const (
+	xdbHeaderLength      = 256
+	xdbVectorIndexCols   = 256
+	xdbVectorIndexSize   = 8
+	xdbSegmentIndexSize  = 14
+	xdbVectorIndexLength = xdbVectorIndexCols * xdbVectorIndexCols * xdbVectorIndexSize
+)
+
+var errXdbCorrupted = errors.New("ip2region数据库文件已损坏")
+
+// ip2region xdb格式查询, 整个文件加载到内存, 只支持IPv4
+type xdb struct {
+	content []byte
+}
+
+func openXdb(path string) (searcher, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(content) < xdbHeaderLength+xdbVectorIndexLength {
+		return nil, errXdbCorrupted
+	}
+	return &xdb{content: content}, nil
+}
+
+func (x *xdb) search(ip net.IP) (Location, error) {
+	ip4 := ip.To4()
+	if ip4 == nil {
+		return Location{}, nil
+	}
+	value := binary.BigEndian.Uint32(ip4)
+
+	// 按前两段定位索引区间
+	offset := xdbHeaderLength + (int(ip4[0])*xdbVectorIndexCols+int(ip4[1]))*xdbVectorIndexSize
+	start := int(binary.LittleEndian.Uint32(x.content[offset:]))
+	end := int(binary.LittleEndian.Uint32(x.content[offset+4:]))
+	if end < start || end+xdbSegmentIndexSize > len(x.content) {
+		return Location{}, errXdbCorrupted
+	}
+
+	// 区间内二分查找
+	low, high := 0, (end-start)/xdbSegmentIndexSize
+	for low <= high {
+		mid := (low + high) / 2
+		p := start + mid*xdbSegmentIndexSize
+		startIP := binary.LittleEndian.Uint32(x.content[p:])
+		endIP := binary.LittleEndian.Uint32(x.content[p+4:])
+		switch {
+		case value < startIP:
+			high = mid - 1
+		case value > endIP:
+			low = mid + 1
+		default:
+			length := int(binary.LittleEndian.Uint16(x.content[p+8:]))
+			ptr := int(binary.LittleEndian.Uint32(x.content[p+10:]))
+			if ptr+length > len(x.content) {
+				return Location{}, errXdbCorrupted
+			}
+			return parseXdbRegion(string(x.content[ptr : ptr+length])), nil
+		}
+	}
+	return Location{}, nil
+}
+
+func (x *xdb) Close() error {
+	return nil
+}
+
+// 解析"国家|区域|省份|城市|ISP", 0表示未知
+func parseXdbRegion(region string) Location {
+	fields := strings.Split(region, "|")
+	field := func(i int) string {
+		if i >= len(fields) || fields[i] == "0" {
+			return ""
+		}
+		return fields[i]
+	}
+	return Location{Country: field(0), Region: field(2), City: field(3)}
+}
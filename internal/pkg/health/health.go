@@ -0,0 +1,127 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+// Package health 存活与就绪检查
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 单项检查的超时时间
+const checkTimeout = 3 * time.Second
+
+// 检查状态
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// 单项依赖检查, 返回nil表示可用
+type CheckFunc func(ctx context.Context) error
+
+// 单项检查结果
+type Result struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Latency int64  `json:"latency"` // 毫秒
+	Error   string `json:"error,omitempty"`
+}
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// 就绪检查器, 依赖检查全部通过且未进入停机流程时才算就绪
+type Checker struct {
+	mu           sync.RWMutex
+	checks       []check
+	shuttingDown atomic.Bool
+}
+
+// Checker构造函数
+func New() *Checker {
+	return &Checker{}
+}
+
+// 添加依赖检查, 同名检查会被替换
+func (h *Checker) Add(name string, fn CheckFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, c := range h.checks {
+		if c.name == name {
+			h.checks[i].fn = fn
+			return
+		}
+	}
+	h.checks = append(h.checks, check{name: name, fn: fn})
+}
+
+// 进入停机流程, 之后的就绪检查都会失败, 负载均衡据此摘除流量
+func (h *Checker) Shutdown() {
+	h.shuttingDown.Store(true)
+}
+
+// 是否已进入停机流程
+func (h *Checker) ShuttingDown() bool {
+	return h.shuttingDown.Load()
+}
+
+// 并发执行全部依赖检查, 返回是否就绪与每项检查的结果
+func (h *Checker) Ready(ctx context.Context) (bool, []Result) {
+	h.mu.RLock()
+	checks := make([]check, len(h.checks))
+	copy(checks, h.checks)
+	h.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c check) {
+			defer wg.Done()
+			results[i] = run(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	ready := !h.ShuttingDown()
+	for _, result := range results {
+		if result.Status != StatusUp {
+			ready = false
+		}
+	}
+	return ready, results
+}
+
+// 执行单项检查, 超时视为失败
+func run(ctx context.Context, c check) Result {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	startTime := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- c.fn(ctx)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = errors.New("检查超时")
+	}
+
+	result := Result{Name: c.name, Status: StatusUp, Latency: time.Since(startTime).Milliseconds()}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
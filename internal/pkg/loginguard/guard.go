@@ -0,0 +1,209 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+// Package loginguard 登录保护, 按用户名与IP统计连续登录失败次数, 超过阈值后逐级加长锁定时间
+// 失败次数与锁定保存在数据库中, 多实例部署时任一实例的失败都计入, 解锁对全部实例生效
+package loginguard
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gotribe-admin/internal/pkg/model"
+)
+
+// 默认值
+const (
+	DefaultMaxAttempts     = 5
+	DefaultIPMaxAttempts   = 20
+	DefaultLockDuration    = 5 * time.Minute
+	DefaultMaxLockDuration = 24 * time.Hour
+	DefaultWindow          = time.Hour
+)
+
+// 登录保护配置
+type Options struct {
+	MaxAttempts     int           // 同一用户名连续失败多少次后锁定, 小于等于0不锁定
+	IPMaxAttempts   int           // 同一IP连续失败多少次后锁定, 小于等于0不锁定
+	LockDuration    time.Duration // 首次锁定时长, 之后每次锁定时长翻倍
+	MaxLockDuration time.Duration // 最长锁定时长
+	Window          time.Duration // 失败次数的统计窗口, 超过窗口没有再失败时清零
+}
+
+// 默认配置
+func DefaultOptions() Options {
+	return Options{
+		MaxAttempts:     DefaultMaxAttempts,
+		IPMaxAttempts:   DefaultIPMaxAttempts,
+		LockDuration:    DefaultLockDuration,
+		MaxLockDuration: DefaultMaxLockDuration,
+		Window:          DefaultWindow,
+	}
+}
+
+// 未配置的时长使用默认值
+func (o Options) withDefaults() Options {
+	if o.LockDuration <= 0 {
+		o.LockDuration = DefaultLockDuration
+	}
+	if o.MaxLockDuration < o.LockDuration {
+		o.MaxLockDuration = DefaultMaxLockDuration
+		if o.MaxLockDuration < o.LockDuration {
+			o.MaxLockDuration = o.LockDuration
+		}
+	}
+	if o.Window <= 0 {
+		o.Window = DefaultWindow
+	}
+	return o
+}
+
+// 账号或IP被锁定
+type LockedError struct {
+	IP         bool          // 是否因IP失败次数过多被锁定
+	RetryAfter time.Duration // 剩余锁定时长
+}
+
+func (e *LockedError) Error() string {
+	if e.IP {
+		return fmt.Sprintf("该IP登录失败次数过多, 请%s后重试", humanize(e.RetryAfter))
+	}
+	return fmt.Sprintf("登录失败次数过多, 账号已锁定, 请%s后重试", humanize(e.RetryAfter))
+}
+
+// 剩余锁定时长, 按秒向上取整
+func (e *LockedError) Seconds() int {
+	return int((e.RetryAfter + time.Second - 1) / time.Second)
+}
+
+// 不足一分钟时以秒显示, 否则以分钟显示, 均向上取整
+func humanize(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%d秒", (d+time.Second-1)/time.Second)
+	}
+	return fmt.Sprintf("%d分钟", (d+time.Minute-1)/time.Minute)
+}
+
+// 失败记录的存储, 保存在数据库中, 多实例部署时共享失败次数与锁定
+type Store interface {
+	GetLoginLocks(ctx context.Context, keys []string) ([]model.LoginLock, error)           // 获取多条失败记录
+	UpdateLoginLock(ctx context.Context, key string, fn func(lock *model.LoginLock)) error // 加锁读取失败记录, 不存在时创建, 由fn修改后保存
+	DeleteLoginLock(ctx context.Context, key string) error                                 // 删除失败记录
+}
+
+// 失败计数
+type counter struct {
+	opts  Options
+	store Store
+}
+
+func newCounter(opts Options, store Store) *counter {
+	return &counter{opts: opts.withDefaults(), store: store}
+}
+
+// 用户名按字段长度截断, 与登录日志一致
+func userKey(username string) string {
+	if runes := []rune(username); len(runes) > 64 {
+		username = string(runes[:64])
+	}
+	return "user:" + username
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// 用户名或IP处于锁定中时返回LockedError
+func (c *counter) check(ctx context.Context, username, ip string) (*LockedError, error) {
+	locks, err := c.store.GetLoginLocks(ctx, []string{ipKey(ip), userKey(username)})
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var locked *LockedError
+	for _, lock := range locks {
+		if lock.LockedUntil == nil || !lock.LockedUntil.After(now) {
+			continue
+		}
+		isIP := lock.LockKey == ipKey(ip)
+		// IP锁定优先
+		if locked == nil || (isIP && !locked.IP) {
+			locked = &LockedError{IP: isIP, RetryAfter: lock.LockedUntil.Sub(now)}
+		}
+	}
+	return locked, nil
+}
+
+// 记录一次失败, 本次失败导致锁定时返回LockedError
+func (c *counter) fail(ctx context.Context, username, ip string) (*LockedError, error) {
+	var locked *LockedError
+	if ip != "" && c.opts.IPMaxAttempts > 0 {
+		var d time.Duration
+		err := c.store.UpdateLoginLock(ctx, ipKey(ip), func(lock *model.LoginLock) {
+			d = c.add(lock, c.opts.IPMaxAttempts, time.Now())
+		})
+		if err != nil {
+			return nil, err
+		}
+		if d > 0 {
+			locked = &LockedError{IP: true, RetryAfter: d}
+		}
+	}
+	if username != "" && c.opts.MaxAttempts > 0 {
+		var d time.Duration
+		err := c.store.UpdateLoginLock(ctx, userKey(username), func(lock *model.LoginLock) {
+			d = c.add(lock, c.opts.MaxAttempts, time.Now())
+		})
+		if err != nil {
+			return locked, err
+		}
+		if d > 0 && locked == nil {
+			locked = &LockedError{RetryAfter: d}
+		}
+	}
+	return locked, nil
+}
+
+// 清除用户名的失败记录
+func (c *counter) reset(ctx context.Context, username string) error {
+	return c.store.DeleteLoginLock(ctx, userKey(username))
+}
+
+// 失败次数加一, 达到阈值时锁定并返回锁定时长
+func (c *counter) add(lock *model.LoginLock, max int, now time.Time) time.Duration {
+	// 记录已过期, 失败次数与锁定等级清零
+	if !lock.ExpiresAt.After(now) {
+		lock.Failures = 0
+		lock.Lockouts = 0
+	}
+	// 超过统计窗口没有失败, 重新计数
+	if lock.LastFailure == nil || now.Sub(*lock.LastFailure) > c.opts.Window {
+		lock.Failures = 0
+	}
+	lock.Failures++
+	lock.LastFailure = &now
+
+	var d time.Duration
+	if lock.Failures >= max {
+		d = c.opts.LockDuration
+		for i := 0; i < lock.Lockouts && d < c.opts.MaxLockDuration; i++ {
+			d *= 2
+		}
+		if d > c.opts.MaxLockDuration {
+			d = c.opts.MaxLockDuration
+		}
+		lockedUntil := now.Add(d)
+		lock.Failures = 0
+		lock.Lockouts++
+		lock.LockedUntil = &lockedUntil
+	}
+	// 锁定结束后再经过一个统计窗口没有失败时, 锁定等级随记录一起过期
+	lock.ExpiresAt = now.Add(c.opts.Window)
+	if lock.LockedUntil != nil && lock.LockedUntil.After(now) {
+		lock.ExpiresAt = lock.LockedUntil.Add(c.opts.Window)
+	}
+	return d
+}
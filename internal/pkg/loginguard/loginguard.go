@@ -0,0 +1,104 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package loginguard
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/pkg/api/response"
+)
+
+// 登录日志写入函数, 由调用方补充请求ID、归属地后入库
+type Recorder func(ctx context.Context, log *model.LoginLog)
+
+// 登录保护, 登录前检查锁定, 登录后记录结果与失败次数
+// 密码登录与两步验证登录共用同一个Guard, 只有最终登录成功才清除用户名的失败次数
+// 只有来自可信代理的请求才读取X-Forwarded-For作为客户端IP, 避免每次请求伪造不同的X-Forwarded-For绕过IP锁定
+// 读写失败记录出错时只记录错误日志, 不影响登录
+type Guard struct {
+	counter *counter
+	record  Recorder
+	log     *zap.SugaredLogger
+}
+
+// Guard构造函数, 失败次数与锁定保存在store中
+func New(opts Options, store Store, record Recorder, log *zap.SugaredLogger) *Guard {
+	return &Guard{counter: newCounter(opts, store), record: record, log: log}
+}
+
+// 用户名或请求IP处于锁定中时返回LockedError, 并记录一条失败日志
+func (g *Guard) Check(c *gin.Context, adminID uint, username string) *LockedError {
+	locked, err := g.counter.check(c.Request.Context(), username, c.ClientIP())
+	if err != nil {
+		g.log.Errorf("读取登录失败记录失败, 用户: %s, err: %v", username, err)
+	}
+	if locked != nil {
+		reason := "账号已锁定"
+		if locked.IP {
+			reason = "IP已锁定"
+		}
+		g.save(c, adminID, username, model.LoginFailure, reason)
+	}
+	return locked
+}
+
+// 记录一次登录失败, 本次失败导致锁定时返回LockedError
+func (g *Guard) Fail(c *gin.Context, adminID uint, username, reason string) *LockedError {
+	g.save(c, adminID, username, model.LoginFailure, reason)
+	locked, err := g.counter.fail(c.Request.Context(), username, c.ClientIP())
+	if err != nil {
+		g.log.Errorf("记录登录失败次数失败, 用户: %s, err: %v", username, err)
+	}
+	return locked
+}
+
+// 记录一次登录成功, 清除用户名的失败次数
+func (g *Guard) Succeed(c *gin.Context, adminID uint, username string) {
+	g.save(c, adminID, username, model.LoginSuccess, "")
+	if err := g.counter.reset(c.Request.Context(), username); err != nil {
+		g.log.Errorf("清除登录失败次数失败, 用户: %s, err: %v", username, err)
+	}
+}
+
+// 解除用户名的锁定并清除失败次数, 对全部实例生效
+func (g *Guard) Unlock(ctx context.Context, username string) error {
+	return g.counter.reset(ctx, username)
+}
+
+// 锁定时的响应, 返回429与剩余锁定秒数
+func Respond(c *gin.Context, locked *LockedError) {
+	seconds := locked.Seconds()
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	response.Response(c, http.StatusTooManyRequests, http.StatusTooManyRequests, gin.H{"retryAfter": seconds}, locked.Error())
+}
+
+// 写入登录日志
+func (g *Guard) save(c *gin.Context, adminID uint, username string, status uint, reason string) {
+	if g.record == nil {
+		return
+	}
+	// 不存在的用户名可能很长, 按字段长度截断
+	if runes := []rune(username); len(runes) > 64 {
+		username = string(runes[:64])
+	}
+	userAgent := c.Request.UserAgent()
+	if runes := []rune(userAgent); len(runes) > 255 {
+		userAgent = string(runes[:255])
+	}
+	g.record(c.Request.Context(), &model.LoginLog{
+		AdminID:   adminID,
+		Username:  username,
+		Ip:        c.ClientIP(),
+		UserAgent: userAgent,
+		Status:    status,
+		Reason:    reason,
+	})
+}
@@ -0,0 +1,133 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+// Package metrics Prometheus监控指标
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+)
+
+// 指标名前缀
+const namespace = "gotribe"
+
+// 未匹配到路由的请求使用的route标签, 避免路径过多导致标签爆炸
+const unmatchedRoute = "unmatched"
+
+// 应用的全部监控指标, 每个实例使用独立的Registry, 互不干扰
+type Metrics struct {
+	registry        *prometheus.Registry
+	requestTotal    *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	casbinDenied    *prometheus.CounterVec
+	jobRuns         *prometheus.CounterVec
+	jobFailures     *prometheus.CounterVec
+	oplogOverflow   *prometheus.CounterVec
+}
+
+// Metrics构造函数, db不为空时同时采集数据库连接池状态
+func New(db *gorm.DB) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requestTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP请求数",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP请求耗时(秒)",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		casbinDenied: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "casbin_denied_total",
+			Help:      "casbin鉴权拒绝次数",
+		}, []string{"method", "route"}),
+		jobRuns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cron_job_runs_total",
+			Help:      "定时任务执行次数",
+		}, []string{"job"}),
+		jobFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cron_job_failures_total",
+			Help:      "定时任务失败次数",
+		}, []string{"job"}),
+		oplogOverflow: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "operation_log_overflow_total",
+			Help:      "未能直接入库的操作日志条数, action为drop(丢弃)或spill(写入本地文件)",
+		}, []string{"action"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requestTotal,
+		m.requestDuration,
+		m.casbinDenied,
+		m.jobRuns,
+		m.jobFailures,
+		m.oplogOverflow,
+	)
+	if db != nil {
+		if sqlDB, err := db.DB(); err == nil {
+			m.registry.MustRegister(collectors.NewDBStatsCollector(sqlDB, db.Dialector.Name()))
+		}
+	}
+	return m
+}
+
+// 暴露指标的http处理器
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// 记录一次HTTP请求, route为gin的路由模板(c.FullPath())
+func (m *Metrics) ObserveRequest(method, route string, status int, elapsed time.Duration) {
+	if route == "" {
+		route = unmatchedRoute
+	}
+	m.requestTotal.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.requestDuration.WithLabelValues(method, route).Observe(elapsed.Seconds())
+}
+
+// 记录一次casbin鉴权拒绝
+func (m *Metrics) CasbinDenied(method, route string) {
+	m.casbinDenied.WithLabelValues(method, route).Inc()
+}
+
+// 记录一次定时任务执行结果
+func (m *Metrics) ObserveJob(job string, err error) {
+	m.jobRuns.WithLabelValues(job).Inc()
+	if err != nil {
+		m.jobFailures.WithLabelValues(job).Inc()
+	}
+}
+
+// 记录未能直接入库的操作日志
+func (m *Metrics) OperationLogOverflow(action string, count int) {
+	m.oplogOverflow.WithLabelValues(action).Add(float64(count))
+}
+
+// 注册队列长度指标, 采集时调用depth获取当前长度
+func (m *Metrics) RegisterQueueDepth(queue string, depth func() int) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "queue_depth",
+		Help:        "队列中待处理的元素数",
+		ConstLabels: prometheus.Labels{"queue": queue},
+	}, func() float64 {
+		return float64(depth())
+	}))
+}
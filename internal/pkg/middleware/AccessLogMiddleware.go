@@ -0,0 +1,29 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gotribe-admin/internal/pkg/common"
+	"time"
+)
+
+// 访问日志中间件, 通过zap记录每个请求并附带请求ID
+// 只记录路由模板(c.FullPath()), 不记录原始路径, 避免日志注入
+func AccessLogMiddleware(log *zap.SugaredLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		startTime := time.Now()
+		c.Next()
+		common.LogWithContext(c.Request.Context(), log).Infow("access",
+			"method", c.Request.Method,
+			"route", c.FullPath(),
+			"status", c.Writer.Status(),
+			"latency", time.Since(startTime).String(),
+			"ip", c.ClientIP(),
+		)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package middleware
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gotribe-admin/internal/app/repository"
+	"gotribe-admin/internal/pkg/apikey"
+	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/pkg/api/known"
+	"gotribe-admin/pkg/api/response"
+)
+
+// 最近使用时间的更新间隔, 避免每次请求都写数据库
+const apiKeyTouchInterval = time.Minute
+
+// API Key认证中间件, 与jwt认证中间件二选一
+// 请求携带API Key时以其服务账号认证, 服务账号以绑定的角色参与之后的casbin鉴权; 未携带时交给jwt认证中间件
+func ApiKeyMiddleware(serviceAccountRepository repository.IServiceAccountRepository, jwtMiddleware gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := apikey.FromRequest(c)
+		if key == "" {
+			jwtMiddleware(c)
+			return
+		}
+		account, err := authenticateApiKey(c, serviceAccountRepository, key)
+		if err != nil {
+			response.Response(c, 401, 401, nil, "API Key认证失败: "+err.Error())
+			c.Abort()
+			return
+		}
+		c.Set(known.SERVICE_ACCOUNT_KEY, *account)
+		// 以服务账号构造当前用户, 之后的中间件与控制器按普通用户处理
+		c.Set("user", model.Admin{
+			Username: account.Name,
+			Status:   account.Status,
+			Creator:  account.Creator,
+			Roles:    []*model.Role{account.Role},
+		})
+		c.Next()
+	}
+}
+
+// 校验API Key, 返回其服务账号
+func authenticateApiKey(c *gin.Context, serviceAccountRepository repository.IServiceAccountRepository, key string) (*model.ServiceAccount, error) {
+	ctx := c.Request.Context()
+	prefix, ok := apikey.Prefix(key)
+	if !ok {
+		return nil, errors.New("API Key无效")
+	}
+	apiKey, err := serviceAccountRepository.GetApiKeyByPrefix(ctx, prefix)
+	if err != nil || !apikey.Verify(key, apiKey.KeyHash) {
+		return nil, errors.New("API Key无效")
+	}
+	now := time.Now()
+	if apiKey.RevokedAt != nil {
+		return nil, errors.New("API Key已被吊销")
+	}
+	if apiKey.ExpiresAt != nil && !now.Before(*apiKey.ExpiresAt) {
+		return nil, errors.New("API Key已过期")
+	}
+	// 只有来自可信代理的请求才读取X-Forwarded-For, 避免伪造X-Forwarded-For绕过白名单
+	ip := c.ClientIP()
+	if apiKey.AllowIPs != "" && !apikey.IPAllowed(strings.Split(apiKey.AllowIPs, ","), ip) {
+		return nil, errors.New("来源IP不在白名单内")
+	}
+	account, err := serviceAccountRepository.GetServiceAccountByID(ctx, apiKey.ServiceAccountID)
+	if err != nil {
+		return nil, errors.New("服务账号不存在")
+	}
+	if account.Status != 1 {
+		return nil, errors.New("服务账号已被禁用")
+	}
+	if account.Role == nil {
+		return nil, errors.New("服务账号绑定的角色不存在")
+	}
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyTouchInterval || apiKey.LastUsedIP != ip {
+		// 记录失败不影响本次请求
+		_ = serviceAccountRepository.UpdateApiKeyLastUsed(ctx, apiKey, ip)
+	}
+	return account, nil
+}
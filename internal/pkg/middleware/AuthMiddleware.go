@@ -6,22 +6,35 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
 	"gotribe-admin/config"
 	"gotribe-admin/internal/app/repository"
 	"gotribe-admin/internal/pkg/common"
+	"gotribe-admin/internal/pkg/loginguard"
 	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/internal/pkg/totp"
 	"gotribe-admin/pkg/api/known"
 	"gotribe-admin/pkg/api/response"
 	"gotribe-admin/pkg/api/vo"
 	"gotribe-admin/pkg/util"
 	"time"
+
+	"go.uber.org/zap"
 )
 
+// 需要两步验证时, login在context中保存challenge, 由unauthorized返回给前端
+const totpChallengeKey = "totpChallenge"
+
+// 用户名或IP被锁定时, login在context中保存锁定信息, 由unauthorized返回429
+const loginLockedKey = "loginLocked"
+
+var errTotpRequired = errors.New("需要两步验证")
+
 // 初始化jwt中间件
-func InitAuth() (*jwt.GinJWTMiddleware, error) {
+func InitAuth(adminRepository repository.IAdminRepository, guard *loginguard.Guard, log *zap.SugaredLogger) (*jwt.GinJWTMiddleware, error) {
 	authMiddleware, err := jwt.New(&jwt.GinJWTMiddleware{
 		Realm:           config.Conf.Jwt.Realm,                                 // jwt标识
 		Key:             []byte(config.Conf.Jwt.Key),                           // 服务端密钥
@@ -29,9 +42,9 @@ func InitAuth() (*jwt.GinJWTMiddleware, error) {
 		MaxRefresh:      time.Hour * time.Duration(config.Conf.Jwt.MaxRefresh), // token最大刷新时间(RefreshToken过期时间=Timeout+MaxRefresh)
 		PayloadFunc:     payloadFunc,                                           // 有效载荷处理
 		IdentityHandler: identityHandler,                                       // 解析Claims
-		Authenticator:   login,                                                 // 校验token的正确性, 处理登录逻辑
+		Authenticator:   login(adminRepository, guard),                         // 校验token的正确性, 处理登录逻辑
 		Authorizator:    authorizator,                                          // 用户登录校验成功处理
-		Unauthorized:    unauthorized,                                          // 用户登录校验失败处理
+		Unauthorized:    unauthorized(log),                                     // 用户登录校验失败处理
 		LoginResponse:   loginResponse,                                         // 登录成功后的响应
 		LogoutResponse:  logoutResponse,                                        // 登出后的响应
 		RefreshResponse: refreshResponse,                                       // 刷新token后的响应
@@ -67,34 +80,62 @@ func identityHandler(c *gin.Context) interface{} {
 }
 
 // 校验token的正确性, 处理登录逻辑
-func login(c *gin.Context) (interface{}, error) {
-	var req vo.RegisterAndLoginRequest
-	// 请求json绑定
-	if err := c.ShouldBind(&req); err != nil {
-		return "", err
-	}
+func login(adminRepository repository.IAdminRepository, guard *loginguard.Guard) func(c *gin.Context) (interface{}, error) {
+	return func(c *gin.Context) (interface{}, error) {
+		var req vo.RegisterAndLoginRequest
+		// 请求json绑定
+		if err := c.ShouldBind(&req); err != nil {
+			return "", err
+		}
 
-	// 密码通过RSA解密
-	decodeData, err := util.RSADecrypt([]byte(req.Password), config.Conf.System.RSAPrivateBytes)
-	if err != nil {
-		return nil, err
-	}
+		// 密码通过RSA解密
+		decodeData, err := util.RSADecrypt([]byte(req.Password), config.Conf.System.RSAPrivateBytes)
+		if err != nil {
+			return nil, err
+		}
 
-	u := &model.Admin{
-		Username: req.Username,
-		Password: string(decodeData),
-	}
+		u := &model.Admin{
+			Username: req.Username,
+			Password: string(decodeData),
+		}
+
+		// 用户名或IP已锁定时不校验密码
+		if locked := guard.Check(c, 0, req.Username); locked != nil {
+			c.Set(loginLockedKey, locked)
+			return nil, locked
+		}
 
-	// 密码校验
-	userRepository := repository.NewAdminRepository()
-	user, err := userRepository.Login(u)
-	if err != nil {
-		return nil, err
+		// 密码校验
+		user, err := adminRepository.Login(c.Request.Context(), u)
+		if err != nil {
+			var adminID uint
+			if user != nil {
+				adminID = user.ID
+			}
+			// 本次失败导致锁定时返回锁定信息
+			if locked := guard.Fail(c, adminID, req.Username, err.Error()); locked != nil {
+				c.Set(loginLockedKey, locked)
+				return nil, locked
+			}
+			return nil, err
+		}
+		// 已启用或角色要求两步验证时不签发token, 返回challenge, 提交验证码后再签发
+		if user.TotpEnabled || totp.Required(*user) {
+			challenge, expires := totp.NewChallenge(user.ID, common.TotpChallengeKey(), common.TotpChallengeTimeout())
+			c.Set(totpChallengeKey, gin.H{
+				"totpRequired":   true,
+				"totpEnabled":    user.TotpEnabled,
+				"challengeToken": challenge,
+				"expires":        expires.Format(known.TIME_FORMAT),
+			})
+			return nil, errTotpRequired
+		}
+		guard.Succeed(c, user.ID, user.Username)
+		// 将用户以json格式写入, payloadFunc/authorizator会使用到
+		return map[string]interface{}{
+			"user": util.Struct2Json(user),
+		}, nil
 	}
-	// 将用户以json格式写入, payloadFunc/authorizator会使用到
-	return map[string]interface{}{
-		"user": util.Struct2Json(user),
-	}, nil
 }
 
 // 用户登录校验成功处理
@@ -112,9 +153,25 @@ func authorizator(data interface{}, c *gin.Context) bool {
 }
 
 // 用户登录校验失败处理
-func unauthorized(c *gin.Context, code int, message string) {
-	common.Log.Debugf("JWT认证失败, 错误码: %d, 错误信息: %s", code, message)
-	response.Response(c, code, code, nil, fmt.Sprintf("JWT认证失败, 错误码: %d, 错误信息: %s", code, message))
+func unauthorized(log *zap.SugaredLogger) func(c *gin.Context, code int, message string) {
+	return func(c *gin.Context, code int, message string) {
+		// 密码正确但需要两步验证, 返回challenge而不是认证失败
+		if challenge, ok := c.Value(totpChallengeKey).(gin.H); ok {
+			message = "请输入两步验证码"
+			if challenge["totpEnabled"] == false {
+				message = "角色要求启用两步验证, 请先绑定验证器"
+			}
+			response.Success(c, challenge, message)
+			return
+		}
+		// 登录失败次数过多被锁定
+		if locked, ok := c.Value(loginLockedKey).(*loginguard.LockedError); ok {
+			loginguard.Respond(c, locked)
+			return
+		}
+		common.LogWithContext(c.Request.Context(), log).Debugf("JWT认证失败, 错误码: %d, 错误信息: %s", code, message)
+		response.Response(c, code, code, nil, fmt.Sprintf("JWT认证失败, 错误码: %d, 错误信息: %s", code, message))
+	}
 }
 
 // 登录成功后的响应
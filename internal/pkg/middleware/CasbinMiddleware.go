@@ -6,24 +6,18 @@
 package middleware
 
 import (
+	"github.com/casbin/casbin/v2"
 	"github.com/gin-gonic/gin"
-	"gotribe-admin/config"
 	"gotribe-admin/internal/app/repository"
-	"gotribe-admin/internal/pkg/common"
-	"gotribe-admin/pkg/api/known"
+	"gotribe-admin/internal/pkg/metrics"
+	"gotribe-admin/internal/pkg/permission"
 	"gotribe-admin/pkg/api/response"
-
-	"strings"
-	"sync"
 )
 
-var checkLock sync.Mutex
-
 // Casbin中间件, 基于RBAC的权限访问控制模型
-func CasbinMiddleware() gin.HandlerFunc {
+func CasbinMiddleware(adminRepository repository.IAdminRepository, enforcer *casbin.SyncedCachedEnforcer, m *metrics.Metrics) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ur := repository.NewAdminRepository()
-		admin, err := ur.GetCurrentAdmin(c)
+		admin, err := adminRepository.GetCurrentAdmin(c)
 		if err != nil {
 			response.Response(c, 401, 401, nil, "用户未登录")
 			c.Abort()
@@ -35,26 +29,19 @@ func CasbinMiddleware() gin.HandlerFunc {
 			return
 		}
 		// 增加超级管理员账号
-		if admin.ID == known.DEFAULT_ID {
+		if permission.IsSuperAdmin(admin) {
 			return
 		}
-		// 获得用户的全部角色
-		roles := admin.Roles
 		// 获得用户全部未被禁用的角色的Keyword
-		var subs []string
-		for _, role := range roles {
-			if role.Status == known.DEFAULT_ID {
-				subs = append(subs, role.Keyword)
-			}
-		}
+		subs, _ := permission.Subjects(admin)
 		// 获得请求路径URL
-		//obj := strings.Replace(c.Request.URL.Path, "/"+config.Conf.System.UrlPathPrefix, "", 1)
-		obj := strings.TrimPrefix(c.FullPath(), "/"+config.Conf.System.UrlPathPrefix)
+		obj := permission.Object(c.FullPath())
 		// 获取请求方式
 		act := c.Request.Method
 
-		isPass := check(subs, obj, act)
+		isPass := permission.Allowed(enforcer, subs, obj, act)
 		if !isPass {
+			m.CasbinDenied(act, c.FullPath())
 			response.Response(c, 401, 401, nil, "没有权限")
 			c.Abort()
 			return
@@ -63,18 +50,3 @@ func CasbinMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
-
-func check(subs []string, obj string, act string) bool {
-	// 同一时间只允许一个请求执行校验, 否则可能会校验失败
-	checkLock.Lock()
-	defer checkLock.Unlock()
-	isPass := false
-	for _, sub := range subs {
-		pass, _ := common.CasbinEnforcer.Enforce(sub, obj, act)
-		if pass {
-			isPass = true
-			break
-		}
-	}
-	return isPass
-}
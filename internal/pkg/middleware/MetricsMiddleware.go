@@ -0,0 +1,62 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package middleware
+
+import (
+	"crypto/subtle"
+	"github.com/gin-gonic/gin"
+	"gotribe-admin/internal/pkg/metrics"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 记录每个请求的次数与耗时, 按路由模板(c.FullPath())聚合
+func MetricsMiddleware(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		startTime := time.Now()
+		c.Next()
+		m.ObserveRequest(c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(startTime))
+	}
+}
+
+// 指标接口访问控制, 携带正确的token或来源IP在白名单内才可访问
+func MetricsAuthMiddleware(token string, allowIPs []string) gin.HandlerFunc {
+	var nets []*net.IPNet
+	for _, allow := range allowIPs {
+		allow = strings.TrimSpace(allow)
+		if !strings.Contains(allow, "/") {
+			if strings.Contains(allow, ":") {
+				allow += "/128"
+			} else {
+				allow += "/32"
+			}
+		}
+		if _, ipNet, err := net.ParseCIDR(allow); err == nil {
+			nets = append(nets, ipNet)
+		}
+	}
+	return func(c *gin.Context) {
+		if token != "" {
+			auth := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(auth), []byte(token)) == 1 {
+				c.Next()
+				return
+			}
+		}
+		// 只有来自可信代理的请求才读取X-Forwarded-For, 经本机代理转发的外部请求不会被当作127.0.0.1放行
+		if ip := net.ParseIP(c.ClientIP()); ip != nil {
+			for _, ipNet := range nets {
+				if ipNet.Contains(ip) {
+					c.Next()
+					return
+				}
+			}
+		}
+		c.AbortWithStatus(http.StatusForbidden)
+	}
+}
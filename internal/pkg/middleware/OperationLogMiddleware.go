@@ -6,9 +6,15 @@
 package middleware
 
 import (
+	"bytes"
+	"context"
 	"gotribe-admin/config"
 	"gotribe-admin/internal/app/repository"
 	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/internal/pkg/oplog"
+	"gotribe-admin/pkg/api/known"
+	"io"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 
@@ -16,18 +22,21 @@ import (
 	"time"
 )
 
-// 操作日志channel
-var OperationLogChan = make(chan *model.OperationLog, 30)
-
 // 定义静态资源路径前缀
 var skipPaths = []string{
 	"/static/",
 	"/assets/",
 	"/images/",
 	"/favicon.ico",
+	"/uploads/",
 }
 
-func OperationLogMiddleware() gin.HandlerFunc {
+// 浏览器标识最大长度, 与数据库字段一致
+const maxUserAgentLength = 255
+
+// 记录操作日志, 由writer在后台批量写入数据库
+// 请求体与响应体经capture截断、脱敏后一并记录, 使用SkipBodyCaptureMiddleware的路由不记录
+func OperationLogMiddleware(apiRepository repository.IApiRepository, writer *oplog.Writer, capture *oplog.Capture) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 获取访问路径
 		path := strings.TrimPrefix(c.FullPath(), "/"+config.Conf.System.UrlPathPrefix)
@@ -38,6 +47,14 @@ func OperationLogMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		requestType := c.GetHeader("Content-Type")
+		requestBody, requestTruncated := readRequestBody(c, requestType, capture.RequestBodyLimit())
+		var bodyWriter *responseBodyWriter
+		if limit := capture.ResponseBodyLimit(); limit > 0 {
+			bodyWriter = &responseBodyWriter{ResponseWriter: c.Writer, limit: limit}
+			c.Writer = bodyWriter
+		}
+
 		startTime := time.Now()
 		c.Next()
 		timeCost := time.Since(startTime).Milliseconds()
@@ -46,9 +63,10 @@ func OperationLogMiddleware() gin.HandlerFunc {
 		method := c.Request.Method
 
 		// 获取接口描述
-		apiDesc := getApiDescription(path, method)
+		apiDesc := getApiDescription(c.Request.Context(), apiRepository, path, method)
 
 		log := &model.OperationLog{
+			RequestID: c.GetString(known.X_REQUEST_ID_KEY),
 			Username:  username,
 			Ip:        c.ClientIP(),
 			Method:    method,
@@ -57,17 +75,29 @@ func OperationLogMiddleware() gin.HandlerFunc {
 			Status:    c.Writer.Status(),
 			StartTime: startTime,
 			TimeCost:  timeCost,
+			UserAgent: truncateString(c.Request.UserAgent(), maxUserAgentLength),
+		}
+		// 签发凭证等路由不记录请求体与响应体, 不依赖字段名脱敏
+		if !c.GetBool(known.SKIP_BODY_CAPTURE_KEY) {
+			log.RequestBody = capture.Mask(requestType, requestBody, requestTruncated)
+			if bodyWriter != nil {
+				if responseType := bodyWriter.Header().Get("Content-Type"); oplog.Capturable(responseType) {
+					log.ResponseBody = capture.Mask(responseType, bodyWriter.body.Bytes(), bodyWriter.truncated)
+				}
+			}
 		}
 
 		// 异步写入日志
-		select {
-		case OperationLogChan <- log:
-		default:
-			// 如果channel已满，可以选择记录错误或使用非阻塞方式处理
-			go func() {
-				OperationLogChan <- log
-			}()
-		}
+		writer.Write(log)
+	}
+}
+
+// 不记录请求体与响应体, 注册在签发token、API Key、两步验证密钥等凭证的路由上
+// 仍记录操作日志的其余字段
+func SkipBodyCaptureMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(known.SKIP_BODY_CAPTURE_KEY, true)
+		c.Next()
 	}
 }
 
@@ -101,11 +131,64 @@ func getUsername(c *gin.Context) string {
 }
 
 // 获取API描述
-func getApiDescription(path, method string) string {
-	apiRepository := repository.NewApiRepository()
-	apiDesc, err := apiRepository.GetApiDescByPath(path, method)
+func getApiDescription(ctx context.Context, apiRepository repository.IApiRepository, path, method string) string {
+	apiDesc, err := apiRepository.GetApiDescByPath(ctx, path, method)
 	if err != nil {
 		return ""
 	}
 	return apiDesc
 }
+
+// 请求体, 读取过的部分与剩余部分拼接后交给后续处理
+type requestBody struct {
+	io.Reader
+	io.Closer
+}
+
+// 读取请求体的前limit字节用于记录, 不改变后续处理读到的内容
+func readRequestBody(c *gin.Context, contentType string, limit int) ([]byte, bool) {
+	body := c.Request.Body
+	if limit == 0 || body == nil || body == http.NoBody || !oplog.Capturable(contentType) {
+		return nil, false
+	}
+	head, _ := io.ReadAll(io.LimitReader(body, int64(limit)+1))
+	c.Request.Body = requestBody{Reader: io.MultiReader(bytes.NewReader(head), body), Closer: body}
+	if len(head) > limit {
+		return head[:limit], true
+	}
+	return head, false
+}
+
+// 记录响应体前limit字节的ResponseWriter
+type responseBodyWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (w *responseBodyWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseBodyWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *responseBodyWriter) capture(b []byte) {
+	if remain := w.limit - w.body.Len(); len(b) > remain {
+		w.truncated = true
+		b = b[:remain]
+	}
+	w.body.Write(b)
+}
+
+// 按字节截断字符串, 不截断多字节字符
+func truncateString(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "")
+}
@@ -0,0 +1,36 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"gotribe-admin/internal/app/repository"
+	"gotribe-admin/internal/pkg/common"
+	"gotribe-admin/pkg/api/response"
+)
+
+// 项目隔离中间件, 需放在casbin鉴权中间件之后
+// 将当前用户可管理的项目写入请求context, 之后的数据库操作只作用于这些项目
+func ProjectScopeMiddleware(adminRepository repository.IAdminRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		admin, err := adminRepository.GetCurrentAdmin(c)
+		if err != nil {
+			response.Response(c, 401, 401, nil, "用户未登录")
+			c.Abort()
+			return
+		}
+		projectIDs, restricted, err := adminRepository.GetAdminProjectScope(c.Request.Context(), admin)
+		if err != nil {
+			response.Fail(c, nil, "获取用户可管理的项目失败: "+err.Error())
+			c.Abort()
+			return
+		}
+		if restricted {
+			c.Request = c.Request.WithContext(common.WithProjectScope(c.Request.Context(), projectIDs))
+		}
+		c.Next()
+	}
+}
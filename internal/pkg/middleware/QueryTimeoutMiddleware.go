@@ -0,0 +1,27 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package middleware
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+// 为请求的context设置超时, 控制器将c.Request.Context()传给仓储,
+// 客户端断开或超时后正在执行的数据库查询会被取消
+func QueryTimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gotribe-admin/internal/pkg/common"
+	"gotribe-admin/pkg/api/known"
+	"regexp"
+)
+
+// 客户端传入的请求ID只接受有限长度的常见字符, 避免日志注入
+var requestIDRe = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// 请求ID中间件, 沿用客户端或网关传入的X-Request-ID, 没有或不合法时生成新的ID
+// 请求ID写入gin上下文、请求context与响应头
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(known.X_REQUEST_ID_KEY)
+		if !requestIDRe.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Set(known.X_REQUEST_ID_KEY, requestID)
+		c.Request = c.Request.WithContext(common.WithRequestID(c.Request.Context(), requestID))
+		c.Header(known.X_REQUEST_ID_KEY, requestID)
+		c.Next()
+	}
+}
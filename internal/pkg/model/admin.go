@@ -16,4 +16,11 @@ type Admin struct {
 	Status       uint    `gorm:"type:tinyint(1);default:1;comment:1正常, 2禁用" json:"status"`
 	Creator      string  `gorm:"type:varchar(20);" json:"creator"`
 	Roles        []*Role `gorm:"many2many:admin_roles" json:"roles"`
+	// 可管理的项目, 与角色的项目合并; 都未分配且角色不可管理全部项目时不能管理任何项目
+	Projects []*Project `gorm:"many2many:admin_projects;references:ProjectID;joinReferences:ProjectID" json:"projects"`
+	// 两步验证, 密钥已生成但未启用时为绑定中
+	TotpSecret        string `gorm:"type:varchar(64);comment:两步验证密钥" json:"-"`
+	TotpEnabled       bool   `gorm:"default:false;comment:是否已启用两步验证" json:"totpEnabled"`
+	TotpRecoveryCodes string `gorm:"type:varchar(1000);comment:恢复码哈希, 以逗号分隔" json:"-"`
+	TotpLastStep      int64  `gorm:"default:0;comment:最近一次使用的验证码周期, 防止重复使用" json:"-"`
 }
@@ -0,0 +1,15 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package model
+
+import "time"
+
+// casbin策略版本, 策略变更时递增, 各实例轮询版本号重新加载策略
+type CasbinVersion struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Version   uint64    `gorm:"not null;default:0;comment:策略版本号" json:"version"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
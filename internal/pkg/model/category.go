@@ -26,7 +26,9 @@ type Category struct {
 }
 
 func (c *Category) BeforeCreate(tx *gorm.DB) error {
-	c.CategoryID = gid.GenShortID()
+	if c.CategoryID == "" {
+		c.CategoryID = gid.GenShortID()
+	}
 
 	return nil
 }
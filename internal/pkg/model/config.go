@@ -25,7 +25,9 @@ type Config struct {
 }
 
 func (c *Config) BeforeCreate(tx *gorm.DB) error {
-	c.ConfigID = gid.GenShortID()
+	if c.ConfigID == "" {
+		c.ConfigID = gid.GenShortID()
+	}
 
 	return nil
 }
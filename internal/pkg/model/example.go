@@ -14,7 +14,7 @@ type Example struct {
 	Model
 	ExampleID   string `gorm:"type:char(10);uniqueIndex;comment:唯一字符ID/分布式ID" json:"exampleID"`
 	ProjectID   string `gorm:"type:char(10);not null;index;comment:项目ID;" json:"projectID"`
-	Username    string `gorm:"type:varchar(30);not null;index:idx_username;comment:用户名" json:"username"`
+	Username    string `gorm:"type:varchar(30);not null;index:idx_example_username;comment:用户名" json:"username"`
 	Title       string `gorm:"type:varchar(255);not null;comment:标题" json:"title"`
 	Content     string `gorm:"not null;type:longtext;not null;comment:内容" json:"content"`
 	Description string `gorm:"not null;size:300;not null;comment:描述" json:"description"`
@@ -0,0 +1,20 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package model
+
+import "time"
+
+// 登录失败计数与锁定, 按用户名或IP各一条, 多个实例共享
+type LoginLock struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	LockKey     string     `gorm:"type:varchar(128);not null;uniqueIndex;comment:user:用户名 或 ip:IP" json:"lockKey"`
+	Failures    int        `gorm:"not null;default:0;comment:统计窗口内的连续失败次数" json:"failures"`
+	Lockouts    int        `gorm:"not null;default:0;comment:已锁定的次数, 决定下次锁定时长" json:"lockouts"`
+	LastFailure *time.Time `gorm:"comment:最近一次失败时间" json:"lastFailure"`
+	LockedUntil *time.Time `gorm:"comment:锁定截止时间" json:"lockedUntil"`
+	ExpiresAt   time.Time  `gorm:"index;comment:过期时间, 过期后失败次数与锁定等级清零" json:"expiresAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
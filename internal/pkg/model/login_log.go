@@ -0,0 +1,25 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package model
+
+// 登录结果
+const (
+	LoginSuccess uint = 1 // 登录成功
+	LoginFailure uint = 2 // 登录失败
+)
+
+// 管理员登录日志, 记录密码登录与两步验证登录的结果
+type LoginLog struct {
+	Model
+	RequestID  string `gorm:"type:varchar(64);index;comment:请求ID" json:"requestId"`
+	AdminID    uint   `gorm:"index;comment:管理员ID, 用户不存在时为0" json:"adminId"`
+	Username   string `gorm:"type:varchar(64);index;comment:登录名" json:"username"`
+	Ip         string `gorm:"type:varchar(64);index;comment:Ip地址" json:"ip"`
+	IpLocation string `gorm:"type:varchar(100);comment:Ip所在地" json:"ipLocation"`
+	UserAgent  string `gorm:"type:varchar(255);comment:浏览器标识" json:"userAgent"`
+	Status     uint   `gorm:"type:tinyint(1);comment:结果(1成功, 2失败)" json:"status"`
+	Reason     string `gorm:"type:varchar(100);comment:失败原因" json:"reason"`
+}
@@ -7,12 +7,30 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
 )
 
+type ad struct {
+	Model       baseModel `gorm:"embedded"`
+	AdID        string    `gorm:"type:char(10);uniqueIndex;comment:唯一字符ID/分布式ID"`
+	Title       string    `gorm:"type:varchar(255);not null;comment:标题"`
+	Description string    `gorm:"not null;size:300;not null;comment:描述"`
+	URL         string    `gorm:"type:varchar(255);not null;comment:广告链接"`
+	URLType     uint      `gorm:"type:tinyint;default:1;comment:1.链接，2.文章，3.商品"`
+	Sort        uint      `gorm:"type:tinyint;default:1;comment:排序"`
+	Status      uint      `gorm:"type:tinyint;not null;default:1;comment:状态，1-未发布；2-发布"`
+	SceneID     string    `gorm:"type:char(10);Index;comment:场景 ID"`
+	Ext         string    `gorm:"type:text;comment:扩展字段"`
+	Image       string    `gorm:"type:varchar(255);comment:图片地址"`
+	Video       string    `gorm:"type:varchar(255);comment:视频地址"`
+}
+
+func (ad) TableName() string {
+	return "ad"
+}
+
 // 自动迁移表结构
-func adMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.Ad{},
+func adMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&ad{},
 	)
 }
@@ -7,12 +7,23 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
 )
 
+type adScene struct {
+	Model       baseModel `gorm:"embedded"`
+	AdSceneID   string    `gorm:"type:char(10);uniqueIndex;comment:唯一字符ID/分布式ID"`
+	Title       string    `gorm:"type:varchar(255);not null;comment:标题"`
+	Description string    `gorm:"not null;size:300;not null;comment:描述"`
+	ProjectID   string    `gorm:"type:char(10);not null;index;comment:项目ID;"`
+}
+
+func (adScene) TableName() string {
+	return "ad_scene"
+}
+
 // 自动迁移表结构
-func adSceneMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.AdScene{},
+func adSceneMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&adScene{},
 	)
 }
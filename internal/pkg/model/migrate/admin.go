@@ -7,12 +7,28 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
 )
 
+type admin struct {
+	Model        baseModel `gorm:"embedded"`
+	Username     string    `gorm:"type:varchar(20);not null;unique"`
+	Password     string    `gorm:"size:255;not null"`
+	Mobile       string    `gorm:"type:varchar(11);not null;unique"`
+	Avatar       string    `gorm:"type:varchar(255)"`
+	Nickname     *string   `gorm:"type:varchar(20)"`
+	Introduction *string   `gorm:"type:varchar(255)"`
+	Status       uint      `gorm:"type:tinyint(1);default:1;comment:1正常, 2禁用"`
+	Creator      string    `gorm:"type:varchar(20);"`
+	Roles        []*role   `gorm:"many2many:admin_roles"`
+}
+
+func (admin) TableName() string {
+	return "admin"
+}
+
 // 自动迁移表结构
-func adminMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.Admin{},
+func adminMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&admin{},
 	)
 }
@@ -7,12 +7,24 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
 )
 
+type api struct {
+	Model    baseModel `gorm:"embedded"`
+	Method   string    `gorm:"type:varchar(20);comment:请求方式"`
+	Path     string    `gorm:"type:varchar(100);comment:访问路径"`
+	Category string    `gorm:"type:varchar(50);comment:所属类别"`
+	Desc     string    `gorm:"type:varchar(100);comment:说明"`
+	Creator  string    `gorm:"type:varchar(20);comment:创建人"`
+}
+
+func (api) TableName() string {
+	return "api"
+}
+
 // 自动迁移表结构
-func apiMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.Api{},
+func apiMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&api{},
 	)
 }
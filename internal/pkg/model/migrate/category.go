@@ -7,12 +7,29 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
 )
 
+type category struct {
+	Model       baseModel `gorm:"embedded"`
+	CategoryID  string    `gorm:"type:char(10);uniqueIndex;comment:唯一字符ID/分布式ID"`
+	ParentID    *uint     `gorm:"default:0;comment:父菜单编号(编号为0时表示根菜单)"`
+	Sort        uint      `gorm:"default:1;comment:排序"`
+	Icon        string    `gorm:"type:varchar(255);comment:图标"`
+	Title       string    `gorm:"type:varchar(255);not null;comment:'标题'"`
+	Path        string    `gorm:"type:varchar(100);comment:url"`
+	Hidden      uint      `gorm:"type:tinyint(1);default:1;comment:1显示，2隐藏"`
+	Description string    `gorm:"type:varchar(300);not null;comment:描述"`
+	Ext         string    `gorm:"type:text;comment:扩展字段"`
+	Status      uint      `gorm:"type:tinyint;not null;default:1;comment:状态，1-正常；2-禁用"`
+}
+
+func (category) TableName() string {
+	return "category"
+}
+
 // 自动迁移表结构
-func categoryMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.Category{},
+func categoryMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&category{},
 	)
 }
@@ -7,12 +7,27 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
 )
 
+type column struct {
+	Model       baseModel `gorm:"embedded"`
+	ColumnID    string    `gorm:"type:char(10);not null;uniqueIndex:idx_column_column_id;comment:字符ID，分布式ID"`
+	ProjectID   string    `gorm:"type:char(10);not null;index;comment:项目ID;"`
+	Title       string    `gorm:"type:varchar(30);not null;comment:标题"`
+	Description string    `gorm:"type:varchar(300);comment:描述"`
+	Icon        string    `gorm:"type:varchar(300);comment:图片"`
+	Info        string    `gorm:"type:longtext;comment:内容"`
+	Ext         string    `gorm:"type:text;comment:扩展字段"`
+	Status      uint8     `gorm:"type:tinyint;not null;default:1;comment:状态，1-正常；2-禁用"`
+}
+
+func (column) TableName() string {
+	return "column"
+}
+
 // 自动迁移表结构
-func columnMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.Column{},
+func columnMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&column{},
 	)
 }
@@ -7,12 +7,38 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
 )
 
+type comment struct {
+	Model       baseModel `gorm:"embedded"`
+	CommentID   string    `gorm:"type:char(10);uniqueIndex;comment:唯一字符ID/分布式ID"`
+	ProjectID   string    `gorm:"type:char(10);not null;index;comment:项目ID;"`
+	Content     string    `gorm:"not null;type:longtext;not null;comment:内容"`
+	HtmlContent string    `gorm:"not null;type:longtext;not null;comment:HTML内容"`
+	Status      uint      `gorm:"type:tinyint;not null;index;default:1;comment:状态，1-待审核；2-审核通过"`
+	ObjectID    string    `gorm:"type:char(10);not null;index;comment:评论主题ID"`
+	ObjectType  uint      `gorm:"type:tinyint;not null;default:1;index;comment:评论对象类型，1-文章；2-商品"`
+	Type        uint      `gorm:"type:tinyint;not null;default:1;comment:评论类型，1-评论；2-回复"`
+	UserID      string    `gorm:"type:char(10);not null;index;comment:用户ID"`
+	ToUserID    string    `gorm:"type:char(10);not null;index;comment:被评论用户ID"`
+	ParentID    int       `gorm:"type:int;not null;default:0;comment:父评论ID"`
+	ReplyToID   int       `gorm:"type:int;not null;default:0;comment:回复的评论ID"`
+	Hot         int       `gorm:"type:int;default:0;comment:热度"`
+	Like        int       `gorm:"type:int;default:0;comment:点赞数"`
+	Dislike     int       `gorm:"type:int;default:0;comment:踩数"`
+	IP          string    `gorm:"type:varchar(255);not null;comment:IP地址"`
+	Country     string    `gorm:"type:varchar(255);not null;comment:国家"`
+	RegionName  string    `gorm:"type:varchar(255);not null;comment:地区"`
+	City        string    `gorm:"type:varchar(255);not null;comment:城市"`
+}
+
+func (comment) TableName() string {
+	return "comment"
+}
+
 // 自动迁移表结构
-func commentMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.Comment{},
+func commentMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&comment{},
 	)
 }
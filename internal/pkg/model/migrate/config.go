@@ -7,12 +7,28 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
 )
 
+type config struct {
+	Model       baseModel `gorm:"embedded"`
+	ConfigID    string    `gorm:"type:char(10);not null;uniqueIndex;comment:字符ID，分布式 ID;"`
+	ProjectID   string    `gorm:"type:char(10);not null;index;comment:项目ID;"`
+	Alias       string    `gorm:"type:varchar(20);not null;uniqueIndex;comment:别名"`
+	Title       string    `gorm:"type:varchar(30);not null;comment:标题"`
+	Description string    `gorm:"type:varchar(300);not null;comment:描述"`
+	Type        uint      `gorm:"type:tinyint;not null;default:1;comment:类型，1表示普通配置2:json类型"`
+	Info        string    `gorm:"type:longtext;not null;comment:内容"`
+	MDContent   string    `gorm:"type:longtext;not null;comment:MD内容"`
+	Status      uint      `gorm:"type:tinyint;not null;default:1;comment:状态，1-正常；2-禁用"`
+}
+
+func (config) TableName() string {
+	return "config"
+}
+
 // 自动迁移表结构
-func configMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.Config{},
+func configMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&config{},
 	)
 }
@@ -7,12 +7,26 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
 )
 
+type example struct {
+	Model       baseModel `gorm:"embedded"`
+	ExampleID   string    `gorm:"type:char(10);uniqueIndex;comment:唯一字符ID/分布式ID"`
+	ProjectID   string    `gorm:"type:char(10);not null;index;comment:项目ID;"`
+	Username    string    `gorm:"type:varchar(30);not null;index:idx_example_username;comment:用户名"`
+	Title       string    `gorm:"type:varchar(255);not null;comment:标题"`
+	Content     string    `gorm:"not null;type:longtext;not null;comment:内容"`
+	Description string    `gorm:"not null;size:300;not null;comment:描述"`
+	Status      uint8     `gorm:"type:tinyint;not null;default:1;comment:状态，1-正常；2-禁用"`
+}
+
+func (example) TableName() string {
+	return "example"
+}
+
 // 自动迁移表结构
-func exampleMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.Example{},
+func exampleMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&example{},
 	)
 }
@@ -7,12 +7,24 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
 )
 
+type feedback struct {
+	Model     baseModel `gorm:"embedded"`
+	Title     string    `gorm:"type:varchar(255);uniqueIndex;not null;comment:标题"`
+	Content   string    `gorm:"type:longtext;comment:内容"`
+	Phone     string    `gorm:"type:varchar(20);comment:电话"`
+	UserID    string    `gorm:"type:char(10);Index;comment:用户ID"`
+	ProjectID string    `gorm:"type:char(10);Index;comment:项目 ID"`
+}
+
+func (feedback) TableName() string {
+	return "feedback"
+}
+
 // 自动迁移表结构
-func feedbackMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.Feedback{},
+func feedbackMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&feedback{},
 	)
 }
@@ -7,12 +7,35 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
 )
 
+type menu struct {
+	Model      baseModel `gorm:"embedded"`
+	Name       string    `gorm:"type:varchar(50);comment:菜单名称(英文名, 可用于国际化)"`
+	Title      string    `gorm:"type:varchar(50);comment:菜单标题(无法国际化时使用)"`
+	Icon       *string   `gorm:"type:varchar(50);comment:菜单图标"`
+	Path       string    `gorm:"type:varchar(100);comment:菜单访问路径"`
+	Redirect   *string   `gorm:"type:varchar(100);comment:重定向路径"`
+	Component  string    `gorm:"type:varchar(100);comment:前端组件路径"`
+	Sort       uint      `gorm:"type:int(3) unsigned;default:999;comment:菜单顺序(1-999)"`
+	Status     uint      `gorm:"type:tinyint(1);default:1;comment:菜单状态(正常/禁用, 默认正常)"`
+	Hidden     uint      `gorm:"type:tinyint(1);default:2;comment:菜单在侧边栏隐藏(1隐藏，2显示)"`
+	NoCache    uint      `gorm:"type:tinyint(1);default:2;comment:菜单是否被 <keep-alive> 缓存(1不缓存，2缓存)"`
+	AlwaysShow uint      `gorm:"type:tinyint(1);default:2;comment:忽略之前定义的规则，一直显示根路由(1忽略，2不忽略)"`
+	Breadcrumb uint      `gorm:"type:tinyint(1);default:1;comment:面包屑可见性(可见/隐藏, 默认可见)"`
+	ActiveMenu *string   `gorm:"type:varchar(100);comment:在其它路由时，想在侧边栏高亮的路由"`
+	ParentID   *uint     `gorm:"default:0;comment:父菜单编号(编号为0时表示根菜单)"`
+	Creator    string    `gorm:"type:varchar(20);comment:创建人"`
+	Roles      []*role   `gorm:"many2many:role_menus;"` // 角色菜单多对多关系
+}
+
+func (menu) TableName() string {
+	return "menu"
+}
+
 // 自动迁移表结构
-func menuMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.Menu{},
+func menuMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&menu{},
 	)
 }
@@ -6,69 +6,182 @@
 package migrate
 
 import (
+	"fmt"
 	"gorm.io/gorm"
+	"gotribe-admin/internal/pkg/model"
+	"sort"
+	"time"
 )
 
-// 自动迁移表结构
-func DBAutoMigrate(db *gorm.DB) {
-	// user表
-	userMigrate(db)
-	// admin表
-	adminMigrate(db)
-	// role表
-	roleMigrate(db)
-	// menu表
-	menuMigrate(db)
-	// api表
-	apiMigrate(db)
-	// operationLog表
-	operationLogMigrate(db)
-	// post
-	postMigrate(db)
-	// example 示例表
-	exampleMigrate(db)
-	// config表,用于自定义配置
-	configMigrate(db)
-	// tag表
-	tagMigrate(db)
-	// resource 资源表
-	resourceMigrate(db)
-	// 分类表
-	categoryMigrate(db)
-	// 项目表
-	projectMigrate(db)
-	// 专栏表
-	columnMigrate(db)
-	// 推广场景
-	adSceneMigrate(db)
-	// 广告内容
-	adMigrate(db)
-	// 评论表
-	commentMigrate(db)
-	// 积分相关表
-	pointMigrate(db)
-	// 商品分类
-	productCategoryMigrate(db)
-	// 商品类型（规格组）
-	productTypeMigrate(db)
-	// 商品规格
-	productSpecMigrate(db)
-	// 商品规格属性
-	productSpecItemMigrate(db)
-	// SPU表
-	productMigrate(db)
-	// SKU表
-	productSKUMigrate(db)
-	// 订单表
-	orderMigrate(db)
-	// 订单日志表
-	orderLogMigrate(db)
-	// 系统设置
-	systemConfigMigrate(db)
-	// 第三方帐号表
-	thirdPartyAccountsMigrate(db)
-	// 反馈表
-	feedbackMigrate(db)
-	// 用户事件表
-	userEventMigrate(db)
+// 版本化的数据库迁移
+// 已发布的迁移不可再修改, 表结构变更需新增一个版本号更大的迁移, 并同时提供Up与Down
+// 建表时使用迁移文件内的结构体快照, 不要对model中的模型执行AutoMigrate, 否则模型变更后旧迁移会提前创建出新字段
+type Migration struct {
+	Version     uint                    // 版本号, 递增且唯一
+	Description string                  // 迁移说明
+	Up          func(tx *gorm.DB) error // 升级
+	Down        func(tx *gorm.DB) error // 回滚
+}
+
+// 迁移状态
+type MigrationStatus struct {
+	Version     uint
+	Description string
+	Applied     bool
+	AppliedAt   *time.Time
+}
+
+// 全部迁移, 新迁移追加到末尾
+var migrations = []*Migration{
+	initSchemaMigration,
+	operationLogRequestIDMigration,
+	operationLogBodyMigration,
+	geoIPLocationMigration,
+	projectScopeMigration,
+	casbinVersionMigration,
+	serviceAccountMigration,
+	totpMigration,
+	loginLogMigration,
+	loginLockMigration,
+	roleAllProjectsMigration,
+	legacyIndexMigration,
+	roleSuspendedParentMigration,
+}
+
+// 按版本号升序返回全部迁移
+func Migrations() []*Migration {
+	list := make([]*Migration, len(migrations))
+	copy(list, migrations)
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Version < list[j].Version
+	})
+	return list
+}
+
+// 执行全部未应用的迁移, 返回本次执行的迁移
+func Up(db *gorm.DB) ([]*Migration, error) {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+	var done []*Migration
+	for _, m := range Migrations() {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&model.SchemaMigration{
+				Version:     m.Version,
+				Description: m.Description,
+				AppliedAt:   time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("执行迁移%d(%s)失败: %w", m.Version, m.Description, err)
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// 按版本号倒序回滚最近steps个已应用的迁移, 返回本次回滚的迁移
+func Down(db *gorm.DB, steps int) ([]*Migration, error) {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+	list := Migrations()
+	var done []*Migration
+	for i := len(list) - 1; i >= 0 && len(done) < steps; i-- {
+		m := list[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if m.Down == nil {
+			return done, fmt.Errorf("迁移%d(%s)不支持回滚", m.Version, m.Description)
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&model.SchemaMigration{}, m.Version).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("回滚迁移%d(%s)失败: %w", m.Version, m.Description, err)
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// 全部迁移的执行状态
+func Status(db *gorm.DB) ([]MigrationStatus, error) {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+	var list []MigrationStatus
+	for _, m := range Migrations() {
+		status := MigrationStatus{Version: m.Version, Description: m.Description}
+		if record, ok := applied[m.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = &record.AppliedAt
+			delete(applied, m.Version)
+		}
+		list = append(list, status)
+	}
+	// 数据库中存在但当前程序中没有的版本(通常是用更新的版本升级后又回退了程序)
+	for _, record := range applied {
+		appliedAt := record.AppliedAt
+		list = append(list, MigrationStatus{
+			Version:     record.Version,
+			Description: record.Description + " (未知版本)",
+			Applied:     true,
+			AppliedAt:   &appliedAt,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Version < list[j].Version
+	})
+	return list, nil
+}
+
+// 读取已应用的版本, 版本表不存在时自动创建
+func appliedVersions(db *gorm.DB) (map[uint]model.SchemaMigration, error) {
+	if err := db.AutoMigrate(&model.SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("创建schema_migrations表失败: %w", err)
+	}
+	var records []model.SchemaMigration
+	if err := db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[uint]model.SchemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// 依次执行, 遇到错误立即返回
+func runAll(db *gorm.DB, fns ...func(db *gorm.DB) error) error {
+	for _, fn := range fns {
+		if err := fn(db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 校验迁移版本号不重复
+func init() {
+	seen := make(map[uint]bool, len(migrations))
+	for _, m := range migrations {
+		if seen[m.Version] {
+			panic(fmt.Sprintf("数据库迁移版本号重复: %d", m.Version))
+		}
+		seen[m.Version] = true
+	}
 }
@@ -7,12 +7,30 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
+	"time"
 )
 
+type operationLog struct {
+	Model      baseModel `gorm:"embedded"`
+	Username   string    `gorm:"type:varchar(20);comment:用户登录名"`
+	Ip         string    `gorm:"type:varchar(20);comment:Ip地址"`
+	IpLocation string    `gorm:"type:varchar(20);comment:Ip所在地"`
+	Method     string    `gorm:"type:varchar(20);comment:请求方式"`
+	Path       string    `gorm:"type:varchar(100);comment:访问路径"`
+	Desc       string    `gorm:"type:varchar(100);comment:说明"`
+	Status     int       `gorm:"type:int(4);comment:响应状态码"`
+	StartTime  time.Time `gorm:"type:datetime(3);comment:发起时间"`
+	TimeCost   int64     `gorm:"type:int(6);comment:请求耗时(ms)"`
+	UserAgent  string    `gorm:"type:varchar(20);comment:浏览器标识"`
+}
+
+func (operationLog) TableName() string {
+	return "operation_log"
+}
+
 // 自动迁移表结构
-func operationLogMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.OperationLog{},
+func operationLogMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&operationLog{},
 	)
 }
@@ -7,12 +7,55 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
+	"time"
 )
 
+type order struct {
+	Model        baseModel  `gorm:"embedded"`
+	OrderID      string     `gorm:"type:char(10);uniqueIndex;comment:唯一字符ID/分布式ID"`
+	OrderNumber  string     `gorm:"type:varchar(255);uniqueIndex;not null;comment:订单号"`
+	OrderType    uint       `gorm:"type:tinyint(4);not null;Index;comment:订单类型：1-普通订单；2-积分订单"`
+	UserID       string     `gorm:"type:char(10);not null;Index;comment:用户ID"`
+	Username     string     `gorm:"type:varchar(255);not null;Index;comment:用户名"`
+	ProductID    string     `gorm:"type:char(10);not null;Index;comment:产品ID"`
+	ProductSku   string     `gorm:"type:char(10);not null;comment:产品SKU"`
+	ProductName  string     `gorm:"type:varchar(255);not null;comment:产品名称"`
+	Status       uint       `gorm:"type:tinyint(4);not null;Index;comment:状态1-待支付；2-已支付；3-已发货；4-已收货；5-已取消；6-待退款；7.已退款"`
+	PayNumber    string     `gorm:"type:varchar(255);not null;comment:支付单号"`
+	PayTime      *time.Time `gorm:"type:datetime;default:null;comment:支付时间"`
+	PayMethod    uint       `gorm:"type:tinyint(4);not null;comment:支付方式：1-微信支付；2-支付宝支付；3-积分支付；4-余额支付"`
+	RefundTime   *time.Time `gorm:"type:datetime;default:null;comment:退款时间"`
+	PayStatus    uint       `gorm:"type:tinyint(4);not null;comment:支付状态：1-待支付；2-已支付；3-已退款"`
+	RefundStatus uint       `gorm:"type:tinyint(4);not null;comment:退款状态：1-待退款；2-已退款"`
+	ProjectID    string     `gorm:"type:varchar(10);Index;comment:项目 ID"`
+	ProductImage string     `gorm:"type:varchar(255);not null;comment:产品主图"`
+	Amount       int        `gorm:"type:int(10);not null;comment:总金额"`
+	AmountPay    int        `gorm:"type:int(10);not null;comment:实际支付金额"`
+	Quantity     uint       `gorm:"type:int(10);not null;comment:购买数量"`
+	UnitPrice    int        `gorm:"type:int(10);not null;comment:商品价格"`
+	UnitPoint    int        `gorm:"type:float(20,2);NOT NULL;comment:积分数值"`
+	// 收货人信息
+	ConsigneeName     string `gorm:"type:varchar(255);not null;comment:收货人姓名"`
+	ConsigneePhone    string `gorm:"type:varchar(20);not null;comment:收货人电话"`
+	ConsigneeProvince string `gorm:"type:varchar(100);not null;comment:省"`
+	ConsigneeCity     string `gorm:"type:varchar(100);not null;comment:市"`
+	ConsigneeDistrict string `gorm:"type:varchar(100);not null;comment:区/县"`
+	ConsigneeStreet   string `gorm:"type:varchar(255);not null;comment:街道"`
+	ConsigneeAddress  string `gorm:"type:varchar(255);not null;comment:详细地址"`
+	Remark            string `gorm:"type:varchar(255);not null;comment:买家留言"`
+	RemarkAdmin       string `gorm:"type:varchar(255);not null;comment:订单备注"`
+	// 物流
+	LogisticsNumber  string `gorm:"type:varchar(255);not null;comment:物流单号"`
+	LogisticsCompany string `gorm:"type:varchar(255);not null;comment:物流公司"`
+}
+
+func (order) TableName() string {
+	return "order"
+}
+
 // 自动迁移表结构
-func orderMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.Order{},
+func orderMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&order{},
 	)
 }
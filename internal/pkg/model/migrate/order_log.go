@@ -7,12 +7,22 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
 )
 
+type orderLog struct {
+	Model      baseModel `gorm:"embedded"`
+	OrderLogID string    `gorm:"type:char(10);uniqueIndex;comment:唯一字符ID/分布式ID"`
+	OrderID    string    `gorm:"type:varchar(255);not null;comment:订单号"`
+	Remark     string    `gorm:"type:varchar(255);not null;comment:操作记录"`
+}
+
+func (orderLog) TableName() string {
+	return "order_log"
+}
+
 // 自动迁移表结构
-func orderLogMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.OrderLog{},
+func orderLogMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&orderLog{},
 	)
 }
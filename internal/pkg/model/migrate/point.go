@@ -7,14 +7,59 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
+	"time"
 )
 
+// pointLog 积分记录表
+type pointLog struct {
+	Model     baseModel `gorm:"embedded"`
+	ProjectID string    `gorm:"type:char(10);not null;index;comment:项目ID;"`
+	UserID    string    `gorm:"type:varchar(10);Index;comment:用户ID"`
+	Points    float64   `gorm:"type:float(20,2);NOT NULL;comment:积分数值"`
+	Reason    string    `gorm:"type:varchar(255);NOT NULL;comment:加减原因"`
+	Type      string    `gorm:"type:varchar(20);NOT NULL;comment:类型"`
+	EventID   string    `gorm:"type:char(10);comment:事件ID"`
+	Status    uint      `gorm:"type:tinyint(1);not null;default:1;comment:状态，1-正常；2-删除"`
+}
+
+func (pointLog) TableName() string {
+	return "point_log"
+}
+
+// pointAvailable 积分记录表
+type pointAvailable struct {
+	Model          baseModel `gorm:"embedded"`
+	ProjectID      string    `gorm:"type:char(10);not null;index;comment:项目ID;"`
+	UserID         string    `gorm:"type:varchar(10);Index;comment:用户ID"`
+	Points         float64   `gorm:"type:float(20,2);NOT NULL;comment:积分数值"`
+	PointsLogID    int       `gorm:"type:int;NOT NULL;comment:'积分记录表ID'"`
+	ExpirationDate time.Time `gorm:"column:expiration_date;comment:'过期时间'"`
+	Status         uint      `gorm:"type:tinyint(1);not null;default:1;comment:状态，1-正常；2-删除"`
+}
+
+func (pointAvailable) TableName() string {
+	return "point_available"
+}
+
+// pointDeduction 扣减积分表
+type pointDeduction struct {
+	Model             baseModel `gorm:"embedded"`
+	ProjectID         string    `gorm:"type:char(10);not null;index;comment:项目ID;"`
+	UserID            string    `gorm:"type:varchar(10);Index;comment:用户ID"`
+	Points            float64   `gorm:"type:float(20,2);NOT NULL;comment:积分数值"`
+	PointsDetailID    int       `gorm:"type:int(10);comment:'积分明细ID'"`
+	AvailablePointsID int       `gorm:"type:int(10);NOT NULL;comment:'可用积分表ID'"`
+}
+
+func (pointDeduction) TableName() string {
+	return "point_deduction"
+}
+
 // 自动迁移表结构
-func pointMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.PointLog{},
-		&model.PointAvailable{},
-		&model.PointDeduction{},
+func pointMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&pointLog{},
+		&pointAvailable{},
+		&pointDeduction{},
 	)
 }
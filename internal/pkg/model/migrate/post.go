@@ -7,12 +7,44 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
 )
 
+type post struct {
+	Model       baseModel `gorm:"embedded"`
+	PostID      string    `gorm:"type:char(10);uniqueIndex;comment:唯一字符ID/分布式ID"`
+	CategoryID  string    `gorm:"type:varchar(10);Index;comment:分类 ID"`
+	ProjectID   string    `gorm:"type:varchar(10);Index;comment:项目 ID"`
+	ColumnID    string    `gorm:"type:varchar(10);Index;comment:专栏ID"`
+	UserID      string    `gorm:"type:varchar(10);Index;comment:用户ID"`
+	Author      string    `gorm:"type:varchar(30);not null;index:idx_username;comment:作者"`
+	Title       string    `gorm:"type:varchar(255);not null;comment:标题"`
+	Content     string    `gorm:"not null;type:longtext;comment:内容"`
+	HtmlContent string    `gorm:"not null;type:longtext;comment:html内容"`
+	Description string    `gorm:"not null;size:300;comment:描述"`
+	Ext         string    `gorm:"type:text;comment:'扩展字段'"`
+	Icon        string    `gorm:"type:varchar(255);comment:图标"`
+	Tag         string    `gorm:"type:varchar(30);comment:tag"`
+	View        uint      `gorm:"default:1;comment:'阅读量'"`
+	Type        uint      `gorm:"type:tinyint;default:1;comment:类型，1.文章 2.page 3.短文"`
+	IsTop       uint      `gorm:"type:tinyint;default:1;comment:是否置顶：1-禁用;2-启用"`
+	IsPasswd    uint      `gorm:"type:tinyint;default:1;comment:是否加密：1-禁用;2-启用"`
+	PassWord    string    `gorm:"type:varchar(255);not null;comment:密码"`
+	Status      uint      `gorm:"type:tinyint(1);not null;default:1;comment:状态，1-草稿；2-发布"`
+	UnitPrice   uint      `gorm:"type:int(10);not null;comment:商品价格"`
+	Location    string    `gorm:"type:varchar(255);comment:地点"`
+	People      string    `gorm:"type:varchar(255);comment:人物"`
+	Time        string    `gorm:"type:varchar(255);comment:时间"`
+	Images      string    `gorm:"type:varchar(1000);comment:图片"`
+	Video       string    `gorm:"type:varchar(255);not null;comment:产品视频"`
+}
+
+func (post) TableName() string {
+	return "post"
+}
+
 // 自动迁移表结构
-func postMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.Post{},
+func postMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&post{},
 	)
 }
@@ -7,12 +7,33 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
 )
 
+type product struct {
+	Model         baseModel `gorm:"embedded"`
+	ProductID     string    `gorm:"type:char(10);uniqueIndex;comment:唯一字符ID/分布式ID"`
+	Title         string    `gorm:"type:varchar(255);not null;comment:标题"`
+	ProductNumber string    `gorm:"type:varchar(255);not null;comment:商品货号"`
+	ProjectID     string    `gorm:"type:varchar(10);Index;comment:项目 ID"`
+	Description   string    `gorm:"not null;size:300;not null;comment:产品卖点/描述"`
+	Image         string    `gorm:"type:varchar(255);not null;comment:产品主图"`
+	Video         string    `gorm:"type:varchar(255);not null;comment:产品视频"`
+	BuyLimit      uint      `gorm:"type:tinyint(4);not null;default:1;comment:购买限制"`
+	CategoryID    string    `gorm:"type:char(10);not null;index;comment:分类ID"`
+	ProductSpec   string    `gorm:"type:varchar(2048);not null;comment:产品规格"`
+	Content       string    `gorm:"type:longtext;comment:内容"`
+	HtmlContent   string    `gorm:"type:longtext;comment:html内容"`
+	Tag           string    `gorm:"type:varchar(300);not null;comment:标签"`
+	Enable        uint      `gorm:"type:tinyint(4);not null;default:1;comment:是否启用：1-下架；2-上架"`
+}
+
+func (product) TableName() string {
+	return "product"
+}
+
 // 自动迁移表结构
-func productMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.Product{},
+func productMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&product{},
 	)
 }
@@ -7,12 +7,31 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
 )
 
+type productSku struct {
+	Model         baseModel `gorm:"embedded"`
+	SkuID         string    `gorm:"type:char(10);uniqueIndex;comment:唯一字符ID/分布式ID"`
+	Title         string    `gorm:"type:varchar(255);not null;comment:标题"`
+	ProjectID     string    `gorm:"type:varchar(10);Index;comment:项目 ID"`
+	ProductID     string    `gorm:"type:varchar(10);Index;comment:产品ID"`
+	Image         string    `gorm:"type:varchar(255);not null;comment:产品主图"`
+	Video         string    `gorm:"type:varchar(255);not null;comment:产品视频"`
+	CostPrice     int       `gorm:"type:int(10);not null;comment:成本价"`
+	UnitPrice     int       `gorm:"type:int(10);not null;comment:商品价格"`
+	MarketPrice   int       `gorm:"type:int(10);not null;comment:市场价格"`
+	Quantity      uint      `gorm:"type:int(10);not null;comment:库存"`
+	UnitPoint     int       `gorm:"type:float(20,2);NOT NULL;comment:积分数值"`
+	EnableDefault uint      `gorm:"type:tinyint(4);not null;default:1;comment:是否启用：1-正常；2-默认"`
+}
+
+func (productSku) TableName() string {
+	return "product_sku"
+}
+
 // 自动迁移表结构
-func productSKUMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.ProductSku{},
+func productSKUMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&productSku{},
 	)
 }
@@ -7,12 +7,25 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
 )
 
+type productSpec struct {
+	Model         baseModel `gorm:"embedded"`
+	ProductSpecID string    `gorm:"type:char(10);uniqueIndex;comment:唯一字符ID/分布式ID"`
+	Title         string    `gorm:"type:varchar(255);not null;comment:标题"`
+	Remark        string    `gorm:"type:varchar(50);not null;comment:备注"`
+	Format        uint      `gorm:"type:tinyint(4);not null;default:1;comment:规格类型:1-文字,2-图片"`
+	Image         string    `gorm:"type:varchar(255);comment:图片"`
+	Sort          uint      `gorm:"type:tinyint(4);not null;default:1;comment:排序"`
+}
+
+func (productSpec) TableName() string {
+	return "product_spec"
+}
+
 // 自动迁移表结构
-func productSpecMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.ProductSpec{},
+func productSpecMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&productSpec{},
 	)
 }
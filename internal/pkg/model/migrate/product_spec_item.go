@@ -7,12 +7,24 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
 )
 
+type productSpecItem struct {
+	Model   baseModel `gorm:"embedded"`
+	ItemID  string    `gorm:"type:char(10);uniqueIndex;comment:唯一字符ID/分布式ID"`
+	SpecID  string    `gorm:"type:char(10);comment:唯一字符ID/分布式ID"`
+	Title   string    `gorm:"type:varchar(255);not null;comment:标题"`
+	Sort    uint      `gorm:"type:tinyint(4);not null;default:1;comment:排序"`
+	Enabled uint
+}
+
+func (productSpecItem) TableName() string {
+	return "product_spec_item"
+}
+
 // 自动迁移表结构
-func productSpecItemMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.ProductSpecItem{},
+func productSpecItemMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&productSpecItem{},
 	)
 }
@@ -7,12 +7,24 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
 )
 
+type productType struct {
+	Model             baseModel `gorm:"embedded"`
+	ProductTypeID     string    `gorm:"type:char(10);uniqueIndex;comment:唯一字符ID/分布式ID"`
+	Title             string    `gorm:"type:varchar(255);not null;comment:标题"`
+	Remark            string    `gorm:"type:varchar(50);not null;comment:备注"`
+	ProductCategoryID string    `gorm:"type:char(10);not null;index;comment:分类ID;"`
+	SpecIds           string    `gorm:"type:varchar(255);not null;index;comment:规格编号;"`
+}
+
+func (productType) TableName() string {
+	return "product_type"
+}
+
 // 自动迁移表结构
-func productTypeMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.ProductType{},
+func productTypeMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&productType{},
 	)
 }
@@ -7,12 +7,35 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
 )
 
+type project struct {
+	Model          baseModel `gorm:"embedded"`
+	ProjectID      string    `gorm:"type:char(10);not null;uniqueIndex:idx_project_project_id;comment:字符ID，分布式ID"`
+	Name           string    `gorm:"type:varchar(30);not null;comment:项目名"`
+	Title          string    `gorm:"type:varchar(30);not null;comment:网站标题"`
+	Description    string    `gorm:"type:varchar(300);comment:描述"`
+	Keywords       string    `gorm:"type:varchar(30);comment:网站关键词"`
+	Domain         string    `gorm:"type:varchar(60);comment:项目域名"`
+	PostURL        string    `gorm:"type:varchar(300);comment:内容链接"`
+	ICP            string    `gorm:"type:varchar(255);comment:icp备案信息"`
+	PublicSecurity string    `gorm:"type:varchar(255);comment:公安备案"`
+	Author         string    `gorm:"type:varchar(30);comment:网站版权"`
+	Info           string    `gorm:"type:longtext;comment:内容"`
+	BaiduAnalytics string    `gorm:"type:varchar(255);comment:百度统计"`
+	Favicon        string    `gorm:"type:varchar(255);comment:favicon"`
+	NavImage       string    `gorm:"type:varchar(255);comment:导航图片"`
+	PushToken      string    `gorm:"type:varchar(255);comment:百度推送 API token"`
+	Status         int8      `gorm:"type:tinyint;not null;default:1;comment:状态，1-正常；2-禁用"`
+}
+
+func (project) TableName() string {
+	return "project"
+}
+
 // 自动迁移表结构
-func projectMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.Project{},
+func projectMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&project{},
 	)
 }
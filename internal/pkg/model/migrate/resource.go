@@ -7,12 +7,28 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
 )
 
+type resource struct {
+	Model         baseModel `gorm:"embedded"`
+	ResourceID    string    `gorm:"type:char(10);uniqueIndex;comment:唯一字符ID/分布式ID"`
+	Title         string    `gorm:"type:varchar(255);not null;comment:标题"`
+	Path          string    `gorm:"not null;type:varchar(255);not null;comment:路径"`
+	URL           string    `gorm:"not null;type:varchar(255);not null;comment:当前域名"`
+	FileExtension string    `gorm:"not null;type:char(10);not null;comment:文件拓展"`
+	FileType      uint      `gorm:"type:tinyint;not null;default:1;comment:资源类形，1-图片;2-文件;3-视频;4-音频"`
+	Description   string    `gorm:"not null;size:300;not null;comment:描述"`
+	Size          int64     `gorm:"not null;type:int;not null;default:0;comment:文件大小"`
+	Status        uint      `gorm:"type:tinyint;not null;default:1;comment:状态，1-正常；2-禁用"`
+}
+
+func (resource) TableName() string {
+	return "resource"
+}
+
 // 自动迁移表结构
-func resourceMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.Resource{},
+func resourceMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&resource{},
 	)
 }
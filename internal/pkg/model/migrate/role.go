@@ -7,12 +7,27 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
 )
 
+type role struct {
+	Model   baseModel `gorm:"embedded"`
+	Name    string    `gorm:"type:varchar(20);not null;unique"`
+	Keyword string    `gorm:"type:varchar(20);not null;unique"`
+	Desc    *string   `gorm:"type:varchar(100);"`
+	Status  uint      `gorm:"type:tinyint(1);default:1;comment:1正常, 2禁用"`
+	Sort    uint      `gorm:"type:int(3);default:999;comment:角色排序(排序越大权限越低, 不能查看比自己序号小的角色, 不能编辑同序号用户权限, 排序为1表示超级管理员)"`
+	Creator string    `gorm:"type:varchar(20);"`
+	Admin   []*admin  `gorm:"many2many:admin_roles"`
+	Menus   []*menu   `gorm:"many2many:role_menus;"` // 角色菜单多对多关系
+}
+
+func (role) TableName() string {
+	return "role"
+}
+
 // 自动迁移表结构
-func roleMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.Role{},
+func roleMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&role{},
 	)
 }
@@ -7,12 +7,25 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
 )
 
+type systemConfig struct {
+	Model          baseModel `gorm:"embedded"`
+	SystemConfigID string    `gorm:"type:char(10);uniqueIndex;comment:唯一字符ID/分布式ID"`
+	Title          string    `gorm:"type:varchar(255);uniqueIndex;not null;comment:标题"`
+	Content        string    `gorm:"type:text;not null;comment:内容"`
+	Logo           string    `gorm:"type:varchar(255);comment:logo"`
+	Icon           string    `gorm:"type:varchar(255);comment:icon"`
+	Footer         string    `gorm:"type:varchar(255);comment:footer"`
+}
+
+func (systemConfig) TableName() string {
+	return "system_config"
+}
+
 // 自动迁移表结构
-func systemConfigMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.SystemConfig{},
+func systemConfigMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&systemConfig{},
 	)
 }
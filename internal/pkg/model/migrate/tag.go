@@ -7,12 +7,23 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
 )
 
+type tag struct {
+	Model       baseModel `gorm:"embedded"`
+	TagID       string    `gorm:"type:char(10);uniqueIndex;comment:唯一字符ID/分布式ID"`
+	Title       string    `gorm:"type:varchar(255);uniqueIndex;not null;comment:标题"`
+	Description string    `gorm:"not null;size:300;comment:描述"`
+	Color       string    `gorm:"type:varchar(20);comment:颜色"`
+}
+
+func (tag) TableName() string {
+	return "tag"
+}
+
 // 自动迁移表结构
-func tagMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.Tag{},
+func tagMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&tag{},
 	)
 }
@@ -7,12 +7,23 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
 )
 
+type thirdPartyAccounts struct {
+	Model    baseModel `gorm:"embedded"`
+	UserID   string    `gorm:"type:char(10);Index;comment:用户ID"`
+	Platform string    `gorm:"type:varchar(50);not null;comment:平台"`
+	BindFlag uint      `gorm:"type:tinyint;default:1;comment:是否绑定,2绑定"`
+	OpenID   string    `gorm:"type:varchar(255);uniqueIndex;not null;comment:openID"`
+}
+
+func (thirdPartyAccounts) TableName() string {
+	return "third_party_accounts"
+}
+
 // 自动迁移表结构
-func thirdPartyAccountsMigrate(db *gorm.DB) {
-	db.AutoMigrate(
-		&model.ThirdPartyAccounts{},
+func thirdPartyAccountsMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&thirdPartyAccounts{},
 	)
 }
@@ -6,58 +6,34 @@
 package migrate
 
 import (
-	"fmt"
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
-	"log"
+	"time"
 )
 
-// 自动迁移表结构
-func userMigrate(db *gorm.DB) error {
-	tx := db.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
-
-	// 自动迁移表结构
-	if err := tx.AutoMigrate(&model.User{}).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("auto migrate failed: %w", err)
-	}
-
-	// 添加生日字段
-	if err := tx.Migrator().AddColumn(&model.User{}, "birthday").Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("add column birthday failed: %w", err)
-	}
-	// 增加用户头像
-	if err := tx.Migrator().AddColumn(&model.User{}, "avatar_url").Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("add column avatar_url failed: %w", err)
-	}
-
-	// 删除字段
-	// if err := tx.Migrator().DropColumn(&model.User{}, "Test").Error; err != nil {
-	//     tx.Rollback()
-	//     return fmt.Errorf("drop column Test failed: %w", err)
-	// }
-
-	//手机号&邮箱唯一索引
-	if err := tx.Migrator().CreateIndex(&model.User{}, "idx_phone").Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("create index idx_phone failed: %w", err)
-	}
-	if err := tx.Migrator().CreateIndex(&model.User{}, "idx_email").Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("create index idx_phone failed: %w", err)
-	}
+type user struct {
+	Model      baseModel  `gorm:"embedded"`
+	UserID     string     `gorm:"type:char(10);not null;uniqueIndex;comment:字符ID，分布式 ID;"`
+	Username   string     `gorm:"type:varchar(30);not null;uniqueIndex;comment:用户名"`
+	ProjectID  string     `gorm:"type:char(10);not null;index;comment:项目ID;"`
+	Password   string     `gorm:"type:varchar(255);not null;comment:密码"`
+	Nickname   string     `gorm:"type:varchar(30);not null;comment:昵称"`
+	Email      string     `gorm:"type:varchar(30);not null;comment:邮箱"`
+	Phone      string     `gorm:"type:varchar(21);not null;comment:电话"`
+	Sex        string     `gorm:"type:char(1);not null;default:M;comment:M:男 F:女"`
+	Status     uint8      `gorm:"type:tinyint(1);not null;default:1;comment:用户状态，1-正常；2-禁用"`
+	Birthday   *time.Time `gorm:"type:date;comment:'用户生日，格式为YYYY-MM-DD'"`
+	Background string     `gorm:"type:varchar(255);comment:个人中心背景"`
+	Ext        string     `gorm:"type:text;comment:扩展字段"`
+	AvatarURL  string     `gorm:"type:varchar(255);comment:头像地址"`
+}
 
-	if err := tx.Commit().Error; err != nil {
-		return fmt.Errorf("commit transaction failed: %w", err)
-	}
+func (user) TableName() string {
+	return "user"
+}
 
-	log.Println("User migration completed successfully")
-	return nil
+// 自动迁移表结构
+func userMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&user{},
+	)
 }
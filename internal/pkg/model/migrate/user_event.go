@@ -7,13 +7,28 @@ package migrate
 
 import (
 	"gorm.io/gorm"
-	"gotribe-admin/internal/pkg/model"
 )
 
+type userEvent struct {
+	Model       baseModel `gorm:"embedded"`
+	UserID      string    `gorm:"type:char(30);not null;comment:用户ID;"`
+	ProjectID   string    `gorm:"type:char(10);not null;index;comment:项目ID;"`
+	EventType   uint8     `gorm:"type:tinyint(1);not null;index;default:1;comment:事件类型，1-浏览事件；2-点击事件"`
+	EventDetail string    `gorm:"type:text;comment:事件详情"`
+	Duration    int       `gorm:"type:int(11);comment:事件时长"`
+	IP          string    `gorm:"type:varchar(255);comment:IP地址"`
+	UserAgent   string    `gorm:"type:varchar(255);comment:用户代理"`
+	Referer     string    `gorm:"type:varchar(255);comment:来源页面"`
+	Platform    string    `gorm:"type:varchar(255);comment:平台"`
+}
+
+func (userEvent) TableName() string {
+	return "user_event"
+}
+
 // 自动迁移表结构
 func userEventMigrate(db *gorm.DB) error {
-	db.AutoMigrate(
-		&model.UserEvent{},
+	return db.AutoMigrate(
+		&userEvent{},
 	)
-	return nil
 }
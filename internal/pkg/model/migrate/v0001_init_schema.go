@@ -0,0 +1,99 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package migrate
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// 初始表结构, 对应引入版本化迁移之前DBAutoMigrate创建的全部表
+// 已有数据库执行时AutoMigrate只会补齐缺失的表和字段, 不会影响已有数据
+// 使用各表迁移文件中的结构体快照而不是当前模型, 之后的字段变更由对应版本的迁移完成
+var initSchemaMigration = &Migration{
+	Version:     1,
+	Description: "初始化表结构",
+	Up: func(tx *gorm.DB) error {
+		return runAll(tx,
+			userMigrate,               // user表
+			adminMigrate,              // admin表
+			roleMigrate,               // role表
+			menuMigrate,               // menu表
+			apiMigrate,                // api表
+			operationLogMigrate,       // operationLog表
+			postMigrate,               // post
+			exampleMigrate,            // example 示例表
+			configMigrate,             // config表,用于自定义配置
+			tagMigrate,                // tag表
+			resourceMigrate,           // resource 资源表
+			categoryMigrate,           // 分类表
+			projectMigrate,            // 项目表
+			columnMigrate,             // 专栏表
+			adSceneMigrate,            // 推广场景
+			adMigrate,                 // 广告内容
+			commentMigrate,            // 评论表
+			pointMigrate,              // 积分相关表
+			productCategoryMigrate,    // 商品分类
+			productTypeMigrate,        // 商品类型（规格组）
+			productSpecMigrate,        // 商品规格
+			productSpecItemMigrate,    // 商品规格属性
+			productMigrate,            // SPU表
+			productSKUMigrate,         // SKU表
+			orderMigrate,              // 订单表
+			orderLogMigrate,           // 订单日志表
+			systemConfigMigrate,       // 系统设置
+			thirdPartyAccountsMigrate, // 第三方帐号表
+			feedbackMigrate,           // 反馈表
+			userEventMigrate,          // 用户事件表
+		)
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(
+			&user{},
+			&admin{},
+			"admin_roles",
+			&role{},
+			"role_menus",
+			&menu{},
+			&api{},
+			&operationLog{},
+			&post{},
+			&example{},
+			&config{},
+			&tag{},
+			&resource{},
+			&category{},
+			&project{},
+			&column{},
+			&adScene{},
+			&ad{},
+			&comment{},
+			&pointLog{},
+			&pointAvailable{},
+			&pointDeduction{},
+			&productCategory{},
+			&productType{},
+			&productSpec{},
+			&productSpecItem{},
+			&product{},
+			&productSku{},
+			&order{},
+			&orderLog{},
+			&systemConfig{},
+			&thirdPartyAccounts{},
+			&feedback{},
+			&userEvent{},
+		)
+	},
+}
+
+// 初始表结构共用的基础字段, 对应当时的model.Model
+type baseModel struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
@@ -0,0 +1,42 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package migrate
+
+import (
+	"gorm.io/gorm"
+)
+
+// 操作日志增加请求ID字段, 用于关联访问日志
+var operationLogRequestIDMigration = &Migration{
+	Version:     2,
+	Description: "操作日志增加请求ID",
+	Up: func(tx *gorm.DB) error {
+		if tx.Migrator().HasColumn(&requestIDOperationLog{}, "RequestID") {
+			return nil
+		}
+		if err := tx.Migrator().AddColumn(&requestIDOperationLog{}, "RequestID"); err != nil {
+			return err
+		}
+		return tx.Migrator().CreateIndex(&requestIDOperationLog{}, "RequestID")
+	},
+	Down: func(tx *gorm.DB) error {
+		if tx.Migrator().HasIndex(&requestIDOperationLog{}, "RequestID") {
+			if err := tx.Migrator().DropIndex(&requestIDOperationLog{}, "RequestID"); err != nil {
+				return err
+			}
+		}
+		return tx.Migrator().DropColumn(&requestIDOperationLog{}, "RequestID")
+	},
+}
+
+// 操作日志表新增的请求ID字段
+type requestIDOperationLog struct {
+	RequestID string `gorm:"type:varchar(64);index;comment:请求ID"`
+}
+
+func (requestIDOperationLog) TableName() string {
+	return "operation_log"
+}
@@ -0,0 +1,47 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package migrate
+
+import (
+	"gorm.io/gorm"
+)
+
+// 操作日志增加请求体、响应体字段, 浏览器标识加长到255
+var operationLogBodyMigration = &Migration{
+	Version:     3,
+	Description: "操作日志记录请求体与响应体",
+	Up: func(tx *gorm.DB) error {
+		for _, field := range []string{"RequestBody", "ResponseBody"} {
+			if tx.Migrator().HasColumn(&bodyOperationLog{}, field) {
+				continue
+			}
+			if err := tx.Migrator().AddColumn(&bodyOperationLog{}, field); err != nil {
+				return err
+			}
+		}
+		return tx.Migrator().AlterColumn(&bodyOperationLog{}, "UserAgent")
+	},
+	Down: func(tx *gorm.DB) error {
+		// 浏览器标识保持加长后的长度, 避免截断已有数据
+		for _, field := range []string{"RequestBody", "ResponseBody"} {
+			if err := tx.Migrator().DropColumn(&bodyOperationLog{}, field); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// 操作日志表新增的请求体、响应体字段与加长后的浏览器标识
+type bodyOperationLog struct {
+	UserAgent    string `gorm:"type:varchar(255);comment:浏览器标识"`
+	RequestBody  string `gorm:"type:text;comment:请求体(已脱敏)"`
+	ResponseBody string `gorm:"type:text;comment:响应体(已截断、脱敏)"`
+}
+
+func (bodyOperationLog) TableName() string {
+	return "operation_log"
+}
@@ -0,0 +1,62 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package migrate
+
+import (
+	"gorm.io/gorm"
+)
+
+// 用户事件增加归属地字段, 操作日志的IP与归属地字段加长以容纳IPv6与完整归属地
+var geoIPLocationMigration = &Migration{
+	Version:     4,
+	Description: "IP归属地字段",
+	Up: func(tx *gorm.DB) error {
+		for _, field := range []string{"Country", "RegionName", "City"} {
+			if tx.Migrator().HasColumn(&geoIPUserEvent{}, field) {
+				continue
+			}
+			if err := tx.Migrator().AddColumn(&geoIPUserEvent{}, field); err != nil {
+				return err
+			}
+		}
+		for _, field := range []string{"Ip", "IpLocation"} {
+			if err := tx.Migrator().AlterColumn(&geoIPOperationLog{}, field); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		// 操作日志字段保持加长后的长度, 避免截断已有数据
+		for _, field := range []string{"Country", "RegionName", "City"} {
+			if err := tx.Migrator().DropColumn(&geoIPUserEvent{}, field); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// 用户事件表新增的归属地字段
+type geoIPUserEvent struct {
+	Country    string `gorm:"type:varchar(255);comment:国家"`
+	RegionName string `gorm:"type:varchar(255);comment:地区"`
+	City       string `gorm:"type:varchar(255);comment:城市"`
+}
+
+func (geoIPUserEvent) TableName() string {
+	return "user_event"
+}
+
+// 操作日志表加长后的IP与归属地字段
+type geoIPOperationLog struct {
+	Ip         string `gorm:"type:varchar(64);comment:Ip地址"`
+	IpLocation string `gorm:"type:varchar(100);comment:Ip所在地"`
+}
+
+func (geoIPOperationLog) TableName() string {
+	return "operation_log"
+}
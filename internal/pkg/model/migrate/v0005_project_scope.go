@@ -0,0 +1,53 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package migrate
+
+import (
+	"gorm.io/gorm"
+)
+
+// 管理员、角色与项目的多对多关系, 用于按项目隔离数据
+var projectScopeMigration = &Migration{
+	Version:     5,
+	Description: "管理员与角色关联项目",
+	Up: func(tx *gorm.DB) error {
+		// 只创建缺失的关联表
+		if !tx.Migrator().HasTable(&adminProject{}) {
+			if err := tx.Migrator().CreateTable(&adminProject{}); err != nil {
+				return err
+			}
+		}
+		if !tx.Migrator().HasTable(&roleProject{}) {
+			if err := tx.Migrator().CreateTable(&roleProject{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable("admin_projects", "role_projects")
+	},
+}
+
+// 管理员与项目关联表
+type adminProject struct {
+	AdminID   uint   `gorm:"primaryKey;autoIncrement:false"`
+	ProjectID string `gorm:"type:char(10);not null;primaryKey"`
+}
+
+func (adminProject) TableName() string {
+	return "admin_projects"
+}
+
+// 角色与项目关联表
+type roleProject struct {
+	RoleID    uint   `gorm:"primaryKey;autoIncrement:false"`
+	ProjectID string `gorm:"type:char(10);not null;primaryKey"`
+}
+
+func (roleProject) TableName() string {
+	return "role_projects"
+}
@@ -0,0 +1,33 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package migrate
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// casbin策略版本表, 多实例部署时用于同步权限策略
+var casbinVersionMigration = &Migration{
+	Version:     6,
+	Description: "casbin策略版本表",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().AutoMigrate(&casbinVersion{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&casbinVersion{})
+	},
+}
+
+type casbinVersion struct {
+	ID        uint   `gorm:"primaryKey"`
+	Version   uint64 `gorm:"not null;default:0;comment:策略版本号"`
+	UpdatedAt time.Time
+}
+
+func (casbinVersion) TableName() string {
+	return "casbin_version"
+}
@@ -0,0 +1,54 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package migrate
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// 服务账号与API Key表, 供机器客户端调用接口
+var serviceAccountMigration = &Migration{
+	Version:     7,
+	Description: "服务账号与API Key表",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().AutoMigrate(&serviceAccount{}, &apiKey{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&apiKey{}, &serviceAccount{})
+	},
+}
+
+type serviceAccount struct {
+	Model   baseModel `gorm:"embedded"`
+	Name    string    `gorm:"type:varchar(20);not null;unique;comment:名称, 同时作为操作日志与创建人中的用户名"`
+	Desc    string    `gorm:"type:varchar(100);"`
+	RoleID  uint      `gorm:"not null;index;comment:绑定的角色"`
+	Status  uint      `gorm:"type:tinyint(1);default:1;comment:1正常, 2禁用"`
+	Creator string    `gorm:"type:varchar(20);"`
+}
+
+func (serviceAccount) TableName() string {
+	return "service_account"
+}
+
+type apiKey struct {
+	Model            baseModel  `gorm:"embedded"`
+	ServiceAccountID uint       `gorm:"not null;index"`
+	Name             string     `gorm:"type:varchar(50);comment:用途说明"`
+	Prefix           string     `gorm:"type:varchar(20);not null;uniqueIndex;comment:明文前缀, 用于查找与识别"`
+	KeyHash          string     `gorm:"type:varchar(64);not null;comment:sha256哈希"`
+	AllowIPs         string     `gorm:"type:varchar(500);comment:IP白名单, 多个以逗号分隔, 为空时不限制"`
+	ExpiresAt        *time.Time `gorm:"comment:过期时间, 为空时不过期"`
+	RevokedAt        *time.Time `gorm:"comment:吊销时间"`
+	LastUsedAt       *time.Time
+	LastUsedIP       string `gorm:"type:varchar(64)"`
+	Creator          string `gorm:"type:varchar(20);"`
+}
+
+func (apiKey) TableName() string {
+	return "api_key"
+}
@@ -0,0 +1,64 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package migrate
+
+import (
+	"gorm.io/gorm"
+)
+
+// 管理员两步验证字段, 以及角色是否要求两步验证
+var totpMigration = &Migration{
+	Version:     8,
+	Description: "管理员两步验证",
+	Up: func(tx *gorm.DB) error {
+		for _, column := range []string{"TotpSecret", "TotpEnabled", "TotpRecoveryCodes", "TotpLastStep"} {
+			if tx.Migrator().HasColumn(&totpAdmin{}, column) {
+				continue
+			}
+			if err := tx.Migrator().AddColumn(&totpAdmin{}, column); err != nil {
+				return err
+			}
+		}
+		if tx.Migrator().HasColumn(&totpRole{}, "TotpRequired") {
+			return nil
+		}
+		return tx.Migrator().AddColumn(&totpRole{}, "TotpRequired")
+	},
+	Down: func(tx *gorm.DB) error {
+		for _, column := range []string{"TotpSecret", "TotpEnabled", "TotpRecoveryCodes", "TotpLastStep"} {
+			if tx.Migrator().HasColumn(&totpAdmin{}, column) {
+				if err := tx.Migrator().DropColumn(&totpAdmin{}, column); err != nil {
+					return err
+				}
+			}
+		}
+		if tx.Migrator().HasColumn(&totpRole{}, "TotpRequired") {
+			return tx.Migrator().DropColumn(&totpRole{}, "TotpRequired")
+		}
+		return nil
+	},
+}
+
+// 管理员表新增的两步验证字段
+type totpAdmin struct {
+	TotpSecret        string `gorm:"type:varchar(64);comment:两步验证密钥"`
+	TotpEnabled       bool   `gorm:"default:false;comment:是否已启用两步验证"`
+	TotpRecoveryCodes string `gorm:"type:varchar(1000);comment:恢复码哈希, 以逗号分隔"`
+	TotpLastStep      int64  `gorm:"default:0;comment:最近一次使用的验证码周期, 防止重复使用"`
+}
+
+func (totpAdmin) TableName() string {
+	return "admin"
+}
+
+// 角色表新增的是否要求两步验证字段
+type totpRole struct {
+	TotpRequired uint `gorm:"type:tinyint(1);default:2;comment:1必须启用两步验证, 2不要求"`
+}
+
+func (totpRole) TableName() string {
+	return "role"
+}
@@ -0,0 +1,38 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package migrate
+
+import (
+	"gorm.io/gorm"
+)
+
+// 管理员登录日志表
+var loginLogMigration = &Migration{
+	Version:     9,
+	Description: "管理员登录日志表",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().AutoMigrate(&loginLog{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&loginLog{})
+	},
+}
+
+type loginLog struct {
+	Model      baseModel `gorm:"embedded"`
+	RequestID  string    `gorm:"type:varchar(64);index;comment:请求ID"`
+	AdminID    uint      `gorm:"index;comment:管理员ID, 用户不存在时为0"`
+	Username   string    `gorm:"type:varchar(64);index;comment:登录名"`
+	Ip         string    `gorm:"type:varchar(64);index;comment:Ip地址"`
+	IpLocation string    `gorm:"type:varchar(100);comment:Ip所在地"`
+	UserAgent  string    `gorm:"type:varchar(255);comment:浏览器标识"`
+	Status     uint      `gorm:"type:tinyint(1);comment:结果(1成功, 2失败)"`
+	Reason     string    `gorm:"type:varchar(100);comment:失败原因"`
+}
+
+func (loginLog) TableName() string {
+	return "login_log"
+}
@@ -0,0 +1,38 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package migrate
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// 登录失败计数与锁定表, 多个实例共享登录锁定状态
+var loginLockMigration = &Migration{
+	Version:     10,
+	Description: "登录失败计数与锁定表",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().AutoMigrate(&loginLock{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&loginLock{})
+	},
+}
+
+type loginLock struct {
+	ID          uint       `gorm:"primaryKey"`
+	LockKey     string     `gorm:"type:varchar(128);not null;uniqueIndex;comment:user:用户名 或 ip:IP"`
+	Failures    int        `gorm:"not null;default:0;comment:统计窗口内的连续失败次数"`
+	Lockouts    int        `gorm:"not null;default:0;comment:已锁定的次数, 决定下次锁定时长"`
+	LastFailure *time.Time `gorm:"comment:最近一次失败时间"`
+	LockedUntil *time.Time `gorm:"comment:锁定截止时间"`
+	ExpiresAt   time.Time  `gorm:"index;comment:过期时间, 过期后失败次数与锁定等级清零"`
+	UpdatedAt   time.Time
+}
+
+func (loginLock) TableName() string {
+	return "login_lock"
+}
@@ -0,0 +1,43 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package migrate
+
+import (
+	"gorm.io/gorm"
+)
+
+// 角色是否可管理全部项目, 未分配项目的用户不再默认可管理全部项目
+// 已有的未分配项目的角色设置为可管理全部项目, 保持升级前的数据范围
+var roleAllProjectsMigration = &Migration{
+	Version:     11,
+	Description: "角色可管理全部项目",
+	Up: func(tx *gorm.DB) error {
+		if tx.Migrator().HasColumn(&allProjectsRole{}, "AllProjects") {
+			return nil
+		}
+		if err := tx.Migrator().AddColumn(&allProjectsRole{}, "AllProjects"); err != nil {
+			return err
+		}
+		return tx.Model(&allProjectsRole{}).
+			Where("id NOT IN (?)", tx.Table("role_projects").Select("role_id")).
+			Update("all_projects", 1).Error
+	},
+	Down: func(tx *gorm.DB) error {
+		if tx.Migrator().HasColumn(&allProjectsRole{}, "AllProjects") {
+			return tx.Migrator().DropColumn(&allProjectsRole{}, "AllProjects")
+		}
+		return nil
+	},
+}
+
+// 角色表新增的是否可管理全部项目字段
+type allProjectsRole struct {
+	AllProjects uint `gorm:"type:tinyint(1);default:2;comment:1可管理全部项目, 2只能管理分配的项目"`
+}
+
+func (allProjectsRole) TableName() string {
+	return "role"
+}
@@ -0,0 +1,41 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package migrate
+
+import (
+	"gorm.io/gorm"
+)
+
+// 引入版本化迁移前的索引名, 已改名以避免在postgres/sqlite中与其它表的索引重名
+// 旧数据库执行初始迁移时已按新名称创建了索引, 这里删除重复的旧索引
+var legacyIndexes = []struct {
+	table, name string
+}{
+	{"example", "idx_username"},
+	{"product_type", "idx_product_category_id"},
+	{"product_type", "idx_spec_id"},
+}
+
+// 删除改名前遗留的重复索引
+var legacyIndexMigration = &Migration{
+	Version:     12,
+	Description: "删除改名前遗留的索引",
+	Up: func(tx *gorm.DB) error {
+		for _, index := range legacyIndexes {
+			if !tx.Migrator().HasIndex(index.table, index.name) {
+				continue
+			}
+			if err := tx.Migrator().DropIndex(index.table, index.name); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		// 旧索引与新索引重复, 回滚时不再创建
+		return nil
+	},
+}
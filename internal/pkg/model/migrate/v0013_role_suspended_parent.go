@@ -0,0 +1,31 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package migrate
+
+import (
+	"gorm.io/gorm"
+)
+
+// 父角色被禁用时暂停的继承关系表, 被禁用的父角色不再向子角色传递权限
+var roleSuspendedParentMigration = &Migration{
+	Version:     13,
+	Description: "暂停的角色继承关系",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().AutoMigrate(&roleSuspendedParent{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&roleSuspendedParent{})
+	},
+}
+
+type roleSuspendedParent struct {
+	RoleID   uint `gorm:"primaryKey;autoIncrement:false;comment:子角色ID"`
+	ParentID uint `gorm:"primaryKey;autoIncrement:false;comment:被禁用的父角色ID"`
+}
+
+func (roleSuspendedParent) TableName() string {
+	return "role_suspended_parent"
+}
@@ -43,7 +43,9 @@ type Post struct {
 }
 
 func (p *Post) BeforeCreate(tx *gorm.DB) error {
-	p.PostID = gid.GenShortID()
+	if p.PostID == "" {
+		p.PostID = gid.GenShortID()
+	}
 
 	return nil
 }
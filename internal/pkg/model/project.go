@@ -31,7 +31,9 @@ type Project struct {
 }
 
 func (p *Project) BeforeCreate(tx *gorm.DB) error {
-	p.ProjectID = gid.GenShortID()
+	if p.ProjectID == "" {
+		p.ProjectID = gid.GenShortID()
+	}
 
 	return nil
 }
@@ -9,10 +9,16 @@ type Role struct {
 	Model
 	Name    string   `gorm:"type:varchar(20);not null;unique" json:"name"`
 	Keyword string   `gorm:"type:varchar(20);not null;unique" json:"keyword"`
-	Desc    *string  `gorm:"type:varchar(100);"json:"desc"`
+	Desc    *string  `gorm:"type:varchar(100);" json:"desc"`
 	Status  uint     `gorm:"type:tinyint(1);default:1;comment:1正常, 2禁用" json:"status"`
 	Sort    uint     `gorm:"type:int(3);default:999;comment:角色排序(排序越大权限越低, 不能查看比自己序号小的角色, 不能编辑同序号用户权限, 排序为1表示超级管理员)" json:"sort"`
 	Creator string   `gorm:"type:varchar(20);" json:"creator"`
 	Admin   []*Admin `gorm:"many2many:admin_roles" json:"admins"`
 	Menus   []*Menu  `gorm:"many2many:role_menus;" json:"menus"` // 角色菜单多对多关系
+	// 拥有该角色的管理员可管理的项目
+	Projects []*Project `gorm:"many2many:role_projects;references:ProjectID;joinReferences:ProjectID" json:"projects"`
+	// 拥有该角色的管理员可管理全部项目, 否则只能管理分配的项目
+	AllProjects uint `gorm:"type:tinyint(1);default:2;comment:1可管理全部项目, 2只能管理分配的项目" json:"allProjects"`
+	// 拥有该角色的用户必须启用两步验证
+	TotpRequired uint `gorm:"type:tinyint(1);default:2;comment:1必须启用两步验证, 2不要求" json:"totpRequired"`
 }
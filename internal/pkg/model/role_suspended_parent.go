@@ -0,0 +1,12 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package model
+
+// 因父角色被禁用而暂停的角色继承关系, 父角色启用后恢复为casbin继承策略
+type RoleSuspendedParent struct {
+	RoleID   uint `gorm:"primaryKey;autoIncrement:false;comment:子角色ID" json:"roleId"`
+	ParentID uint `gorm:"primaryKey;autoIncrement:false;comment:被禁用的父角色ID" json:"parentId"`
+}
@@ -0,0 +1,21 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package model
+
+import (
+	"time"
+)
+
+// 已执行的数据库迁移版本
+type SchemaMigration struct {
+	Version     uint      `gorm:"primaryKey;autoIncrement:false;comment:迁移版本号" json:"version"`
+	Description string    `gorm:"type:varchar(255);comment:迁移说明" json:"description"`
+	AppliedAt   time.Time `gorm:"comment:执行时间" json:"appliedAt"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
@@ -0,0 +1,35 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package model
+
+import "time"
+
+// 服务账号, 供CI等机器客户端通过API Key调用接口, 权限取决于绑定的角色
+type ServiceAccount struct {
+	Model
+	Name    string    `gorm:"type:varchar(20);not null;unique;comment:名称, 同时作为操作日志与创建人中的用户名" json:"name"`
+	Desc    string    `gorm:"type:varchar(100);" json:"desc"`
+	RoleID  uint      `gorm:"not null;index;comment:绑定的角色" json:"roleID"`
+	Role    *Role     `json:"role"`
+	Status  uint      `gorm:"type:tinyint(1);default:1;comment:1正常, 2禁用" json:"status"`
+	Creator string    `gorm:"type:varchar(20);" json:"creator"`
+	ApiKeys []*ApiKey `json:"apiKeys"`
+}
+
+// 服务账号的API Key, 只保存哈希, 明文只在创建时返回一次
+type ApiKey struct {
+	Model
+	ServiceAccountID uint       `gorm:"not null;index" json:"serviceAccountID"`
+	Name             string     `gorm:"type:varchar(50);comment:用途说明" json:"name"`
+	Prefix           string     `gorm:"type:varchar(20);not null;uniqueIndex;comment:明文前缀, 用于查找与识别" json:"prefix"`
+	KeyHash          string     `gorm:"type:varchar(64);not null;comment:sha256哈希" json:"-"`
+	AllowIPs         string     `gorm:"type:varchar(500);comment:IP白名单, 多个以逗号分隔, 为空时不限制" json:"allowIPs"`
+	ExpiresAt        *time.Time `gorm:"comment:过期时间, 为空时不过期" json:"expiresAt"`
+	RevokedAt        *time.Time `gorm:"comment:吊销时间" json:"revokedAt"`
+	LastUsedAt       *time.Time `json:"lastUsedAt"`
+	LastUsedIP       string     `gorm:"type:varchar(64)" json:"lastUsedIP"`
+	Creator          string     `gorm:"type:varchar(20);" json:"creator"`
+}
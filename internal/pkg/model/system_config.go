@@ -21,6 +21,8 @@ type SystemConfig struct {
 }
 
 func (t *SystemConfig) BeforeCreate(tx *gorm.DB) error {
-	t.SystemConfigID = gid.GenShortID()
+	if t.SystemConfigID == "" {
+		t.SystemConfigID = gid.GenShortID()
+	}
 	return nil
 }
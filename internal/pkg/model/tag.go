@@ -19,6 +19,8 @@ type Tag struct {
 }
 
 func (t *Tag) BeforeCreate(tx *gorm.DB) error {
-	t.TagID = gid.GenShortID()
+	if t.TagID == "" {
+		t.TagID = gid.GenShortID()
+	}
 	return nil
 }
@@ -31,7 +31,9 @@ type User struct {
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
-	u.UserID = gid.GenShortID()
+	if u.UserID == "" {
+		u.UserID = gid.GenShortID()
+	}
 	// Encrypt the user password.
 	u.Password, err = util.Encrypt(u.Password)
 	if err != nil {
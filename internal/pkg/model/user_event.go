@@ -13,6 +13,9 @@ type UserEvent struct {
 	EventDetail string `gorm:"type:text;comment:事件详情" json:"eventDetail"`
 	Duration    int    `gorm:"type:int(11);comment:事件时长" json:"duration"`
 	IP          string `gorm:"type:varchar(255);comment:IP地址" json:"ip"`
+	Country     string `gorm:"type:varchar(255);comment:国家" json:"country"`
+	RegionName  string `gorm:"type:varchar(255);comment:地区" json:"regionName"`
+	City        string `gorm:"type:varchar(255);comment:城市" json:"city"`
 	UserAgent   string `gorm:"type:varchar(255);comment:用户代理" json:"userAgent"`
 	Referer     string `gorm:"type:varchar(255);comment:来源页面" json:"referer"`
 	Platform    string `gorm:"type:varchar(255);comment:平台" json:"platform"`
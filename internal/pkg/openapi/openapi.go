@@ -0,0 +1,268 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+// Package openapi 根据路由与vo请求、dto响应结构体生成OpenAPI 3文档
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// OpenAPI版本
+const Version = "3.0.3"
+
+// 统一响应结构在components中的名称
+const responseSchema = "Response"
+
+// 认证方式在components中的名称, jwt与服务账号的API Key二选一
+const (
+	bearerAuth = "bearerAuth"
+	apiKeyAuth = "apiKeyAuth"
+)
+
+// OpenAPI文档
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Tags       []Tag                `json:"tags,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// 同一路径下各请求方式的接口
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+}
+
+// 路由的请求与响应说明
+type Doc struct {
+	Request  interface{}            // 请求参数结构体, GET请求为查询参数, 其它为json请求体
+	Response map[string]interface{} // 响应data中的字段, 与controller中的gin.H一致, 值只用于取类型
+	Files    []string               // 以multipart/form-data上传的文件字段
+	Produces string                 // 非json响应的内容类型, 如导出的text/csv
+	Public   bool                   // 不需要jwt认证
+}
+
+// 文档生成器
+type Generator struct {
+	doc     *Document
+	schemas *schemaRegistry
+	tags    map[string]bool
+}
+
+// Generator构造函数, server为接口前缀
+func NewGenerator(title string, version string, server string) *Generator {
+	g := &Generator{
+		doc: &Document{
+			OpenAPI: Version,
+			Info:    Info{Title: title, Version: version},
+			Servers: []Server{{URL: server}},
+			Paths:   make(map[string]*PathItem),
+			Components: Components{
+				Schemas: make(map[string]*Schema),
+				SecuritySchemes: map[string]*SecurityScheme{
+					bearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+				},
+			},
+		},
+		tags: make(map[string]bool),
+	}
+	g.schemas = newSchemaRegistry(g.doc.Components.Schemas)
+	g.doc.Components.Schemas[responseSchema] = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"code":      {Type: "integer", Description: "业务状态码, 与http状态码一致"},
+			"data":      {Type: "object", Nullable: true},
+			"message":   {Type: "string"},
+			"requestId": {Type: "string", Description: "请求ID, 用于定位日志"},
+		},
+	}
+	return g
+}
+
+// 添加接口, path为gin的路由路径(不含接口前缀)
+func (g *Generator) Add(method string, path string, summary string, tag string, doc Doc) {
+	oasPath, params := convertPath(path)
+	op := &Operation{
+		Summary:     summary,
+		OperationID: operationID(method, path),
+		Parameters:  params,
+		Responses:   make(map[string]*Response),
+		Security:    []map[string][]string{{bearerAuth: {}}, {apiKeyAuth: {}}},
+	}
+	if tag != "" {
+		op.Tags = []string{tag}
+		g.tags[tag] = true
+	}
+	if doc.Public {
+		op.Security = []map[string][]string{}
+	}
+
+	g.addRequest(op, method, doc)
+	g.addResponses(op, doc)
+
+	item, ok := g.doc.Paths[oasPath]
+	if !ok {
+		item = &PathItem{}
+		g.doc.Paths[oasPath] = item
+	}
+	(*item)[strings.ToLower(method)] = op
+}
+
+// 生成文档
+func (g *Generator) Document() *Document {
+	g.doc.Tags = make([]Tag, 0, len(g.tags))
+	for tag := range g.tags {
+		g.doc.Tags = append(g.doc.Tags, Tag{Name: tag})
+	}
+	sort.Slice(g.doc.Tags, func(i, j int) bool {
+		return g.doc.Tags[i].Name < g.doc.Tags[j].Name
+	})
+	return g.doc
+}
+
+// 与gin的ShouldBind一致, GET请求绑定查询参数, 其它请求绑定json请求体
+func (g *Generator) addRequest(op *Operation, method string, doc Doc) {
+	if len(doc.Files) > 0 {
+		schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		for _, name := range doc.Files {
+			schema.Properties[name] = &Schema{Type: "string", Format: "binary"}
+			schema.Required = append(schema.Required, name)
+		}
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{"multipart/form-data": {Schema: schema}}}
+		return
+	}
+	if doc.Request == nil {
+		return
+	}
+	t := reflect.TypeOf(doc.Request)
+	if method == http.MethodGet {
+		op.Parameters = append(op.Parameters, g.schemas.queryParameters(t)...)
+		return
+	}
+	op.RequestBody = &RequestBody{
+		Required: true,
+		Content:  map[string]*MediaType{"application/json": {Schema: g.schemas.schema(t)}},
+	}
+}
+
+func (g *Generator) addResponses(op *Operation, doc Doc) {
+	ref := &Schema{Ref: refPrefix + responseSchema}
+	if doc.Produces != "" {
+		op.Responses["200"] = &Response{
+			Description: "成功",
+			Content:     map[string]*MediaType{doc.Produces: {Schema: &Schema{Type: "string", Format: "binary"}}},
+		}
+	} else {
+		data := &Schema{Type: "object", Nullable: true}
+		if len(doc.Response) > 0 {
+			data = &Schema{Type: "object", Properties: make(map[string]*Schema, len(doc.Response))}
+			for key, value := range doc.Response {
+				data.Properties[key] = g.schemas.schema(reflect.TypeOf(value))
+			}
+		}
+		op.Responses["200"] = &Response{
+			Description: "成功",
+			Content: map[string]*MediaType{"application/json": {Schema: &Schema{AllOf: []*Schema{
+				ref,
+				{Type: "object", Properties: map[string]*Schema{"data": data}},
+			}}}},
+		}
+	}
+	op.Responses["400"] = &Response{Description: "参数错误或业务失败", Content: map[string]*MediaType{"application/json": {Schema: ref}}}
+	if !doc.Public {
+		op.Responses["401"] = &Response{Description: "未登录或没有权限", Content: map[string]*MediaType{"application/json": {Schema: ref}}}
+	}
+}
+
+var pathParam = regexp.MustCompile(`[:*](\w+)`)
+
+// gin路径参数转为OpenAPI路径参数, 如 /post/:postID 转为 /post/{postID}
+func convertPath(path string) (string, []*Parameter) {
+	var params []*Parameter
+	converted := pathParam.ReplaceAllStringFunc(path, func(s string) string {
+		params = append(params, &Parameter{Name: s[1:], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		return "{" + s[1:] + "}"
+	})
+	return converted, params
+}
+
+// 由请求方式与路径生成operationId, 如 GET /post/:postID 为 getPostByPostID
+func operationID(method string, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, segment := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '.' || r == '-' || r == '_' }) {
+		if segment[0] == ':' || segment[0] == '*' {
+			b.WriteString("By")
+			segment = segment[1:]
+		}
+		if segment == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(segment[:1]) + segment[1:])
+	}
+	return b.String()
+}
@@ -0,0 +1,283 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// components中schema的引用前缀
+const refPrefix = "#/components/schemas/"
+
+// 数据结构
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *uint64            `json:"minLength,omitempty"`
+	MaxLength            *uint64            `json:"maxLength,omitempty"`
+	MinItems             *uint64            `json:"minItems,omitempty"`
+	MaxItems             *uint64            `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+	rawJSONType   = reflect.TypeOf(json.RawMessage{})
+)
+
+// 具名结构体注册到components中, 以 包名.类型名 引用, 可以表示递归结构
+type schemaRegistry struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaRegistry(schemas map[string]*Schema) *schemaRegistry {
+	return &schemaRegistry{schemas: schemas, names: make(map[reflect.Type]string)}
+}
+
+// 类型对应的schema
+func (r *schemaRegistry) schema(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case deletedAtType:
+		return &Schema{Type: "string", Format: "date-time", Nullable: true}
+	case rawJSONType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: float(0)}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: r.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.structSchema(t)
+		}
+		return r.ref(t)
+	}
+	// interface{}等任意类型
+	return &Schema{}
+}
+
+// 具名结构体的引用, 先占位再生成属性, 避免递归结构无限展开
+func (r *schemaRegistry) ref(t reflect.Type) *Schema {
+	name, ok := r.names[t]
+	if !ok {
+		name = schemaName(t)
+		// 不同包下的同名类型加上完整包路径区分
+		if _, exists := r.schemas[name]; exists {
+			name = strings.ReplaceAll(t.PkgPath(), "/", ".") + "." + t.Name()
+		}
+		r.names[t] = name
+		r.schemas[name] = &Schema{}
+		*r.schemas[name] = *r.structSchema(t)
+	}
+	return &Schema{Ref: refPrefix + name}
+}
+
+// 结构体的属性, 匿名嵌入的结构体展开到当前结构体
+func (r *schemaRegistry) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	eachField(t, "json", func(name string, field reflect.StructField) {
+		fs := r.schema(field.Type)
+		if opts := tagOptions(field.Tag.Get("json")); opts["string"] {
+			fs = &Schema{Type: "string"}
+		}
+		if applyValidate(fs, field) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = fs
+	})
+	return s
+}
+
+// GET请求的查询参数, 参数名与gin一致取form标签
+func (r *schemaRegistry) queryParameters(t reflect.Type) []*Parameter {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var params []*Parameter
+	eachField(t, "form", func(name string, field reflect.StructField) {
+		s := r.schema(field.Type)
+		params = append(params, &Parameter{Name: name, In: "query", Required: applyValidate(s, field), Schema: s})
+	})
+	return params
+}
+
+// 遍历结构体的可导出字段, 名称取tag标签, 没有时取json标签, 都没有时取字段名
+func eachField(t reflect.Type, tag string, fn func(name string, field reflect.StructField)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		value, ok := field.Tag.Lookup(tag)
+		if !ok {
+			value, ok = field.Tag.Lookup("json")
+		}
+		name, _, _ := strings.Cut(value, ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && ft != timeType {
+				eachField(ft, tag, fn)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fn(name, field)
+	}
+}
+
+// 根据validate与binding标签补充约束, 返回是否必填
+func applyValidate(s *Schema, field reflect.StructField) bool {
+	required := false
+	for _, tag := range []string{field.Tag.Get("validate"), field.Tag.Get("binding")} {
+		for _, rule := range strings.Split(tag, ",") {
+			// dive之后的规则作用于元素
+			if rule == "dive" {
+				break
+			}
+			name, param, _ := strings.Cut(rule, "=")
+			switch name {
+			case "required":
+				required = true
+			case "min", "gte":
+				setBound(s, param, true)
+			case "max", "lte":
+				setBound(s, param, false)
+			case "len":
+				setBound(s, param, true)
+				setBound(s, param, false)
+			case "oneof":
+				for _, v := range strings.Fields(param) {
+					s.Enum = append(s.Enum, enumValue(s, v))
+				}
+			case "email":
+				s.Format = "email"
+			case "url", "uri":
+				s.Format = "uri"
+			}
+		}
+	}
+	return required
+}
+
+// 字符串为长度, 数组为元素个数, 数字为取值范围
+func setBound(s *Schema, param string, min bool) {
+	switch s.Type {
+	case "string":
+		if n, err := strconv.ParseUint(param, 10, 64); err == nil {
+			if min {
+				s.MinLength = &n
+			} else {
+				s.MaxLength = &n
+			}
+		}
+	case "array":
+		if n, err := strconv.ParseUint(param, 10, 64); err == nil {
+			if min {
+				s.MinItems = &n
+			} else {
+				s.MaxItems = &n
+			}
+		}
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(param, 64); err == nil {
+			if min {
+				s.Minimum = &n
+			} else {
+				s.Maximum = &n
+			}
+		}
+	}
+}
+
+func enumValue(s *Schema, v string) interface{} {
+	if s.Type == "integer" || s.Type == "number" {
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	}
+	return v
+}
+
+func tagOptions(tag string) map[string]bool {
+	opts := make(map[string]bool)
+	_, rest, _ := strings.Cut(tag, ",")
+	for _, opt := range strings.Split(rest, ",") {
+		opts[opt] = true
+	}
+	return opts
+}
+
+// 如 gotribe-admin/pkg/api/dto.PostsDto 为 dto.PostsDto
+func schemaName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	name := t.Name()
+	// 泛型类型名中含有[]等字符
+	name = strings.NewReplacer("[", "_", "]", "", "/", ".", "*", "", ",", "_", " ", "").Replace(name)
+	if pkg == "" {
+		return name
+	}
+	return pkg + "." + name
+}
+
+func float(v float64) *float64 {
+	return &v
+}
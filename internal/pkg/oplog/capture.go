@@ -0,0 +1,118 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package oplog
+
+import (
+	"mime"
+	"regexp"
+	"strings"
+)
+
+// 脱敏后的字段值
+const maskedValue = "******"
+
+// 默认配置
+const (
+	defaultRequestBodyLimit  = 4096
+	defaultResponseBodyLimit = 1024
+)
+
+// 默认脱敏字段, 签发凭证的路由应整体不记录请求体与响应体, 不依赖此列表
+var defaultMaskFields = []string{"password", "oldPassword", "newPassword", "token", "consigneePhone", "secretKey"}
+
+// 请求体与响应体记录配置, 零值字段使用默认值
+type CaptureOptions struct {
+	RequestBodyLimit  int      // 请求体最多记录的字节数, 小于0时不记录
+	ResponseBodyLimit int      // 响应体最多记录的字节数, 小于0时不记录
+	MaskFields        []string // 需要脱敏的字段名, 不区分大小写
+}
+
+// 请求体与响应体的截断与脱敏
+// 记录的内容可能已被截断无法完整解析, 因此按字段名正则匹配替换字段值
+type Capture struct {
+	opts      CaptureOptions
+	jsonField *regexp.Regexp
+	formField *regexp.Regexp
+}
+
+// Capture构造函数
+func NewCapture(opts CaptureOptions) *Capture {
+	if opts.RequestBodyLimit == 0 {
+		opts.RequestBodyLimit = defaultRequestBodyLimit
+	}
+	if opts.ResponseBodyLimit == 0 {
+		opts.ResponseBodyLimit = defaultResponseBodyLimit
+	}
+	if opts.MaskFields == nil {
+		opts.MaskFields = defaultMaskFields
+	}
+
+	c := &Capture{opts: opts}
+	var names []string
+	for _, field := range opts.MaskFields {
+		if field = strings.TrimSpace(field); field != "" {
+			names = append(names, regexp.QuoteMeta(field))
+		}
+	}
+	if len(names) > 0 {
+		fields := strings.Join(names, "|")
+		// json字段值可以是字符串(含转义, 截断时可能缺少结尾引号)或数字、布尔等字面量
+		c.jsonField = regexp.MustCompile(`(?i)("(?:` + fields + `)"\s*:\s*)(?:"(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`)
+		c.formField = regexp.MustCompile(`(?i)((?:^|&)(?:` + fields + `)=)[^&]*`)
+	}
+	return c
+}
+
+// 请求体最多记录的字节数, 返回0表示不记录
+func (c *Capture) RequestBodyLimit() int {
+	return limit(c.opts.RequestBodyLimit)
+}
+
+// 响应体最多记录的字节数, 返回0表示不记录
+func (c *Capture) ResponseBodyLimit() int {
+	return limit(c.opts.ResponseBodyLimit)
+}
+
+func limit(n int) int {
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// 是否记录该类型的内容, 只记录json、表单与纯文本, 不记录文件上传等二进制内容
+func Capturable(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" ||
+		strings.HasSuffix(mediaType, "+json") ||
+		mediaType == "application/x-www-form-urlencoded" ||
+		strings.HasPrefix(mediaType, "text/")
+}
+
+// 截断并脱敏, truncated表示body已被截断
+func (c *Capture) Mask(contentType string, body []byte, truncated bool) string {
+	if len(body) == 0 {
+		return ""
+	}
+	// 截断处可能落在多字节字符中间, 去掉不完整的字符
+	text := strings.ToValidUTF8(string(body), "")
+
+	if c.jsonField != nil {
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		if mediaType == "application/x-www-form-urlencoded" {
+			text = c.formField.ReplaceAllString(text, "${1}"+maskedValue)
+		} else {
+			text = c.jsonField.ReplaceAllString(text, `${1}"`+maskedValue+`"`)
+		}
+	}
+	if truncated {
+		text += "...(truncated)"
+	}
+	return text
+}
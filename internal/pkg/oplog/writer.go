@@ -0,0 +1,346 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+// Package oplog 操作日志的批量异步写入
+package oplog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gotribe-admin/internal/pkg/metrics"
+	"gotribe-admin/internal/pkg/model"
+)
+
+// 队列已满或写库失败时的处理策略
+const (
+	OverflowDrop  = "drop"  // 丢弃并计数
+	OverflowSpill = "spill" // 追加到本地文件, 下次启动时补写入库
+)
+
+// 默认配置
+const (
+	defaultBatchSize     = 100
+	defaultFlushInterval = time.Second
+	defaultQueueSize     = 1000
+	defaultSpillPath     = "logs/operation_log.spill"
+)
+
+// 批量写入数据库的函数
+type SaveFunc func(ctx context.Context, logs []model.OperationLog) error
+
+// 写入器配置, 零值字段使用默认值
+type Options struct {
+	BatchSize     int           // 每批最多写入的条数, 攒够后立即写入
+	FlushInterval time.Duration // 不足一批时的最长等待时间
+	QueueSize     int           // 内存队列长度
+	Overflow      string        // 溢出策略, drop或spill
+	SpillPath     string        // spill策略的本地文件
+}
+
+// 操作日志写入器, 请求处理中只入队, 由后台协程按批写入数据库
+type Writer struct {
+	opts    Options
+	save    SaveFunc
+	log     *zap.SugaredLogger
+	metrics *metrics.Metrics
+
+	queue   chan *model.OperationLog
+	flushes chan chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+
+	// 关闭后不再入队, 写锁保证关闭前入队的日志都能被后台协程取到
+	mu      sync.RWMutex
+	closed  bool
+	started bool
+
+	spillMu sync.Mutex
+}
+
+// Writer构造函数, m为nil时不记录指标
+func NewWriter(opts Options, save SaveFunc, log *zap.SugaredLogger, m *metrics.Metrics) *Writer {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultFlushInterval
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaultQueueSize
+	}
+	if opts.Overflow != OverflowSpill {
+		opts.Overflow = OverflowDrop
+	}
+	if opts.SpillPath == "" {
+		opts.SpillPath = defaultSpillPath
+	}
+	return &Writer{
+		opts:    opts,
+		save:    save,
+		log:     log,
+		metrics: m,
+		queue:   make(chan *model.OperationLog, opts.QueueSize),
+		flushes: make(chan chan struct{}),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+// 队列中等待写入的日志数
+func (w *Writer) Len() int {
+	return len(w.queue)
+}
+
+// 日志入队, 不阻塞请求; 队列已满或写入器已关闭时按溢出策略处理
+func (w *Writer) Write(log *model.OperationLog) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if !w.closed {
+		select {
+		case w.queue <- log:
+			return
+		default:
+		}
+	}
+	w.overflow([]model.OperationLog{*log})
+}
+
+// 启动后台写入协程, spill策略下先将上次遗留的文件补写入库
+func (w *Writer) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.started || w.closed {
+		return
+	}
+	w.started = true
+	if w.opts.Overflow == OverflowSpill {
+		w.replaySpill()
+	}
+	go w.run()
+}
+
+// 立即写入队列中的全部日志, ctx结束时提前返回
+func (w *Writer) Flush(ctx context.Context) error {
+	w.mu.RLock()
+	started := w.started
+	w.mu.RUnlock()
+	if !started {
+		w.drain(ctx, nil)
+		return nil
+	}
+	ack := make(chan struct{})
+	select {
+	case w.flushes <- ack:
+	case <-w.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-ack:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// 停止接收新日志并写完队列中剩余的日志, ctx结束时放弃等待
+func (w *Writer) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	started := w.started
+	w.mu.Unlock()
+
+	close(w.done)
+	if !started {
+		// 未启动时直接在当前协程写入
+		w.drain(ctx, nil)
+		close(w.stopped)
+		return nil
+	}
+	select {
+	case <-w.stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("等待操作日志写入超时, 队列中剩余%d条: %w", w.Len(), ctx.Err())
+	}
+}
+
+// 后台写入循环, 攒够一批或到达间隔时写入
+func (w *Writer) run() {
+	defer close(w.stopped)
+	ticker := time.NewTicker(w.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]model.OperationLog, 0, w.opts.BatchSize)
+	for {
+		select {
+		case log := <-w.queue:
+			batch = append(batch, *log)
+			if len(batch) >= w.opts.BatchSize {
+				batch = w.flush(context.Background(), batch)
+			}
+		case <-ticker.C:
+			batch = w.flush(context.Background(), batch)
+		case ack := <-w.flushes:
+			batch = w.drain(context.Background(), batch)
+			close(ack)
+		case <-w.done:
+			w.drain(context.Background(), batch)
+			return
+		}
+	}
+}
+
+// 取出队列中现有的全部日志并按批写入
+func (w *Writer) drain(ctx context.Context, batch []model.OperationLog) []model.OperationLog {
+	for {
+		select {
+		case log := <-w.queue:
+			batch = append(batch, *log)
+			if len(batch) >= w.opts.BatchSize {
+				batch = w.flush(ctx, batch)
+			}
+		default:
+			return w.flush(ctx, batch)
+		}
+	}
+}
+
+// 写入一批日志, 失败时按溢出策略处理, 返回清空后的batch供复用
+func (w *Writer) flush(ctx context.Context, batch []model.OperationLog) []model.OperationLog {
+	if len(batch) == 0 {
+		return batch
+	}
+	if err := w.save(ctx, batch); err != nil {
+		w.log.Errorf("写入操作日志失败: %v", err)
+		w.overflow(batch)
+	}
+	return batch[:0]
+}
+
+// 按溢出策略处理无法入库的日志
+func (w *Writer) overflow(logs []model.OperationLog) {
+	if w.opts.Overflow == OverflowSpill {
+		err := w.spill(logs)
+		if err == nil {
+			w.observe(OverflowSpill, len(logs))
+			return
+		}
+		w.log.Errorf("操作日志写入本地文件失败: %v", err)
+	}
+	w.observe(OverflowDrop, len(logs))
+}
+
+func (w *Writer) observe(action string, count int) {
+	if w.metrics != nil {
+		w.metrics.OperationLogOverflow(action, count)
+	}
+}
+
+// 以json lines格式追加到本地文件
+func (w *Writer) spill(logs []model.OperationLog) error {
+	w.spillMu.Lock()
+	defer w.spillMu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(w.opts.SpillPath), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(w.opts.SpillPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	encoder := json.NewEncoder(file)
+	for i := range logs {
+		if err := encoder.Encode(&logs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 将本地文件中的日志补写入库, 全部成功后删除文件, 中途失败时只保留未写入的部分
+func (w *Writer) replaySpill() {
+	w.spillMu.Lock()
+	defer w.spillMu.Unlock()
+	logs, err := w.readSpill()
+	if err != nil {
+		if !os.IsNotExist(err) {
+			w.log.Errorf("读取操作日志本地文件失败: %v", err)
+		}
+		return
+	}
+	for start := 0; start < len(logs); start += w.opts.BatchSize {
+		end := start + w.opts.BatchSize
+		if end > len(logs) {
+			end = len(logs)
+		}
+		if err := w.save(context.Background(), logs[start:end]); err != nil {
+			w.log.Errorf("补写操作日志失败, 剩余%d条保留在本地文件: %v", len(logs)-start, err)
+			if err := w.rewriteSpill(logs[start:]); err != nil {
+				w.log.Errorf("重写操作日志本地文件失败: %v", err)
+			}
+			return
+		}
+	}
+	if err := os.Remove(w.opts.SpillPath); err != nil {
+		w.log.Errorf("删除操作日志本地文件失败: %v", err)
+	}
+}
+
+// 读取本地文件中的全部日志, 跳过无法解析的行
+func (w *Writer) readSpill() ([]model.OperationLog, error) {
+	file, err := os.Open(w.opts.SpillPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var logs []model.OperationLog
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var log model.OperationLog
+		if err := json.Unmarshal(scanner.Bytes(), &log); err != nil {
+			continue
+		}
+		// 重新分配主键, 避免与已有记录冲突
+		log.ID = 0
+		logs = append(logs, log)
+	}
+	return logs, scanner.Err()
+}
+
+// 用未写入的日志覆盖本地文件
+func (w *Writer) rewriteSpill(logs []model.OperationLog) error {
+	tmpPath := w.opts.SpillPath + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	for i := range logs {
+		if err := encoder.Encode(&logs[i]); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, w.opts.SpillPath)
+}
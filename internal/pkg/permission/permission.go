@@ -0,0 +1,190 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+// Package permission 接口权限校验与解释, CasbinMiddleware与权限诊断接口共用同一套规则
+package permission
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/util"
+	"github.com/thoas/go-funk"
+	"gotribe-admin/config"
+	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/pkg/api/known"
+)
+
+// 返回的最接近的未匹配策略数量
+const closestLimit = 5
+
+// 策略
+type Policy struct {
+	Subject string `json:"subject"`          // 发起校验的角色(用户拥有的角色)
+	Role    string `json:"role"`             // 策略所属角色, 继承时为上级角色
+	Path    string `json:"path"`             // 策略路径
+	Method  string `json:"method"`           // 策略请求方式
+	Reason  string `json:"reason,omitempty"` // 未匹配的原因
+	score   int
+}
+
+// 校验结果
+type Decision struct {
+	Allowed bool     `json:"allowed"`
+	Matched *Policy  `json:"matchedPolicy"`   // 命中的策略
+	Closest []Policy `json:"closestPolicies"` // 未通过时最接近的策略
+}
+
+// 是否为不受权限限制的超级管理员
+func IsSuperAdmin(admin model.Admin) bool {
+	return admin.ID == known.DEFAULT_ID
+}
+
+// 用户参与校验的角色关键字以及被禁用的角色, 被禁用的角色不参与校验
+func Subjects(admin model.Admin) ([]string, []*model.Role) {
+	var subs []string
+	var disabled []*model.Role
+	for _, role := range admin.Roles {
+		if role.Status == known.DEFAULT_ID {
+			subs = append(subs, role.Keyword)
+		} else {
+			disabled = append(disabled, role)
+		}
+	}
+	return subs, disabled
+}
+
+// 请求路径转为策略中的路径, 去掉接口前缀
+func Object(path string) string {
+	return strings.TrimPrefix(path, "/"+config.Conf.System.UrlPathPrefix)
+}
+
+// 任一角色通过即通过, SyncedCachedEnforcer并发安全, 校验结果带缓存
+func Allowed(enforcer *casbin.SyncedCachedEnforcer, subs []string, obj string, act string) bool {
+	for _, sub := range subs {
+		if pass, _ := enforcer.Enforce(sub, obj, act); pass {
+			return true
+		}
+	}
+	return false
+}
+
+// 校验并返回命中的策略, 未通过时返回最接近的策略
+func Explain(enforcer *casbin.SyncedCachedEnforcer, subs []string, obj string, act string) (Decision, error) {
+	decision := Decision{Closest: make([]Policy, 0)}
+	for _, sub := range subs {
+		pass, rule, err := enforcer.EnforceEx(sub, obj, act)
+		if err != nil {
+			return decision, err
+		}
+		if pass {
+			decision.Allowed = true
+			decision.Matched = &Policy{Subject: sub, Role: rule[0], Path: rule[1], Method: rule[2]}
+			return decision, nil
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, sub := range subs {
+		// 包括从上级角色继承的策略
+		rules, err := enforcer.GetImplicitPermissionsForUser(sub)
+		if err != nil {
+			return decision, err
+		}
+		for _, rule := range rules {
+			key := strings.Join(rule, " ")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if policy := compare(sub, rule, obj, act); policy.score > 0 {
+				decision.Closest = append(decision.Closest, policy)
+			}
+		}
+	}
+	sort.SliceStable(decision.Closest, func(i, j int) bool {
+		return decision.Closest[i].score > decision.Closest[j].score
+	})
+	if len(decision.Closest) > closestLimit {
+		decision.Closest = decision.Closest[:closestLimit]
+	}
+	return decision, nil
+}
+
+// 路径是否匹配, 与rbac_model.conf中的匹配规则一致
+func PathMatch(obj string, pattern string) bool {
+	return util.KeyMatch2(obj, pattern) || util.KeyMatch(obj, pattern)
+}
+
+// 比较未匹配的策略与请求的接近程度, 路径匹配只差请求方式的最接近, 其次按相同的路径前缀段数
+func compare(sub string, rule []string, obj string, act string) Policy {
+	policy := Policy{Subject: sub, Role: rule[0], Path: rule[1], Method: rule[2]}
+	pathMatch := PathMatch(obj, policy.Path)
+	methodMatch := policy.Method == act || policy.Method == "*"
+	switch {
+	case pathMatch:
+		policy.Reason = "请求方式不匹配"
+		policy.score = 1000
+	case methodMatch:
+		policy.Reason = "路径不匹配"
+		policy.score = commonSegments(obj, policy.Path) * 10
+	default:
+		policy.Reason = "路径与请求方式均不匹配"
+		policy.score = commonSegments(obj, policy.Path) * 5
+	}
+	return policy
+}
+
+// 相同的路径前缀段数
+func commonSegments(a string, b string) int {
+	as := strings.Split(strings.Trim(a, "/"), "/")
+	bs := strings.Split(strings.Trim(b, "/"), "/")
+	n := 0
+	for n < len(as) && n < len(bs) && as[n] == bs[n] && as[n] != "" {
+		n++
+	}
+	return n
+}
+
+// 用户对一个接口的权限
+type MatrixRow struct {
+	ID       uint     `json:"id"`
+	Method   string   `json:"method"`
+	Path     string   `json:"path"`
+	Category string   `json:"category"`
+	Desc     string   `json:"desc"`
+	Allowed  bool     `json:"allowed"`
+	Roles    []string `json:"roles"` // 授权的策略所属角色, 继承时为上级角色
+}
+
+// 用户对全部接口的有效权限
+func Matrix(enforcer *casbin.SyncedCachedEnforcer, subs []string, apis []*model.Api) ([]MatrixRow, error) {
+	rows := make([]MatrixRow, 0, len(apis))
+	for _, api := range apis {
+		row := MatrixRow{
+			ID:       api.ID,
+			Method:   api.Method,
+			Path:     api.Path,
+			Category: api.Category,
+			Desc:     api.Desc,
+			Roles:    make([]string, 0),
+		}
+		for _, sub := range subs {
+			pass, rule, err := enforcer.EnforceEx(sub, api.Path, api.Method)
+			if err != nil {
+				return nil, err
+			}
+			if pass {
+				row.Allowed = true
+				if !funk.ContainsString(row.Roles, rule[0]) {
+					row.Roles = append(row.Roles, rule[0])
+				}
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,426 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package seed
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/casbin/casbin/v2"
+	"gorm.io/gorm"
+	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/pkg/api/known"
+	"gotribe-admin/pkg/util"
+)
+
+const creator = "系统"
+
+// 写入初始数据
+// 角色与菜单、接口的关联只在任意一方为本次新写入时建立, 避免覆盖后台中手动调整过的权限
+type seeder struct {
+	db           *gorm.DB
+	enforcer     *casbin.SyncedCachedEnforcer
+	roles        map[string]*model.Role // keyword -> 角色
+	createdRoles map[string]bool        // 本次新写入的角色
+	allApiRoles  []string               // 拥有全部接口权限的角色
+	menus        map[string]*model.Menu // 完整路径 -> 菜单
+	rules        [][]string             // 待写入的casbin策略
+}
+
+func newSeeder(db *gorm.DB, enforcer *casbin.SyncedCachedEnforcer) *seeder {
+	return &seeder{
+		db:           db,
+		enforcer:     enforcer,
+		roles:        make(map[string]*model.Role),
+		createdRoles: make(map[string]bool),
+		menus:        make(map[string]*model.Menu),
+	}
+}
+
+// 按角色、菜单、接口、管理员、内容的顺序写入, 各文件之间可以相互引用
+func (s *seeder) apply(list []*Fixture) error {
+	for _, f := range list {
+		for _, role := range f.Roles {
+			if err := s.applyRole(role); err != nil {
+				return err
+			}
+		}
+	}
+	var roles []*model.Role
+	if err := s.db.Find(&roles).Error; err != nil {
+		return err
+	}
+	for _, role := range roles {
+		s.roles[role.Keyword] = role
+	}
+
+	if err := s.loadMenus(); err != nil {
+		return err
+	}
+	if err := s.applyMenus(list); err != nil {
+		return err
+	}
+
+	for _, f := range list {
+		for _, api := range f.Apis {
+			if err := s.applyApi(api); err != nil {
+				return err
+			}
+		}
+	}
+	if err := s.grantAllApis(); err != nil {
+		return err
+	}
+	if err := s.addPolicies(); err != nil {
+		return err
+	}
+
+	for _, f := range list {
+		for _, admin := range f.Admins {
+			if err := s.applyAdmin(admin); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.applyContent(list)
+}
+
+// 写入角色
+func (s *seeder) applyRole(f RoleFixture) error {
+	if f.Keyword == "" {
+		return errors.New("角色keyword不能为空")
+	}
+	if f.AllApis && !s.isAllApiRole(f.Keyword) {
+		s.allApiRoles = append(s.allApiRoles, f.Keyword)
+	}
+	role := &model.Role{
+		Name:    f.Name,
+		Keyword: f.Keyword,
+		Desc:    &f.Desc,
+		Sort:    f.Sort,
+		Status:  f.Status,
+		Creator: creator,
+	}
+	if f.AllProjects {
+		role.AllProjects = known.DEFAULT_ID
+	}
+	created, err := s.createIfMissing(role, "keyword = ?", f.Keyword)
+	if err != nil {
+		return fmt.Errorf("写入角色%s失败: %v", f.Keyword, err)
+	}
+	if created {
+		s.createdRoles[f.Keyword] = true
+	}
+	return nil
+}
+
+func (s *seeder) isAllApiRole(keyword string) bool {
+	for _, k := range s.allApiRoles {
+		if k == keyword {
+			return true
+		}
+	}
+	return false
+}
+
+// 根据keyword获取角色
+func (s *seeder) findRoles(keywords []string) ([]*model.Role, error) {
+	roles := make([]*model.Role, 0, len(keywords))
+	for _, keyword := range keywords {
+		role, ok := s.roles[keyword]
+		if !ok {
+			return nil, fmt.Errorf("角色%s不存在", keyword)
+		}
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+// 读取已有菜单并计算完整路径
+func (s *seeder) loadMenus() error {
+	var menus []*model.Menu
+	if err := s.db.Find(&menus).Error; err != nil {
+		return err
+	}
+	byID := make(map[uint]*model.Menu, len(menus))
+	for _, menu := range menus {
+		byID[menu.ID] = menu
+	}
+	for _, menu := range menus {
+		fullPath := menu.Path
+		for parent := menu; !strings.HasPrefix(fullPath, "/") && parent.ParentID != nil; {
+			if parent = byID[*parent.ParentID]; parent == nil {
+				break
+			}
+			fullPath = joinMenuPath(parent.Path, fullPath)
+		}
+		s.menus[fullPath] = menu
+	}
+	return nil
+}
+
+// 子菜单使用相对路径时拼接父菜单路径
+func joinMenuPath(parent, p string) string {
+	if strings.HasPrefix(p, "/") || parent == "" {
+		return p
+	}
+	return strings.TrimSuffix(parent, "/") + "/" + p
+}
+
+// 写入全部文件中的菜单, 父菜单可以定义在任意文件中
+// 文件按名称顺序读取, 父菜单所在的文件可能排在后面, 因此逐轮写入父菜单已存在的菜单, 直到全部写入
+func (s *seeder) applyMenus(list []*Fixture) error {
+	var pending []MenuFixture
+	for _, f := range list {
+		pending = append(pending, f.Menus...)
+	}
+	for len(pending) > 0 {
+		var rest []MenuFixture
+		for _, menu := range pending {
+			if _, ok := s.menus[menu.Parent]; menu.Parent != "" && !ok {
+				rest = append(rest, menu)
+				continue
+			}
+			if err := s.applyMenu(menu, menu.Parent); err != nil {
+				return err
+			}
+		}
+		// 本轮没有新写入的菜单, 剩余菜单的父菜单不存在
+		if len(rest) == len(pending) {
+			menu := rest[0]
+			return fmt.Errorf("菜单%s的父菜单%s不存在", joinMenuPath(menu.Parent, menu.Path), menu.Parent)
+		}
+		pending = rest
+	}
+	return nil
+}
+
+// 写入菜单及其子菜单
+func (s *seeder) applyMenu(f MenuFixture, parentPath string) error {
+	fullPath := joinMenuPath(parentPath, f.Path)
+	if fullPath == "" {
+		return fmt.Errorf("菜单%s的路径不能为空", f.Name)
+	}
+	roles, err := s.findRoles(f.Roles)
+	if err != nil {
+		return fmt.Errorf("写入菜单%s失败: %v", fullPath, err)
+	}
+
+	menu, ok := s.menus[fullPath]
+	if ok {
+		// 菜单已存在, 仅关联本次新写入的角色
+		for _, role := range roles {
+			if !s.createdRoles[role.Keyword] {
+				continue
+			}
+			if err := s.db.Model(menu).Association("Roles").Append(role); err != nil {
+				return fmt.Errorf("关联菜单%s与角色%s失败: %v", fullPath, role.Keyword, err)
+			}
+		}
+	} else {
+		var parentID uint
+		if parentPath != "" {
+			parent, ok := s.menus[parentPath]
+			if !ok {
+				return fmt.Errorf("菜单%s的父菜单%s不存在", fullPath, parentPath)
+			}
+			parentID = parent.ID
+		}
+		menu = &f.Menu
+		menu.ParentID = &parentID
+		menu.Roles = roles
+		menu.Children = nil
+		if menu.Creator == "" {
+			menu.Creator = creator
+		}
+		if err := s.db.Create(menu).Error; err != nil {
+			return fmt.Errorf("写入菜单%s失败: %v", fullPath, err)
+		}
+		s.menus[fullPath] = menu
+	}
+
+	for _, child := range f.Children {
+		if err := s.applyMenu(child, fullPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 写入接口, 并为相关角色添加访问权限
+func (s *seeder) applyApi(f ApiFixture) error {
+	api := f.Api
+	api.Method = strings.ToUpper(api.Method)
+	if api.Method == "" || api.Path == "" {
+		return fmt.Errorf("接口%s %s的method与path不能为空", api.Method, api.Path)
+	}
+	if api.Creator == "" {
+		api.Creator = creator
+	}
+	roles, err := s.findRoles(f.Roles)
+	if err != nil {
+		return fmt.Errorf("写入接口%s %s失败: %v", api.Method, api.Path, err)
+	}
+	created, err := s.createIfMissing(&api, "method = ? AND path = ?", api.Method, api.Path)
+	if err != nil {
+		return fmt.Errorf("写入接口%s %s失败: %v", api.Method, api.Path, err)
+	}
+
+	for _, role := range roles {
+		if created || s.createdRoles[role.Keyword] {
+			s.rules = append(s.rules, []string{role.Keyword, api.Path, api.Method})
+		}
+	}
+	// 拥有全部接口权限的角色, 新角色在grantAllApis中统一处理
+	if created {
+		for _, keyword := range s.allApiRoles {
+			if !s.createdRoles[keyword] {
+				s.rules = append(s.rules, []string{keyword, api.Path, api.Method})
+			}
+		}
+	}
+	return nil
+}
+
+// 为新写入的拥有全部接口权限的角色添加所有接口的访问权限
+func (s *seeder) grantAllApis() error {
+	var apis []model.Api
+	for _, keyword := range s.allApiRoles {
+		if !s.createdRoles[keyword] {
+			continue
+		}
+		if apis == nil {
+			if err := s.db.Find(&apis).Error; err != nil {
+				return err
+			}
+		}
+		for _, api := range apis {
+			s.rules = append(s.rules, []string{keyword, api.Path, api.Method})
+		}
+	}
+	return nil
+}
+
+// 写入casbin策略, 跳过已存在的策略
+func (s *seeder) addPolicies() error {
+	rules := make([][]string, 0, len(s.rules))
+	seen := make(map[string]bool, len(s.rules))
+	for _, rule := range s.rules {
+		key := strings.Join(rule, " ")
+		if seen[key] || s.enforcer.HasPolicy(rule[0], rule[1], rule[2]) {
+			continue
+		}
+		seen[key] = true
+		rules = append(rules, rule)
+	}
+	s.rules = nil
+	if len(rules) == 0 {
+		return nil
+	}
+	if _, err := s.enforcer.AddPolicies(rules); err != nil {
+		return fmt.Errorf("写入casbin数据失败: %v", err)
+	}
+	return nil
+}
+
+// 写入管理员
+func (s *seeder) applyAdmin(f AdminFixture) error {
+	admin := f.Admin
+	if admin.Username == "" || admin.Password == "" {
+		return errors.New("管理员username与password不能为空")
+	}
+	roles, err := s.findRoles(f.Roles)
+	if err != nil {
+		return fmt.Errorf("写入管理员%s失败: %v", admin.Username, err)
+	}
+	admin.Password = util.GenPasswd(admin.Password)
+	admin.Roles = roles
+	if admin.Nickname == nil {
+		admin.Nickname = new(string)
+	}
+	if admin.Introduction == nil {
+		admin.Introduction = new(string)
+	}
+	if admin.Creator == "" {
+		admin.Creator = creator
+	}
+	if _, err := s.createIfMissing(&admin, "username = ?", admin.Username); err != nil {
+		return fmt.Errorf("写入管理员%s失败: %v", admin.Username, err)
+	}
+	return nil
+}
+
+// 写入项目、分类、标签、配置、用户、文章与后台配置
+func (s *seeder) applyContent(list []*Fixture) error {
+	for _, f := range list {
+		for i := range f.Projects {
+			item := &f.Projects[i]
+			if _, err := s.createIfMissing(item, "project_id = ?", item.ProjectID); err != nil {
+				return fmt.Errorf("写入项目%s失败: %v", item.ProjectID, err)
+			}
+		}
+		for i := range f.Categories {
+			item := &f.Categories[i]
+			if _, err := s.createIfMissing(item, "category_id = ?", item.CategoryID); err != nil {
+				return fmt.Errorf("写入分类%s失败: %v", item.CategoryID, err)
+			}
+		}
+		for i := range f.Tags {
+			item := &f.Tags[i]
+			if _, err := s.createIfMissing(item, "title = ?", item.Title); err != nil {
+				return fmt.Errorf("写入标签%s失败: %v", item.Title, err)
+			}
+		}
+		for i := range f.Configs {
+			item := &f.Configs[i]
+			if _, err := s.createIfMissing(item, "alias = ?", item.Alias); err != nil {
+				return fmt.Errorf("写入配置%s失败: %v", item.Alias, err)
+			}
+		}
+		for i := range f.Users {
+			item := &f.Users[i]
+			if _, err := s.createIfMissing(item, "username = ?", item.Username); err != nil {
+				return fmt.Errorf("写入用户%s失败: %v", item.Username, err)
+			}
+		}
+		for i := range f.Posts {
+			item := &f.Posts[i]
+			if _, err := s.createIfMissing(item, "post_id = ?", item.PostID); err != nil {
+				return fmt.Errorf("写入文章%s失败: %v", item.PostID, err)
+			}
+		}
+		for i := range f.SystemConfigs {
+			item := &f.SystemConfigs[i]
+			if _, err := s.createIfMissing(item, "title = ?", item.Title); err != nil {
+				return fmt.Errorf("写入后台配置%s失败: %v", item.Title, err)
+			}
+		}
+	}
+	return nil
+}
+
+// 按唯一键查询, 不存在时写入, 返回是否写入
+// 已被软删除的数据视为存在, 不会重新写入
+func (s *seeder) createIfMissing(record interface{}, query string, args ...interface{}) (bool, error) {
+	for _, arg := range args {
+		if arg == "" {
+			return false, errors.New("唯一键不能为空")
+		}
+	}
+	var count int64
+	if err := s.db.Unscoped().Model(record).Where(query, args...).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+	if err := s.db.Create(record).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,218 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package seed
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/casbin/casbin/v2"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+	"gotribe-admin/config"
+	"gotribe-admin/internal/pkg/common"
+	"gotribe-admin/internal/pkg/model"
+)
+
+// 内置初始数据
+//
+//go:embed fixtures
+var fixtures embed.FS
+
+type source struct {
+	name string
+	fsys fs.FS
+}
+
+var (
+	sourcesMu sync.Mutex
+	sources   []source
+)
+
+// 注册额外的初始数据目录, 供插件或二次开发使用, 需在写入初始数据前调用
+// fsys根目录下的 yml/yaml/json 文件会在内置初始数据之后按文件名顺序写入
+func Register(name string, fsys fs.FS) {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	sources = append(sources, source{name: name, fsys: fsys})
+}
+
+// 初始数据文件, 各字段名与对应模型的json字段一致
+type Fixture struct {
+	Roles         []RoleFixture        `json:"roles"`
+	Menus         []MenuFixture        `json:"menus"`
+	Apis          []ApiFixture         `json:"apis"`
+	Admins        []AdminFixture       `json:"admins"`
+	Projects      []model.Project      `json:"projects"`
+	Categories    []model.Category     `json:"categories"`
+	Tags          []model.Tag          `json:"tags"`
+	Configs       []model.Config       `json:"configs"`
+	Users         []model.User         `json:"users"`
+	Posts         []model.Post         `json:"posts"`
+	SystemConfigs []model.SystemConfig `json:"systemConfigs"`
+}
+
+// 角色, 按keyword判断是否存在
+type RoleFixture struct {
+	Name    string `json:"name"`
+	Keyword string `json:"keyword"`
+	Desc    string `json:"desc"`
+	Sort    uint   `json:"sort"`
+	Status  uint   `json:"status"`
+	AllApis bool   `json:"allApis"` // 拥有全部接口权限
+	// 可管理全部项目, 否则只能管理分配的项目
+	AllProjects bool `json:"allProjects"`
+}
+
+// 菜单, 按完整路径判断是否存在
+type MenuFixture struct {
+	model.Menu
+	Parent   string        `json:"parent"`   // 父菜单完整路径, 用于挂载到其它文件中的菜单下
+	Roles    []string      `json:"roles"`    // 可访问的角色keyword
+	Children []MenuFixture `json:"children"` // 子菜单
+}
+
+// 接口, 按method+path判断是否存在
+type ApiFixture struct {
+	model.Api
+	Roles []string `json:"roles"` // 可访问的角色keyword
+}
+
+// 管理员, 按username判断是否存在, 密码为明文
+type AdminFixture struct {
+	model.Admin
+	Roles []string `json:"roles"` // 角色keyword
+}
+
+// 启动时写入初始数据
+func InitData(db *gorm.DB, enforcer *casbin.SyncedCachedEnforcer) {
+	// 是否初始化数据
+	if !config.Conf.System.InitData {
+		return
+	}
+	if err := Run(db, enforcer); err != nil {
+		common.Log.Errorf("写入初始数据失败：%v", err)
+	}
+}
+
+// 依次读取内置数据、注册的数据目录与system.seed-dirs配置的目录并写入数据库
+// 已存在的数据不会重复写入, 可以多次执行
+func Run(db *gorm.DB, enforcer *casbin.SyncedCachedEnforcer) error {
+	list, err := loadAll()
+	if err != nil {
+		return err
+	}
+	return newSeeder(db, enforcer).apply(list)
+}
+
+// 初始数据中登记的全部接口, 作为路由的所属类别与说明, key为"METHOD path"
+// 与写入初始数据时一致, 同一接口以先读取的为准
+func ApiCatalog() (map[string]model.Api, error) {
+	list, err := loadAll()
+	if err != nil {
+		return nil, err
+	}
+	catalog := make(map[string]model.Api)
+	for _, fixture := range list {
+		for _, f := range fixture.Apis {
+			api := f.Api
+			api.Method = strings.ToUpper(api.Method)
+			key := api.Method + " " + api.Path
+			if _, ok := catalog[key]; !ok {
+				catalog[key] = api
+			}
+		}
+	}
+	return catalog, nil
+}
+
+// 依次读取内置数据、注册的数据目录与system.seed-dirs配置的目录
+func loadAll() ([]*Fixture, error) {
+	sub, err := fs.Sub(fixtures, "fixtures")
+	if err != nil {
+		return nil, err
+	}
+	all := []source{{name: "内置数据", fsys: sub}}
+	sourcesMu.Lock()
+	all = append(all, sources...)
+	sourcesMu.Unlock()
+	for _, dir := range config.Conf.System.SeedDirs {
+		all = append(all, source{name: dir, fsys: os.DirFS(dir)})
+	}
+
+	var list []*Fixture
+	for _, src := range all {
+		items, err := load(src)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, items...)
+	}
+	return list, nil
+}
+
+// 读取目录下的全部数据文件
+func load(src source) ([]*Fixture, error) {
+	entries, err := fs.ReadDir(src.fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("读取初始数据目录%s失败: %v", src.name, err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		ext := strings.ToLower(path.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yml" && ext != ".yaml" && ext != ".json") {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	list := make([]*Fixture, 0, len(names))
+	for _, name := range names {
+		data, err := fs.ReadFile(src.fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		fixture, err := parse(name, data)
+		if err != nil {
+			return nil, fmt.Errorf("解析初始数据文件%s/%s失败: %v", src.name, name, err)
+		}
+		list = append(list, fixture)
+	}
+	return list, nil
+}
+
+// 解析数据文件, yaml先转为json再解析, 以复用模型上的json字段名
+func parse(name string, data []byte) (*Fixture, error) {
+	if !strings.EqualFold(path.Ext(name), ".json") {
+		var v interface{}
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+	fixture := new(Fixture)
+	if bytes.Equal(data, []byte("null")) {
+		return fixture, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(fixture); err != nil {
+		return nil, err
+	}
+	return fixture, nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errInvalidChallenge = errors.New("两步验证challenge无效或已过期")
+
+// 密码校验通过后签发的challenge, 在有效期内提交验证码换取token
+// 格式为 adminID.过期时间戳.签名, 不保存在服务端, 多实例部署时同样有效
+func NewChallenge(adminID uint, key []byte, ttl time.Duration) (string, time.Time) {
+	expires := time.Now().Add(ttl)
+	payload := strconv.FormatUint(uint64(adminID), 10) + "." + strconv.FormatInt(expires.Unix(), 10)
+	return payload + "." + sign(payload, key), expires
+}
+
+// 校验challenge, 返回用户ID
+func ParseChallenge(challenge string, key []byte) (uint, error) {
+	i := strings.LastIndex(challenge, ".")
+	if i < 0 {
+		return 0, errInvalidChallenge
+	}
+	payload, signature := challenge[:i], challenge[i+1:]
+	if !hmac.Equal([]byte(signature), []byte(sign(payload, key))) {
+		return 0, errInvalidChallenge
+	}
+	idStr, expiresStr, ok := strings.Cut(payload, ".")
+	if !ok {
+		return 0, errInvalidChallenge
+	}
+	expires, err := strconv.ParseInt(expiresStr, 10, 64)
+	if err != nil || time.Now().Unix() >= expires {
+		return 0, errInvalidChallenge
+	}
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil || id == 0 {
+		return 0, errInvalidChallenge
+	}
+	return uint(id), nil
+}
+
+func sign(payload string, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
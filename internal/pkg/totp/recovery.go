@@ -0,0 +1,66 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package totp
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+)
+
+// 恢复码个数, 每个恢复码只能使用一次
+const RecoveryCodeCount = 10
+
+// 恢复码字符, 去掉了容易混淆的字符
+const recoveryAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// 生成恢复码, 返回明文与以逗号分隔的哈希, 明文只在绑定时返回给用户
+func GenerateRecoveryCodes() ([]string, string, error) {
+	codes := make([]string, 0, RecoveryCodeCount)
+	hashes := make([]string, 0, RecoveryCodeCount)
+	for i := 0; i < RecoveryCodeCount; i++ {
+		buf := make([]byte, 10)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, "", err
+		}
+		for j, b := range buf {
+			buf[j] = recoveryAlphabet[int(b)%len(recoveryAlphabet)]
+		}
+		code := string(buf[:5]) + "-" + string(buf[5:])
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, strings.Join(hashes, ","), nil
+}
+
+// 使用恢复码, 返回剩余恢复码的哈希
+func UseRecoveryCode(hashes string, code string) (string, bool) {
+	hashed := hashRecoveryCode(code)
+	list := strings.Split(hashes, ",")
+	for i, h := range list {
+		if h != "" && subtle.ConstantTimeCompare([]byte(h), []byte(hashed)) == 1 {
+			return strings.Join(append(list[:i:i], list[i+1:]...), ","), true
+		}
+	}
+	return hashes, false
+}
+
+// 剩余恢复码个数
+func RecoveryCodesLeft(hashes string) int {
+	if hashes == "" {
+		return 0
+	}
+	return len(strings.Split(hashes, ","))
+}
+
+// 恢复码不区分大小写, 忽略空格
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
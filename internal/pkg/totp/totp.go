@@ -0,0 +1,108 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+// Package totp 基于RFC 6238的两步验证, 包括验证码、恢复码与登录过程中的challenge
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/pkg/api/known"
+)
+
+// 与主流验证器App的默认参数一致: SHA1, 6位数字, 30秒
+const (
+	Digits = 6
+	Period = 30
+	// 允许前后各一个周期的时钟误差
+	skew = 1
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// 生成密钥, 为base32编码的20字节随机数
+func GenerateSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(secret), nil
+}
+
+// 验证器App扫码绑定的otpauth地址, 即二维码的内容
+func URI(issuer string, account string, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(Digits))
+	query.Set("period", fmt.Sprint(Period))
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// 时间所在的周期
+func Step(t time.Time) int64 {
+	return t.Unix() / Period
+}
+
+// 指定周期的验证码
+func Code(secret string, step int64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", Digits, value%1000000), nil
+}
+
+// 校验验证码, 返回验证码所在的周期
+// 只接受晚于lastStep的周期, 同一验证码不能重复使用
+func Verify(secret string, code string, t time.Time, lastStep int64) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != Digits {
+		return 0, false
+	}
+	current := Step(t)
+	for step := current - skew; step <= current+skew; step++ {
+		if step <= lastStep {
+			continue
+		}
+		expected, err := Code(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// 用户是否必须启用两步验证, 拥有任一要求两步验证的正常角色即为必须
+func Required(admin model.Admin) bool {
+	for _, role := range admin.Roles {
+		if role.Status == known.DEFAULT_ID && role.TotpRequired == known.DEFAULT_ID {
+			return true
+		}
+	}
+	return false
+}
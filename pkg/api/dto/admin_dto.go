@@ -5,7 +5,10 @@
 
 package dto
 
-import "gotribe-admin/internal/pkg/model"
+import (
+	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/internal/pkg/totp"
+)
 
 // 返回给前端的当前用户信息
 type AdminInfoDto struct {
@@ -16,6 +19,8 @@ type AdminInfoDto struct {
 	Nickname     string        `json:"nickname"`
 	Introduction string        `json:"introduction"`
 	Roles        []*model.Role `json:"roles"`
+	TotpEnabled  bool          `json:"totpEnabled"`
+	TotpRequired bool          `json:"totpRequired"` // 角色要求启用两步验证
 }
 
 func ToAdminInfoDto(user model.Admin) AdminInfoDto {
@@ -27,20 +32,24 @@ func ToAdminInfoDto(user model.Admin) AdminInfoDto {
 		Nickname:     *user.Nickname,
 		Introduction: *user.Introduction,
 		Roles:        user.Roles,
+		TotpEnabled:  user.TotpEnabled,
+		TotpRequired: totp.Required(user),
 	}
 }
 
 // 返回给前端的用户列表
 type AdminsDto struct {
-	ID           uint   `json:"id"`
-	Username     string `json:"username"`
-	Mobile       string `json:"mobile"`
-	Avatar       string `json:"avatar"`
-	Nickname     string `json:"nickname"`
-	Introduction string `json:"introduction"`
-	Status       uint   `json:"status"`
-	Creator      string `json:"creator"`
-	RoleIds      []uint `json:"roleIds"`
+	ID           uint     `json:"id"`
+	Username     string   `json:"username"`
+	Mobile       string   `json:"mobile"`
+	Avatar       string   `json:"avatar"`
+	Nickname     string   `json:"nickname"`
+	Introduction string   `json:"introduction"`
+	Status       uint     `json:"status"`
+	Creator      string   `json:"creator"`
+	RoleIds      []uint   `json:"roleIds"`
+	ProjectIds   []string `json:"projectIds"`
+	TotpEnabled  bool     `json:"totpEnabled"`
 }
 
 func ToAdminsDto(userList []*model.Admin) []AdminsDto {
@@ -55,12 +64,18 @@ func ToAdminsDto(userList []*model.Admin) []AdminsDto {
 			Introduction: *user.Introduction,
 			Status:       user.Status,
 			Creator:      user.Creator,
+			TotpEnabled:  user.TotpEnabled,
 		}
 		roleIds := make([]uint, 0)
 		for _, role := range user.Roles {
 			roleIds = append(roleIds, role.ID)
 		}
 		userDto.RoleIds = roleIds
+		projectIds := make([]string, 0)
+		for _, project := range user.Projects {
+			projectIds = append(projectIds, project.ProjectID)
+		}
+		userDto.ProjectIds = projectIds
 		users = append(users, userDto)
 	}
 
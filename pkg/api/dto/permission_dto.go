@@ -0,0 +1,35 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package dto
+
+import (
+	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/internal/pkg/permission"
+)
+
+// 返回给前端的权限诊断结果
+type PermissionExplainDto struct {
+	Allowed         bool                `json:"allowed"`
+	Reason          string              `json:"reason"`
+	Method          string              `json:"method"`
+	Path            string              `json:"path"` // 去掉接口前缀后参与校验的路径
+	Roles           []*model.Role       `json:"roles"`
+	DisabledRoles   []*model.Role       `json:"disabledRoles"`
+	MatchedPolicy   *permission.Policy  `json:"matchedPolicy"`
+	ClosestPolicies []permission.Policy `json:"closestPolicies"`
+	Registered      bool                `json:"registered"` // 接口是否已在接口管理中登记
+	Api             *model.Api          `json:"api"`
+}
+
+// 返回给前端的用户有效权限
+type PermissionMatrixDto struct {
+	AdminID       uint                   `json:"adminID"`
+	Username      string                 `json:"username"`
+	SuperAdmin    bool                   `json:"superAdmin"`
+	Disabled      bool                   `json:"disabled"`
+	DisabledRoles []*model.Role          `json:"disabledRoles"`
+	Apis          []permission.MatrixRow `json:"apis"`
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package dto
+
+import "gotribe-admin/internal/pkg/model"
+
+// 角色及其直接拥有的接口和菜单
+type RoleGrant struct {
+	Role  *model.Role
+	Apis  []*model.Api
+	Menus []*model.Menu
+}
+
+// 权限来源角色
+type PermissionSourceDto struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Keyword   string `json:"keyword"`
+	Inherited bool   `json:"inherited"` // 是否继承自上级角色
+}
+
+// 角色的有效接口权限
+type RoleApiPermissionDto struct {
+	ID       uint                  `json:"id"`
+	Method   string                `json:"method"`
+	Path     string                `json:"path"`
+	Category string                `json:"category"`
+	Desc     string                `json:"desc"`
+	Sources  []PermissionSourceDto `json:"sources"`
+}
+
+// 角色的有效菜单权限
+type RoleMenuPermissionDto struct {
+	ID       uint                  `json:"id"`
+	Name     string                `json:"name"`
+	Title    string                `json:"title"`
+	Path     string                `json:"path"`
+	ParentID uint                  `json:"parentID"`
+	Sources  []PermissionSourceDto `json:"sources"`
+}
+
+// 返回给前端的角色有效权限, 标明每项权限来自角色本身还是哪个上级角色
+type RolePermissionsDto struct {
+	Apis  []*RoleApiPermissionDto  `json:"apis"`
+	Menus []*RoleMenuPermissionDto `json:"menus"`
+}
+
+// 合并角色本身与上级角色的权限, grants按角色本身、由近到远的上级角色排列
+func ToRolePermissionsDto(roleID uint, grants []RoleGrant) RolePermissionsDto {
+	permissions := RolePermissionsDto{
+		Apis:  make([]*RoleApiPermissionDto, 0),
+		Menus: make([]*RoleMenuPermissionDto, 0),
+	}
+	apis := make(map[uint]*RoleApiPermissionDto)
+	menus := make(map[uint]*RoleMenuPermissionDto)
+	for _, grant := range grants {
+		source := PermissionSourceDto{
+			ID:        grant.Role.ID,
+			Name:      grant.Role.Name,
+			Keyword:   grant.Role.Keyword,
+			Inherited: grant.Role.ID != roleID,
+		}
+		for _, api := range grant.Apis {
+			item, ok := apis[api.ID]
+			if !ok {
+				item = &RoleApiPermissionDto{
+					ID:       api.ID,
+					Method:   api.Method,
+					Path:     api.Path,
+					Category: api.Category,
+					Desc:     api.Desc,
+				}
+				apis[api.ID] = item
+				permissions.Apis = append(permissions.Apis, item)
+			}
+			item.Sources = append(item.Sources, source)
+		}
+		for _, menu := range grant.Menus {
+			item, ok := menus[menu.ID]
+			if !ok {
+				item = &RoleMenuPermissionDto{
+					ID:    menu.ID,
+					Name:  menu.Name,
+					Title: menu.Title,
+					Path:  menu.Path,
+				}
+				if menu.ParentID != nil {
+					item.ParentID = *menu.ParentID
+				}
+				menus[menu.ID] = item
+				permissions.Menus = append(permissions.Menus, item)
+			}
+			item.Sources = append(item.Sources, source)
+		}
+	}
+	return permissions
+}
@@ -12,6 +12,12 @@ const (
 	// XUsernameKey 用来定义 Gin 上下文的键，代表请求的所有者.
 	X_USERNAME_KEY = "X-Username"
 
+	// 通过API Key认证的服务账号在Gin上下文中的键
+	SERVICE_ACCOUNT_KEY = "serviceAccount"
+
+	// 不记录请求体与响应体的路由在Gin上下文中的键
+	SKIP_BODY_CAPTURE_KEY = "skipBodyCapture"
+
 	// 日期格式化
 	TIME_FORMAT_DAY   = "20060102"
 	TIME_FORMAT       = "2006-01-02 15:04:05"
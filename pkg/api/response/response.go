@@ -7,12 +7,14 @@ package response
 
 import (
 	"github.com/gin-gonic/gin"
+	"gotribe-admin/pkg/api/known"
 	"net/http"
 )
 
 // 返回前端
+// 响应中附带请求ID, 便于根据前端截图定位日志
 func Response(c *gin.Context, httpStatus int, code int, data gin.H, message string) {
-	c.JSON(httpStatus, gin.H{"code": code, "data": data, "message": message})
+	c.JSON(httpStatus, gin.H{"code": code, "data": data, "message": message, "requestId": c.GetString(known.X_REQUEST_ID_KEY)})
 }
 
 // 返回前端-成功
@@ -43,3 +43,8 @@ type ChangePwdRequest struct {
 	OldPassword string `json:"oldPassword" form:"oldPassword" validate:"required"`
 	NewPassword string `json:"newPassword" form:"newPassword" validate:"required"`
 }
+
+// 更新用户可管理的项目结构体
+type UpdateAdminProjectsRequest struct {
+	ProjectIds []string `json:"projectIds" form:"projectIds"`
+}
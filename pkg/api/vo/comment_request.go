@@ -12,6 +12,7 @@ type CommentListRequest struct {
 	ObjectType uint   `form:"objectType" json:"objectType"`
 	Status     uint   `form:"status" json:"status"`
 	Nickname   string `form:"nickname" json:"nickname"`
+	Region     string `form:"region" json:"region"` // 国家、地区或城市
 	PageNum    uint   `json:"pageNum" form:"pageNum"`
 	PageSize   uint   `json:"pageSize" form:"pageSize"`
 }
@@ -0,0 +1,18 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package vo
+
+// 登录日志列表请求结构体
+type LoginLogListRequest struct {
+	AdminID   uint   `json:"-" form:"-"` // 只查询指定管理员的登录日志, 由控制器设置
+	Username  string `json:"username" form:"username"`
+	Ip        string `json:"ip" form:"ip"`
+	Status    uint   `json:"status" form:"status" validate:"omitempty,oneof=1 2"`
+	StartTime string `json:"startTime" form:"startTime" validate:"omitempty,datetime=2006-01-02 15:04:05"`
+	EndTime   string `json:"endTime" form:"endTime" validate:"omitempty,datetime=2006-01-02 15:04:05"`
+	PageNum   int    `json:"pageNum" form:"pageNum"`
+	PageSize  int    `json:"pageSize" form:"pageSize"`
+}
@@ -7,12 +7,19 @@ package vo
 
 // 操作日志请求结构体
 type OperationLogListRequest struct {
-	Username string `json:"username" form:"username"`
-	Ip       string `json:"ip" form:"ip"`
-	Path     string `json:"path" form:"path"`
-	Status   int    `json:"status" form:"status"`
-	PageNum  int    `json:"pageNum" form:"pageNum"`
-	PageSize int    `json:"pageSize" form:"pageSize"`
+	RequestID string `json:"requestId" form:"requestId"`
+	Username  string `json:"username" form:"username"`
+	Ip        string `json:"ip" form:"ip"`
+	Path      string `json:"path" form:"path"`
+	Status    int    `json:"status" form:"status"`
+	StatusMin int    `json:"statusMin" form:"statusMin" validate:"omitempty,min=100,max=599"`
+	StatusMax int    `json:"statusMax" form:"statusMax" validate:"omitempty,min=100,max=599"`
+	StartTime string `json:"startTime" form:"startTime" validate:"omitempty,datetime=2006-01-02 15:04:05"`
+	EndTime   string `json:"endTime" form:"endTime" validate:"omitempty,datetime=2006-01-02 15:04:05"`
+	Body      string `json:"body" form:"body"`     // 请求体或响应体包含的内容
+	Region    string `json:"region" form:"region"` // IP归属地
+	PageNum   int    `json:"pageNum" form:"pageNum"`
+	PageSize  int    `json:"pageSize" form:"pageSize"`
 }
 
 // 批量删除操作日志结构体
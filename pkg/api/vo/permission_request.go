@@ -0,0 +1,14 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package vo
+
+// 权限诊断结构体, 用户ID与角色关键字二选一
+type PermissionExplainRequest struct {
+	AdminID     uint   `json:"adminID" form:"adminID"`
+	RoleKeyword string `json:"roleKeyword" form:"roleKeyword"`
+	Method      string `json:"method" form:"method" validate:"required"`
+	Path        string `json:"path" form:"path" validate:"required"`
+}
@@ -12,17 +12,17 @@ type CreatePostRequest struct {
 	CategoryID  string   `form:"categoryID" json:"categoryID" validate:"required"`
 	ProjectID   string   `form:"projectID" json:"projectID" validate:"required"`
 	UserID      string   `form:"userID" json:"userID" validate:"required"`
-	Author      string   `form:"author"json:"author" validate:"required"`
-	Content     string   `form:"content"json:"content" validate:"required"`
-	HtmlContent string   ` form:"htmlContent"json:"htmlContent" validate:"required"`
+	Author      string   `form:"author" json:"author" validate:"required"`
+	Content     string   `form:"content" json:"content" validate:"required"`
+	HtmlContent string   `form:"htmlContent" json:"htmlContent" validate:"required"`
 	ColumnID    string   `form:"columnID" json:"columnID"`
-	Tag         string   `form:"tag"json:"tag"`
-	Ext         string   `form:"ext"json:"ext"`
-	Icon        string   ` form:"icon"json:"icon"`
+	Tag         string   `form:"tag" json:"tag"`
+	Ext         string   `form:"ext" json:"ext"`
+	Icon        string   `form:"icon" json:"icon"`
 	Type        uint     `form:"type" json:"type" validate:"required"`
-	IsTop       uint     ` form:"isTop"json:"isTop"`
-	IsPasswd    uint     `form:"isPasswd"json:"isPasswd"`
-	Password    string   `form:"password"json:"password"`
+	IsTop       uint     `form:"isTop" json:"isTop"`
+	IsPasswd    uint     `form:"isPasswd" json:"isPasswd"`
+	Password    string   `form:"password" json:"password"`
 	Location    string   `form:"location" json:"location"`
 	People      string   `form:"people" json:"people"`
 	Time        string   `form:"time" json:"time"`
@@ -38,17 +38,17 @@ type UpdatePostRequest struct {
 	CategoryID  string   `form:"categoryID" json:"categoryID" validate:"required"`
 	ProjectID   string   `form:"projectID" json:"projectID" validate:"required"`
 	UserID      string   `form:"userID" json:"userID" validate:"required"`
-	Author      string   `form:"author"json:"author" validate:"required"`
-	Content     string   `form:"content"json:"content" validate:"required"`
-	HtmlContent string   ` form:"htmlContent"json:"htmlContent" validate:"required"`
+	Author      string   `form:"author" json:"author" validate:"required"`
+	Content     string   `form:"content" json:"content" validate:"required"`
+	HtmlContent string   `form:"htmlContent" json:"htmlContent" validate:"required"`
 	ColumnID    string   `form:"columnID" json:"columnID"`
-	Tag         string   `form:"tag"json:"tag"`
-	Ext         string   `form:"ext"json:"ext"`
-	Icon        string   ` form:"icon"json:"icon"`
+	Tag         string   `form:"tag" json:"tag"`
+	Ext         string   `form:"ext" json:"ext"`
+	Icon        string   `form:"icon" json:"icon"`
 	Type        uint     `form:"type" json:"type" validate:"required"`
-	IsTop       uint     ` form:"isTop"json:"isTop"`
-	IsPasswd    uint     `form:"isPasswd"json:"isPasswd"`
-	Password    string   `form:"password"json:"password"`
+	IsTop       uint     `form:"isTop" json:"isTop"`
+	IsPasswd    uint     `form:"isPasswd" json:"isPasswd"`
+	Password    string   `form:"password" json:"password"`
 	Status      uint     `form:"status" json:"status"`
 	Location    string   `form:"location" json:"location"`
 	People      string   `form:"people" json:"people"`
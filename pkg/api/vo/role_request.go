@@ -12,6 +12,10 @@ type CreateRoleRequest struct {
 	Desc    string `json:"desc" form:"desc" validate:"min=0,max=100"`
 	Status  uint   `json:"status" form:"status" validate:"oneof=1 2"`
 	Sort    uint   `json:"sort" form:"sort" validate:"gte=1,lte=999"`
+	// 1拥有该角色的用户必须启用两步验证, 2不要求, 为空时不要求
+	TotpRequired uint `json:"totpRequired" form:"totpRequired" validate:"omitempty,oneof=1 2"`
+	// 1拥有该角色的用户可管理全部项目, 2只能管理分配的项目, 为空时只能管理分配的项目
+	AllProjects uint `json:"allProjects" form:"allProjects" validate:"omitempty,oneof=1 2"`
 }
 
 // 获取用户角色结构体
@@ -37,3 +41,13 @@ type UpdateRoleMenusRequest struct {
 type UpdateRoleApisRequest struct {
 	ApiIds []uint `json:"apiIds" form:"apiIds"`
 }
+
+// 更新角色可管理的项目
+type UpdateRoleProjectsRequest struct {
+	ProjectIds []string `json:"projectIds" form:"projectIds"`
+}
+
+// 更新角色继承的父角色
+type UpdateRoleParentsRequest struct {
+	ParentIds []uint `json:"parentIds" form:"parentIds"`
+}
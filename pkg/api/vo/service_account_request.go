@@ -0,0 +1,34 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package vo
+
+// 新增服务账号结构体
+type CreateServiceAccountRequest struct {
+	Name   string `json:"name" form:"name" validate:"required,min=2,max=20"`
+	Desc   string `json:"desc" form:"desc" validate:"min=0,max=100"`
+	RoleID uint   `json:"roleID" form:"roleID" validate:"required"`
+	Status uint   `json:"status" form:"status" validate:"oneof=1 2"`
+}
+
+// 获取服务账号列表结构体
+type ServiceAccountListRequest struct {
+	Name     string `json:"name" form:"name"`
+	Status   uint   `json:"status" form:"status"`
+	PageNum  uint   `json:"pageNum" form:"pageNum"`
+	PageSize uint   `json:"pageSize" form:"pageSize"`
+}
+
+// 批量删除服务账号结构体
+type DeleteServiceAccountRequest struct {
+	ServiceAccountIds []uint `json:"serviceAccountIds" form:"serviceAccountIds"`
+}
+
+// 新增API Key结构体
+type CreateApiKeyRequest struct {
+	Name      string   `json:"name" form:"name" validate:"min=0,max=50"`
+	ExpiresAt string   `json:"expiresAt" form:"expiresAt" validate:"omitempty,datetime=2006-01-02 15:04:05"`
+	AllowIPs  []string `json:"allowIPs" form:"allowIPs"`
+}
@@ -0,0 +1,22 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package vo
+
+// 两步验证登录结构体, code为验证码或恢复码
+type TotpLoginRequest struct {
+	ChallengeToken string `json:"challengeToken" form:"challengeToken" validate:"required"`
+	Code           string `json:"code" form:"code" validate:"required"`
+}
+
+// 登录过程中绑定两步验证结构体
+type TotpLoginEnrollRequest struct {
+	ChallengeToken string `json:"challengeToken" form:"challengeToken" validate:"required"`
+}
+
+// 两步验证码结构体, code为验证码或恢复码
+type TotpCodeRequest struct {
+	Code string `json:"code" form:"code" validate:"required"`
+}
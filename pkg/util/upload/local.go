@@ -0,0 +1,126 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalUploader 本地磁盘存储, 文件通过gin静态路由对外访问
+type LocalUploader struct {
+	Dir    string // 文件保存目录
+	Domain string // 文件访问前缀, 如 /uploads/ 或 https://example.com/uploads/
+}
+
+// NewLocal 构造函数
+func NewLocal(dir, domain string) LocalUploader {
+	if domain != "" && !strings.HasSuffix(domain, "/") {
+		domain += "/"
+	}
+	return LocalUploader{
+		Dir:    dir,
+		Domain: domain,
+	}
+}
+
+// UploadFile 保存文件到本地目录
+func (l LocalUploader) UploadFile(file *multipart.FileHeader) (UploadResource, error) {
+	src, err := file.Open()
+	if err != nil {
+		return UploadResource{}, err
+	}
+	defer src.Close()
+
+	currentTime := time.Now().Format("20060102")
+	fileUnixName := strconv.FormatInt(time.Now().UnixNano(), 10)
+	fileExt := path.Ext(file.Filename)
+	if strings.ContainsAny(fileExt, `/\`) {
+		return UploadResource{}, fmt.Errorf("文件扩展名不合法: %s", fileExt)
+	}
+	key := currentTime + "/" + fileUnixName + fileExt
+
+	dst := filepath.Join(l.Dir, filepath.FromSlash(key))
+	if err = os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return UploadResource{}, err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return UploadResource{}, err
+	}
+	defer out.Close()
+
+	if _, err = io.Copy(out, src); err != nil {
+		return UploadResource{}, err
+	}
+
+	fileRet := UploadResource{
+		FileExt: fileExt,
+		Key:     key,
+		Domain:  l.Domain,
+	}
+	return fileRet, nil
+}
+
+// PutFile 以指定key保存文件到本地目录
+func (l LocalUploader) PutFile(ctx context.Context, key string, data []byte) (UploadResource, error) {
+	cleanKey, err := cleanLocalKey(key)
+	if err != nil {
+		return UploadResource{}, err
+	}
+	dst := filepath.Join(l.Dir, filepath.FromSlash(cleanKey))
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return UploadResource{}, err
+	}
+	if err := os.WriteFile(dst, data, 0644); err != nil {
+		return UploadResource{}, err
+	}
+	return UploadResource{FileExt: path.Ext(cleanKey), Key: cleanKey, Domain: l.Domain}, nil
+}
+
+// DeleteFile 删除本地文件
+func (l LocalUploader) DeleteFile(key string) error {
+	cleanKey, err := cleanLocalKey(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(filepath.Join(l.Dir, filepath.FromSlash(cleanKey)))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// 清理文件key, key为空或越出保存目录时返回错误
+func cleanLocalKey(key string) (string, error) {
+	cleanKey := path.Clean(strings.TrimPrefix(strings.ReplaceAll(key, `\`, "/"), "/"))
+	if key == "" || cleanKey == "." || cleanKey == "/" {
+		return "", errors.New("文件key不能为空")
+	}
+	if cleanKey == ".." || strings.HasPrefix(cleanKey, "../") || path.IsAbs(cleanKey) {
+		return "", fmt.Errorf("文件key越出保存目录: %s", key)
+	}
+	return cleanKey, nil
+}
+
+// Ping 检查保存目录是否存在, 不存在时尝试创建
+func (l LocalUploader) Ping(ctx context.Context) error {
+	if err := os.MkdirAll(l.Dir, 0755); err != nil {
+		return err
+	}
+	info, err := os.Stat(l.Dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s不是目录", l.Dir)
+	}
+	return nil
+}
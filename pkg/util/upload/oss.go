@@ -1,6 +1,9 @@
 package upload
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"github.com/aliyun/aliyun-oss-go-sdk/oss"
 	"mime/multipart"
 	"path"
@@ -62,6 +65,25 @@ func (o OSSUploader) UploadFile(file *multipart.FileHeader) (UploadResource, err
 	return fileRet, nil
 }
 
+// PutFile OSS以指定key上传文件
+func (o OSSUploader) PutFile(ctx context.Context, key string, data []byte) (UploadResource, error) {
+	client, err := oss.New(o.Endpoint, o.AccessKeyId, o.AccessKeySecret)
+	if err != nil {
+		return UploadResource{}, err
+	}
+
+	bucket, err := client.Bucket(o.Bucket)
+	if err != nil {
+		return UploadResource{}, err
+	}
+
+	err = bucket.PutObject(key, bytes.NewReader(data), oss.WithContext(ctx))
+	if err != nil {
+		return UploadResource{}, err
+	}
+	return UploadResource{FileExt: path.Ext(key), Key: key}, nil
+}
+
 // DeleteFile 删除文件
 func (o OSSUploader) DeleteFile(key string) error {
 	client, err := oss.New(o.Endpoint, o.AccessKeyId, o.AccessKeySecret)
@@ -81,3 +103,19 @@ func (o OSSUploader) DeleteFile(key string) error {
 
 	return nil
 }
+
+// Ping 检查bucket是否存在
+func (o OSSUploader) Ping(ctx context.Context) error {
+	client, err := oss.New(o.Endpoint, o.AccessKeyId, o.AccessKeySecret)
+	if err != nil {
+		return err
+	}
+	exist, err := client.IsBucketExist(o.Bucket)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return fmt.Errorf("bucket %s不存在", o.Bucket)
+	}
+	return nil
+}
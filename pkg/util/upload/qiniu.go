@@ -1,6 +1,7 @@
 package upload
 
 import (
+	"bytes"
 	"context"
 	"mime/multipart"
 	"path"
@@ -16,17 +17,29 @@ type QiniuUploader struct {
 	AccessKey string
 	SecretKey string
 	Bucket    string
+	Region    string // 存储区域ID, 如z0(华东)、z1(华北)、z2(华南), 默认z2
 }
 
 // NewQiniu 构造函数
-func NewQiniu(ak, sk, bucket string) QiniuUploader {
+func NewQiniu(ak, sk, bucket, region string) QiniuUploader {
 	return QiniuUploader{
 		AccessKey: ak,
 		SecretKey: sk,
 		Bucket:    bucket,
+		Region:    region,
 	}
 }
 
+// 存储区域, 未配置或配置错误时使用华南区
+func (q QiniuUploader) zone() *storage.Region {
+	if q.Region != "" {
+		if region, ok := storage.GetRegionByID(storage.RegionID(q.Region)); ok {
+			return &region
+		}
+	}
+	return &storage.ZoneHuanan
+}
+
 // UploadFile 七牛上传文件
 func (q QiniuUploader) UploadFile(file *multipart.FileHeader) (UploadResource, error) {
 	src, err := file.Open()
@@ -40,7 +53,7 @@ func (q QiniuUploader) UploadFile(file *multipart.FileHeader) (UploadResource, e
 	upToken := putPolicy.UploadToken(mac)
 	// 配置参数
 	cfg := storage.Config{
-		Zone:          &storage.ZoneHuanan, // 华南区
+		Zone:          q.zone(),
 		UseCdnDomains: false,
 		UseHTTPS:      false, // 非https
 	}
@@ -67,10 +80,33 @@ func (q QiniuUploader) UploadFile(file *multipart.FileHeader) (UploadResource, e
 	return fileRet, nil
 }
 
+// PutFile 七牛以指定key上传文件, 已存在时覆盖
+func (q QiniuUploader) PutFile(ctx context.Context, key string, data []byte) (UploadResource, error) {
+	// scope指定key时允许覆盖同名文件
+	putPolicy := storage.PutPolicy{Scope: q.Bucket + ":" + key}
+	mac := qbox.NewMac(q.AccessKey, q.SecretKey)
+	upToken := putPolicy.UploadToken(mac)
+	cfg := storage.Config{
+		Zone:          q.zone(),
+		UseCdnDomains: false,
+		UseHTTPS:      false,
+	}
+
+	formUploader := storage.NewFormUploader(&cfg)
+	ret := storage.PutRet{}
+	putExtra := storage.PutExtra{}
+	err := formUploader.Put(ctx, &ret, upToken, key, bytes.NewReader(data), int64(len(data)), &putExtra)
+	if err != nil {
+		return UploadResource{}, err
+	}
+	return UploadResource{FileExt: path.Ext(key), Key: key}, nil
+}
+
 // DeleteFile 删除文件
 func (q QiniuUploader) DeleteFile(key string) error {
 	mac := qbox.NewMac(q.AccessKey, q.SecretKey)
 	cfg := storage.Config{
+		Zone: q.zone(),
 		// 是否使用https域名进行资源管理
 		UseHTTPS: false,
 	}
@@ -81,3 +117,15 @@ func (q QiniuUploader) DeleteFile(key string) error {
 	}
 	return nil
 }
+
+// Ping 获取bucket信息检查是否可用
+func (q QiniuUploader) Ping(ctx context.Context) error {
+	mac := qbox.NewMac(q.AccessKey, q.SecretKey)
+	cfg := storage.Config{
+		Zone:     q.zone(),
+		UseHTTPS: false,
+	}
+	bucketManager := storage.NewBucketManager(mac, &cfg)
+	_, err := bucketManager.GetBucketInfo(q.Bucket)
+	return err
+}
@@ -0,0 +1,122 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Uploader 兼容S3协议的对象存储(AWS S3、MinIO、Cloudflare R2等)
+type S3Uploader struct {
+	Endpoint        string
+	AccessKeyId     string
+	AccessKeySecret string
+	Bucket          string
+	Region          string
+}
+
+// NewS3 构造函数
+func NewS3(endpoint, accessKeyId, accessKeySecret, bucket, region string) S3Uploader {
+	return S3Uploader{
+		Endpoint:        endpoint,
+		AccessKeyId:     accessKeyId,
+		AccessKeySecret: accessKeySecret,
+		Bucket:          bucket,
+		Region:          region,
+	}
+}
+
+// endpoint支持带协议头, http://开头时不使用https
+func (s S3Uploader) client() (*minio.Client, error) {
+	endpoint := s.Endpoint
+	secure := true
+	if strings.HasPrefix(endpoint, "http://") {
+		secure = false
+	}
+	endpoint = strings.TrimPrefix(strings.TrimPrefix(endpoint, "http://"), "https://")
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	return minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(s.AccessKeyId, s.AccessKeySecret, ""),
+		Secure: secure,
+		Region: s.Region,
+	})
+}
+
+// UploadFile S3上传文件
+func (s S3Uploader) UploadFile(file *multipart.FileHeader) (UploadResource, error) {
+	client, err := s.client()
+	if err != nil {
+		return UploadResource{}, err
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return UploadResource{}, err
+	}
+	defer src.Close()
+
+	currentTime := time.Now().Format("20060102")
+	fileUnixName := strconv.FormatInt(time.Now().UnixNano(), 10)
+	fileExt := path.Ext(file.Filename)
+	objectName := currentTime + "/" + fileUnixName + fileExt
+
+	_, err = client.PutObject(context.Background(), s.Bucket, objectName, src, file.Size, minio.PutObjectOptions{
+		ContentType: file.Header.Get("Content-Type"),
+	})
+	if err != nil {
+		return UploadResource{}, err
+	}
+
+	fileRet := UploadResource{
+		FileExt: fileExt,
+		Key:     objectName,
+	}
+	return fileRet, nil
+}
+
+// PutFile S3以指定key上传文件
+func (s S3Uploader) PutFile(ctx context.Context, key string, data []byte) (UploadResource, error) {
+	client, err := s.client()
+	if err != nil {
+		return UploadResource{}, err
+	}
+
+	_, err = client.PutObject(ctx, s.Bucket, key, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{})
+	if err != nil {
+		return UploadResource{}, err
+	}
+	return UploadResource{FileExt: path.Ext(key), Key: key}, nil
+}
+
+// DeleteFile 删除文件
+func (s S3Uploader) DeleteFile(key string) error {
+	client, err := s.client()
+	if err != nil {
+		return err
+	}
+	return client.RemoveObject(context.Background(), s.Bucket, key, minio.RemoveObjectOptions{})
+}
+
+// Ping 检查bucket是否存在
+func (s S3Uploader) Ping(ctx context.Context) error {
+	client, err := s.client()
+	if err != nil {
+		return err
+	}
+	exist, err := client.BucketExists(ctx, s.Bucket)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return fmt.Errorf("bucket %s不存在", s.Bucket)
+	}
+	return nil
+}
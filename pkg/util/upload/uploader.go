@@ -1,20 +1,44 @@
 package upload
 
 import (
+	"context"
+	"fmt"
 	"mime/multipart"
 	"os"
 )
 
+// 存储驱动
+const (
+	DriverLocal = "local"
+	DriverOSS   = "oss"
+	DriverQiniu = "qiniu"
+	DriverS3    = "s3"
+)
+
 // Uploader 定义上传接口
 type Uploader interface {
 	UploadFile(file *multipart.FileHeader) (UploadResource, error)
+	PutFile(ctx context.Context, key string, data []byte) (UploadResource, error) // 以指定key保存文件, 已存在时覆盖
 	DeleteFile(key string) error
+	Ping(ctx context.Context) error // 检查存储是否可用
 }
 
 type UploadResource struct {
 	FileExt string
 	Key     string
-	Domain  string
+	Domain  string // 为空时使用系统配置的cdn域名
+}
+
+// Options 上传配置
+type Options struct {
+	Driver          string
+	Endpoint        string
+	AccessKeyId     string
+	AccessKeySecret string
+	Bucket          string
+	Region          string
+	LocalPath       string
+	LocalURL        string
 }
 
 // Service 提供上传和删除文件的功能
@@ -23,12 +47,19 @@ type Service struct {
 }
 
 // NewService 创建一个新的 Service 实例
-func NewUploadFile(endpoint, accessKeyId, accessKeySecret, bucketName string, enableOss bool) (*Service, error) {
+func NewUploadFile(opts Options) (*Service, error) {
 	var uploader Uploader
-	if enableOss {
-		uploader = NewOSS(endpoint, accessKeyId, accessKeySecret, bucketName)
-	} else {
-		uploader = NewQiniu(accessKeyId, accessKeySecret, bucketName)
+	switch opts.Driver {
+	case DriverLocal:
+		uploader = NewLocal(opts.LocalPath, opts.LocalURL)
+	case DriverOSS:
+		uploader = NewOSS(opts.Endpoint, opts.AccessKeyId, opts.AccessKeySecret, opts.Bucket)
+	case DriverQiniu:
+		uploader = NewQiniu(opts.AccessKeyId, opts.AccessKeySecret, opts.Bucket, opts.Region)
+	case DriverS3:
+		uploader = NewS3(opts.Endpoint, opts.AccessKeyId, opts.AccessKeySecret, opts.Bucket, opts.Region)
+	default:
+		return nil, fmt.Errorf("不支持的存储驱动: %s", opts.Driver)
 	}
 
 	return &Service{
@@ -48,6 +79,14 @@ func (s *Service) UploadFile(file *multipart.FileHeader) (UploadResource, error)
 	return result, nil
 }
 
+// PutFile 公用按key保存文件方法
+func (s *Service) PutFile(ctx context.Context, key string, data []byte) (UploadResource, error) {
+	if s.uploader == nil {
+		return UploadResource{}, os.ErrInvalid
+	}
+	return s.uploader.PutFile(ctx, key, data)
+}
+
 // DeleteFile 公用删除文件方法
 func (s *Service) DeleteFile(key string) error {
 	if s.uploader == nil {
@@ -59,3 +98,11 @@ func (s *Service) DeleteFile(key string) error {
 	}
 	return nil
 }
+
+// Ping 检查存储是否可用
+func (s *Service) Ping(ctx context.Context) error {
+	if s.uploader == nil {
+		return os.ErrInvalid
+	}
+	return s.uploader.Ping(ctx)
+}
@@ -0,0 +1,97 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"gotribe-admin/internal/app/routes"
+	"gotribe-admin/internal/pkg/model"
+)
+
+func TestApiSync(t *testing.T) {
+	ctx := context.Background()
+
+	// 初始数据与已注册路由一致
+	report, err := routes.DiffApis(ctx, engine, container)
+	if err != nil {
+		t.Fatalf("比较接口表失败: %v", err)
+	}
+	if !report.Empty() {
+		t.Fatalf("初始数据与已注册路由不一致: 缺少%v, 多余%v, 多余策略%v", report.Missing, report.Stale, report.StalePolicies)
+	}
+
+	// 缺少一个已注册的接口, 多出一个已删除的接口, 以及指向不存在路由的策略
+	var removed model.Api
+	if err := container.DB.Where("method = ? AND path = ?", http.MethodGet, "/index/data").First(&removed).Error; err != nil {
+		t.Fatalf("获取接口失败: %v", err)
+	}
+	if err := container.DB.Unscoped().Delete(&removed).Error; err != nil {
+		t.Fatalf("删除接口失败: %v", err)
+	}
+	stale := model.Api{Method: http.MethodGet, Path: "/e2e/removed", Category: "e2e", Creator: "e2e"}
+	if err := container.Repositories.Api.CreateApi(ctx, &stale); err != nil {
+		t.Fatalf("创建接口失败: %v", err)
+	}
+	policies := [][]string{
+		{"guest", "/e2e/removed", http.MethodGet},
+		{"guest", "/e2e/orphan/*", http.MethodGet},
+		{"guest", "/post/*", http.MethodGet}, // 通配路径能匹配已注册路由
+	}
+	if _, err := container.Enforcer.AddPolicies(policies); err != nil {
+		t.Fatalf("添加策略失败: %v", err)
+	}
+	t.Cleanup(func() {
+		container.Enforcer.RemovePolicies(policies)
+	})
+
+	report, err = routes.DiffApis(ctx, engine, container)
+	if err != nil {
+		t.Fatalf("比较接口表失败: %v", err)
+	}
+	if len(report.Missing) != 1 || report.Missing[0].Path != "/index/data" ||
+		report.Missing[0].Category != removed.Category || report.Missing[0].Desc != removed.Desc {
+		t.Fatalf("缺少的接口为%+v, 期望%s %s [%s] %s", report.Missing, removed.Method, removed.Path, removed.Category, removed.Desc)
+	}
+	if len(report.Stale) != 1 || report.Stale[0].ID != stale.ID {
+		t.Fatalf("多余的接口为%+v, 期望%s", report.Stale, stale.Path)
+	}
+	if len(report.StalePolicies) != 2 {
+		t.Fatalf("多余的策略为%v, 期望%v", report.StalePolicies, policies[:2])
+	}
+
+	// 只登记缺少的接口, 不删除
+	if err := routes.ApplyApiSync(ctx, container, report, "e2e", false); err != nil {
+		t.Fatalf("同步接口表失败: %v", err)
+	}
+	if desc, err := container.Repositories.Api.GetApiDescByPath(ctx, "/index/data", http.MethodGet); err != nil || desc != removed.Desc {
+		t.Fatalf("登记的接口说明为%q, err: %v", desc, err)
+	}
+	if !container.Enforcer.HasPolicy(policies[0]) {
+		t.Fatal("未指定prune时不应删除策略")
+	}
+
+	// 删除多余的接口与策略
+	report, err = routes.DiffApis(ctx, engine, container)
+	if err != nil {
+		t.Fatalf("比较接口表失败: %v", err)
+	}
+	if err := routes.ApplyApiSync(ctx, container, report, "e2e", true); err != nil {
+		t.Fatalf("同步接口表失败: %v", err)
+	}
+	if !container.Enforcer.HasPolicy(policies[2]) {
+		t.Fatal("能匹配已注册路由的策略不应删除")
+	}
+	report, err = routes.DiffApis(ctx, engine, container)
+	if err != nil {
+		t.Fatalf("比较接口表失败: %v", err)
+	}
+	if !report.Empty() {
+		t.Fatalf("同步后仍有差异: 缺少%v, 多余%v, 多余策略%v", report.Missing, report.Stale, report.StalePolicies)
+	}
+}
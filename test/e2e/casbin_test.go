@@ -0,0 +1,98 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"gotribe-admin/internal/pkg/common"
+	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/pkg/util"
+)
+
+func TestCasbinDeny(t *testing.T) {
+	admin := createAdmin(t, "e2e_user", "123456", "user")
+	c := login(t, admin.Username, "123456")
+
+	// user角色拥有获取可访问菜单树的权限
+	c.do(t, http.MethodGet, fmt.Sprintf("/api/menu/access/tree/%d", admin.ID), nil).ok(t)
+
+	// user角色没有内容管理的权限
+	res := c.do(t, http.MethodGet, "/api/post", nil)
+	if res.Status != http.StatusUnauthorized || res.Message != "没有权限" {
+		t.Fatalf("无权限访问时status为%d, message为%q, 期望%d %q", res.Status, res.Message, http.StatusUnauthorized, "没有权限")
+	}
+
+	// 超级管理员不受casbin限制
+	login(t, adminUsername, adminPassword).do(t, http.MethodGet, "/api/post", nil).ok(t)
+}
+
+// 测试中创建的管理员, 用于生成不重复的手机号
+var adminSeq []string
+
+// 创建指定角色的管理员
+func createAdmin(t *testing.T, username, password string, keywords ...string) *model.Admin {
+	t.Helper()
+	var roles []*model.Role
+	if err := container.DB.Where("keyword IN ?", keywords).Find(&roles).Error; err != nil || len(roles) != len(keywords) {
+		t.Fatalf("获取角色%v失败: %v", keywords, err)
+	}
+	adminSeq = append(adminSeq, username)
+	nickname, introduction := username, ""
+	admin := &model.Admin{
+		Username:     username,
+		Password:     util.GenPasswd(password),
+		Mobile:       fmt.Sprintf("1390000%04d", len(adminSeq)),
+		Nickname:     &nickname,
+		Introduction: &introduction,
+		Status:       1,
+		Creator:      "e2e",
+		Roles:        roles,
+	}
+	if err := container.Repositories.Admin.CreateAdmin(context.Background(), admin); err != nil {
+		t.Fatalf("创建管理员%s失败: %v", username, err)
+	}
+	return admin
+}
+
+// 模拟另一个实例, 修改策略后通过策略版本在轮询间隔内同步
+func TestCasbinPolicySync(t *testing.T) {
+	other := common.InitCasbinEnforcer(container.DB)
+	rule := []string{"e2e_sync", "/post", "GET"}
+
+	waitFor := func(want bool) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			pass, err := other.Enforce(rule[0], rule[1], rule[2])
+			if err != nil {
+				t.Fatalf("校验权限失败: %v", err)
+			}
+			if pass == want {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("另一个实例的校验结果为%v, 期望在轮询间隔内同步为%v", pass, want)
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	// 先校验一次, 确认缓存的拒绝结果在策略变更后失效
+	waitFor(false)
+	if _, err := container.Enforcer.AddPolicy(rule[0], rule[1], rule[2]); err != nil {
+		t.Fatalf("添加策略失败: %v", err)
+	}
+	waitFor(true)
+	if _, err := container.Enforcer.RemovePolicy(rule[0], rule[1], rule[2]); err != nil {
+		t.Fatalf("删除策略失败: %v", err)
+	}
+	waitFor(false)
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package e2e
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotribe-admin/config"
+	"gotribe-admin/internal/pkg/common"
+	"gotribe-admin/internal/pkg/gen"
+	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/internal/pkg/model/migrate"
+	"gotribe-admin/internal/pkg/seed"
+)
+
+func TestGenFixtureSeed(t *testing.T) {
+	root := projectRoot()
+	entity, err := gen.Load(filepath.Join(root, "docs", "gen", "example.yml"))
+	if err != nil {
+		t.Fatalf("读取实体定义失败: %v", err)
+	}
+	files, err := gen.Generate(entity, root)
+	if err != nil {
+		t.Fatalf("生成代码失败: %v", err)
+	}
+
+	// 生成的初始数据放在seed-dirs目录中, 另有排在其前面的文件引用其中的菜单作为父菜单
+	dir := t.TempDir()
+	for _, f := range files {
+		if strings.HasPrefix(f.Path, "internal/pkg/seed/fixtures/") {
+			if err := os.WriteFile(filepath.Join(dir, filepath.Base(f.Path)), f.Content, 0644); err != nil {
+				t.Fatalf("写入初始数据文件失败: %v", err)
+			}
+		}
+	}
+	child := "menus:\n  - name: NoticeArchive\n    title: 公告归档\n    path: archive\n    component: /content/notice/archive\n    parent: /content/notice\n    roles: [admin]\n"
+	if err := os.WriteFile(filepath.Join(dir, "0_notice_archive.yml"), []byte(child), 0644); err != nil {
+		t.Fatalf("写入初始数据文件失败: %v", err)
+	}
+	seedDirs := config.Conf.System.SeedDirs
+	config.Conf.System.SeedDirs = []string{dir}
+	t.Cleanup(func() { config.Conf.System.SeedDirs = seedDirs })
+
+	// 在全新的数据库中执行迁移并写入初始数据
+	db := newDB(t, "gen")
+	if _, err := migrate.Up(db); err != nil {
+		t.Fatalf("执行数据库迁移失败: %v", err)
+	}
+	if err := seed.Run(db, common.InitCasbinEnforcer(db)); err != nil {
+		t.Fatalf("写入初始数据失败: %v", err)
+	}
+
+	var content, notice, archive model.Menu
+	if err := db.Where("path = ?", "/content").First(&content).Error; err != nil {
+		t.Fatalf("父菜单/content不存在: %v", err)
+	}
+	if err := db.Where("path = ?", "/content/notice").First(&notice).Error; err != nil || *notice.ParentID != content.ID {
+		t.Fatalf("生成的菜单应挂载到/content下: %+v, %v", notice, err)
+	}
+	if err := db.Where("path = ?", "archive").First(&archive).Error; err != nil || *archive.ParentID != notice.ID {
+		t.Fatalf("引用后面文件中菜单的子菜单应挂载到/content/notice下: %+v, %v", archive, err)
+	}
+	var apis int64
+	if err := db.Model(&model.Api{}).Where("category = ?", entity.Snake).Count(&apis).Error; err != nil || apis != 5 {
+		t.Fatalf("应写入生成的5个接口, 实际: %d, %v", apis, err)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package e2e
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotribe-admin/internal/app/jobs"
+	"gotribe-admin/internal/pkg/geoip"
+	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/pkg/api/known"
+)
+
+// 生成只包含1.2.3.0/24一个网段的ip2region xdb文件
+func writeTestXdb(t *testing.T) string {
+	t.Helper()
+	const header, vectorIndex, segment = 256, 256 * 256 * 8, 14
+	region := []byte("中国|0|广东省|深圳市|电信")
+	segmentPtr := header + vectorIndex
+	content := make([]byte, segmentPtr+segment+len(region))
+
+	vector := header + (1*256+2)*8
+	binary.LittleEndian.PutUint32(content[vector:], uint32(segmentPtr))
+	binary.LittleEndian.PutUint32(content[vector+4:], uint32(segmentPtr))
+	binary.LittleEndian.PutUint32(content[segmentPtr:], 0x01020300)
+	binary.LittleEndian.PutUint32(content[segmentPtr+4:], 0x010203ff)
+	binary.LittleEndian.PutUint16(content[segmentPtr+8:], uint16(len(region)))
+	binary.LittleEndian.PutUint32(content[segmentPtr+10:], uint32(segmentPtr+segment))
+	copy(content[segmentPtr+segment:], region)
+
+	path := filepath.Join(t.TempDir(), "ip2region.xdb")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatalf("写入xdb文件失败: %v", err)
+	}
+	return path
+}
+
+// 替换容器的IP归属地数据库, 测试结束后恢复
+func useTestGeoIP(t *testing.T) geoip.Resolver {
+	t.Helper()
+	resolver, err := geoip.New(geoip.DriverIP2Region, writeTestXdb(t), "")
+	if err != nil {
+		t.Fatalf("打开xdb文件失败: %v", err)
+	}
+	original := container.GeoIP
+	container.GeoIP = resolver
+	t.Cleanup(func() {
+		container.GeoIP = original
+		resolver.Close()
+	})
+	return resolver
+}
+
+func TestGeoIPLookup(t *testing.T) {
+	resolver := useTestGeoIP(t)
+
+	cases := map[string]string{
+		"1.2.3.4":     "中国 广东省 深圳市",
+		"10.0.0.1":    geoip.Intranet,
+		"8.8.8.8":     "",
+		"2001:db8::1": "",
+	}
+	for ip, want := range cases {
+		location, err := resolver.Lookup(ip)
+		if err != nil || location.String() != want {
+			t.Fatalf("%s 归属地应为%q, 实际: %q, err: %v", ip, want, location.String(), err)
+		}
+	}
+	if _, err := resolver.Lookup("not-an-ip"); !errors.Is(err, geoip.ErrInvalidIP) {
+		t.Fatalf("无效IP应返回ErrInvalidIP, 实际: %v", err)
+	}
+}
+
+func TestOperationLogIpLocation(t *testing.T) {
+	useTestGeoIP(t)
+
+	// 经可信代理转发的请求按X-Forwarded-For记录客户端IP, 其他请求的X-Forwarded-For不可信
+	admin := login(t, adminUsername, adminPassword)
+	send := func(remoteIP string) model.OperationLog {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/log/operation/list", nil)
+		req.RemoteAddr = net.JoinHostPort(remoteIP, "1234")
+		req.Header.Set("Authorization", "Bearer "+admin.token)
+		req.Header.Set("X-Forwarded-For", "1.2.3.4")
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return operationLogByRequestID(t, w.Header().Get(known.X_REQUEST_ID_KEY))
+	}
+	if log := send("198.51.100.30"); log.Ip != "198.51.100.30" {
+		t.Fatalf("不可信来源的X-Forwarded-For不应作为客户端IP, ip: %s", log.Ip)
+	}
+	log := send(trustedProxy)
+	if log.Ip != "1.2.3.4" || log.IpLocation != "中国 广东省 深圳市" {
+		t.Fatalf("操作日志应记录IP归属地, ip: %s, 归属地: %s", log.Ip, log.IpLocation)
+	}
+
+	res := admin.do(t, http.MethodGet, "/api/log/operation/list?region=深圳&requestId="+log.RequestID, nil).ok(t)
+	var data struct {
+		Total int64 `json:"total"`
+	}
+	res.decode(t, &data)
+	if data.Total != 1 {
+		t.Fatalf("按归属地应查到1条操作日志, 实际: %d", data.Total)
+	}
+}
+
+func TestGeoIPBackfill(t *testing.T) {
+	useTestGeoIP(t)
+
+	log := model.OperationLog{Username: adminUsername, Ip: "1.2.3.5", Method: http.MethodGet, Path: "/backfill"}
+	comment := model.Comment{
+		ProjectID: projectID, Content: "归属地回填", HtmlContent: "归属地回填", ObjectID: "e2eobject",
+		UserID: "e2euser", ToUserID: "e2euser", IP: "1.2.3.6",
+	}
+	event := model.UserEvent{UserID: "e2euser", ProjectID: projectID, IP: "1.2.3.7"}
+	for _, record := range []interface{}{&log, &comment, &event} {
+		if err := container.DB.Create(record).Error; err != nil {
+			t.Fatalf("写入测试数据失败: %v", err)
+		}
+	}
+
+	if err := jobs.NewGeoIPBackfill().Run(container); err != nil {
+		t.Fatalf("回填归属地失败: %v", err)
+	}
+
+	container.DB.First(&log, log.ID)
+	container.DB.First(&comment, comment.ID)
+	container.DB.First(&event, event.ID)
+	if log.IpLocation != "中国 广东省 深圳市" {
+		t.Fatalf("操作日志归属地未回填: %q", log.IpLocation)
+	}
+	if comment.Country != "中国" || comment.RegionName != "广东省" || comment.City != "深圳市" {
+		t.Fatalf("评论归属地未回填: %+v", comment)
+	}
+	if event.Country != "中国" || event.City != "深圳市" {
+		t.Fatalf("用户事件归属地未回填: %+v", event)
+	}
+
+	res := login(t, adminUsername, adminPassword).do(t, http.MethodGet, "/api/comment?region=深圳&objectID=e2eobject", nil).ok(t)
+	var data struct {
+		Total int64 `json:"total"`
+	}
+	res.decode(t, &data)
+	if data.Total != 1 {
+		t.Fatalf("按归属地应查到1条评论, 实际: %d", data.Total)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package e2e
+
+import (
+	"embed"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gotribe-admin/internal/app"
+	"gotribe-admin/internal/app/routes"
+	"gotribe-admin/internal/pkg/common"
+	"gotribe-admin/internal/pkg/health"
+)
+
+func TestHealthz(t *testing.T) {
+	anonymous().do(t, http.MethodGet, "/healthz", nil).ok(t)
+}
+
+// 未知的接口与探针路径返回404而不是跳转到首页, 前端页面路径仍跳转到首页
+func TestNoRoute(t *testing.T) {
+	for _, path := range []string{"/api/not-exists", "/api", "/healthzz", "/health", "/readiness", "/metrics/x"} {
+		res := anonymous().do(t, http.MethodGet, path, nil)
+		if res.Status != http.StatusNotFound || res.Code != http.StatusNotFound {
+			t.Errorf("%s 应返回404, 实际status: %d, code: %d", path, res.Status, res.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/dashboard/not-exists", nil))
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/" {
+		t.Fatalf("前端页面路径应跳转到首页, 实际: %d %s", w.Code, w.Header().Get("Location"))
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	res := anonymous().do(t, http.MethodGet, "/readyz", nil).ok(t)
+	var data struct {
+		Status string          `json:"status"`
+		Checks []health.Result `json:"checks"`
+	}
+	res.decode(t, &data)
+	if data.Status != health.StatusUp {
+		t.Fatalf("期望就绪, 实际: %s", data.Status)
+	}
+	names := map[string]bool{}
+	for _, check := range data.Checks {
+		names[check.Name] = true
+		if check.Status != health.StatusUp {
+			t.Errorf("检查%s未通过: %s", check.Name, check.Error)
+		}
+	}
+	for _, name := range []string{"database", "casbin", "upload"} {
+		if !names[name] {
+			t.Errorf("缺少检查项: %s", name)
+		}
+	}
+}
+
+func TestReadyzShuttingDown(t *testing.T) {
+	// 使用独立的容器与路由, 避免停机状态影响其他用例
+	c := app.NewContainer(container.DB, container.Enforcer, common.Log)
+	r := routes.InitRoutes(embed.FS{}, c)
+	c.Health.Shutdown()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("进入停机流程后应返回503, 实际: %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `"shuttingDown":true`) {
+		t.Fatalf("响应中缺少停机状态: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package e2e
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestIndexData(t *testing.T) {
+	c := login(t, adminUsername, adminPassword)
+
+	res := c.do(t, http.MethodGet, "/api/index?projectID="+projectID, nil)
+	res.ok(t)
+}
+
+func TestQueryCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// 已取消的context应中断首页统计查询
+	_, err := container.Repositories.Index.GetIndexData(ctx, projectID)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
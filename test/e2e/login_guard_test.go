@@ -0,0 +1,183 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package e2e
+
+import (
+	"embed"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"gotribe-admin/internal/app"
+	"gotribe-admin/internal/app/routes"
+	"gotribe-admin/internal/pkg/common"
+	"gotribe-admin/internal/pkg/model"
+)
+
+// 登录日志列表
+type loginLogs struct {
+	Logs  []model.LoginLog `json:"logs"`
+	Total int64            `json:"total"`
+}
+
+// 断言登录被锁定, 返回剩余锁定秒数
+func assertLocked(t *testing.T, res *result, message string) int {
+	t.Helper()
+	if res.Status != http.StatusTooManyRequests || !strings.Contains(res.Message, message) {
+		t.Fatalf("应返回429且提示%q, 实际: %d %s", message, res.Status, res.Message)
+	}
+	var data struct {
+		RetryAfter int `json:"retryAfter"`
+	}
+	res.decode(t, &data)
+	return data.RetryAfter
+}
+
+// 连续登录失败, 最后一次应触发锁定, 返回锁定秒数
+func failUntilLocked(t *testing.T, c *client, username string) int {
+	t.Helper()
+	for i := 1; i < 3; i++ {
+		if res := c.postLogin(t, username, "wrong-password"); res.Status != http.StatusUnauthorized {
+			t.Fatalf("第%d次密码错误应返回401, 实际: %d %s", i, res.Status, res.Message)
+		}
+	}
+	return assertLocked(t, c.postLogin(t, username, "wrong-password"), "账号已锁定")
+}
+
+func TestLoginLockout(t *testing.T) {
+	admin := createAdmin(t, "e2e_lockout", "123456", "user")
+	c := &client{ip: "198.51.100.1"}
+
+	// 锁定期间密码正确也不能登录
+	if seconds := failUntilLocked(t, c, admin.Username); seconds != 1 {
+		t.Fatalf("首次锁定%d秒, 期望1秒", seconds)
+	}
+	assertLocked(t, c.postLogin(t, admin.Username, "123456"), "账号已锁定")
+
+	// 锁定结束后再次连续失败, 锁定时长翻倍
+	time.Sleep(1100 * time.Millisecond)
+	if seconds := failUntilLocked(t, c, admin.Username); seconds != 2 {
+		t.Fatalf("第二次锁定%d秒, 期望2秒", seconds)
+	}
+
+	// 普通管理员不能解锁, 超级管理员解锁后可以立即登录
+	other := createAdmin(t, "e2e_lockout_other", "123456", "user")
+	if res := login(t, other.Username, "123456").do(t, http.MethodPatch, fmt.Sprintf("/api/admin/unlock/%d", admin.ID), nil); res.Code == http.StatusOK {
+		t.Fatal("普通管理员不应能解锁其他用户")
+	}
+	login(t, adminUsername, adminPassword).do(t, http.MethodPatch, fmt.Sprintf("/api/admin/unlock/%d", admin.ID), nil).ok(t)
+	var data struct {
+		Token string `json:"token"`
+	}
+	c.postLogin(t, admin.Username, "123456").ok(t).decode(t, &data)
+	user := &client{token: data.Token}
+
+	// 当前用户的登录日志, 按时间倒序
+	var own loginLogs
+	user.do(t, http.MethodGet, "/api/admin/loginLog/list?pageNum=1&pageSize=20", nil).ok(t).decode(t, &own)
+	// 2次锁定各3次失败, 锁定期间1次, 解锁后1次成功
+	if own.Total != 8 || len(own.Logs) != 8 {
+		t.Fatalf("登录日志%d条, 期望8条", own.Total)
+	}
+	latest := own.Logs[0]
+	if latest.Status != model.LoginSuccess || latest.AdminID != admin.ID || latest.Ip != c.ip || latest.RequestID == "" {
+		t.Fatalf("最近一条登录日志不正确: %+v", latest)
+	}
+	reasons := map[string]int{}
+	for _, log := range own.Logs {
+		reasons[log.Reason]++
+	}
+	if reasons["密码错误"] != 6 || reasons["账号已锁定"] != 1 {
+		t.Fatalf("失败原因统计不正确: %v", reasons)
+	}
+	var failures loginLogs
+	user.do(t, http.MethodGet, "/api/admin/loginLog/list?status=2", nil).ok(t).decode(t, &failures)
+	if failures.Total != 7 {
+		t.Fatalf("失败的登录日志%d条, 期望7条", failures.Total)
+	}
+
+	// 当前用户的登录日志只包含自己的记录, 忽略用户名参数
+	var others loginLogs
+	user.do(t, http.MethodGet, "/api/admin/loginLog/list?username="+adminUsername, nil).ok(t).decode(t, &others)
+	if others.Total != own.Total {
+		t.Fatalf("指定用户名时登录日志%d条, 期望%d条", others.Total, own.Total)
+	}
+
+	// 全部登录日志需要相应的接口权限
+	if res := user.do(t, http.MethodGet, "/api/log/login/list", nil); res.Code == http.StatusOK {
+		t.Fatal("普通管理员不应能查看全部登录日志")
+	}
+	var all loginLogs
+	login(t, adminUsername, adminPassword).do(t, http.MethodGet, "/api/log/login/list?ip="+c.ip, nil).ok(t).decode(t, &all)
+	if all.Total != own.Total {
+		t.Fatalf("按IP筛选的登录日志%d条, 期望%d条", all.Total, own.Total)
+	}
+}
+
+func TestLoginIPLockout(t *testing.T) {
+	c := &client{ip: "198.51.100.2"}
+
+	// 同一IP尝试不同用户名, 达到IP的阈值后锁定该IP
+	for i := 1; i < 10; i++ {
+		if res := c.postLogin(t, fmt.Sprintf("e2e_nobody_%d", i), "123456"); res.Status != http.StatusUnauthorized {
+			t.Fatalf("第%d次登录应返回401, 实际: %d %s", i, res.Status, res.Message)
+		}
+	}
+	assertLocked(t, c.postLogin(t, "e2e_nobody_10", "123456"), "该IP")
+	assertLocked(t, c.postLogin(t, adminUsername, adminPassword), "该IP")
+	// 伪造X-Forwarded-For不能绕过IP锁定
+	assertLocked(t, (&client{ip: c.ip, xff: "203.0.113.9"}).postLogin(t, adminUsername, adminPassword), "该IP")
+
+	// 其他IP不受影响
+	(&client{ip: "198.51.100.3"}).postLogin(t, adminUsername, adminPassword).ok(t)
+
+	// 用户不存在时管理员ID为0
+	var logs loginLogs
+	login(t, adminUsername, adminPassword).do(t, http.MethodGet, "/api/log/login/list?username=e2e_nobody_5", nil).ok(t).decode(t, &logs)
+	if logs.Total != 1 || logs.Logs[0].AdminID != 0 || logs.Logs[0].Reason != "用户不存在" {
+		t.Fatalf("不存在用户的登录日志不正确: %+v", logs.Logs)
+	}
+}
+
+// 经由可信代理的请求按X-Forwarded-For中的客户端IP锁定与记录, 不会因代理IP相同而互相影响
+func TestLoginIPLockoutBehindProxy(t *testing.T) {
+	c := &client{ip: trustedProxy, xff: "203.0.113.20"}
+	for i := 1; i < 10; i++ {
+		if res := c.postLogin(t, fmt.Sprintf("e2e_proxied_%d", i), "123456"); res.Status != http.StatusUnauthorized {
+			t.Fatalf("第%d次登录应返回401, 实际: %d %s", i, res.Status, res.Message)
+		}
+	}
+	assertLocked(t, c.postLogin(t, "e2e_proxied_10", "123456"), "该IP")
+
+	// 同一代理后的其他客户端不受影响
+	(&client{ip: trustedProxy, xff: "203.0.113.21"}).postLogin(t, adminUsername, adminPassword).ok(t)
+
+	var logs loginLogs
+	login(t, adminUsername, adminPassword).do(t, http.MethodGet, "/api/log/login/list?username=e2e_proxied_5", nil).ok(t).decode(t, &logs)
+	if logs.Total != 1 || logs.Logs[0].Ip != "203.0.113.20" {
+		t.Fatalf("登录日志应记录代理转发的客户端IP: %+v", logs.Logs)
+	}
+}
+
+func TestLoginLockoutSharedAcrossInstances(t *testing.T) {
+	admin := createAdmin(t, "e2e_lockout_shared", "123456", "user")
+	// 共享同一数据库的另一个实例
+	other := routes.InitRoutes(embed.FS{}, app.NewContainer(container.DB, container.Enforcer, common.Log))
+
+	// 在两个实例上交替失败, 失败次数合并计算
+	first := &client{ip: "198.51.100.4"}
+	second := &client{ip: "198.51.100.5", engine: other}
+	first.postLogin(t, admin.Username, "wrong-password")
+	second.postLogin(t, admin.Username, "wrong-password")
+	assertLocked(t, first.postLogin(t, admin.Username, "wrong-password"), "账号已锁定")
+	assertLocked(t, second.postLogin(t, admin.Username, "123456"), "账号已锁定")
+
+	// 在一个实例上解锁, 另一个实例立即可以登录
+	login(t, adminUsername, adminPassword).do(t, http.MethodPatch, fmt.Sprintf("/api/admin/unlock/%d", admin.ID), nil).ok(t)
+	second.postLogin(t, admin.Username, "123456").ok(t)
+}
@@ -0,0 +1,41 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package e2e
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestLogin(t *testing.T) {
+	c := login(t, adminUsername, adminPassword)
+
+	// 携带token访问需要认证的接口
+	res := c.do(t, http.MethodPost, "/api/admin/info", nil).ok(t)
+	var data struct {
+		Admin struct {
+			Username string `json:"username"`
+		} `json:"admin"`
+	}
+	res.decode(t, &data)
+	if data.Admin.Username != adminUsername {
+		t.Fatalf("当前管理员为%q, 期望%q", data.Admin.Username, adminUsername)
+	}
+}
+
+func TestLoginWrongPassword(t *testing.T) {
+	res := postLogin(t, adminUsername, "wrong-password")
+	if res.Status != http.StatusUnauthorized {
+		t.Fatalf("密码错误时status为%d, 期望%d, message: %s", res.Status, http.StatusUnauthorized, res.Message)
+	}
+}
+
+func TestUnauthenticated(t *testing.T) {
+	res := anonymous().do(t, http.MethodGet, "/api/post", nil)
+	if res.Status != http.StatusUnauthorized {
+		t.Fatalf("未登录时status为%d, 期望%d", res.Status, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+// Package e2e 端到端接口测试
+// 使用SQLite内存数据库执行迁移并写入内置初始数据, 通过httptest调用完整路由(含jwt认证与casbin鉴权)
+// 运行: go test ./test/e2e/...
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gotribe-admin/config"
+	"gotribe-admin/internal/app"
+	"gotribe-admin/internal/app/routes"
+	"gotribe-admin/internal/pkg/apikey"
+	"gotribe-admin/internal/pkg/common"
+	"gotribe-admin/internal/pkg/model/migrate"
+	"gotribe-admin/internal/pkg/seed"
+	"gotribe-admin/pkg/util"
+)
+
+// 内置初始数据中的超级管理员
+const (
+	adminUsername = "admin"
+	adminPassword = "123456"
+)
+
+// 可信的反向代理IP, 来自该IP的请求读取X-Forwarded-For作为客户端IP
+const trustedProxy = "192.0.2.10"
+
+var (
+	container *app.Container
+	engine    *gin.Engine
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "gotribe-e2e")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "创建临时目录失败: %v\n", err)
+		os.Exit(1)
+	}
+	if err := setup(dir); err != nil {
+		fmt.Fprintf(os.Stderr, "初始化测试环境失败: %v\n", err)
+		os.RemoveAll(dir)
+		os.Exit(1)
+	}
+	container.OperationLogs.Start()
+	code := m.Run()
+	container.OperationLogs.Close(context.Background())
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// 按serve命令的顺序初始化配置、日志、数据库、casbin、校验器、初始数据与路由
+func setup(dir string) error {
+	root := projectRoot()
+	conf := config.Conf
+	conf.System = &config.SystemConfig{
+		Mode:            gin.TestMode,
+		UrlPathPrefix:   "api",
+		TrustedProxies:  []string{trustedProxy},
+		RSAPublicBytes:  util.RSAReadKeyFromFile(filepath.Join(root, "public.pem")),
+		RSAPrivateBytes: util.RSAReadKeyFromFile(filepath.Join(root, "private.pem")),
+	}
+	conf.Logs = &config.LogsConfig{Level: 1, Path: filepath.Join(dir, "logs"), MaxSize: 1, MaxBackups: 1, MaxAge: 1}
+	conf.Database = &config.DatabaseConfig{Driver: common.DriverSQLite, QueryTimeout: 10}
+	// 共享缓存的内存数据库, 同一进程内的连接访问同一份数据
+	conf.Sqlite = &config.SqliteConfig{Path: "file:gotribe-e2e?mode=memory&cache=shared"}
+	conf.Casbin = &config.CasbinConfig{ModelPath: filepath.Join(root, "rbac_model.conf"), WatchInterval: 1}
+	conf.Jwt = &config.JwtConfig{Realm: "gotribe-admin-test", Key: "gotribe-admin-test-key", Timeout: 1, MaxRefresh: 1}
+	conf.RateLimit = &config.RateLimitConfig{FillInterval: 1, Capacity: 10000}
+	conf.UploadFile = &config.UploadFile{Driver: "local", LocalPath: filepath.Join(dir, "uploads"), LocalURL: "/uploads"}
+	conf.Metrics = &config.MetricsConfig{Enable: true, Path: "/metrics", Token: metricsToken}
+	// 锁定时长为1秒, 便于测试逐级加长的锁定
+	conf.LoginGuard = &config.LoginGuardConfig{MaxAttempts: 3, IPMaxAttempts: 10, LockDuration: 1, MaxLockDuration: 60, Window: 60}
+	if len(conf.System.RSAPublicBytes) == 0 || len(conf.System.RSAPrivateBytes) == 0 {
+		return fmt.Errorf("读取rsa密钥失败, 目录: %s", root)
+	}
+
+	common.InitLogger()
+	db := common.InitDB()
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	// 内存数据库在最后一个连接关闭时销毁, 单连接同时避免共享缓存的表锁冲突
+	sqlDB.SetMaxOpenConns(1)
+	sqlDB.SetConnMaxLifetime(0)
+	if _, err := migrate.Up(db); err != nil {
+		return fmt.Errorf("执行数据库迁移失败: %v", err)
+	}
+	container = app.NewContainer(db, common.InitCasbinEnforcer(db), common.Log)
+	common.InitValidate()
+	if err := seed.Run(container.DB, container.Enforcer); err != nil {
+		return fmt.Errorf("写入初始数据失败: %v", err)
+	}
+	engine = routes.InitRoutes(embed.FS{}, container)
+	return nil
+}
+
+// 项目根目录, 用于读取rsa密钥与casbin模型文件
+func projectRoot() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..")
+}
+
+// 接口统一响应
+type result struct {
+	Status    int             `json:"-"` // http状态码
+	Code      int             `json:"code"`
+	Data      json.RawMessage `json:"data"`
+	Message   string          `json:"message"`
+	RequestID string          `json:"requestId"`
+}
+
+// 将data解析到v
+func (r *result) decode(t *testing.T, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(r.Data, v); err != nil {
+		t.Fatalf("解析响应数据失败: %v, data: %s", err, r.Data)
+	}
+}
+
+// 断言请求成功
+func (r *result) ok(t *testing.T) *result {
+	t.Helper()
+	if r.Status != http.StatusOK || r.Code != http.StatusOK {
+		t.Fatalf("请求失败, status: %d, code: %d, message: %s", r.Status, r.Code, r.Message)
+	}
+	return r
+}
+
+// 测试客户端, 登录后自动携带token, 服务账号携带API Key
+type client struct {
+	token  string
+	apiKey string
+	ip     string      // 客户端直连IP, 为空时为httptest默认的192.0.2.1
+	xff    string      // X-Forwarded-For请求头
+	engine *gin.Engine // 处理请求的实例, 为空时为共享的engine, 用于模拟多实例部署
+}
+
+// 匿名客户端
+func anonymous() *client {
+	return &client{}
+}
+
+// 以指定管理员登录, 密码与前端一致先经过rsa公钥加密
+func login(t *testing.T, username, password string) *client {
+	t.Helper()
+	res := postLogin(t, username, password).ok(t)
+	var data struct {
+		Token string `json:"token"`
+	}
+	res.decode(t, &data)
+	if data.Token == "" {
+		t.Fatal("登录响应中缺少token")
+	}
+	return &client{token: data.Token}
+}
+
+// 调用登录接口
+func postLogin(t *testing.T, username, password string) *result {
+	t.Helper()
+	return anonymous().postLogin(t, username, password)
+}
+
+// 以当前客户端调用登录接口
+func (c *client) postLogin(t *testing.T, username, password string) *result {
+	t.Helper()
+	encrypted, err := util.RSAEncrypt([]byte(password), config.Conf.System.RSAPublicBytes)
+	if err != nil {
+		t.Fatalf("rsa加密密码失败: %v", err)
+	}
+	return c.do(t, http.MethodPost, "/api/base/login", map[string]string{
+		"username": username,
+		"password": string(encrypted),
+	})
+}
+
+// 发送请求, body不为nil时以json格式发送
+func (c *client) do(t *testing.T, method, path string, body interface{}) *result {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatalf("序列化请求数据失败: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.apiKey != "" {
+		req.Header.Set(apikey.Header, c.apiKey)
+	}
+	if c.ip != "" {
+		req.RemoteAddr = net.JoinHostPort(c.ip, "1234")
+	}
+	if c.xff != "" {
+		req.Header.Set("X-Forwarded-For", c.xff)
+	}
+	w := httptest.NewRecorder()
+	if c.engine != nil {
+		c.engine.ServeHTTP(w, req)
+	} else {
+		engine.ServeHTTP(w, req)
+	}
+
+	res := &result{Status: w.Code}
+	if err := json.Unmarshal(w.Body.Bytes(), res); err != nil {
+		t.Fatalf("%s %s 响应不是json: %v, body: %s", method, path, err, w.Body.String())
+	}
+	return res
+}
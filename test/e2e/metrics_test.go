@@ -0,0 +1,88 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package e2e
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gotribe-admin/internal/pkg/middleware"
+)
+
+const metricsToken = "gotribe-e2e-metrics"
+
+// 抓取指标, 返回http状态码与响应内容
+func scrapeMetrics(token string) (int, string) {
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w.Code, w.Body.String()
+}
+
+func TestMetrics(t *testing.T) {
+	if status, _ := scrapeMetrics(""); status != http.StatusForbidden {
+		t.Fatalf("未携带token应返回403, 实际: %d", status)
+	}
+	if status, _ := scrapeMetrics("wrong"); status != http.StatusForbidden {
+		t.Fatalf("token错误应返回403, 实际: %d", status)
+	}
+
+	// 产生一次请求与一次casbin拒绝
+	login(t, adminUsername, adminPassword).do(t, http.MethodGet, "/api/post?pageNum=1&pageSize=10", nil).ok(t)
+	admin := createAdmin(t, "e2e_metrics", "123456", "user")
+	if res := login(t, admin.Username, "123456").do(t, http.MethodGet, "/api/post", nil); res.Status != http.StatusUnauthorized {
+		t.Fatalf("普通用户访问内容列表应被拒绝, 实际: %d", res.Status)
+	}
+
+	status, body := scrapeMetrics(metricsToken)
+	if status != http.StatusOK {
+		t.Fatalf("携带token应返回200, 实际: %d", status)
+	}
+	for _, want := range []string{
+		`gotribe_http_requests_total{method="GET",route="/api/post",status="200"}`,
+		`gotribe_http_request_duration_seconds_bucket{method="GET",route="/api/post"`,
+		`gotribe_casbin_denied_total{method="GET",route="/api/post"}`,
+		`gotribe_queue_depth{queue="operation_log"}`,
+		`go_sql_open_connections{db_name="sqlite"}`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("指标中缺少: %s", want)
+		}
+	}
+}
+
+// 经本机反向代理转发的外部请求按X-Forwarded-For中的客户端IP判断白名单
+func TestMetricsAllowIPsBehindProxy(t *testing.T) {
+	r := gin.New()
+	if err := r.SetTrustedProxies([]string{"127.0.0.1"}); err != nil {
+		t.Fatalf("设置可信代理失败: %v", err)
+	}
+	r.GET("/metrics", middleware.MetricsAuthMiddleware("", []string{"127.0.0.1"}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	scrape := func(xff string) int {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		req.RemoteAddr = "127.0.0.1:1234"
+		if xff != "" {
+			req.Header.Set("X-Forwarded-For", xff)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	if status := scrape(""); status != http.StatusOK {
+		t.Fatalf("本机抓取应返回200, 实际: %d", status)
+	}
+	if status := scrape("203.0.113.50"); status != http.StatusForbidden {
+		t.Fatalf("经代理转发的外部请求应返回403, 实际: %d", status)
+	}
+}
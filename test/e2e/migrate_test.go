@@ -0,0 +1,140 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package e2e
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+	"gotribe-admin/config"
+	"gotribe-admin/internal/pkg/common"
+	"gotribe-admin/internal/pkg/model/migrate"
+)
+
+// 创建一个全新的SQLite内存数据库
+func newDB(t *testing.T, name string) *gorm.DB {
+	t.Helper()
+	sqliteConf := config.Conf.Sqlite
+	config.Conf.Sqlite = &config.SqliteConfig{Path: "file:gotribe-e2e-" + name + "?mode=memory&cache=shared"}
+	db := common.InitDB()
+	config.Conf.Sqlite = sqliteConf
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取数据库连接失败: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// 初始迁移只创建引入版本化迁移时的表结构, 之后的字段与表由各自的迁移创建
+func TestMigrationInitSchemaFrozen(t *testing.T) {
+	db := newDB(t, "migrate")
+	if err := migrate.Migrations()[0].Up(db); err != nil {
+		t.Fatalf("执行初始迁移失败: %v", err)
+	}
+	columns := []struct {
+		table, column string
+	}{
+		{"operation_log", "request_id"},
+		{"operation_log", "request_body"},
+		{"user_event", "country"},
+		{"admin", "totp_secret"},
+		{"role", "totp_required"},
+		{"role", "all_projects"},
+	}
+	tables := []string{"admin_projects", "role_projects", "casbin_version", "service_account", "api_key", "login_log", "login_lock", "role_suspended_parent"}
+	for _, c := range columns {
+		if db.Migrator().HasColumn(c.table, c.column) {
+			t.Errorf("初始迁移不应创建%s.%s", c.table, c.column)
+		}
+	}
+	for _, table := range tables {
+		if db.Migrator().HasTable(table) {
+			t.Errorf("初始迁移不应创建%s表", table)
+		}
+	}
+
+	if _, err := migrate.Up(db); err != nil {
+		t.Fatalf("执行数据库迁移失败: %v", err)
+	}
+	for _, c := range columns {
+		if !db.Migrator().HasColumn(c.table, c.column) {
+			t.Errorf("迁移后缺少%s.%s", c.table, c.column)
+		}
+	}
+	for _, table := range tables {
+		if !db.Migrator().HasTable(table) {
+			t.Errorf("迁移后缺少%s表", table)
+		}
+	}
+}
+
+// 引入版本化迁移前创建的数据库中, 改名前的重复索引会被删除
+// SQLite中索引名全局唯一, 其余旧索引名与其它表的索引冲突, 只能在mysql中出现
+func TestMigrationLegacyIndex(t *testing.T) {
+	db := newDB(t, "legacy-index")
+	for _, sql := range []string{
+		"CREATE TABLE product_type (id integer PRIMARY KEY AUTOINCREMENT, product_category_id char(10) NOT NULL, spec_ids varchar(255) NOT NULL)",
+		"CREATE INDEX idx_spec_id ON product_type(spec_ids)",
+	} {
+		if err := db.Exec(sql).Error; err != nil {
+			t.Fatalf("创建旧表结构失败: %v", err)
+		}
+	}
+	if _, err := migrate.Up(db); err != nil {
+		t.Fatalf("执行数据库迁移失败: %v", err)
+	}
+	if db.Migrator().HasIndex("product_type", "idx_spec_id") {
+		t.Error("旧索引idx_spec_id未删除")
+	}
+	if !db.Migrator().HasIndex("product_type", "idx_product_type_spec_ids") {
+		t.Error("缺少索引idx_product_type_spec_ids")
+	}
+}
+
+// 升级前已有的角色中, 未分配项目的角色设置为可管理全部项目, 分配了项目的角色仍只能管理分配的项目
+func TestMigrationRoleAllProjectsBackfill(t *testing.T) {
+	db := newDB(t, "all-projects")
+	if _, err := migrate.Up(db); err != nil {
+		t.Fatalf("执行数据库迁移失败: %v", err)
+	}
+	// 回滚到增加all_projects字段之前
+	for {
+		done, err := migrate.Down(db, 1)
+		if err != nil || len(done) == 0 {
+			t.Fatalf("回滚数据库迁移失败: %v", err)
+		}
+		if done[0].Version <= 11 {
+			break
+		}
+	}
+	if db.Migrator().HasColumn("role", "all_projects") {
+		t.Fatal("回滚后不应存在role.all_projects")
+	}
+	for _, sql := range []string{
+		"INSERT INTO role (id, name, keyword, sort) VALUES (10, 'unscoped', 'unscoped', 10), (11, 'scoped', 'scoped', 10)",
+		"INSERT INTO role_projects (role_id, project_id) VALUES (11, '245eko')",
+	} {
+		if err := db.Exec(sql).Error; err != nil {
+			t.Fatalf("写入升级前数据失败: %v", err)
+		}
+	}
+
+	if _, err := migrate.Up(db); err != nil {
+		t.Fatalf("执行数据库迁移失败: %v", err)
+	}
+	want := map[uint]uint{10: 1, 11: 2}
+	for id, allProjects := range want {
+		var got uint
+		if err := db.Table("role").Where("id = ?", id).Pluck("all_projects", &got).Error; err != nil {
+			t.Fatalf("查询角色失败: %v", err)
+		}
+		if got != allProjects {
+			t.Errorf("角色%d的all_projects应为%d, 实际: %d", id, allProjects, got)
+		}
+	}
+}
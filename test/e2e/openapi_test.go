@@ -0,0 +1,122 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gotribe-admin/internal/app/routes"
+	"gotribe-admin/internal/pkg/openapi"
+)
+
+// 匿名请求, 返回http状态码与响应内容
+func fetch(path string) (int, string) {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w.Code, w.Body.String()
+}
+
+func TestOpenApiDocument(t *testing.T) {
+	status, body := fetch("/api/openapi.json")
+	if status != http.StatusOK {
+		t.Fatalf("获取文档应返回200, 实际: %d", status)
+	}
+	var doc openapi.Document
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		t.Fatalf("解析文档失败: %v", err)
+	}
+	if doc.OpenAPI != openapi.Version {
+		t.Fatalf("openapi版本为%q", doc.OpenAPI)
+	}
+
+	// 已注册的路由都在文档中
+	apis, err := routes.RegisteredApis(engine)
+	if err != nil {
+		t.Fatalf("获取已注册路由失败: %v", err)
+	}
+	for _, api := range apis {
+		path := api.Path
+		for _, segment := range strings.Split(path, "/") {
+			if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+				path = strings.Replace(path, segment, "{"+segment[1:]+"}", 1)
+			}
+		}
+		item, ok := doc.Paths[path]
+		if !ok || (*item)[strings.ToLower(api.Method)] == nil {
+			t.Fatalf("文档中缺少接口%s %s", api.Method, path)
+		}
+	}
+
+	// 路径参数
+	item := doc.Paths["/post/{postID}"]
+	if item == nil || (*item)["get"] == nil {
+		t.Fatal("文档中缺少GET /post/{postID}")
+	}
+	op := (*item)["get"]
+	if len(op.Parameters) == 0 || op.Parameters[0].Name != "postID" || op.Parameters[0].In != "path" {
+		t.Fatalf("路径参数不正确: %+v", op.Parameters)
+	}
+
+	// GET请求的查询参数
+	list := (*doc.Paths["/post"])["get"]
+	found := false
+	for _, p := range list.Parameters {
+		found = found || (p.Name == "pageNum" && p.In == "query")
+	}
+	if !found {
+		t.Fatalf("内容列表缺少查询参数pageNum: %+v", list.Parameters)
+	}
+
+	// 请求体引用vo结构体, 必填与长度约束取自validate标签
+	create := (*doc.Paths["/post"])["post"]
+	if create.RequestBody == nil || create.RequestBody.Content["application/json"].Schema.Ref != "#/components/schemas/vo.CreatePostRequest" {
+		t.Fatalf("创建内容的请求体不正确: %+v", create.RequestBody)
+	}
+	schema := doc.Components.Schemas["vo.CreatePostRequest"]
+	if schema == nil || !contains(schema.Required, "title") {
+		t.Fatalf("请求体缺少必填字段title: %+v", schema)
+	}
+	if title := schema.Properties["title"]; title.MinLength == nil || *title.MinLength != 2 || *title.MaxLength != 60 {
+		t.Fatalf("title的长度约束不正确: %+v", title)
+	}
+
+	// 公开接口不需要认证
+	if login := (*doc.Paths["/base/login"])["post"]; len(login.Security) != 0 {
+		t.Fatalf("登录接口不应需要认证: %+v", login.Security)
+	}
+	if len(create.Security) == 0 {
+		t.Fatal("创建内容应需要认证")
+	}
+}
+
+func TestSwaggerUI(t *testing.T) {
+	// index.html重定向到目录
+	if status, _ := fetch("/api/docs/index.html"); status != http.StatusMovedPermanently {
+		t.Fatalf("index.html应重定向到目录, 实际: %d", status)
+	}
+	status, body := fetch("/api/docs/")
+	if status != http.StatusOK || !strings.Contains(body, "swagger-ui") {
+		t.Fatalf("Swagger UI页面不正确: %d", status)
+	}
+	status, body = fetch("/api/docs/swagger-initializer.js")
+	if status != http.StatusOK || !strings.Contains(body, `"/api/openapi.json"`) {
+		t.Fatalf("Swagger UI应加载本服务的文档: %d %s", status, body)
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
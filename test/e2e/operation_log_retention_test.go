@@ -0,0 +1,121 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package e2e
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gotribe-admin/config"
+	"gotribe-admin/internal/app/jobs"
+	"gotribe-admin/internal/pkg/model"
+)
+
+func TestExportOperationLogs(t *testing.T) {
+	log := model.OperationLog{
+		Username:    adminUsername,
+		Method:      http.MethodPost,
+		Path:        "/export",
+		Desc:        "=HYPERLINK(\"http://example.com\")",
+		Status:      http.StatusOK,
+		StartTime:   time.Now(),
+		RequestBody: `{"remark":"e2e-export-marker","note":"含有,逗号"}`,
+	}
+	if err := container.DB.Create(&log).Error; err != nil {
+		t.Fatalf("写入操作日志失败: %v", err)
+	}
+
+	admin := login(t, adminUsername, adminPassword)
+	req := httptest.NewRequest(http.MethodGet, "/api/log/operation/export?body=e2e-export-marker", nil)
+	req.Header.Set("Authorization", "Bearer "+admin.token)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("导出失败, status: %d, body: %s", w.Code, w.Body.String())
+	}
+
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(w.Body.String(), "\xEF\xBB\xBF"))).ReadAll()
+	if err != nil {
+		t.Fatalf("导出内容不是csv: %v", err)
+	}
+	if len(records) != 2 || records[0][0] != "ID" {
+		t.Fatalf("应导出表头与1条操作日志, 实际: %v", records)
+	}
+	row := records[1]
+	if row[7] != "'"+log.Desc || row[12] != log.RequestBody {
+		t.Fatalf("导出内容不正确: %v", row)
+	}
+}
+
+func TestOperationLogRetention(t *testing.T) {
+	original := config.Conf.OperationLog
+	config.Conf.OperationLog = &config.OperationLogConfig{RetentionDays: 30, Archive: true, ArchivePath: "archive/e2e"}
+	t.Cleanup(func() {
+		config.Conf.OperationLog = original
+	})
+
+	expired := model.OperationLog{Username: adminUsername, Method: http.MethodGet, Path: "/expired", StartTime: time.Now().AddDate(0, 0, -40)}
+	recent := model.OperationLog{Username: adminUsername, Method: http.MethodGet, Path: "/recent", StartTime: time.Now().AddDate(0, 0, -20)}
+	for _, log := range []*model.OperationLog{&expired, &recent} {
+		if err := container.DB.Create(log).Error; err != nil {
+			t.Fatalf("写入操作日志失败: %v", err)
+		}
+	}
+
+	if err := jobs.OperationLogRetention(container); err != nil {
+		t.Fatalf("清理操作日志失败: %v", err)
+	}
+
+	var count int64
+	container.DB.Model(&model.OperationLog{}).Where("id IN ?", []uint{expired.ID, recent.ID}).Count(&count)
+	if count != 1 || container.DB.First(&model.OperationLog{}, recent.ID).Error != nil {
+		t.Fatal("应只删除超过保留天数的操作日志")
+	}
+
+	// 归档文件中保存了被删除的日志
+	files, _ := filepath.Glob(filepath.Join(config.Conf.UploadFile.LocalPath, "archive", "e2e", "*", "*.jsonl.gz"))
+	if len(files) != 1 {
+		t.Fatalf("应生成1个归档文件, 实际: %v", files)
+	}
+	file, err := os.Open(files[0])
+	if err != nil {
+		t.Fatalf("打开归档文件失败: %v", err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("归档文件不是gzip格式: %v", err)
+	}
+	scanner := bufio.NewScanner(gz)
+	var archived []model.OperationLog
+	for scanner.Scan() {
+		var log model.OperationLog
+		if err := json.Unmarshal(scanner.Bytes(), &log); err != nil {
+			t.Fatalf("归档内容不是json lines: %v", err)
+		}
+		archived = append(archived, log)
+	}
+	// 其他用例写入的未设置发起时间的日志同样会被清理
+	found := false
+	for _, log := range archived {
+		if log.ID == recent.ID {
+			t.Fatal("未超过保留天数的日志不应归档")
+		}
+		found = found || (log.ID == expired.ID && log.Path == "/expired")
+	}
+	if !found {
+		t.Fatalf("归档内容中缺少被删除的日志: %+v", archived)
+	}
+}
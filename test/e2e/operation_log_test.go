@@ -0,0 +1,168 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package e2e
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gotribe-admin/internal/pkg/common"
+	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/internal/pkg/oplog"
+	"gotribe-admin/pkg/api/known"
+)
+
+func TestOperationLogWriter(t *testing.T) {
+	res := login(t, adminUsername, adminPassword).do(t, http.MethodGet, "/api/log/operation/list", nil).ok(t)
+	log := operationLogByRequestID(t, res.RequestID)
+	if log.Path != "/log/operation/list" || log.Username != adminUsername || log.Desc != "获取操作日志列表" {
+		t.Fatalf("操作日志内容不正确: %+v", log)
+	}
+}
+
+func TestOperationLogSpill(t *testing.T) {
+	spillPath := filepath.Join(t.TempDir(), "operation_log.spill")
+	opts := oplog.Options{BatchSize: 2, QueueSize: 1, Overflow: oplog.OverflowSpill, SpillPath: spillPath}
+	failing := func(ctx context.Context, logs []model.OperationLog) error {
+		return errors.New("database unavailable")
+	}
+
+	// 未启动的写入器队列只能放1条, 其余直接写入本地文件, 关闭时写库失败的也写入本地文件
+	writer := oplog.NewWriter(opts, failing, common.Log, nil)
+	for i := 0; i < 3; i++ {
+		writer.Write(&model.OperationLog{RequestID: "e2e-spill", Method: http.MethodGet, Path: "/spill"})
+	}
+	if err := writer.Close(context.Background()); err != nil {
+		t.Fatalf("关闭写入器失败: %v", err)
+	}
+
+	// 下次启动时补写入库
+	var saved []model.OperationLog
+	writer = oplog.NewWriter(opts, func(ctx context.Context, logs []model.OperationLog) error {
+		saved = append(saved, logs...)
+		return nil
+	}, common.Log, nil)
+	writer.Start()
+	defer writer.Close(context.Background())
+	if len(saved) != 3 {
+		t.Fatalf("应补写3条操作日志, 实际: %d", len(saved))
+	}
+	for _, log := range saved {
+		if log.RequestID != "e2e-spill" {
+			t.Fatalf("补写的操作日志内容不正确: %+v", log)
+		}
+	}
+}
+
+// 写入队列中的操作日志后按请求ID查询
+func operationLogByRequestID(t *testing.T, requestID string) model.OperationLog {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := container.OperationLogs.Flush(ctx); err != nil {
+		t.Fatalf("写入操作日志失败: %v", err)
+	}
+	var log model.OperationLog
+	if err := container.DB.Where("request_id = ?", requestID).First(&log).Error; err != nil {
+		t.Fatalf("请求后应写入操作日志: %v", err)
+	}
+	return log
+}
+
+func TestOperationLogBodyMasking(t *testing.T) {
+	// 原密码无法解密, 请求失败但仍记录脱敏后的请求体
+	res := login(t, adminUsername, adminPassword).do(t, http.MethodPut, "/api/admin/changePwd", map[string]string{
+		"oldPassword": "e2e-old-secret",
+		"newPassword": "e2e-new-secret",
+	})
+	log := operationLogByRequestID(t, res.RequestID)
+
+	if !strings.Contains(log.RequestBody, `"oldPassword":"******"`) || !strings.Contains(log.RequestBody, `"newPassword":"******"`) {
+		t.Fatalf("请求体应脱敏密码: %s", log.RequestBody)
+	}
+	if strings.Contains(log.RequestBody, "e2e-old-secret") || strings.Contains(log.RequestBody, "e2e-new-secret") {
+		t.Fatalf("请求体不应包含密码明文: %s", log.RequestBody)
+	}
+}
+
+func TestOperationLogSkipBodyCapture(t *testing.T) {
+	// 登录接口签发token, 不记录请求体与响应体, 其余字段照常记录
+	res := postLogin(t, adminUsername, adminPassword).ok(t)
+	log := operationLogByRequestID(t, res.RequestID)
+
+	if log.Path != "/base/login" || log.Status != http.StatusOK {
+		t.Fatalf("操作日志内容不正确: %+v", log)
+	}
+	if log.RequestBody != "" || log.ResponseBody != "" {
+		t.Fatalf("签发凭证的接口不应记录请求体与响应体, 请求体: %s, 响应体: %s", log.RequestBody, log.ResponseBody)
+	}
+}
+
+func TestOperationLogCapture(t *testing.T) {
+	capture := oplog.NewCapture(oplog.CaptureOptions{MaskFields: []string{"password", "secretKey"}})
+
+	// 截断后缺少结尾引号的字段值同样脱敏
+	masked := capture.Mask("application/json", []byte(`{"name":"a","SecretKey":"abc\"d","nested":{"password":"sec`), true)
+	want := `{"name":"a","SecretKey":"******","nested":{"password":"******"...(truncated)`
+	if masked != want {
+		t.Fatalf("json脱敏结果不正确: %s", masked)
+	}
+	masked = capture.Mask("application/x-www-form-urlencoded", []byte("username=admin&password=123456"), false)
+	if masked != "username=admin&password=******" {
+		t.Fatalf("表单脱敏结果不正确: %s", masked)
+	}
+	if oplog.Capturable("multipart/form-data; boundary=x") {
+		t.Fatal("不应记录文件上传内容")
+	}
+}
+
+func TestOperationLogListFilters(t *testing.T) {
+	startTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	log := model.OperationLog{
+		Username:    adminUsername,
+		Method:      http.MethodPut,
+		Path:        "/order/:orderID",
+		Status:      http.StatusNotFound,
+		StartTime:   startTime,
+		RequestBody: `{"remark":"e2e-body-marker"}`,
+	}
+	if err := container.DB.Create(&log).Error; err != nil {
+		t.Fatalf("写入操作日志失败: %v", err)
+	}
+
+	admin := login(t, adminUsername, adminPassword)
+	list := func(query string) int64 {
+		t.Helper()
+		res := admin.do(t, http.MethodGet, "/api/log/operation/list?body=e2e-body-marker&"+query, nil).ok(t)
+		var data struct {
+			Total int64 `json:"total"`
+		}
+		res.decode(t, &data)
+		return data.Total
+	}
+	from := startTime.Add(-time.Minute).Format(known.TIME_FORMAT)
+	to := startTime.Add(time.Minute).Format(known.TIME_FORMAT)
+	if total := list("statusMin=400&statusMax=499&startTime=" + url.QueryEscape(from) + "&endTime=" + url.QueryEscape(to)); total != 1 {
+		t.Fatalf("应查到1条操作日志, 实际: %d", total)
+	}
+	if total := list("statusMax=299"); total != 0 {
+		t.Fatalf("状态码范围外不应查到操作日志, 实际: %d", total)
+	}
+	if total := list("endTime=" + url.QueryEscape(startTime.Add(-time.Minute).Format(known.TIME_FORMAT))); total != 0 {
+		t.Fatalf("时间范围外不应查到操作日志, 实际: %d", total)
+	}
+
+	res := admin.do(t, http.MethodGet, "/api/log/operation/list?startTime=yesterday", nil)
+	if res.Code == http.StatusOK {
+		t.Fatal("时间格式错误时应返回失败")
+	}
+}
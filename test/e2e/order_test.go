@@ -0,0 +1,68 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"gotribe-admin/internal/pkg/model"
+)
+
+func TestUpdateOrder(t *testing.T) {
+	// 后台没有创建订单的接口, 直接写入数据库
+	order := &model.Order{
+		OrderNumber: "E2E0000000001",
+		OrderType:   1,
+		UserID:      projectID,
+		Username:    "gotribe",
+		ProductID:   "e2eproduct",
+		ProductSku:  "e2esku",
+		ProductName: "端到端测试商品",
+		Status:      1,
+		PayMethod:   1,
+		PayStatus:   1,
+		ProjectID:   projectID,
+		Amount:      1000,
+		AmountPay:   1000,
+		Quantity:    1,
+		UnitPrice:   1000,
+	}
+	if err := container.DB.Create(order).Error; err != nil {
+		t.Fatalf("创建订单失败: %v", err)
+	}
+
+	c := login(t, adminUsername, adminPassword)
+	c.do(t, http.MethodPatch, "/api/order/"+order.OrderID, map[string]interface{}{
+		"amountPay":   8.5,
+		"remarkAdmin": "端到端测试改价",
+		"status":      2,
+	}).ok(t)
+
+	var data struct {
+		Order struct {
+			AmountPay   float64 `json:"amountPay"`
+			RemarkAdmin string  `json:"remarkAdmin"`
+			Status      uint    `json:"status"`
+		} `json:"order"`
+	}
+	c.do(t, http.MethodGet, "/api/order/"+order.OrderID, nil).ok(t).decode(t, &data)
+	if data.Order.AmountPay != 8.5 || data.Order.RemarkAdmin != "端到端测试改价" || data.Order.Status != 2 {
+		t.Fatalf("更新后的订单为%+v, 期望实付8.5、备注%q、状态2", data.Order, "端到端测试改价")
+	}
+
+	// 更新订单会记录操作日志
+	var logs int64
+	if err := container.DB.Model(&model.OrderLog{}).Where("order_id = ?", order.OrderID).Count(&logs).Error; err != nil || logs != 1 {
+		t.Fatalf("订单操作记录为%d条(%v), 期望1条", logs, err)
+	}
+
+	// 实付金额校验
+	res := c.do(t, http.MethodPatch, "/api/order/"+order.OrderID, map[string]interface{}{"amountPay": 0, "status": 2})
+	if res.Status != http.StatusBadRequest {
+		t.Fatalf("实付金额为0时status为%d, 期望%d", res.Status, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package e2e
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/internal/pkg/permission"
+)
+
+// 权限诊断结果
+type explainResult struct {
+	Allowed         bool                `json:"allowed"`
+	Reason          string              `json:"reason"`
+	Path            string              `json:"path"`
+	DisabledRoles   []*model.Role       `json:"disabledRoles"`
+	MatchedPolicy   *permission.Policy  `json:"matchedPolicy"`
+	ClosestPolicies []permission.Policy `json:"closestPolicies"`
+	Registered      bool                `json:"registered"`
+}
+
+// 调用权限诊断接口
+func explain(t *testing.T, c *client, query url.Values) explainResult {
+	t.Helper()
+	var data struct {
+		Explain explainResult `json:"explain"`
+	}
+	c.do(t, http.MethodGet, "/api/permission/explain?"+query.Encode(), nil).ok(t).decode(t, &data)
+	return data.Explain
+}
+
+func TestPermissionExplain(t *testing.T) {
+	ctx := context.Background()
+	root := login(t, adminUsername, adminPassword)
+
+	editor := createRole(t, "e2e_explain_editor", 20, []string{"/post", "GET"}, []string{"/post/:postID", "GET"})
+	chief := createRole(t, "e2e_explain_chief", 15, []string{"/project", "GET"})
+	if err := container.Repositories.Role.UpdateRoleParents(ctx, chief, []*model.Role{editor}); err != nil {
+		t.Fatalf("设置角色继承失败: %v", err)
+	}
+	admin := createAdmin(t, "e2e_explain", "123456", chief.Keyword)
+	id := fmt.Sprint(admin.ID)
+
+	// 继承的策略, 带接口前缀与查询参数的路径
+	res := explain(t, root, url.Values{"adminID": {id}, "method": {"get"}, "path": {"/api/post?page=1"}})
+	if !res.Allowed || res.Path != "/post" || !res.Registered {
+		t.Fatalf("诊断结果不正确: %+v", res)
+	}
+	if res.MatchedPolicy == nil || res.MatchedPolicy.Subject != chief.Keyword || res.MatchedPolicy.Role != editor.Keyword {
+		t.Fatalf("命中策略为%+v, 期望继承自%s", res.MatchedPolicy, editor.Keyword)
+	}
+	if !strings.Contains(res.Reason, "继承") {
+		t.Fatalf("原因为%q, 期望说明继承", res.Reason)
+	}
+
+	// 未通过时给出最接近的策略, 路径匹配只差请求方式的排在最前
+	res = explain(t, root, url.Values{"adminID": {id}, "method": {http.MethodDelete}, "path": {"/post"}})
+	if res.Allowed || res.MatchedPolicy != nil || len(res.ClosestPolicies) == 0 {
+		t.Fatalf("诊断结果不正确: %+v", res)
+	}
+	if closest := res.ClosestPolicies[0]; closest.Path != "/post" || closest.Method != http.MethodGet {
+		t.Fatalf("最接近的策略为%+v, 期望GET /post", closest)
+	}
+
+	// 未登记的接口
+	res = explain(t, root, url.Values{"adminID": {id}, "method": {http.MethodGet}, "path": {"/e2e/unregistered"}})
+	if res.Allowed || res.Registered {
+		t.Fatalf("未登记接口的诊断结果不正确: %+v", res)
+	}
+
+	// 按角色诊断
+	res = explain(t, root, url.Values{"roleKeyword": {editor.Keyword}, "method": {http.MethodGet}, "path": {"/post/abc"}})
+	if !res.Allowed || res.MatchedPolicy == nil || res.MatchedPolicy.Path != "/post/:postID" {
+		t.Fatalf("按角色诊断结果不正确: %+v", res)
+	}
+
+	// 被禁用的角色不参与校验
+	if err := container.DB.Model(chief).Update("status", 2).Error; err != nil {
+		t.Fatalf("禁用角色失败: %v", err)
+	}
+	res = explain(t, root, url.Values{"adminID": {id}, "method": {http.MethodGet}, "path": {"/post"}})
+	if res.Allowed || len(res.DisabledRoles) != 1 || res.DisabledRoles[0].Keyword != chief.Keyword {
+		t.Fatalf("角色禁用后诊断结果不正确: %+v", res)
+	}
+
+	// 用户ID与角色关键字不能同时为空
+	if r := root.do(t, http.MethodGet, "/api/permission/explain?method=GET&path=/post", nil); r.Status != http.StatusBadRequest {
+		t.Fatalf("缺少诊断对象时status为%d, 期望%d", r.Status, http.StatusBadRequest)
+	}
+}
+
+func TestPermissionMatrix(t *testing.T) {
+	root := login(t, adminUsername, adminPassword)
+	editor := createRole(t, "e2e_matrix_editor", 20, []string{"/post", "GET"})
+	admin := createAdmin(t, "e2e_matrix", "123456", editor.Keyword)
+
+	var data struct {
+		Matrix struct {
+			Username string                 `json:"username"`
+			Apis     []permission.MatrixRow `json:"apis"`
+		} `json:"matrix"`
+	}
+	root.do(t, http.MethodGet, fmt.Sprintf("/api/permission/matrix/%d", admin.ID), nil).ok(t).decode(t, &data)
+	allowed := 0
+	for _, row := range data.Matrix.Apis {
+		if !row.Allowed {
+			continue
+		}
+		allowed++
+		if row.Method != http.MethodGet || row.Path != "/post" || len(row.Roles) != 1 || row.Roles[0] != editor.Keyword {
+			t.Fatalf("不应允许的接口: %+v", row)
+		}
+	}
+	if allowed != 1 || data.Matrix.Username != admin.Username {
+		t.Fatalf("有效权限不正确, 允许%d个接口: %+v", allowed, data.Matrix)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/permission/matrix/export/%d", admin.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+root.token)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Body.String(), "\xEF\xBB\xBF") {
+		t.Fatalf("导出失败, status: %d, body: %s", w.Code, w.Body.String())
+	}
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(w.Body.String(), "\xEF\xBB\xBF"))).ReadAll()
+	if err != nil {
+		t.Fatalf("导出内容不是csv: %v", err)
+	}
+	if len(records) != len(data.Matrix.Apis)+1 || records[0][0] != "接口ID" {
+		t.Fatalf("导出行数为%d, 期望%d", len(records), len(data.Matrix.Apis)+1)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package e2e
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+// 内置初始数据中的项目与分类
+const (
+	projectID  = "245eko"
+	categoryID = "24ejga"
+)
+
+type postInfo struct {
+	PostID      string `json:"postID"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Status      uint   `json:"status"`
+}
+
+func TestPostCRUD(t *testing.T) {
+	c := login(t, adminUsername, adminPassword)
+
+	// 创建
+	post := map[string]interface{}{
+		"title":       "端到端测试内容",
+		"description": "端到端测试内容描述",
+		"categoryID":  categoryID,
+		"projectID":   projectID,
+		"userID":      projectID,
+		"author":      "e2e",
+		"content":     "# 端到端测试",
+		"htmlContent": "<h1>端到端测试</h1>",
+		"type":        1,
+	}
+	c.do(t, http.MethodPost, "/api/post", post).ok(t)
+
+	// 创建接口不返回ID, 按标题查询
+	posts := listPosts(t, c, post["title"].(string))
+	if len(posts) != 1 {
+		t.Fatalf("按标题查询到%d条内容, 期望1条", len(posts))
+	}
+	postID := posts[0].PostID
+
+	// 详情
+	if got := getPost(t, c, postID); got.Title != post["title"] {
+		t.Fatalf("内容标题为%q, 期望%q", got.Title, post["title"])
+	}
+
+	// 更新
+	post["title"] = "端到端测试内容(已更新)"
+	post["description"] = "更新后的描述"
+	post["status"] = 2
+	c.do(t, http.MethodPatch, "/api/post/"+postID, post).ok(t)
+	got := getPost(t, c, postID)
+	if got.Title != post["title"] || got.Description != post["description"] || got.Status != 2 {
+		t.Fatalf("更新后的内容为%+v, 期望标题%q、描述%q、状态2", got, post["title"], post["description"])
+	}
+
+	// 删除
+	c.do(t, http.MethodDelete, "/api/post", map[string]string{"postIds": postID}).ok(t)
+	if res := c.do(t, http.MethodGet, "/api/post/"+postID, nil); res.Status != http.StatusBadRequest {
+		t.Fatalf("删除后获取内容status为%d, 期望%d", res.Status, http.StatusBadRequest)
+	}
+	if posts := listPosts(t, c, "端到端测试内容"); len(posts) != 0 {
+		t.Fatalf("删除后仍能查询到%d条内容", len(posts))
+	}
+}
+
+func TestCreatePostValidation(t *testing.T) {
+	c := login(t, adminUsername, adminPassword)
+	res := c.do(t, http.MethodPost, "/api/post", map[string]interface{}{"title": "缺少必填字段"})
+	if res.Status != http.StatusBadRequest || res.Message == "" {
+		t.Fatalf("缺少必填字段时status为%d, message为%q, 期望%d及校验提示", res.Status, res.Message, http.StatusBadRequest)
+	}
+}
+
+func listPosts(t *testing.T, c *client, title string) []postInfo {
+	t.Helper()
+	var data struct {
+		Posts []postInfo `json:"posts"`
+	}
+	c.do(t, http.MethodGet, "/api/post?"+url.Values{"title": {title}}.Encode(), nil).ok(t).decode(t, &data)
+	return data.Posts
+}
+
+func getPost(t *testing.T, c *client, postID string) postInfo {
+	t.Helper()
+	var data struct {
+		Post postInfo `json:"post"`
+	}
+	c.do(t, http.MethodGet, "/api/post/"+postID, nil).ok(t).decode(t, &data)
+	return data.Post
+}
@@ -0,0 +1,267 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"gotribe-admin/internal/pkg/model"
+)
+
+func TestProjectScope(t *testing.T) {
+	ctx := context.Background()
+	root := login(t, adminUsername, adminPassword)
+
+	// 另一个客户的站点
+	other := &model.Project{Name: "e2e_other", Title: "其他站点"}
+	if err := container.Repositories.Project.CreateProject(ctx, other); err != nil {
+		t.Fatalf("创建项目失败: %v", err)
+	}
+	t.Cleanup(func() {
+		container.DB.Unscoped().Where("project_id = ?", other.ProjectID).Delete(&model.Post{})
+		container.DB.Unscoped().Delete(other)
+	})
+	otherPost := newScopedPost("项目隔离-其他站点", other.ProjectID)
+	root.do(t, http.MethodPost, "/api/post", otherPost).ok(t)
+	otherPostID := listPosts(t, root, otherPost["title"].(string))[0].PostID
+
+	// 只能管理内置项目的编辑角色
+	role := &model.Role{Name: "e2e编辑", Keyword: "e2e_editor", Status: 1, Sort: 10, Creator: "e2e"}
+	if err := container.Repositories.Role.CreateRole(ctx, role); err != nil {
+		t.Fatalf("创建角色失败: %v", err)
+	}
+	policies := [][]string{
+		{role.Keyword, "/project", "GET"},
+		{role.Keyword, "/post", "GET"},
+		{role.Keyword, "/post", "POST"},
+		{role.Keyword, "/post/:postID", "GET"},
+		{role.Keyword, "/post/:postID", "PATCH"},
+	}
+	if _, err := container.Enforcer.AddPolicies(policies); err != nil {
+		t.Fatalf("添加角色权限失败: %v", err)
+	}
+	t.Cleanup(func() {
+		container.Enforcer.RemovePolicies(policies)
+	})
+	root.do(t, http.MethodPatch, fmt.Sprintf("/api/role/projects/update/%d", role.ID), map[string]interface{}{
+		"projectIds": []string{projectID},
+	}).ok(t)
+	var data struct {
+		Projects []*model.Project `json:"projects"`
+	}
+	root.do(t, http.MethodGet, fmt.Sprintf("/api/role/projects/get/%d", role.ID), nil).ok(t).decode(t, &data)
+	if len(data.Projects) != 1 || data.Projects[0].ProjectID != projectID {
+		t.Fatalf("角色可管理的项目为%+v, 期望只有%s", data.Projects, projectID)
+	}
+
+	editor := createAdmin(t, "e2e_editor", "123456", role.Keyword)
+	c := login(t, editor.Username, "123456")
+
+	// 项目列表只返回可管理的项目
+	var projects struct {
+		Projects []struct {
+			ProjectID string `json:"projectID"`
+		} `json:"projects"`
+	}
+	c.do(t, http.MethodGet, "/api/project", nil).ok(t).decode(t, &projects)
+	if len(projects.Projects) != 1 || projects.Projects[0].ProjectID != projectID {
+		t.Fatalf("项目列表为%+v, 期望只有%s", projects.Projects, projectID)
+	}
+
+	// 其他项目的内容不可见
+	if posts := listPosts(t, c, otherPost["title"].(string)); len(posts) != 0 {
+		t.Fatalf("查询到其他项目的%d条内容", len(posts))
+	}
+	if res := c.do(t, http.MethodGet, "/api/post/"+otherPostID, nil); res.Status != http.StatusBadRequest {
+		t.Fatalf("获取其他项目的内容status为%d, 期望%d", res.Status, http.StatusBadRequest)
+	}
+
+	// 不能在其他项目中新增内容
+	res := c.do(t, http.MethodPost, "/api/post", newScopedPost("项目隔离-越权新增", other.ProjectID))
+	if res.Status != http.StatusBadRequest {
+		t.Fatalf("在其他项目中新增内容status为%d, 期望%d", res.Status, http.StatusBadRequest)
+	}
+
+	// 可以管理自己项目的内容, 但不能移到其他项目
+	own := newScopedPost("项目隔离-本站点", projectID)
+	c.do(t, http.MethodPost, "/api/post", own).ok(t)
+	ownPostID := listPosts(t, c, own["title"].(string))[0].PostID
+	t.Cleanup(func() {
+		container.DB.Unscoped().Where("post_id = ?", ownPostID).Delete(&model.Post{})
+	})
+	own["projectID"] = other.ProjectID
+	if res := c.do(t, http.MethodPatch, "/api/post/"+ownPostID, own); res.Status != http.StatusBadRequest {
+		t.Fatalf("将内容移到其他项目status为%d, 期望%d", res.Status, http.StatusBadRequest)
+	}
+	if got := listPosts(t, root, own["title"].(string)); len(got) != 1 {
+		t.Fatalf("越权更新后按标题查询到%d条内容, 期望1条", len(got))
+	}
+
+	// 超级管理员不受限制
+	if posts := listPosts(t, root, otherPost["title"].(string)); len(posts) != 1 {
+		t.Fatalf("超级管理员查询到其他项目的%d条内容, 期望1条", len(posts))
+	}
+}
+
+func newScopedPost(title, project string) map[string]interface{} {
+	return map[string]interface{}{
+		"title":       title,
+		"description": title,
+		"categoryID":  categoryID,
+		"projectID":   project,
+		"userID":      project,
+		"author":      "e2e",
+		"content":     title,
+		"htmlContent": title,
+		"type":        1,
+	}
+}
+
+func TestProjectScopeAd(t *testing.T) {
+	ctx := context.Background()
+	root := login(t, adminUsername, adminPassword)
+
+	// 广告没有项目字段, 通过推广场景关联项目
+	other := &model.Project{Name: "e2e_ad_other", Title: "其他站点"}
+	if err := container.Repositories.Project.CreateProject(ctx, other); err != nil {
+		t.Fatalf("创建项目失败: %v", err)
+	}
+	otherScene := &model.AdScene{Title: "e2e其他站点场景", ProjectID: other.ProjectID}
+	ownScene := &model.AdScene{Title: "e2e本站点场景", ProjectID: projectID}
+	for _, scene := range []*model.AdScene{otherScene, ownScene} {
+		if err := container.DB.Create(scene).Error; err != nil {
+			t.Fatalf("创建推广场景失败: %v", err)
+		}
+	}
+	otherAd := &model.Ad{Title: "e2e其他站点广告", URL: "https://example.com", URLType: 1, Sort: 1, Status: 1, SceneID: otherScene.AdSceneID}
+	if err := container.DB.Create(otherAd).Error; err != nil {
+		t.Fatalf("创建广告失败: %v", err)
+	}
+	t.Cleanup(func() {
+		container.DB.Unscoped().Where("scene_id IN ?", []string{otherScene.AdSceneID, ownScene.AdSceneID}).Delete(&model.Ad{})
+		container.DB.Unscoped().Delete(otherScene)
+		container.DB.Unscoped().Delete(ownScene)
+		container.DB.Unscoped().Delete(other)
+	})
+
+	role := createRole(t, "e2e_ad_editor", 10,
+		[]string{"/ad", "GET"}, []string{"/ad", "POST"}, []string{"/ad", "DELETE"},
+		[]string{"/ad/:adID", "GET"}, []string{"/ad/:adID", "PATCH"})
+	root.do(t, http.MethodPatch, fmt.Sprintf("/api/role/projects/update/%d", role.ID), map[string]interface{}{
+		"projectIds": []string{projectID},
+	}).ok(t)
+	editor := createAdmin(t, "e2e_ad_editor", "123456", role.Keyword)
+	c := login(t, editor.Username, "123456")
+
+	ad := func(title, sceneID string) map[string]interface{} {
+		return map[string]interface{}{"title": title, "url": "https://example.com", "urlType": 1, "sort": 1, "status": 1, "sceneID": sceneID}
+	}
+	listAds := func(c *client) []string {
+		t.Helper()
+		var data struct {
+			Ads []struct {
+				AdID string `json:"adID"`
+			} `json:"ads"`
+		}
+		c.do(t, http.MethodGet, "/api/ad?title=e2e", nil).ok(t).decode(t, &data)
+		var ids []string
+		for _, ad := range data.Ads {
+			ids = append(ids, ad.AdID)
+		}
+		return ids
+	}
+
+	// 其他项目的广告不可见, 不能修改或删除
+	for _, id := range listAds(c) {
+		if id == otherAd.AdID {
+			t.Fatal("查询到其他项目的广告")
+		}
+	}
+	if res := c.do(t, http.MethodGet, "/api/ad/"+otherAd.AdID, nil); res.Code == http.StatusOK {
+		t.Fatal("不应能获取其他项目的广告")
+	}
+	if res := c.do(t, http.MethodPatch, "/api/ad/"+otherAd.AdID, ad("e2e越权修改", otherScene.AdSceneID)); res.Code == http.StatusOK {
+		t.Fatal("不应能修改其他项目的广告")
+	}
+	if res := c.do(t, http.MethodDelete, "/api/ad", map[string]string{"adsIds": otherAd.AdID}); res.Code == http.StatusOK {
+		t.Fatal("不应能删除其他项目的广告")
+	}
+	var stored model.Ad
+	if err := container.DB.Where("ad_id = ?", otherAd.AdID).First(&stored).Error; err != nil || stored.Title != otherAd.Title {
+		t.Fatalf("其他项目的广告被修改或删除: %+v, %v", stored, err)
+	}
+
+	// 不能在其他项目的场景下新增广告, 也不能把自己的广告移到其他项目的场景
+	if res := c.do(t, http.MethodPost, "/api/ad", ad("e2e越权新增", otherScene.AdSceneID)); res.Code == http.StatusOK {
+		t.Fatal("不应能在其他项目的场景下新增广告")
+	}
+	c.do(t, http.MethodPost, "/api/ad", ad("e2e本站点广告", ownScene.AdSceneID)).ok(t)
+	var own model.Ad
+	if err := container.DB.Where("scene_id = ?", ownScene.AdSceneID).First(&own).Error; err != nil {
+		t.Fatalf("获取新增的广告失败: %v", err)
+	}
+	if res := c.do(t, http.MethodPatch, "/api/ad/"+own.AdID, ad("e2e本站点广告", otherScene.AdSceneID)); res.Code == http.StatusOK {
+		t.Fatal("不应能把广告移到其他项目的场景")
+	}
+	c.do(t, http.MethodPatch, "/api/ad/"+own.AdID, ad("e2e本站点广告-修改", ownScene.AdSceneID)).ok(t)
+
+	// 超级管理员不受限制
+	if ids := listAds(root); len(ids) != 2 {
+		t.Fatalf("超级管理员查询到%d条广告, 期望2条", len(ids))
+	}
+}
+
+func TestProjectScopeDefault(t *testing.T) {
+	// 未分配任何项目的用户不能管理任何项目
+	role := createRole(t, "e2e_unassigned", 10,
+		[]string{"/project", "GET"}, []string{"/post", "GET"}, []string{"/role/create", "POST"})
+	editor := createAdmin(t, "e2e_unassigned", "123456", role.Keyword)
+	c := login(t, editor.Username, "123456")
+
+	var projects struct {
+		Projects []struct {
+			ProjectID string `json:"projectID"`
+		} `json:"projects"`
+	}
+	c.do(t, http.MethodGet, "/api/project", nil).ok(t).decode(t, &projects)
+	if len(projects.Projects) != 0 {
+		t.Fatalf("未分配项目的用户查询到%d个项目", len(projects.Projects))
+	}
+	var posts struct {
+		Total int64 `json:"total"`
+	}
+	c.do(t, http.MethodGet, "/api/post", nil).ok(t).decode(t, &posts)
+	if posts.Total != 0 {
+		t.Fatalf("未分配项目的用户查询到%d条内容", posts.Total)
+	}
+
+	// 受项目限制的用户不能创建可管理全部项目的角色
+	res := c.do(t, http.MethodPost, "/api/role/create", map[string]interface{}{
+		"name": "e2e_escalate", "keyword": "e2e_escalate", "status": 1, "sort": 20, "allProjects": 1,
+	})
+	if res.Code == http.StatusOK || res.Message != "不能授予管理全部项目的权限" {
+		container.DB.Unscoped().Where("keyword = ?", "e2e_escalate").Delete(&model.Role{})
+		t.Fatalf("受项目限制的用户不应能创建可管理全部项目的角色, code: %d, message: %s", res.Code, res.Message)
+	}
+
+	// 设置为可管理全部项目的角色不限制
+	all := createRole(t, "e2e_all_projects", 10, []string{"/project", "GET"})
+	if err := container.DB.Model(all).Update("all_projects", 1).Error; err != nil {
+		t.Fatalf("设置角色可管理全部项目失败: %v", err)
+	}
+	chief := createAdmin(t, "e2e_all_projects", "123456", all.Keyword)
+	var total int64
+	if err := container.DB.Model(&model.Project{}).Count(&total).Error; err != nil {
+		t.Fatalf("统计项目失败: %v", err)
+	}
+	login(t, chief.Username, "123456").do(t, http.MethodGet, "/api/project", nil).ok(t).decode(t, &projects)
+	if int64(len(projects.Projects)) != total {
+		t.Fatalf("可管理全部项目的用户查询到%d个项目, 期望%d个", len(projects.Projects), total)
+	}
+}
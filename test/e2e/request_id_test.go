@@ -0,0 +1,67 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/pkg/api/known"
+)
+
+// 携带X-Request-ID请求健康检查, 返回响应头与响应体中的请求ID
+func requestWithID(t *testing.T, requestID string) (string, string) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	if requestID != "" {
+		req.Header.Set(known.X_REQUEST_ID_KEY, requestID)
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	var res result
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("响应不是json: %v", err)
+	}
+	return w.Header().Get(known.X_REQUEST_ID_KEY), res.RequestID
+}
+
+func TestRequestID(t *testing.T) {
+	header, body := requestWithID(t, "support-ticket-42")
+	if header != "support-ticket-42" || body != "support-ticket-42" {
+		t.Fatalf("应沿用传入的请求ID, 响应头: %q, 响应体: %q", header, body)
+	}
+
+	// 未传入或不合法时生成新的ID
+	for _, requestID := range []string{"", "bad id\nwith newline"} {
+		header, body := requestWithID(t, requestID)
+		if header == "" || header == requestID || header != body {
+			t.Fatalf("传入%q时应生成新的请求ID, 响应头: %q, 响应体: %q", requestID, header, body)
+		}
+	}
+}
+
+func TestOperationLogFilterByRequestID(t *testing.T) {
+	log := model.OperationLog{RequestID: "e2e-request-id", Username: adminUsername, Method: http.MethodGet, Path: "/post", Status: http.StatusOK}
+	if err := container.DB.Create(&log).Error; err != nil {
+		t.Fatalf("写入操作日志失败: %v", err)
+	}
+
+	res := login(t, adminUsername, adminPassword).do(t, http.MethodGet, "/api/log/operation/list?requestId=e2e-request-id", nil).ok(t)
+	var data struct {
+		Logs  []model.OperationLog `json:"logs"`
+		Total int64                `json:"total"`
+	}
+	res.decode(t, &data)
+	if data.Total != 1 || data.Logs[0].ID != log.ID {
+		t.Fatalf("按请求ID应查到1条日志, 实际: %d", data.Total)
+	}
+	if res.RequestID == "" {
+		t.Fatal("响应体中缺少请求ID")
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gotribe-admin/internal/pkg/model"
+)
+
+func TestRoleInheritance(t *testing.T) {
+	ctx := context.Background()
+	root := login(t, adminUsername, adminPassword)
+
+	// 编辑拥有内容列表接口与一个菜单, 主编继承编辑并额外拥有项目列表接口
+	editor := createRole(t, "e2e_inherit_editor", 20, []string{"/post", "GET"})
+	chief := createRole(t, "e2e_inherit_chief", 15, []string{"/project", "GET"}, []string{"/menu/access/list/:userID", "GET"})
+	var menu model.Menu
+	if err := container.DB.Where("status = ?", 1).First(&menu).Error; err != nil {
+		t.Fatalf("获取菜单失败: %v", err)
+	}
+	editor.Menus = []*model.Menu{&menu}
+	if err := container.Repositories.Role.UpdateRoleMenus(ctx, editor); err != nil {
+		t.Fatalf("设置角色菜单失败: %v", err)
+	}
+
+	admin := createAdmin(t, "e2e_chief", "123456", chief.Keyword)
+	c := login(t, admin.Username, "123456")
+	if res := c.do(t, http.MethodGet, "/api/post", nil); res.Status != http.StatusUnauthorized {
+		t.Fatalf("继承前访问内容列表status为%d, 期望%d", res.Status, http.StatusUnauthorized)
+	}
+
+	root.do(t, http.MethodPatch, fmt.Sprintf("/api/role/parents/update/%d", chief.ID), map[string]interface{}{
+		"parentIds": []uint{editor.ID},
+	}).ok(t)
+
+	// 继承上级角色的接口与菜单
+	c.do(t, http.MethodGet, "/api/post", nil).ok(t)
+	c.do(t, http.MethodGet, "/api/project", nil).ok(t)
+	var access struct {
+		Menus []*model.Menu `json:"menus"`
+	}
+	c.do(t, http.MethodGet, fmt.Sprintf("/api/menu/access/list/%d", admin.ID), nil).ok(t).decode(t, &access)
+	if len(access.Menus) != 1 || access.Menus[0].ID != menu.ID {
+		t.Fatalf("可访问菜单为%+v, 期望继承菜单%d", access.Menus, menu.ID)
+	}
+
+	// 不能循环继承
+	res := root.do(t, http.MethodPatch, fmt.Sprintf("/api/role/parents/update/%d", editor.ID), map[string]interface{}{
+		"parentIds": []uint{chief.ID},
+	})
+	if res.Status != http.StatusBadRequest || !strings.Contains(res.Message, "循环继承") {
+		t.Fatalf("循环继承时status为%d, message为%q", res.Status, res.Message)
+	}
+
+	// 有效权限标明来源
+	var data struct {
+		Ancestors   []*model.Role `json:"ancestors"`
+		Permissions struct {
+			Apis []struct {
+				Method  string `json:"method"`
+				Path    string `json:"path"`
+				Sources []struct {
+					Keyword   string `json:"keyword"`
+					Inherited bool   `json:"inherited"`
+				} `json:"sources"`
+			} `json:"apis"`
+			Menus []struct {
+				ID uint `json:"id"`
+			} `json:"menus"`
+		} `json:"permissions"`
+	}
+	root.do(t, http.MethodGet, fmt.Sprintf("/api/role/permissions/get/%d", chief.ID), nil).ok(t).decode(t, &data)
+	if len(data.Ancestors) != 1 || data.Ancestors[0].ID != editor.ID {
+		t.Fatalf("上级角色为%+v, 期望%s", data.Ancestors, editor.Keyword)
+	}
+	sources := make(map[string]string)
+	for _, api := range data.Permissions.Apis {
+		for _, source := range api.Sources {
+			sources[api.Method+" "+api.Path] = fmt.Sprintf("%s/%v", source.Keyword, source.Inherited)
+		}
+	}
+	if sources["GET /post"] != editor.Keyword+"/true" || sources["GET /project"] != chief.Keyword+"/false" {
+		t.Fatalf("接口权限来源为%v", sources)
+	}
+	if len(data.Permissions.Menus) != 1 || data.Permissions.Menus[0].ID != menu.ID {
+		t.Fatalf("菜单权限为%+v, 期望继承菜单%d", data.Permissions.Menus, menu.ID)
+	}
+
+	// 取消继承后立即失去上级角色的权限
+	root.do(t, http.MethodPatch, fmt.Sprintf("/api/role/parents/update/%d", chief.ID), map[string]interface{}{
+		"parentIds": []uint{},
+	}).ok(t)
+	if res := c.do(t, http.MethodGet, "/api/post", nil); res.Status != http.StatusUnauthorized {
+		t.Fatalf("取消继承后访问内容列表status为%d, 期望%d", res.Status, http.StatusUnauthorized)
+	}
+}
+
+// 删除父角色后, 子角色立即失去经由父角色继承的权限
+func TestRoleInheritanceParentDeleted(t *testing.T) {
+	ctx := context.Background()
+	root := login(t, adminUsername, adminPassword)
+
+	// 父角色自身没有接口权限, 只继承上级角色的权限
+	grandparent := createRole(t, "e2e_deleted_grandparent", 25, []string{"/post", "GET"})
+	parent := &model.Role{Name: "e2e_deleted_parent", Keyword: "e2e_deleted_parent", Status: 1, Sort: 20, Creator: "e2e"}
+	if err := container.Repositories.Role.CreateRole(ctx, parent); err != nil {
+		t.Fatalf("创建角色失败: %v", err)
+	}
+	child := createRole(t, "e2e_deleted_child", 15, []string{"/project", "GET"})
+	root.do(t, http.MethodPatch, fmt.Sprintf("/api/role/parents/update/%d", parent.ID), map[string]interface{}{
+		"parentIds": []uint{grandparent.ID},
+	}).ok(t)
+	root.do(t, http.MethodPatch, fmt.Sprintf("/api/role/parents/update/%d", child.ID), map[string]interface{}{
+		"parentIds": []uint{parent.ID},
+	}).ok(t)
+
+	admin := createAdmin(t, "e2e_deleted_child", "123456", child.Keyword)
+	c := login(t, admin.Username, "123456")
+	c.do(t, http.MethodGet, "/api/post", nil).ok(t)
+
+	root.do(t, http.MethodDelete, "/api/role/delete/batch", map[string]interface{}{
+		"roleIds": []uint{parent.ID},
+	}).ok(t)
+	if res := c.do(t, http.MethodGet, "/api/post", nil); res.Status != http.StatusUnauthorized {
+		t.Fatalf("删除父角色后访问内容列表status为%d, 期望%d", res.Status, http.StatusUnauthorized)
+	}
+	c.do(t, http.MethodGet, "/api/project", nil).ok(t)
+}
+
+// 禁用父角色后, 子角色立即失去父角色及其上级角色的接口与菜单, 重新启用后恢复
+func TestRoleInheritanceParentDisabled(t *testing.T) {
+	ctx := context.Background()
+	root := login(t, adminUsername, adminPassword)
+
+	grandparent := createRole(t, "e2e_disabled_grandparent", 25, []string{"/tag", "GET"})
+	parent := createRole(t, "e2e_disabled_parent", 20, []string{"/post", "GET"})
+	child := createRole(t, "e2e_disabled_child", 15, []string{"/project", "GET"}, []string{"/menu/access/list/:userID", "GET"})
+	var menu model.Menu
+	if err := container.DB.Where("status = ?", 1).First(&menu).Error; err != nil {
+		t.Fatalf("获取菜单失败: %v", err)
+	}
+	parent.Menus = []*model.Menu{&menu}
+	if err := container.Repositories.Role.UpdateRoleMenus(ctx, parent); err != nil {
+		t.Fatalf("设置角色菜单失败: %v", err)
+	}
+	root.do(t, http.MethodPatch, fmt.Sprintf("/api/role/parents/update/%d", parent.ID), map[string]interface{}{
+		"parentIds": []uint{grandparent.ID},
+	}).ok(t)
+	root.do(t, http.MethodPatch, fmt.Sprintf("/api/role/parents/update/%d", child.ID), map[string]interface{}{
+		"parentIds": []uint{parent.ID},
+	}).ok(t)
+
+	admin := createAdmin(t, "e2e_disabled_child", "123456", child.Keyword)
+	c := login(t, admin.Username, "123456")
+	menus := func() []*model.Menu {
+		t.Helper()
+		var access struct {
+			Menus []*model.Menu `json:"menus"`
+		}
+		c.do(t, http.MethodGet, fmt.Sprintf("/api/menu/access/list/%d", admin.ID), nil).ok(t).decode(t, &access)
+		return access.Menus
+	}
+	setStatus := func(status uint) {
+		t.Helper()
+		root.do(t, http.MethodPatch, fmt.Sprintf("/api/role/update/%d", parent.ID), map[string]interface{}{
+			"name":    parent.Name,
+			"keyword": parent.Keyword,
+			"status":  status,
+			"sort":    parent.Sort,
+		}).ok(t)
+	}
+	c.do(t, http.MethodGet, "/api/post", nil).ok(t)
+	c.do(t, http.MethodGet, "/api/tag", nil).ok(t)
+	if got := menus(); len(got) != 1 || got[0].ID != menu.ID {
+		t.Fatalf("可访问菜单为%+v, 期望继承菜单%d", got, menu.ID)
+	}
+
+	setStatus(2)
+	for _, path := range []string{"/api/post", "/api/tag"} {
+		if res := c.do(t, http.MethodGet, path, nil); res.Status != http.StatusUnauthorized {
+			t.Fatalf("禁用父角色后访问%s status为%d, 期望%d", path, res.Status, http.StatusUnauthorized)
+		}
+	}
+	c.do(t, http.MethodGet, "/api/project", nil).ok(t)
+	if got := menus(); len(got) != 0 {
+		t.Fatalf("禁用父角色后可访问菜单为%+v, 期望为空", got)
+	}
+	// 暂停的继承关系仍显示在父角色列表中
+	var parents struct {
+		Parents []*model.Role `json:"parents"`
+	}
+	root.do(t, http.MethodGet, fmt.Sprintf("/api/role/parents/get/%d", child.ID), nil).ok(t).decode(t, &parents)
+	if len(parents.Parents) != 1 || parents.Parents[0].ID != parent.ID {
+		t.Fatalf("父角色为%+v, 期望%s", parents.Parents, parent.Keyword)
+	}
+
+	setStatus(1)
+	c.do(t, http.MethodGet, "/api/post", nil).ok(t)
+	c.do(t, http.MethodGet, "/api/tag", nil).ok(t)
+	if got := menus(); len(got) != 1 || got[0].ID != menu.ID {
+		t.Fatalf("重新启用父角色后可访问菜单为%+v, 期望继承菜单%d", got, menu.ID)
+	}
+}
+
+// 创建拥有指定接口权限的角色, 测试结束后删除
+func createRole(t *testing.T, keyword string, sort uint, apis ...[]string) *model.Role {
+	t.Helper()
+	role := &model.Role{Name: keyword, Keyword: keyword, Status: 1, Sort: sort, Creator: "e2e"}
+	if err := container.Repositories.Role.CreateRole(context.Background(), role); err != nil {
+		t.Fatalf("创建角色%s失败: %v", keyword, err)
+	}
+	if len(apis) > 0 {
+		policies := make([][]string, 0, len(apis))
+		for _, api := range apis {
+			policies = append(policies, []string{keyword, api[0], api[1]})
+		}
+		if _, err := container.Enforcer.AddPolicies(policies); err != nil {
+			t.Fatalf("添加角色%s的权限失败: %v", keyword, err)
+		}
+	}
+	t.Cleanup(func() {
+		if err := container.Repositories.Role.BatchDeleteRoleByIds(context.Background(), []uint{role.ID}); err != nil {
+			t.Errorf("删除角色%s失败: %v", keyword, err)
+		}
+	})
+	return role
+}
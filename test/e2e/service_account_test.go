@@ -0,0 +1,187 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gotribe-admin/internal/pkg/apikey"
+	"gotribe-admin/internal/pkg/model"
+	"gotribe-admin/pkg/api/known"
+)
+
+// 创建API Key, 返回明文
+func createApiKey(t *testing.T, c *client, accountID uint, body map[string]interface{}) (string, model.ApiKey) {
+	t.Helper()
+	var data struct {
+		ApiKey model.ApiKey `json:"apiKey"`
+		Key    string       `json:"key"`
+	}
+	c.do(t, http.MethodPost, fmt.Sprintf("/api/serviceAccount/key/create/%d", accountID), body).ok(t).decode(t, &data)
+	if data.Key == "" || !strings.HasPrefix(data.Key, data.ApiKey.Prefix) {
+		t.Fatalf("API Key明文不正确: %q, 前缀: %q", data.Key, data.ApiKey.Prefix)
+	}
+	return data.Key, data.ApiKey
+}
+
+// 断言API Key认证失败, 返回响应信息
+func apiKeyDenied(t *testing.T, key string, method, path string) string {
+	t.Helper()
+	res := (&client{apiKey: key}).do(t, method, path, nil)
+	if res.Status != http.StatusUnauthorized {
+		t.Fatalf("%s %s 应返回401, 实际: %d %s", method, path, res.Status, res.Message)
+	}
+	return res.Message
+}
+
+func TestServiceAccount(t *testing.T) {
+	ctx := context.Background()
+	root := login(t, adminUsername, adminPassword)
+	// 先以超级管理员请求一次, 使其进入用户信息缓存
+	root.do(t, http.MethodGet, "/api/post?pageNum=1&pageSize=10", nil).ok(t)
+
+	editor := createRole(t, "e2e_sa_editor", 20, []string{"/post", "GET"})
+
+	// 与超级管理员同名的服务账号也只有绑定角色的权限
+	var created struct {
+		ServiceAccount model.ServiceAccount `json:"serviceAccount"`
+	}
+	root.do(t, http.MethodPost, "/api/serviceAccount/create", map[string]interface{}{
+		"name": adminUsername, "desc": "CI发布", "roleID": editor.ID, "status": 1,
+	}).ok(t).decode(t, &created)
+	account := created.ServiceAccount
+	t.Cleanup(func() {
+		if err := container.Repositories.ServiceAccount.BatchDeleteServiceAccountByIds(ctx, []uint{account.ID}); err != nil {
+			t.Errorf("删除服务账号失败: %v", err)
+		}
+	})
+
+	key, apiKey := createApiKey(t, root, account.ID, map[string]interface{}{
+		"name": "ci", "expiresAt": time.Now().Add(time.Hour).Format(known.TIME_FORMAT),
+	})
+	ci := &client{apiKey: key}
+
+	// 绑定角色的权限参与casbin鉴权
+	ci.do(t, http.MethodGet, "/api/post?pageNum=1&pageSize=10", nil).ok(t)
+	if msg := apiKeyDenied(t, key, http.MethodGet, "/api/serviceAccount/list"); msg != "没有权限" {
+		t.Fatalf("服务账号访问未授权接口的响应为%q", msg)
+	}
+	// Authorization: ApiKey <key> 同样可用
+	req := httptest.NewRequest(http.MethodGet, "/api/post?pageNum=1&pageSize=10", nil)
+	req.Header.Set("Authorization", "ApiKey "+key)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("以Authorization携带API Key应返回200, 实际: %d %s", w.Code, w.Body.String())
+	}
+
+	// 记录最近使用时间与IP
+	used, err := container.Repositories.ServiceAccount.GetApiKeyByID(ctx, apiKey.ID)
+	if err != nil {
+		t.Fatalf("获取API Key失败: %v", err)
+	}
+	if used.LastUsedAt == nil || used.LastUsedIP != "192.0.2.1" || used.KeyHash != apikey.Hash(key) {
+		t.Fatalf("API Key使用记录不正确: %+v", used)
+	}
+
+	// 无效的API Key
+	apiKeyDenied(t, "gt_invalid", http.MethodGet, "/api/post")
+	apiKeyDenied(t, key[:len(key)-4]+"AAAA", http.MethodGet, "/api/post")
+
+	// IP白名单
+	restricted, _ := createApiKey(t, root, account.ID, map[string]interface{}{"allowIPs": []string{"10.0.0.0/8"}})
+	if msg := apiKeyDenied(t, restricted, http.MethodGet, "/api/post"); !strings.Contains(msg, "白名单") {
+		t.Fatalf("白名单外IP的响应为%q", msg)
+	}
+	allowed, _ := createApiKey(t, root, account.ID, map[string]interface{}{"allowIPs": []string{"192.0.2.0/24"}})
+	(&client{apiKey: allowed}).do(t, http.MethodGet, "/api/post?pageNum=1&pageSize=10", nil).ok(t)
+	// 经可信代理转发时按X-Forwarded-For中的客户端IP匹配白名单, 其他来源伪造的X-Forwarded-For无效
+	(&client{apiKey: restricted, ip: trustedProxy, xff: "10.1.2.3"}).do(t, http.MethodGet, "/api/post?pageNum=1&pageSize=10", nil).ok(t)
+	if res := (&client{apiKey: restricted, ip: trustedProxy, xff: "198.51.100.41"}).do(t, http.MethodGet, "/api/post", nil); res.Status != http.StatusUnauthorized || !strings.Contains(res.Message, "白名单") {
+		t.Fatalf("可信代理转发的白名单外IP的响应为%d %q", res.Status, res.Message)
+	}
+	if res := (&client{apiKey: restricted, ip: "198.51.100.40", xff: "10.1.2.3"}).do(t, http.MethodGet, "/api/post", nil); res.Status != http.StatusUnauthorized || !strings.Contains(res.Message, "白名单") {
+		t.Fatalf("伪造X-Forwarded-For的响应为%d %q", res.Status, res.Message)
+	}
+	if res := root.do(t, http.MethodPost, fmt.Sprintf("/api/serviceAccount/key/create/%d", account.ID), map[string]interface{}{"allowIPs": []string{"not-an-ip"}}); res.Code == http.StatusOK {
+		t.Fatal("格式不正确的IP白名单应创建失败")
+	}
+
+	// 已过期的API Key
+	expiredKey, prefix, hash, err := apikey.Generate()
+	if err != nil {
+		t.Fatalf("生成API Key失败: %v", err)
+	}
+	past := time.Now().Add(-time.Minute)
+	expired := model.ApiKey{ServiceAccountID: account.ID, Prefix: prefix, KeyHash: hash, ExpiresAt: &past, Creator: "e2e"}
+	if err := container.Repositories.ServiceAccount.CreateApiKey(ctx, &expired); err != nil {
+		t.Fatalf("创建API Key失败: %v", err)
+	}
+	if msg := apiKeyDenied(t, expiredKey, http.MethodGet, "/api/post"); !strings.Contains(msg, "过期") {
+		t.Fatalf("过期API Key的响应为%q", msg)
+	}
+
+	// 吊销
+	root.do(t, http.MethodPatch, fmt.Sprintf("/api/serviceAccount/key/revoke/%d", apiKey.ID), nil).ok(t)
+	if msg := apiKeyDenied(t, key, http.MethodGet, "/api/post"); !strings.Contains(msg, "吊销") {
+		t.Fatalf("已吊销API Key的响应为%q", msg)
+	}
+
+	// 禁用服务账号后其全部API Key不可用
+	root.do(t, http.MethodPatch, fmt.Sprintf("/api/serviceAccount/update/%d", account.ID), map[string]interface{}{
+		"name": adminUsername, "roleID": editor.ID, "status": 2,
+	}).ok(t)
+	if msg := apiKeyDenied(t, allowed, http.MethodGet, "/api/post"); !strings.Contains(msg, "禁用") {
+		t.Fatalf("禁用服务账号的响应为%q", msg)
+	}
+
+	// 不能创建绑定比自己等级高或相同等级角色的服务账号
+	chief := createRole(t, "e2e_sa_chief", 10, []string{"/serviceAccount/create", "POST"})
+	admin := createAdmin(t, "e2e_sa_admin", "123456", chief.Keyword)
+	res := login(t, admin.Username, "123456").do(t, http.MethodPost, "/api/serviceAccount/create", map[string]interface{}{
+		"name": "e2e_sa_chief", "roleID": chief.ID, "status": 1,
+	})
+	if res.Code == http.StatusOK {
+		t.Fatal("不应能创建与自己等级相同的服务账号")
+	}
+}
+
+func TestServiceAccountKeyNotLogged(t *testing.T) {
+	ctx := context.Background()
+	root := login(t, adminUsername, adminPassword)
+	editor := createRole(t, "e2e_sa_log_editor", 20, []string{"/post", "GET"})
+
+	var created struct {
+		ServiceAccount model.ServiceAccount `json:"serviceAccount"`
+	}
+	root.do(t, http.MethodPost, "/api/serviceAccount/create", map[string]interface{}{
+		"name": "e2e_sa_log", "roleID": editor.ID, "status": 1,
+	}).ok(t).decode(t, &created)
+	account := created.ServiceAccount
+	t.Cleanup(func() {
+		if err := container.Repositories.ServiceAccount.BatchDeleteServiceAccountByIds(ctx, []uint{account.ID}); err != nil {
+			t.Errorf("删除服务账号失败: %v", err)
+		}
+	})
+
+	var data struct {
+		Key string `json:"key"`
+	}
+	res := root.do(t, http.MethodPost, fmt.Sprintf("/api/serviceAccount/key/create/%d", account.ID), map[string]interface{}{"name": "log"}).ok(t)
+	res.decode(t, &data)
+
+	// 操作日志中不能出现API Key明文
+	log := operationLogByRequestID(t, res.RequestID)
+	if data.Key == "" || strings.Contains(log.RequestBody, data.Key) || strings.Contains(log.ResponseBody, data.Key) {
+		t.Fatalf("操作日志不应记录API Key明文, 请求体: %s, 响应体: %s", log.RequestBody, log.ResponseBody)
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package e2e
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"gotribe-admin/internal/pkg/totp"
+)
+
+// 密码登录需要两步验证时的响应
+type totpChallenge struct {
+	Token          string `json:"token"`
+	TotpRequired   bool   `json:"totpRequired"`
+	TotpEnabled    bool   `json:"totpEnabled"`
+	ChallengeToken string `json:"challengeToken"`
+}
+
+// 绑定验证器的响应
+type totpEnrollment struct {
+	Secret        string   `json:"secret"`
+	URI           string   `json:"uri"`
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// 密码登录, 断言需要两步验证
+func passwordStep(t *testing.T, username, password string, enabled bool) string {
+	t.Helper()
+	var data totpChallenge
+	postLogin(t, username, password).ok(t).decode(t, &data)
+	if data.Token != "" || !data.TotpRequired || data.TotpEnabled != enabled || data.ChallengeToken == "" {
+		t.Fatalf("密码登录应返回两步验证challenge: %+v", data)
+	}
+	return data.ChallengeToken
+}
+
+// 提交验证码登录
+func totpStep(t *testing.T, challenge, code string) *result {
+	t.Helper()
+	return anonymous().do(t, http.MethodPost, "/api/base/login/totp", map[string]string{"challengeToken": challenge, "code": code})
+}
+
+// 以验证码登录成功后的客户端
+func totpLogin(t *testing.T, challenge, code string) *client {
+	t.Helper()
+	var data struct {
+		Token string `json:"token"`
+	}
+	totpStep(t, challenge, code).ok(t).decode(t, &data)
+	if data.Token == "" {
+		t.Fatal("两步验证登录响应中缺少token")
+	}
+	return &client{token: data.Token}
+}
+
+// 指定周期的验证码
+func totpCode(t *testing.T, secret string, step int64) string {
+	t.Helper()
+	code, err := totp.Code(secret, step)
+	if err != nil {
+		t.Fatalf("生成验证码失败: %v", err)
+	}
+	return code
+}
+
+func TestTotpCode(t *testing.T) {
+	// RFC 6238 附录B的SHA1测试向量, 密钥为"12345678901234567890"
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	for unix, want := range map[int64]string{59: "287082", 1111111109: "081804", 1234567890: "005924", 2000000000: "279037"} {
+		if code := totpCode(t, secret, totp.Step(time.Unix(unix, 0))); code != want {
+			t.Fatalf("T=%d的验证码为%s, 期望%s", unix, code, want)
+		}
+	}
+}
+
+func TestTotpLogin(t *testing.T) {
+	admin := createAdmin(t, "e2e_totp", "123456", "user")
+	c := login(t, admin.Username, "123456")
+
+	// 绑定后未启用前仍只需密码登录
+	var enrollment totpEnrollment
+	c.do(t, http.MethodPost, "/api/admin/totp/enroll", nil).ok(t).decode(t, &enrollment)
+	if !strings.HasPrefix(enrollment.URI, "otpauth://totp/") || !strings.Contains(enrollment.URI, "secret="+enrollment.Secret) ||
+		len(enrollment.RecoveryCodes) != totp.RecoveryCodeCount {
+		t.Fatalf("绑定结果不正确: %+v", enrollment)
+	}
+	login(t, admin.Username, "123456")
+
+	// 启用
+	if res := c.do(t, http.MethodPost, "/api/admin/totp/activate", map[string]string{"code": "000000"}); res.Code == http.StatusOK {
+		t.Fatal("错误的验证码不应启用两步验证")
+	}
+	step := totp.Step(time.Now())
+	c.do(t, http.MethodPost, "/api/admin/totp/activate", map[string]string{"code": totpCode(t, enrollment.Secret, step)}).ok(t)
+
+	// 密码登录返回challenge, 已使用的验证码不能再次使用
+	challenge := passwordStep(t, admin.Username, "123456", true)
+	if res := totpStep(t, challenge, totpCode(t, enrollment.Secret, step)); res.Status != http.StatusUnauthorized {
+		t.Fatalf("重复使用的验证码应返回401, 实际: %d %s", res.Status, res.Message)
+	}
+	c = totpLogin(t, challenge, totpCode(t, enrollment.Secret, step+1))
+	if res := c.do(t, http.MethodPost, "/api/admin/totp/enroll", nil); !strings.Contains(res.Message, "已启用") {
+		t.Fatalf("已启用时重新绑定的响应为%q", res.Message)
+	}
+
+	// challenge无效或被篡改
+	if res := totpStep(t, "1.9999999999.invalid", "123456"); res.Status != http.StatusUnauthorized {
+		t.Fatalf("无效challenge应返回401, 实际: %d", res.Status)
+	}
+	if res := totpStep(t, strings.Replace(challenge, fmt.Sprintf("%d.", admin.ID), "1.", 1), totpCode(t, enrollment.Secret, step+1)); res.Status != http.StatusUnauthorized {
+		t.Fatalf("篡改的challenge应返回401, 实际: %d", res.Status)
+	}
+
+	// 恢复码只能使用一次
+	recovery := enrollment.RecoveryCodes[0]
+	totpLogin(t, passwordStep(t, admin.Username, "123456", true), strings.ToUpper(recovery))
+	if res := totpStep(t, passwordStep(t, admin.Username, "123456", true), recovery); res.Status != http.StatusUnauthorized {
+		t.Fatalf("已使用的恢复码应返回401, 实际: %d", res.Status)
+	}
+
+	// 关闭后只需密码登录
+	c.do(t, http.MethodPost, "/api/admin/totp/disable", map[string]string{"code": enrollment.RecoveryCodes[1]}).ok(t)
+	login(t, admin.Username, "123456")
+}
+
+func TestTotpRequiredByRole(t *testing.T) {
+	role := createRole(t, "e2e_totp_required", 20, []string{"/admin/totp/disable", "POST"})
+	if err := container.DB.Model(role).Update("totp_required", 1).Error; err != nil {
+		t.Fatalf("设置角色要求两步验证失败: %v", err)
+	}
+	admin := createAdmin(t, "e2e_totp_role", "123456", role.Keyword)
+
+	// 未绑定时在登录过程中绑定, 第一次校验通过后启用并签发token
+	challenge := passwordStep(t, admin.Username, "123456", false)
+	if res := totpStep(t, challenge, "000000"); res.Code == http.StatusOK {
+		t.Fatal("未绑定时不应登录成功")
+	}
+	var enrollment totpEnrollment
+	anonymous().do(t, http.MethodPost, "/api/base/login/totp/enroll", map[string]string{"challengeToken": challenge}).ok(t).decode(t, &enrollment)
+	step := totp.Step(time.Now())
+	c := totpLogin(t, challenge, totpCode(t, enrollment.Secret, step))
+
+	// 已启用后不能通过challenge重新绑定, 角色要求时不能关闭
+	challenge = passwordStep(t, admin.Username, "123456", true)
+	if res := anonymous().do(t, http.MethodPost, "/api/base/login/totp/enroll", map[string]string{"challengeToken": challenge}); res.Code == http.StatusOK {
+		t.Fatal("已启用时不应能重新绑定")
+	}
+	if res := c.do(t, http.MethodPost, "/api/admin/totp/disable", map[string]string{"code": enrollment.RecoveryCodes[0]}); !strings.Contains(res.Message, "角色要求") {
+		t.Fatalf("角色要求时关闭的响应为%q", res.Message)
+	}
+
+	// 超级管理员重置后重新绑定
+	login(t, adminUsername, adminPassword).do(t, http.MethodPatch, fmt.Sprintf("/api/admin/totp/reset/%d", admin.ID), nil).ok(t)
+	passwordStep(t, admin.Username, "123456", false)
+}
+
+func TestTotpNotLogged(t *testing.T) {
+	role := createRole(t, "e2e_totp_log", 20, []string{"/admin/totp/enroll", "POST"})
+	if err := container.DB.Model(role).Update("totp_required", 1).Error; err != nil {
+		t.Fatalf("设置角色要求两步验证失败: %v", err)
+	}
+	admin := createAdmin(t, "e2e_totp_log", "123456", role.Keyword)
+
+	// 操作日志中不能出现challenge、密钥、恢复码与验证码
+	notLogged := func(res *result, secrets ...string) {
+		t.Helper()
+		log := operationLogByRequestID(t, res.RequestID)
+		for _, secret := range secrets {
+			if strings.Contains(log.RequestBody, secret) || strings.Contains(log.ResponseBody, secret) {
+				t.Fatalf("%s 的操作日志不应记录凭证, 请求体: %s, 响应体: %s", log.Path, log.RequestBody, log.ResponseBody)
+			}
+		}
+	}
+
+	res := postLogin(t, admin.Username, "123456").ok(t)
+	var challenge totpChallenge
+	res.decode(t, &challenge)
+	notLogged(res, challenge.ChallengeToken)
+
+	res = anonymous().do(t, http.MethodPost, "/api/base/login/totp/enroll", map[string]string{"challengeToken": challenge.ChallengeToken}).ok(t)
+	var enrollment totpEnrollment
+	res.decode(t, &enrollment)
+	notLogged(res, append([]string{challenge.ChallengeToken, enrollment.Secret}, enrollment.RecoveryCodes...)...)
+
+	code := totpCode(t, enrollment.Secret, totp.Step(time.Now()))
+	res = totpStep(t, challenge.ChallengeToken, code).ok(t)
+	notLogged(res, challenge.ChallengeToken, code)
+
+	// 已登录用户重新绑定
+	var data struct {
+		Token string `json:"token"`
+	}
+	res.decode(t, &data)
+	c := &client{token: data.Token}
+	login(t, adminUsername, adminPassword).do(t, http.MethodPatch, fmt.Sprintf("/api/admin/totp/reset/%d", admin.ID), nil).ok(t)
+	res = c.do(t, http.MethodPost, "/api/admin/totp/enroll", nil).ok(t)
+	res.decode(t, &enrollment)
+	notLogged(res, append([]string{enrollment.Secret}, enrollment.RecoveryCodes...)...)
+}
@@ -0,0 +1,122 @@
+// Copyright 2023 Innkeeper gotribe <info@gotribe.cn>. All rights reserved.
+// Use of this source code is governed by a Apache style
+// license that can be found in the LICENSE file. The original repo for
+// this file is https://www.gotribe.cn
+
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotribe-admin/pkg/util/upload"
+)
+
+// 构造multipart上传请求体
+func multipartFile(t *testing.T, filename string, content []byte) (*bytes.Buffer, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	part, err := w.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatalf("创建上传文件失败: %v", err)
+	}
+	part.Write(content)
+	w.Close()
+	return &buf, w.FormDataContentType()
+}
+
+// 构造上传文件头
+func fileHeader(t *testing.T, filename string, content []byte) *multipart.FileHeader {
+	t.Helper()
+	body, contentType := multipartFile(t, filename, content)
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", contentType)
+	if err := req.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatalf("解析上传文件失败: %v", err)
+	}
+	return req.MultipartForm.File["file"][0]
+}
+
+func TestLocalUploaderRejectsEscape(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "uploads")
+	secret := filepath.Join(base, "secret.txt")
+	if err := os.WriteFile(secret, []byte("secret"), 0644); err != nil {
+		t.Fatalf("写入文件失败: %v", err)
+	}
+	u := upload.NewLocal(root, "/uploads")
+
+	for _, key := range []string{"", "../secret.txt", "/../secret.txt", "a/../../secret.txt", `..\secret.txt`} {
+		if err := u.DeleteFile(key); err == nil {
+			t.Fatalf("删除越出保存目录的key %q 应失败", key)
+		}
+		if _, err := u.PutFile(context.Background(), key, []byte("x")); err == nil {
+			t.Fatalf("写入越出保存目录的key %q 应失败", key)
+		}
+	}
+	if data, err := os.ReadFile(secret); err != nil || string(data) != "secret" {
+		t.Fatalf("保存目录外的文件不应被修改: %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(base, "x")); !os.IsNotExist(err) {
+		t.Fatalf("不应在保存目录外创建文件: %v", err)
+	}
+
+	// 扩展名中带路径分隔符的文件名
+	if _, err := u.UploadFile(fileHeader(t, `a.\..\..\evil`, []byte("x"))); err == nil {
+		t.Fatal("扩展名中带路径分隔符的文件应上传失败")
+	}
+
+	// 目录内的key正常写入与删除
+	res, err := u.PutFile(context.Background(), "/archive/../logs/a.txt", []byte("a"))
+	if err != nil || res.Key != "logs/a.txt" {
+		t.Fatalf("写入文件失败: %+v, %v", res, err)
+	}
+	if err := u.DeleteFile(res.Key); err != nil {
+		t.Fatalf("删除文件失败: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "logs", "a.txt")); !os.IsNotExist(err) {
+		t.Fatalf("文件应已删除: %v", err)
+	}
+}
+
+func TestLocalUploadServedByStaticRoute(t *testing.T) {
+	c := login(t, adminUsername, adminPassword)
+	content := []byte("gotribe upload")
+	body, contentType := multipartFile(t, "hello.txt", content)
+	req := httptest.NewRequest(http.MethodPost, "/api/resource/upload", body)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	res := &result{Status: w.Code}
+	if err := json.Unmarshal(w.Body.Bytes(), res); err != nil {
+		t.Fatalf("上传响应不是json: %v, body: %s", err, w.Body.String())
+	}
+	res.ok(t)
+	var data struct {
+		Upload struct {
+			Key    string `json:"key"`
+			Domain string `json:"domain"`
+		} `json:"upload"`
+	}
+	res.decode(t, &data)
+	if data.Upload.Key == "" || data.Upload.Domain != "/uploads/" {
+		t.Fatalf("上传结果不正确: %+v", data.Upload)
+	}
+
+	// 通过静态路由访问上传的文件
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, data.Upload.Domain+data.Upload.Key, nil))
+	if w.Code != http.StatusOK || w.Body.String() != string(content) {
+		t.Fatalf("静态路由应返回上传的文件, status: %d, body: %s", w.Code, w.Body.String())
+	}
+}